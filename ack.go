@@ -5,8 +5,11 @@
 package factom
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 )
 
 type FactoidTxStatus struct {
@@ -73,9 +76,15 @@ type Malleated struct {
 
 // EntryCommitACK takes the txid of the commit and searches for the entry/chain commit
 func EntryCommitACK(txID, fullTransaction string) (*EntryStatus, error) {
+	return EntryCommitACKWithContext(context.Background(), txID, fullTransaction)
+}
+
+// EntryCommitACKWithContext is like EntryCommitACK but cancels its requests
+// when ctx is done.
+func EntryCommitACKWithContext(ctx context.Context, txID, fullTransaction string) (*EntryStatus, error) {
 	params := ackRequest{Hash: txID, ChainID: "c", FullTransaction: fullTransaction}
 	req := NewJSON2Request("ack", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -92,9 +101,15 @@ func EntryCommitACK(txID, fullTransaction string) (*EntryStatus, error) {
 }
 
 func FactoidACK(txID, fullTransaction string) (*FactoidTxStatus, error) {
+	return FactoidACKWithContext(context.Background(), txID, fullTransaction)
+}
+
+// FactoidACKWithContext is like FactoidACK but cancels its requests when ctx is
+// done.
+func FactoidACKWithContext(ctx context.Context, txID, fullTransaction string) (*FactoidTxStatus, error) {
 	params := ackRequest{Hash: txID, ChainID: "f", FullTransaction: fullTransaction}
 	req := NewJSON2Request("ack", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -112,9 +127,15 @@ func FactoidACK(txID, fullTransaction string) (*FactoidTxStatus, error) {
 
 // EntryRevealACK will take the entryhash and search for the entry and the commit
 func EntryRevealACK(entryhash, fullTransaction, chainiID string) (*EntryStatus, error) {
+	return EntryRevealACKWithContext(context.Background(), entryhash, fullTransaction, chainiID)
+}
+
+// EntryRevealACKWithContext is like EntryRevealACK but cancels its requests
+// when ctx is done.
+func EntryRevealACKWithContext(ctx context.Context, entryhash, fullTransaction, chainiID string) (*EntryStatus, error) {
 	params := ackRequest{Hash: entryhash, ChainID: chainiID, FullTransaction: fullTransaction}
 	req := NewJSON2Request("ack", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -134,5 +155,95 @@ func EntryRevealACK(entryhash, fullTransaction, chainiID string) (*EntryStatus,
 // Use either EntryCommitAck or EntryRevealAck depending on the
 // type of hash you are sending.
 func EntryACK(entryhash, fullTransaction string) (*EntryStatus, error) {
-	return EntryRevealACK(entryhash, fullTransaction, "0000000000000000000000000000000000000000000000000000000000000000")
+	return EntryACKWithContext(context.Background(), entryhash, fullTransaction)
+}
+
+// EntryACKWithContext is like EntryACK but cancels its requests when ctx is
+// done.
+func EntryACKWithContext(ctx context.Context, entryhash, fullTransaction string) (*EntryStatus, error) {
+	return EntryRevealACKWithContext(ctx, entryhash, fullTransaction, "0000000000000000000000000000000000000000000000000000000000000000")
+}
+
+// The statuses reported by the ack calls, from the least to the most
+// confirmed.
+const (
+	AckStatusUnknown         = "Unknown"
+	AckStatusNotConfirmed    = "NotConfirmed"
+	AckStatusTransactionACK  = "TransactionACK"
+	AckStatusDBlockConfirmed = "DBlockConfirmed"
+)
+
+// ackReached reports whether status is at least as confirmed as want.
+func ackReached(status, want string) bool {
+	switch status {
+	case AckStatusDBlockConfirmed:
+		return true
+	case AckStatusTransactionACK:
+		return want != AckStatusDBlockConfirmed
+	}
+	return false
+}
+
+// WaitForConfirmation polls factomd every interval until both the commit and
+// the reveal of the entry entryhash in the chain chainID have reached the
+// status want, AckStatusTransactionACK or AckStatusDBlockConfirmed. It
+// returns the last status seen, with ctx.Err() if ctx is done first; use
+// context.WithTimeout to give up after a while.
+func WaitForConfirmation(ctx context.Context, entryhash, chainID, want string, interval time.Duration) (*EntryStatus, error) {
+	var status *EntryStatus
+	err := pollAck(ctx, interval, func() error {
+		s, err := EntryRevealACKWithContext(ctx, entryhash, "", chainID)
+		if err != nil {
+			return err
+		}
+		status = s
+		if ackReached(status.CommitData.Status, want) && ackReached(status.EntryData.Status, want) {
+			return nil
+		}
+		return errNotReached
+	})
+	return status, err
+}
+
+// WaitForFactoidConfirmation is like WaitForConfirmation for the factoid
+// transaction txID.
+func WaitForFactoidConfirmation(ctx context.Context, txID, want string, interval time.Duration) (*FactoidTxStatus, error) {
+	var status *FactoidTxStatus
+	err := pollAck(ctx, interval, func() error {
+		s, err := FactoidACKWithContext(ctx, txID, "")
+		if err != nil {
+			return err
+		}
+		status = s
+		if ackReached(status.Status, want) {
+			return nil
+		}
+		return errNotReached
+	})
+	return status, err
+}
+
+// errNotReached is returned by the checks of pollAck to poll again.
+var errNotReached = errors.New("status not reached")
+
+// pollAck calls check every interval until it returns nil or an error other
+// than errNotReached, or ctx is done.
+func pollAck(ctx context.Context, interval time.Duration, check func() error) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		err := check()
+		if err != nil && ctx.Err() != nil {
+			// the request was cut short by ctx
+			return ctx.Err()
+		}
+		if err != errNotReached {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
 }
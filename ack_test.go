@@ -5,10 +5,13 @@
 package factom_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	. "github.com/FactomProject/factom"
 )
@@ -152,3 +155,45 @@ Date: 2015-12-07 22:10:01
 		t.Fail()
 	}
 }
+
+func TestWaitForConfirmation(t *testing.T) {
+	// the entry is acknowledged on the second request and confirmed on the
+	// third
+	statuses := []string{"NotConfirmed", "TransactionACK", "DBlockConfirmed"}
+	acks := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[acks]
+		if acks < len(statuses)-1 {
+			acks++
+		}
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 0, "result": {"txid": "abc", "status": "%s", "commitdata": {"status": "%[1]s"}, "entrydata": {"status": "%[1]s"}}}`, status)
+	}))
+	defer ts.Close()
+	SetFactomdServer(ts.URL[7:])
+
+	ctx := context.Background()
+	s, err := WaitForConfirmation(ctx, "9228b4b080b3cf94cceea866b74c48319f2093f56bd5a63465288e9a71437ee8", "", AckStatusTransactionACK, time.Millisecond)
+	if err != nil || s.EntryData.Status != AckStatusTransactionACK || acks != 2 {
+		t.Errorf("got %v, %v after %d requests", s, err, acks)
+	}
+	s, err = WaitForConfirmation(ctx, "9228b4b080b3cf94cceea866b74c48319f2093f56bd5a63465288e9a71437ee8", "", AckStatusDBlockConfirmed, time.Millisecond)
+	if err != nil || s.CommitData.Status != AckStatusDBlockConfirmed {
+		t.Errorf("got %v, %v", s, err)
+	}
+
+	// a confirmed transaction also counts as acknowledged
+	f, err := WaitForFactoidConfirmation(ctx, "abc", AckStatusTransactionACK, time.Millisecond)
+	if err != nil || f.Status != AckStatusDBlockConfirmed {
+		t.Errorf("got %v, %v", f, err)
+	}
+
+	// the last status is returned when the context times out
+	acks = 0
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	statuses = []string{"NotConfirmed"}
+	s, err = WaitForConfirmation(ctx, "9228b4b080b3cf94cceea866b74c48319f2093f56bd5a63465288e9a71437ee8", "", AckStatusTransactionACK, time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) || s == nil || s.EntryData.Status != AckStatusNotConfirmed {
+		t.Errorf("got %v, %v, expecting the deadline to be exceeded", s, err)
+	}
+}
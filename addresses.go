@@ -6,7 +6,6 @@ package factom
 
 import (
 	"bytes"
-	"fmt"
 	"strings"
 
 	"github.com/FactomProject/btcutil/base58"
@@ -119,7 +118,7 @@ func (a *ECAddress) UnmarshalBinary(data []byte) error {
 
 func (a *ECAddress) UnmarshalBinaryData(data []byte) ([]byte, error) {
 	if len(data) < 32 {
-		return nil, fmt.Errorf("secret key portion must be 32 bytes")
+		return nil, validationErrorf("secret key portion must be 32 bytes")
 	}
 
 	if a.Sec == nil {
@@ -139,13 +138,14 @@ func (a *ECAddress) MarshalBinary() ([]byte, error) {
 // GetECAddress takes a private address string (Es...) and returns an ECAddress.
 func GetECAddress(s string) (*ECAddress, error) {
 	if !IsValidAddress(s) {
-		return nil, fmt.Errorf("Invalid Address")
+		return nil, validationErrorf("Invalid Address")
 	}
 
 	p := base58.Decode(s)
+	defer SecureBytes(p).Wipe()
 
 	if !bytes.Equal(p[:PrefixLength], ecSecPrefix) {
-		return nil, fmt.Errorf("Invalid Entry Credit Private Address")
+		return nil, validationErrorf("Invalid Entry Credit Private Address")
 	}
 
 	return MakeECAddress(p[PrefixLength:BodyLength])
@@ -153,7 +153,7 @@ func GetECAddress(s string) (*ECAddress, error) {
 
 func MakeECAddress(sec []byte) (*ECAddress, error) {
 	if len(sec) != 32 {
-		return nil, fmt.Errorf("secret key portion must be 32 bytes")
+		return nil, validationErrorf("secret key portion must be 32 bytes")
 	}
 
 	a := NewECAddress()
@@ -193,8 +193,12 @@ func (a *ECAddress) PubString() string {
 	return base58.Encode(buf.Bytes())
 }
 
-// SecBytes returns the []byte representation of the secret key
+// SecBytes returns the []byte representation of the secret key, or nil for
+// an address without one.
 func (a *ECAddress) SecBytes() []byte {
+	if a.Sec == nil {
+		return nil
+	}
 	return a.Sec[:]
 }
 
@@ -203,8 +207,12 @@ func (a *ECAddress) SecFixed() *[ed.PrivateKeySize]byte {
 	return a.Sec
 }
 
-// SecString returns the string encoding of the secret key
+// SecString returns the string encoding of the secret key, or "" for an
+// address without one, such as a key held by a hardware signer.
 func (a *ECAddress) SecString() string {
+	if a.Sec == nil {
+		return ""
+	}
 	buf := new(bytes.Buffer)
 
 	// EC address prefix
@@ -250,7 +258,7 @@ func (t *FactoidAddress) UnmarshalBinary(data []byte) error {
 
 func (t *FactoidAddress) UnmarshalBinaryData(data []byte) ([]byte, error) {
 	if len(data) < 32 {
-		return nil, fmt.Errorf("secret key portion must be 32 bytes")
+		return nil, validationErrorf("secret key portion must be 32 bytes")
 	}
 
 	if t.Sec == nil {
@@ -273,13 +281,14 @@ func (t *FactoidAddress) MarshalBinary() ([]byte, error) {
 // FactoidAddress.
 func GetFactoidAddress(s string) (*FactoidAddress, error) {
 	if !IsValidAddress(s) {
-		return nil, fmt.Errorf("Invalid Address")
+		return nil, validationErrorf("Invalid Address")
 	}
 
 	p := base58.Decode(s)
+	defer SecureBytes(p).Wipe()
 
 	if !bytes.Equal(p[:PrefixLength], fcSecPrefix) {
-		return nil, fmt.Errorf("Invalid Factoid Private Address")
+		return nil, validationErrorf("Invalid Factoid Private Address")
 	}
 
 	return MakeFactoidAddress(p[PrefixLength:BodyLength])
@@ -287,7 +296,7 @@ func GetFactoidAddress(s string) (*FactoidAddress, error) {
 
 func MakeFactoidAddress(sec []byte) (*FactoidAddress, error) {
 	if len(sec) != 32 {
-		return nil, fmt.Errorf("secret key portion must be 32 bytes")
+		return nil, validationErrorf("secret key portion must be 32 bytes")
 	}
 
 	a := NewFactoidAddress()
@@ -300,8 +309,8 @@ func MakeFactoidAddress(sec []byte) (*FactoidAddress, error) {
 }
 
 func ParseAndValidateMnemonic(mnemonic string) (string, error) {
-	if l := len(strings.Fields(mnemonic)); l != 12 {
-		return "", fmt.Errorf("Incorrect mnemonic length. Expecitng 12 words, found %d", l)
+	if l := len(strings.Fields(mnemonic)); l != 12 && l != 24 {
+		return "", validationErrorf("Incorrect mnemonic length. Expecting 12 or 24 words, found %d", l)
 	}
 
 	mnemonic = strings.ToLower(strings.TrimSpace(mnemonic))
@@ -325,6 +334,9 @@ func ParseAndValidateMnemonic(mnemonic string) (string, error) {
 // MakeFactoidAddressFromKoinify takes the 12 word string used in the Koinify
 // sale and returns a Factoid Address.
 func MakeFactoidAddressFromKoinify(mnemonic string) (*FactoidAddress, error) {
+	if l := len(strings.Fields(mnemonic)); l != 12 {
+		return nil, validationErrorf("Incorrect Koinify mnemonic length. Expecting 12 words, found %d", l)
+	}
 	mnemonic, err := ParseAndValidateMnemonic(mnemonic)
 	if err != nil {
 		return nil, err
@@ -334,14 +346,17 @@ func MakeFactoidAddressFromKoinify(mnemonic string) (*FactoidAddress, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer SecureBytes(seed).Wipe()
 	masterKey, err := bip32.NewMasterKey(seed)
 	if err != nil {
 		return nil, err
 	}
+	defer SecureBytes(masterKey.Key).Wipe()
 	child, err := masterKey.NewChildKey(bip32.FirstHardenedChild + 7)
 	if err != nil {
 		return nil, err
 	}
+	defer SecureBytes(child.Key).Wipe()
 
 	return MakeFactoidAddress(child.Key)
 }
@@ -356,6 +371,7 @@ func MakeBIP44FactoidAddress(mnemonic string, account, chain, address uint32) (*
 	if err != nil {
 		return nil, err
 	}
+	defer SecureBytes(child.Key).Wipe()
 
 	return MakeFactoidAddress(child.Key)
 }
@@ -370,6 +386,7 @@ func MakeBIP44ECAddress(mnemonic string, account, chain, address uint32) (*ECAdd
 	if err != nil {
 		return nil, err
 	}
+	defer SecureBytes(child.Key).Wipe()
 
 	return MakeECAddress(child.Key)
 }
@@ -387,6 +404,9 @@ func (a *FactoidAddress) PubBytes() []byte {
 }
 
 func (a *FactoidAddress) SecBytes() []byte {
+	if a.Sec == nil {
+		return nil
+	}
 	return a.Sec[:]
 }
 
@@ -395,6 +415,9 @@ func (a *FactoidAddress) SecFixed() *[ed.PrivateKeySize]byte {
 }
 
 func (a *FactoidAddress) SecString() string {
+	if a.Sec == nil {
+		return ""
+	}
 	buf := new(bytes.Buffer)
 
 	// Factoid address prefix
@@ -7,6 +7,7 @@ package factom_test
 import (
 	"bytes"
 	"crypto/rand"
+	"fmt"
 	"testing"
 
 	ed "github.com/FactomProject/ed25519"
@@ -277,6 +278,8 @@ func TestParseAndValidateMnemonic(t *testing.T) {
 		"yellow  yellow yellow yellow yellow yellow yellow yellow yellow yellow yellow yellow",  //extra space
 		"YELLOW yellow yellow yellow yellow yellow yellow yellow yellow yellow yellow yellow",   //capitalization
 		" yellow yellow yellow yellow yellow yellow yellow yellow yellow yellow yellow yellow ", //spaces on sides
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon " +
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art", //24 words
 	}
 	for i, m := range ms {
 		_, err := ParseAndValidateMnemonic(m)
@@ -285,3 +288,42 @@ func TestParseAndValidateMnemonic(t *testing.T) {
 		}
 	}
 }
+
+func TestWipeAddresses(t *testing.T) {
+	fs := "Fs2GCfAa2HBKaGEUWCtw8eGDkN1CfyS6HhdgLv8783shkrCgvcpJ"
+	f, err := GetFactoidAddress(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := f.String()
+	f.Wipe()
+	if !bytes.Equal(f.SecBytes(), make([]byte, ed.PrivateKeySize)) {
+		t.Error("secret key was not wiped")
+	}
+	if f.String() != pub {
+		t.Error("wiping changed the public address")
+	}
+
+	// the address string is decoded again unchanged after a wipe
+	if g, err := GetFactoidAddress(fs); err != nil {
+		t.Error(err)
+	} else if g.SecString() != fs {
+		t.Errorf("wrong secret %s", g.SecString())
+	}
+
+	e := NewECAddress()
+	copy(e.Sec[:], []byte("secret"))
+	e.Wipe()
+	if !bytes.Equal(e.SecBytes(), make([]byte, ed.PrivateKeySize)) {
+		t.Error("secret key was not wiped")
+	}
+
+	s := SecureBytes("secret")
+	if fmt.Sprint(s) != "[secret]" || fmt.Sprintf("%#v", s) != "[secret]" {
+		t.Errorf("SecureBytes printed its contents: %v", s)
+	}
+	s.Wipe()
+	if !bytes.Equal(s, make([]byte, 6)) {
+		t.Error("SecureBytes was not wiped")
+	}
+}
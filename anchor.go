@@ -0,0 +1,240 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// Anchors are the records of the transactions on other blockchains that
+// anchor a Directory Block. Bitcoin or Ethereum is nil if the Directory Block
+// has not been anchored there yet.
+type Anchors struct {
+	DBHeight uint32          `json:"directoryblockheight"`
+	KeyMR    string          `json:"directoryblockkeymr"`
+	Bitcoin  *BitcoinAnchor  `json:"bitcoin"`
+	Ethereum *EthereumAnchor `json:"ethereum"`
+}
+
+// BitcoinAnchor is a Bitcoin transaction holding the Directory Block KeyMR.
+type BitcoinAnchor struct {
+	TxHash    string `json:"transactionhash"`
+	BlockHash string `json:"blockhash"`
+}
+
+// EthereumAnchor is an Ethereum transaction holding the Merkle root of a
+// window of Directory Blocks. MerkleBranch leads from the Directory Block
+// KeyMR to WindowMR.
+type EthereumAnchor struct {
+	RecordHeight    uint32       `json:"recordheight"`
+	DBHeightMax     uint32       `json:"dbheightmax"`
+	DBHeightMin     uint32       `json:"dbheightmin"`
+	WindowMR        string       `json:"windowmr"`
+	MerkleBranch    []*ProofNode `json:"merklebranch"`
+	ContractAddress string       `json:"contractaddress"`
+	TxID            string       `json:"txid"`
+	BlockHash       string       `json:"blockhash"`
+	TxIndex         int64        `json:"txindex"`
+}
+
+// UnmarshalJSON reads the anchors result of factomd, which gives false
+// instead of null for a missing anchor.
+func (a *Anchors) UnmarshalJSON(data []byte) error {
+	type js struct {
+		DBHeight uint32          `json:"directoryblockheight"`
+		KeyMR    string          `json:"directoryblockkeymr"`
+		Bitcoin  json.RawMessage `json:"bitcoin"`
+		Ethereum json.RawMessage `json:"ethereum"`
+	}
+	j := new(js)
+	if err := json.Unmarshal(data, j); err != nil {
+		return err
+	}
+
+	a.DBHeight, a.KeyMR, a.Bitcoin, a.Ethereum = j.DBHeight, j.KeyMR, nil, nil
+	if anchored(j.Bitcoin) {
+		a.Bitcoin = new(BitcoinAnchor)
+		if err := json.Unmarshal(j.Bitcoin, a.Bitcoin); err != nil {
+			return err
+		}
+	}
+	if anchored(j.Ethereum) {
+		a.Ethereum = new(EthereumAnchor)
+		if err := json.Unmarshal(j.Ethereum, a.Ethereum); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func anchored(p json.RawMessage) bool {
+	s := string(bytes.TrimSpace(p))
+	return s != "" && s != "false" && s != "null"
+}
+
+// GetAnchors returns the anchors of the Directory Block with the given KeyMR.
+func GetAnchors(keymr string) (*Anchors, error) {
+	return GetAnchorsWithContext(context.Background(), keymr)
+}
+
+// GetAnchorsWithContext is like GetAnchors but cancels its requests when ctx
+// is done.
+func GetAnchorsWithContext(ctx context.Context, keymr string) (*Anchors, error) {
+	params := keyMRRequest{KeyMR: keymr}
+	req := NewJSON2Request("anchors", APICounter(), params)
+	resp, err := factomdRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	a := new(Anchors)
+	if err := json.Unmarshal(resp.JSONResult(), a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// AnchorTransaction is a transaction on another blockchain, as seen by an
+// AnchorSource.
+type AnchorTransaction struct {
+	// BlockHash is the hash of the block that includes the transaction.
+	BlockHash string
+
+	// Data is the OP_RETURN data of a Bitcoin transaction or the input of
+	// an Ethereum transaction.
+	Data []byte
+}
+
+// AnchorSource looks up anchor transactions on Bitcoin and Ethereum, for
+// example from a node or a block explorer that the application trusts. A
+// source that does not follow one of the blockchains returns an error for
+// it.
+type AnchorSource interface {
+	BitcoinTransaction(ctx context.Context, txHash string) (*AnchorTransaction, error)
+	EthereumTransaction(ctx context.Context, txID string) (*AnchorTransaction, error)
+}
+
+// VerifyAnchors fetches the anchors of the Directory Block with the given
+// KeyMR and checks every one of them against src: the transaction must be in
+// the block given by factomd and hold the KeyMR, or for Ethereum the root of
+// a Merkle branch from the KeyMR. It returns the verified anchors. Anchors
+// that do not match give an error matching ErrValidation, and a Directory
+// Block that is not anchored yet is an error too.
+func VerifyAnchors(keymr string, src AnchorSource) (*Anchors, error) {
+	return VerifyAnchorsWithContext(context.Background(), keymr, src)
+}
+
+// VerifyAnchorsWithContext is like VerifyAnchors but cancels its requests
+// when ctx is done.
+func VerifyAnchorsWithContext(ctx context.Context, keymr string, src AnchorSource) (*Anchors, error) {
+	a, err := GetAnchorsWithContext(ctx, keymr)
+	if err != nil {
+		return nil, err
+	}
+	if a.KeyMR != keymr {
+		return nil, validationErrorf("anchors are for directory block %s, not %s", a.KeyMR, keymr)
+	}
+	if a.Bitcoin == nil && a.Ethereum == nil {
+		return nil, validationErrorf("directory block %s is not anchored yet", keymr)
+	}
+
+	if a.Bitcoin != nil {
+		tx, err := src.BitcoinTransaction(ctx, a.Bitcoin.TxHash)
+		if err != nil {
+			return nil, err
+		}
+		if err := verifyBitcoinAnchor(a, tx); err != nil {
+			return nil, err
+		}
+	}
+	if a.Ethereum != nil {
+		tx, err := src.EthereumTransaction(ctx, a.Ethereum.TxID)
+		if err != nil {
+			return nil, err
+		}
+		if err := verifyEthereumAnchor(a, tx); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// verifyBitcoinAnchor checks that the OP_RETURN data of tx is "Fa", the 6
+// byte Directory Block height and the KeyMR.
+func verifyBitcoinAnchor(a *Anchors, tx *AnchorTransaction) error {
+	if !strings.EqualFold(tx.BlockHash, a.Bitcoin.BlockHash) {
+		return validationErrorf("bitcoin transaction %s is not in block %s", a.Bitcoin.TxHash, a.Bitcoin.BlockHash)
+	}
+	keymr, err := decodeProofHex(a.KeyMR, sha256.Size)
+	if err != nil {
+		return err
+	}
+	// the height fits in the 6 bytes after the prefix
+	want := make([]byte, 8, 8+len(keymr))
+	binary.BigEndian.PutUint64(want, uint64(a.DBHeight))
+	want[0], want[1] = 'F', 'a'
+	want = append(want, keymr...)
+	if !bytes.Equal(tx.Data, want) {
+		return validationErrorf("bitcoin transaction %s does not hold directory block %s", a.Bitcoin.TxHash, a.KeyMR)
+	}
+	return nil
+}
+
+// verifyEthereumAnchor checks the Merkle branch from the KeyMR to the window
+// Merkle root and that the input of tx holds the root.
+func verifyEthereumAnchor(a *Anchors, tx *AnchorTransaction) error {
+	e := a.Ethereum
+	if !strings.EqualFold(trim0x(tx.BlockHash), trim0x(e.BlockHash)) {
+		return validationErrorf("ethereum transaction %s is not in block %s", e.TxID, e.BlockHash)
+	}
+	if a.DBHeight < e.DBHeightMin || a.DBHeight > e.DBHeightMax {
+		return validationErrorf("ethereum anchor window %d-%d does not include height %d", e.DBHeightMin, e.DBHeightMax, a.DBHeight)
+	}
+
+	cur := a.KeyMR
+	for i, n := range e.MerkleBranch {
+		if cur != n.Left && cur != n.Right {
+			return validationErrorf("anchor merkle branch is broken at step %d", i)
+		}
+		left, err := decodeProofHex(n.Left, sha256.Size)
+		if err != nil {
+			return err
+		}
+		right, err := decodeProofHex(n.Right, sha256.Size)
+		if err != nil {
+			return err
+		}
+		top := sha256.Sum256(append(left, right...))
+		if hex.EncodeToString(top[:]) != n.Top {
+			return validationErrorf("anchor merkle branch has a bad hash at step %d", i)
+		}
+		cur = n.Top
+	}
+	if cur != e.WindowMR {
+		return validationErrorf("anchor merkle branch does not end at window %s", e.WindowMR)
+	}
+
+	root, err := decodeProofHex(e.WindowMR, sha256.Size)
+	if err != nil {
+		return err
+	}
+	if !bytes.Contains(tx.Data, root) {
+		return validationErrorf("ethereum transaction %s does not hold window %s", e.TxID, e.WindowMR)
+	}
+	return nil
+}
+
+func trim0x(s string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+}
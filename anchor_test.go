@@ -0,0 +1,95 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/FactomProject/factom"
+)
+
+// anchorSource returns fixed transactions.
+type anchorSource struct {
+	btc, eth *AnchorTransaction
+}
+
+func (s *anchorSource) BitcoinTransaction(ctx context.Context, txHash string) (*AnchorTransaction, error) {
+	return s.btc, nil
+}
+
+func (s *anchorSource) EthereumTransaction(ctx context.Context, txID string) (*AnchorTransaction, error) {
+	if s.eth == nil {
+		return nil, errors.New("no ethereum node")
+	}
+	return s.eth, nil
+}
+
+func TestVerifyAnchors(t *testing.T) {
+	keymr := strings.Repeat("ab", 32)
+	sibling := strings.Repeat("cd", 32)
+	k, _ := hex.DecodeString(keymr)
+	s, _ := hex.DecodeString(sibling)
+	window := sha256.Sum256(append(k, s...))
+	windowMR := hex.EncodeToString(window[:])
+
+	result := fmt.Sprintf(`{"directoryblockheight": 200000, "directoryblockkeymr": "%s",
+		"bitcoin": {"transactionhash": "b7", "blockhash": "00ff"},
+		"ethereum": {"dbheightmin": 199990, "dbheightmax": 200010, "windowmr": "%s",
+			"merklebranch": [{"left": "%s", "right": "%s", "top": "%s"}],
+			"txid": "0xe7", "blockhash": "0xEE"}}`, keymr, windowMR, keymr, sibling, windowMR)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 0, "result": %s}`, result)
+	}))
+	defer ts.Close()
+	SetFactomdServer(ts.URL[7:])
+
+	opReturn := append([]byte{'F', 'a', 0, 0, 0, 3, 0x0d, 0x40}, k...)
+	src := &anchorSource{
+		btc: &AnchorTransaction{BlockHash: "00FF", Data: opReturn},
+		eth: &AnchorTransaction{BlockHash: "0xee", Data: append([]byte{1, 2, 3, 4}, window[:]...)},
+	}
+	a, err := VerifyAnchors(keymr, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.DBHeight != 200000 || a.Bitcoin.TxHash != "b7" || a.Ethereum.TxID != "0xe7" {
+		t.Errorf("anchors %+v", a)
+	}
+
+	// a transaction that does not hold the keymr fails
+	src.btc.Data = append([]byte{'F', 'a', 0, 0, 0, 3, 0x0d, 0x41}, k...)
+	if _, err := VerifyAnchors(keymr, src); !errors.Is(err, ErrValidation) {
+		t.Errorf("wrong height: got %v", err)
+	}
+	src.btc.Data = opReturn
+
+	src.eth.Data = []byte{1, 2, 3, 4}
+	if _, err := VerifyAnchors(keymr, src); !errors.Is(err, ErrValidation) {
+		t.Errorf("wrong window: got %v", err)
+	}
+
+	// errors of the source are returned
+	src.eth = nil
+	if _, err := VerifyAnchors(keymr, src); err == nil || errors.Is(err, ErrValidation) {
+		t.Errorf("expected the source error, got %v", err)
+	}
+
+	// factomd gives false for anchors that are not there yet
+	result = fmt.Sprintf(`{"directoryblockheight": 200000, "directoryblockkeymr": "%s", "bitcoin": false, "ethereum": false}`, keymr)
+	if a, err := GetAnchors(keymr); err != nil || a.Bitcoin != nil || a.Ethereum != nil {
+		t.Errorf("GetAnchors = %+v, %v", a, err)
+	}
+	if _, err := VerifyAnchors(keymr, src); !errors.Is(err, ErrValidation) {
+		t.Errorf("not anchored: got %v", err)
+	}
+}
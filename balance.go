@@ -0,0 +1,55 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"context"
+	"fmt"
+)
+
+// FactoidBalance returns the balance in factoshis of the Factoid Public
+// Address addr. Unlike GetFactoidBalance it checks the address before asking
+// factomd, returning an error matching ErrInvalidAddress if it is not a
+// valid FA address.
+func FactoidBalance(addr string) (uint64, error) {
+	return FactoidBalanceWithContext(context.Background(), addr)
+}
+
+// FactoidBalanceWithContext is like FactoidBalance but cancels its requests
+// when ctx is done.
+func FactoidBalanceWithContext(ctx context.Context, addr string) (uint64, error) {
+	if AddressStringType(addr) != FactoidPub {
+		return 0, invalidAddressErrorf("%q is not a Factoid Public Address", addr)
+	}
+	return uintBalance(GetFactoidBalanceWithContext(ctx, addr))
+}
+
+// ECBalance returns the balance in entry credits of the Entry Credit Public
+// Address addr. Unlike GetECBalance it checks the address before asking
+// factomd, returning an error matching ErrInvalidAddress if it is not a
+// valid EC address.
+func ECBalance(addr string) (uint64, error) {
+	return ECBalanceWithContext(context.Background(), addr)
+}
+
+// ECBalanceWithContext is like ECBalance but cancels its requests when ctx is
+// done.
+func ECBalanceWithContext(ctx context.Context, addr string) (uint64, error) {
+	if AddressStringType(addr) != ECPub {
+		return 0, invalidAddressErrorf("%q is not an Entry Credit Public Address", addr)
+	}
+	return uintBalance(GetECBalanceWithContext(ctx, addr))
+}
+
+// uintBalance converts a balance returned by factomd to a uint64.
+func uintBalance(b int64, err error) (uint64, error) {
+	if err != nil {
+		return 0, err
+	}
+	if b < 0 {
+		return 0, fmt.Errorf("factomd returned the negative balance %d", b)
+	}
+	return uint64(b), nil
+}
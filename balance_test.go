@@ -0,0 +1,51 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestBalances(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"balance": 966582271}}`)
+	}))
+	defer ts.Close()
+	SetFactomdServer(ts.URL[7:])
+
+	if b, err := FactoidBalance("FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"); err != nil || b != 966582271 {
+		t.Errorf("FactoidBalance = %d, %v", b, err)
+	}
+	if b, err := ECBalance("EC3MAHiZyfuEb5fZP2fSp2gXMv8WemhQEUFXyQ2f2HjSkYx7xY1S"); err != nil || b != 966582271 {
+		t.Errorf("ECBalance = %d, %v", b, err)
+	}
+
+	// bad addresses are rejected without asking factomd
+	calls = 0
+	bad := []string{
+		"FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1R", // bad checksum
+		"EC3MAHiZyfuEb5fZP2fSp2gXMv8WemhQEUFXyQ2f2HjSkYx7xY1S", // wrong type
+		"Fs1KWJrpLdfucvmYwN2nWrwepLn8ercpMbzXshd1g8zyhKXLVLWj", // private
+	}
+	for _, a := range bad {
+		if _, err := FactoidBalance(a); !errors.Is(err, ErrInvalidAddress) || !errors.Is(err, ErrValidation) {
+			t.Errorf("%s: got %v, expecting an invalid address error", a, err)
+		}
+	}
+	if _, err := ECBalance("FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("got %v, expecting an invalid address error", err)
+	}
+	if calls != 0 {
+		t.Errorf("factomd was called %d times", calls)
+	}
+}
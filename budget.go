@@ -0,0 +1,177 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ECBudget limits the Entry Credits that CommitEntry and CommitChain may
+// spend from each Entry Credit address over the last hour and the last day,
+// so that a runaway writer can not drain an address. A commit that would go
+// over the budget fails with an error matching ErrBudgetExceeded, or waits
+// until it fits if Wait is set. Failed commits are not counted. An ECBudget
+// is safe for concurrent use and may be shared by several clients.
+type ECBudget struct {
+	// PerHour and PerDay are the Entry Credits that may be spent per
+	// address in any hour and any day. 0 is no limit.
+	PerHour int64
+	PerDay  int64
+
+	// Wait makes a commit over the budget wait until enough earlier
+	// spending has aged out, or until its context is done.
+	Wait bool
+
+	mu     sync.Mutex
+	spends map[string][]ecSpend
+}
+
+type ecSpend struct {
+	at   time.Time
+	cost int64
+}
+
+// NewECBudget returns an ECBudget of perHour and perDay Entry Credits per
+// address.
+func NewECBudget(perHour, perDay int64) *ECBudget {
+	return &ECBudget{PerHour: perHour, PerDay: perDay}
+}
+
+// Spent returns the Entry Credits spent from the address ec in the last hour
+// and the last day.
+func (b *ECBudget) Spent(ec string) (hour, day int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	hour, day, _ = b.spent(ec, time.Now(), 0)
+	return hour, day
+}
+
+// spent sums the spending of ec in the hour and day before now, dropping the
+// older spends. It also returns how long to wait until cost more would fit.
+func (b *ECBudget) spent(ec string, now time.Time, cost int64) (hour, day int64, wait time.Duration) {
+	spends := b.spends[ec]
+	for len(spends) > 0 && now.Sub(spends[0].at) >= 24*time.Hour {
+		spends = spends[1:]
+	}
+	if b.spends != nil {
+		b.spends[ec] = spends
+	}
+
+	for _, s := range spends {
+		day += s.cost
+		if now.Sub(s.at) < time.Hour {
+			hour += s.cost
+		}
+	}
+
+	// the oldest spends age out first
+	if b.PerDay > 0 && day+cost > b.PerDay {
+		free := day
+		for _, s := range spends {
+			free -= s.cost
+			if free+cost <= b.PerDay {
+				wait = s.at.Add(24 * time.Hour).Sub(now)
+				break
+			}
+		}
+	}
+	if b.PerHour > 0 && hour+cost > b.PerHour {
+		free := hour
+		for _, s := range spends {
+			if now.Sub(s.at) >= time.Hour {
+				continue
+			}
+			free -= s.cost
+			if free+cost <= b.PerHour {
+				if w := s.at.Add(time.Hour).Sub(now); w > wait {
+					wait = w
+				}
+				break
+			}
+		}
+	}
+	return hour, day, wait
+}
+
+// reserve records cost Entry Credits spent from ec, waiting or failing if it
+// goes over the budget. A nil budget allows everything.
+func (b *ECBudget) reserve(ctx context.Context, ec string, cost int64) (*ecSpend, error) {
+	if b == nil {
+		return nil, nil
+	}
+	if b.PerHour > 0 && cost > b.PerHour || b.PerDay > 0 && cost > b.PerDay {
+		return nil, budgetErrorf("a commit of %d entry credits is larger than the budget", cost)
+	}
+
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		hour, day, wait := b.spent(ec, now, cost)
+		if wait <= 0 {
+			if b.spends == nil {
+				b.spends = make(map[string][]ecSpend)
+			}
+			s := ecSpend{at: now, cost: cost}
+			b.spends[ec] = append(b.spends[ec], s)
+			b.mu.Unlock()
+			return &s, nil
+		}
+		b.mu.Unlock()
+
+		if !b.Wait {
+			return nil, budgetErrorf("%s spent %d entry credits in the last hour and %d in the last day", ec, hour, day)
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// release forgets a spend recorded by reserve for a commit that failed.
+func (b *ECBudget) release(ec string, s *ecSpend) {
+	if b == nil || s == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	spends := b.spends[ec]
+	for i := range spends {
+		if spends[i] == *s {
+			b.spends[ec] = append(spends[:i:i], spends[i+1:]...)
+			return
+		}
+	}
+}
+
+// spendEC calls commit if the budget of the default Client allows spending
+// cost Entry Credits from ec, and counts them unless commit fails.
+func spendEC(ctx context.Context, ec *ECAddress, cost int8, commit func() error) error {
+	b := DefaultClient().ECBudget
+	s, err := b.reserve(ctx, ec.PubString(), int64(cost))
+	if err != nil {
+		return err
+	}
+	if err := commit(); err != nil {
+		b.release(ec.PubString(), s)
+		return err
+	}
+	return nil
+}
+
+// ecBudget is the budget of the default Client.
+var ecBudget *ECBudget
+
+// SetECBudget sets the budget for the commits made by the package level api
+// functions, unless SetDefaultClient has been called. A nil budget removes
+// the limit.
+func SetECBudget(b *ECBudget) {
+	ecBudget = b
+}
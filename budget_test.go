@@ -0,0 +1,86 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestECBudget(t *testing.T) {
+	commits := 0
+	reject := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(JSON2Request)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+		commits++
+		if reject {
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "error": {"code": -32602, "message": "Invalid params"}}`)
+			return
+		}
+		fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"message": "Entry Commit Success", "txid": "00"}}`)
+	}))
+	defer ts.Close()
+
+	budget := NewECBudget(3, 0)
+	SetDefaultClient(NewClient(WithFactomdServer(ts.URL[7:]), WithECBudget(budget)))
+	defer SetDefaultClient(nil)
+
+	ec, _ := GetECAddress("Es2Rf7iM6PdsqfYCo3D1tnAR65SkLENyWJG1deUzpRMQmbh9F3eG")
+	e := &Entry{
+		ChainID: "954d5a49fd70d9b8bcdb35d252267829957f7ef7fa6c74f88419bdc5e82209f4",
+		Content: []byte("budget"),
+	}
+
+	// a failed commit is not counted
+	reject = true
+	if _, err := CommitEntry(e, ec); err == nil {
+		t.Error("rejected commit succeeded")
+	}
+	reject = false
+
+	for i := 0; i < 3; i++ {
+		if _, err := CommitEntry(e, ec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if hour, day := budget.Spent(ec.PubString()); hour != 3 || day != 3 {
+		t.Errorf("spent %d in the hour and %d in the day", hour, day)
+	}
+	if _, err := CommitEntry(e, ec); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("commit over the budget returned %v", err)
+	}
+	if commits != 4 {
+		t.Errorf("factomd got %d commits", commits)
+	}
+
+	// the budget is per address
+	if _, err := CommitEntry(e, NewECAddress()); err != nil {
+		t.Error(err)
+	}
+
+	// a chain costs 10 more than its first entry
+	if _, err := CommitChain(NewChain(e), NewECAddress()); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("chain commit over the budget returned %v", err)
+	}
+
+	budget.Wait = true
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := CommitEntryWithContext(ctx, e, ec); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("waiting commit returned %v", err)
+	}
+}
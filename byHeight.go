@@ -5,6 +5,7 @@
 package factom
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -83,9 +84,15 @@ func (f *BlockByHeightRawResponse) String() string {
 }
 
 func GetBlockByHeightRaw(blockType string, height int64) (*BlockByHeightRawResponse, error) {
+	return GetBlockByHeightRawWithContext(context.Background(), blockType, height)
+}
+
+// GetBlockByHeightRawWithContext is like GetBlockByHeightRaw but cancels its
+// requests when ctx is done.
+func GetBlockByHeightRawWithContext(ctx context.Context, blockType string, height int64) (*BlockByHeightRawResponse, error) {
 	params := heightRequest{Height: height}
 	req := NewJSON2Request(fmt.Sprintf("%vblock-by-height", blockType), APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -102,9 +109,15 @@ func GetBlockByHeightRaw(blockType string, height int64) (*BlockByHeightRawRespo
 }
 
 func GetDBlockByHeight(height int64) (*BlockByHeightResponse, error) {
+	return GetDBlockByHeightWithContext(context.Background(), height)
+}
+
+// GetDBlockByHeightWithContext is like GetDBlockByHeight but cancels its
+// requests when ctx is done.
+func GetDBlockByHeightWithContext(ctx context.Context, height int64) (*BlockByHeightResponse, error) {
 	params := heightRequest{Height: height}
 	req := NewJSON2Request("dblock-by-height", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -120,10 +133,48 @@ func GetDBlockByHeight(height int64) (*BlockByHeightResponse, error) {
 	return block, nil
 }
 
+// GetDirectoryBlockByHeight requests the Directory Block at height from
+// factomd. Unlike GetDBlockByHeight it returns the block as a DirectoryBlock.
+func GetDirectoryBlockByHeight(height int64) (*DirectoryBlock, error) {
+	return GetDirectoryBlockByHeightWithContext(context.Background(), height)
+}
+
+// GetDirectoryBlockByHeightWithContext is like GetDirectoryBlockByHeight but
+// cancels its requests when ctx is done.
+func GetDirectoryBlockByHeightWithContext(ctx context.Context, height int64) (*DirectoryBlock, error) {
+	params := heightRequest{Height: height}
+	req := NewJSON2Request("dblock-by-height", APICounter(), params)
+	resp, err := factomdRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	block := new(struct {
+		DBlock *DirectoryBlock `json:"dblock"`
+	})
+	if err := json.Unmarshal(resp.JSONResult(), block); err != nil {
+		return nil, err
+	}
+	if block.DBlock == nil {
+		return nil, fmt.Errorf("factomd returned no directory block at height %d", height)
+	}
+
+	return block.DBlock, nil
+}
+
 func GetECBlockByHeight(height int64) (*BlockByHeightResponse, error) {
+	return GetECBlockByHeightWithContext(context.Background(), height)
+}
+
+// GetECBlockByHeightWithContext is like GetECBlockByHeight but cancels its
+// requests when ctx is done.
+func GetECBlockByHeightWithContext(ctx context.Context, height int64) (*BlockByHeightResponse, error) {
 	params := heightRequest{Height: height}
 	req := NewJSON2Request("ecblock-by-height", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -140,9 +191,15 @@ func GetECBlockByHeight(height int64) (*BlockByHeightResponse, error) {
 }
 
 func GetFBlockByHeight(height int64) (*BlockByHeightResponse, error) {
+	return GetFBlockByHeightWithContext(context.Background(), height)
+}
+
+// GetFBlockByHeightWithContext is like GetFBlockByHeight but cancels its
+// requests when ctx is done.
+func GetFBlockByHeightWithContext(ctx context.Context, height int64) (*BlockByHeightResponse, error) {
 	params := heightRequest{Height: height}
 	req := NewJSON2Request("fblock-by-height", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -159,9 +216,15 @@ func GetFBlockByHeight(height int64) (*BlockByHeightResponse, error) {
 }
 
 func GetABlockByHeight(height int64) (*BlockByHeightResponse, error) {
+	return GetABlockByHeightWithContext(context.Background(), height)
+}
+
+// GetABlockByHeightWithContext is like GetABlockByHeight but cancels its
+// requests when ctx is done.
+func GetABlockByHeightWithContext(ctx context.Context, height int64) (*BlockByHeightResponse, error) {
 	params := heightRequest{Height: height}
 	req := NewJSON2Request("ablock-by-height", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -112,6 +112,17 @@ func TestDBlockByHeight(t *testing.T) {
 		fmt.Println(expectedString)
 		t.Fail()
 	}
+
+	dblock, err := GetDirectoryBlockByHeight(height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dblock.Header.DBHeight != 14460 || dblock.Header.Timestamp != 24671414 || len(dblock.DBEntries) != 3 {
+		t.Errorf("wrong header or entries: %v", dblock)
+	}
+	if dblock.KeyMR != "18509c431ee852edbe1029d676217a0d9cb4fcc11ef8e9aef27fd6075167120c" || dblock.DBEntries[2].ChainID != "000000000000000000000000000000000000000000000000000000000000000f" {
+		t.Errorf("wrong block: %v", dblock)
+	}
 }
 
 func TestABlockByHeight(t *testing.T) {
@@ -0,0 +1,121 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"container/list"
+	"encoding/json"
+	"sync"
+)
+
+// cachedMethods are the factomd calls whose results are looked up by hash or
+// KeyMR and never change, so they can be kept in a Cache.
+var cachedMethods = map[string]bool{
+	"entry":           true,
+	"entry-block":     true,
+	"directory-block": true,
+	"raw-data":        true,
+}
+
+// Cache keeps the results of the factomd calls for Entries and blocks, which
+// are immutable, so that walking a chain again does not ask factomd for them
+// again. The least recently used results are dropped when the Cache holds
+// more than its number of results or bytes. A Cache is safe for concurrent
+// use and may be shared by several clients.
+type Cache struct {
+	maxEntries int
+	maxBytes   int
+
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type cacheItem struct {
+	key    string
+	result json.RawMessage
+}
+
+// NewCache returns a Cache that holds up to maxEntries results and maxBytes
+// bytes of results. A limit of 0 is not enforced.
+func NewCache(maxEntries, maxBytes int) *Cache {
+	return &Cache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Len returns the number of results in the Cache.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// Clear drops every result.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+	c.size = 0
+}
+
+func (c *Cache) get(key string) (json.RawMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*cacheItem).result, true
+}
+
+func (c *Cache) add(key string, result json.RawMessage) {
+	// a result larger than the whole cache would only evict everything
+	if c.maxBytes > 0 && len(result) > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&cacheItem{key: key, result: result})
+	c.size += len(result)
+
+	for c.ll.Len() > 0 && (c.maxEntries > 0 && c.ll.Len() > c.maxEntries ||
+		c.maxBytes > 0 && c.size > c.maxBytes) {
+		el := c.ll.Back()
+		item := c.ll.Remove(el).(*cacheItem)
+		delete(c.items, item.key)
+		c.size -= len(item.result)
+	}
+}
+
+// cacheKey returns the key of req in a Cache, or false if its result must not
+// be cached.
+func cacheKey(req *JSON2Request) (string, bool) {
+	if !cachedMethods[req.Method] {
+		return "", false
+	}
+	return req.Method + "\x00" + string(req.Params), true
+}
+
+// factomdCache is the cache of the default Client.
+var factomdCache *Cache
+
+// SetFactomdCache sets the cache for the calls to factomd made by the package
+// level api functions, unless SetDefaultClient has been called. A nil cache
+// disables caching.
+func SetFactomdCache(c *Cache) {
+	factomdCache = c
+}
@@ -0,0 +1,72 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestFactomdCache(t *testing.T) {
+	calls := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(JSON2Request)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+		params := make(map[string]string)
+		json.Unmarshal(req.Params, &params)
+		calls[req.Method+" "+params["hash"]]++
+
+		switch req.Method {
+		case "entry":
+			fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 0, "result": {"chainid": "%s", "content": "%s", "extids": []}}`,
+				ZeroHash, hex.EncodeToString([]byte(params["hash"])))
+		case "heights":
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"directoryblockheight": 10}}`)
+		}
+	}))
+	defer ts.Close()
+
+	cache := NewCache(2, 0)
+	SetDefaultClient(NewClient(WithFactomdServer(ts.URL[7:]), WithFactomdCache(cache)))
+	defer SetDefaultClient(nil)
+
+	for _, h := range []string{"e1", "e1", "e2", "e1", "e3", "e2"} {
+		e, err := GetEntry(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(e.Content) != h {
+			t.Errorf("entry %s has content %s", h, e.Content)
+		}
+	}
+	// e2 was dropped when e3 was added, as e1 was used more recently
+	if calls["entry e1"] != 1 || calls["entry e2"] != 2 || calls["entry e3"] != 1 || cache.Len() != 2 {
+		t.Errorf("calls %v, %d cached", calls, cache.Len())
+	}
+
+	// results that change are not cached
+	GetHeights()
+	GetHeights()
+	if calls["heights "] != 2 {
+		t.Errorf("heights called %d times", calls["heights "])
+	}
+
+	// nor results larger than the cache
+	cache = NewCache(0, 10)
+	SetDefaultClient(NewClient(WithFactomdServer(ts.URL[7:]), WithFactomdCache(cache)))
+	GetEntry("e4")
+	if cache.Len() != 0 {
+		t.Errorf("%d cached", cache.Len())
+	}
+}
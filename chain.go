@@ -6,6 +6,7 @@ package factom
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -16,36 +17,186 @@ type Chain struct {
 	//it is now overwritten with the chainid derived from the extid elements
 	ChainID    string `json:"chainid"`
 	FirstEntry *Entry `json:"firstentry"`
+
+	// the last computed chainid and the first entry and ExtIDs it was
+	// computed from. ID uses them to avoid rehashing an unchanged chain.
+	idCache  string
+	idEntry  *Entry
+	idExtIDs [][]byte
 }
 
 func NewChain(e *Entry) *Chain {
 	c := new(Chain)
 	c.FirstEntry = e
+	c.ChainID = c.ID()
+	c.FirstEntry.ChainID = c.ChainID
+
+	return c
+}
+
+// ID returns the chainid derived from the ExtIDs of the First Entry. The
+// result is cached on the Chain and only recomputed when the First Entry or
+// its ExtIDs have changed since the last call.
+func (c *Chain) ID() string {
+	if c.FirstEntry == nil {
+		return ""
+	}
+	if c.idCache != "" && c.idEntry == c.FirstEntry &&
+		equalExtIDs(c.idExtIDs, c.FirstEntry.ExtIDs) {
+		return c.idCache
+	}
+
+	c.idCache = ChainIDFromExtIDs(c.FirstEntry.ExtIDs)
+	c.idEntry = c.FirstEntry
+	c.idExtIDs = make([][]byte, len(c.FirstEntry.ExtIDs))
+	for i, id := range c.FirstEntry.ExtIDs {
+		c.idExtIDs[i] = append([]byte(nil), id...)
+	}
+
+	return c.idCache
+}
+
+// ChainHashes are the hashes that a chain commit is made of.
+type ChainHashes struct {
+	// ChainIDHash is sha256(sha256(ChainID)).
+	ChainIDHash []byte
+
+	// Weld is sha256(sha256(EntryHash + ChainID)). It ties the commit to
+	// both the chain and its First Entry.
+	Weld []byte
+
+	// EntryHash is the Entry Hash of the First Entry.
+	EntryHash []byte
+}
+
+// Hash returns the hashes of the Chain that are signed by its commit.
+func (c *Chain) Hash() (*ChainHashes, error) {
+	if c.FirstEntry == nil {
+		return nil, validationErrorf("chain has no First Entry")
+	}
+	cid, err := hex.DecodeString(c.ChainID)
+	if err != nil {
+		return nil, validationErrorf("invalid chainid %q", c.ChainID)
+	}
+
+	h := &ChainHashes{ChainIDHash: shad(cid), EntryHash: c.FirstEntry.Hash()}
+	h.Weld = shad(append(append([]byte(nil), h.EntryHash...), cid...))
+	return h, nil
+}
+
+// MarshalBinary returns the First Entry of the Chain in the form sent to
+// factomd by RevealChain.
+func (c *Chain) MarshalBinary() ([]byte, error) {
+	if c.FirstEntry == nil {
+		return nil, validationErrorf("chain has no First Entry")
+	}
+	return c.FirstEntry.MarshalBinary()
+}
+
+// UnmarshalBinary reads a Chain written by MarshalBinary. The chainid of the
+// First Entry must be the one derived from its ExtIDs.
+func (c *Chain) UnmarshalBinary(data []byte) error {
+	e := new(Entry)
+	if err := e.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	if id := ChainIDFromExtIDs(e.ExtIDs); id != e.ChainID {
+		return validationErrorf("chainid %s does not match the chainid %s of the First Entry ExtIDs", e.ChainID, id)
+	}
+	c.FirstEntry = e
+	c.ChainID = c.ID()
+	return nil
+}
 
-	// create the chainid from a series of hashes of the Entries ExtIDs
+// MarshalJSON writes the Chain with its First Entry in the form used by the
+// factomd api. The chainid is the one derived from the First Entry ExtIDs.
+func (c *Chain) MarshalJSON() ([]byte, error) {
+	type js struct {
+		ChainID    string `json:"chainid"`
+		FirstEntry *Entry `json:"firstentry"`
+	}
+	j := &js{ChainID: c.ChainID, FirstEntry: c.FirstEntry}
+	if c.FirstEntry != nil {
+		j.ChainID = c.ID()
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON reads a Chain written by MarshalJSON. The chainid of the
+// Chain and of its First Entry are set from the First Entry ExtIDs.
+func (c *Chain) UnmarshalJSON(data []byte) error {
+	type js struct {
+		ChainID    string `json:"chainid"`
+		FirstEntry *Entry `json:"firstentry"`
+	}
+	j := new(js)
+	if err := json.Unmarshal(data, j); err != nil {
+		return err
+	}
+	c.FirstEntry = j.FirstEntry
+	c.ChainID = j.ChainID
+	if c.FirstEntry != nil {
+		c.ChainID = c.ID()
+		c.FirstEntry.ChainID = c.ChainID
+	}
+	return nil
+}
+
+// ChainIDFromExtIDs returns the chainid that the network gives a Chain whose
+// First Entry has the ExtIDs ids, sha256 of the concatenated sha256 of each
+// ExtID.
+func ChainIDFromExtIDs(ids [][]byte) string {
 	hs := sha256.New()
-	for _, id := range e.ExtIDs {
+	for _, id := range ids {
 		h := sha256.Sum256(id)
 		hs.Write(h[:])
 	}
-	c.ChainID = hex.EncodeToString(hs.Sum(nil))
-	c.FirstEntry.ChainID = c.ChainID
+	return hex.EncodeToString(hs.Sum(nil))
+}
 
-	return c
+func equalExtIDs(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
 }
 
 func ChainExists(chainid string) bool {
-	if _, err := GetChainHead(chainid); err == nil {
+	return ChainExistsWithContext(context.Background(), chainid)
+}
+
+// ChainExistsWithContext is like ChainExists but cancels its requests when ctx
+// is done.
+func ChainExistsWithContext(ctx context.Context, chainid string) bool {
+	if _, err := GetChainHeadWithContext(ctx, chainid); err == nil {
 		// no error means we found the Chain
 		return true
 	}
 	return false
 }
 
+// CommitSigner signs a chain or entry commit message with the secret key of
+// an Entry Credit address. It lets the key be kept outside of this process,
+// for example by a remote signer.
+type CommitSigner func(msg []byte) ([]byte, error)
+
 // ComposeChainCommit creates a JSON2Request to commit a new Chain via the
 // factomd web api. The request includes the marshaled MessageRequest with the
 // Entry Credit Signature.
 func ComposeChainCommit(c *Chain, ec *ECAddress) (*JSON2Request, error) {
+	return ComposeChainCommitSigner(c, ec.PubBytes(), func(msg []byte) ([]byte, error) {
+		return ec.Sign(msg)[:], nil
+	})
+}
+
+// ComposeChainCommitSigner creates the same request as ComposeChainCommit
+// for the Entry Credit public key ecPub, using sign to make the signature.
+func ComposeChainCommitSigner(c *Chain, ecPub []byte, sign CommitSigner) (*JSON2Request, error) {
 	buf := new(bytes.Buffer)
 
 	// 1 byte version
@@ -55,25 +206,19 @@ func ComposeChainCommit(c *Chain, ec *ECAddress) (*JSON2Request, error) {
 	buf.Write(milliTime())
 
 	e := c.FirstEntry
-
-	// 32 byte ChainID Hash
-	if p, err := hex.DecodeString(c.ChainID); err != nil {
+	h, err := c.Hash()
+	if err != nil {
 		return nil, err
-	} else {
-		// double sha256 hash of ChainID
-		buf.Write(shad(p))
 	}
 
+	// 32 byte ChainID Hash
+	buf.Write(h.ChainIDHash)
+
 	// 32 byte Weld; sha256(sha256(EntryHash + ChainID))
-	if cid, err := hex.DecodeString(c.ChainID); err != nil {
-		return nil, err
-	} else {
-		s := append(e.Hash(), cid...)
-		buf.Write(shad(s))
-	}
+	buf.Write(h.Weld)
 
 	// 32 byte Entry Hash of the First Entry
-	buf.Write(e.Hash())
+	buf.Write(h.EntryHash)
 
 	// 1 byte number of Entry Credits to pay
 	if d, err := EntryCost(e); err != nil {
@@ -83,9 +228,15 @@ func ComposeChainCommit(c *Chain, ec *ECAddress) (*JSON2Request, error) {
 	}
 
 	// 32 byte Entry Credit Address Public Key + 64 byte Signature
-	sig := ec.Sign(buf.Bytes())
-	buf.Write(ec.PubBytes())
-	buf.Write(sig[:])
+	sig, err := sign(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if len(ecPub) != 32 || len(sig) != 64 {
+		return nil, validationErrorf("invalid entry credit public key or signature")
+	}
+	buf.Write(ecPub)
+	buf.Write(sig)
 
 	params := messageRequest{Message: hex.EncodeToString(buf.Bytes())}
 	req := NewJSON2Request("commit-chain", APICounter(), params)
@@ -109,8 +260,15 @@ func ComposeChainReveal(c *Chain) (*JSON2Request, error) {
 // CommitChain sends the signed ChainID, the Entry Hash, and the Entry Credit
 // public key to the factom network. Once the payment is verified and the
 // network is commited to publishing the Chain it may be published by revealing
-// the First Entry in the Chain.
+// the First Entry in the Chain. The commit is counted against the ECBudget of
+// the Client, if any.
 func CommitChain(c *Chain, ec *ECAddress) (string, error) {
+	return CommitChainWithContext(context.Background(), c, ec)
+}
+
+// CommitChainWithContext is like CommitChain but cancels its requests when ctx
+// is done.
+func CommitChainWithContext(ctx context.Context, c *Chain, ec *ECAddress) (string, error) {
 	type commitResponse struct {
 		Message string `json:"message"`
 		TxID    string `json:"txid"`
@@ -120,16 +278,24 @@ func CommitChain(c *Chain, ec *ECAddress) (string, error) {
 	if err != nil {
 		return "", err
 	}
-
-	resp, err := factomdRequest(req)
+	cost, err := EntryCost(c.FirstEntry)
 	if err != nil {
 		return "", err
 	}
-	if resp.Error != nil {
-		return "", resp.Error
-	}
+
+	// a new chain costs 10 Entry Credits on top of its First Entry
 	r := new(commitResponse)
-	if err := json.Unmarshal(resp.JSONResult(), r); err != nil {
+	err = spendEC(ctx, ec, cost+10, func() error {
+		resp, err := factomdRequest(ctx, req)
+		if err != nil {
+			return err
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		return json.Unmarshal(resp.JSONResult(), r)
+	})
+	if err != nil {
 		return "", err
 	}
 
@@ -137,6 +303,12 @@ func CommitChain(c *Chain, ec *ECAddress) (string, error) {
 }
 
 func RevealChain(c *Chain) (string, error) {
+	return RevealChainWithContext(context.Background(), c)
+}
+
+// RevealChainWithContext is like RevealChain but cancels its requests when ctx
+// is done.
+func RevealChainWithContext(ctx context.Context, c *Chain) (string, error) {
 	type revealResponse struct {
 		Message string `json:"message"`
 		Entry   string `json:"entryhash"`
@@ -147,7 +319,7 @@ func RevealChain(c *Chain) (string, error) {
 		return "", err
 	}
 
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return "", err
 	}
@@ -161,3 +333,32 @@ func RevealChain(c *Chain) (string, error) {
 	}
 	return r.Entry, nil
 }
+
+// ChainSubmission is the result of SubmitChain.
+type ChainSubmission struct {
+	ChainID   string `json:"chainid"`
+	TxID      string `json:"txid"`
+	EntryHash string `json:"entryhash"`
+}
+
+// SubmitChain commits the Chain, paying with the Entry Credit address ec, and
+// then reveals its First Entry. It returns the chainid, the transaction id of
+// the commit and the Entry Hash of the First Entry. Errors are *SubmitError;
+// after a failed reveal the Chain can be revealed again with RevealChain.
+func SubmitChain(c *Chain, ec *ECAddress) (*ChainSubmission, error) {
+	return SubmitChainWithContext(context.Background(), c, ec)
+}
+
+// SubmitChainWithContext is like SubmitChain but cancels its requests when ctx
+// is done.
+func SubmitChainWithContext(ctx context.Context, c *Chain, ec *ECAddress) (*ChainSubmission, error) {
+	txid, err := CommitChainWithContext(ctx, c, ec)
+	if err != nil {
+		return nil, &SubmitError{Method: "commit-chain", Err: err}
+	}
+	hash, err := RevealChainWithContext(ctx, c)
+	if err != nil {
+		return nil, &SubmitError{Method: "reveal-chain", TxID: txid, Err: err}
+	}
+	return &ChainSubmission{ChainID: c.ChainID, TxID: txid, EntryHash: hash}, nil
+}
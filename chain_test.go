@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -36,6 +37,107 @@ func TestNewChain(t *testing.T) {
 	}
 }
 
+func TestChainMarshalBinary(t *testing.T) {
+	chains := []*Entry{
+		{ExtIDs: [][]byte{[]byte("This is the first extid."), []byte("This is the second extid.")}, Content: []byte("This is a test Entry.")},
+		{ExtIDs: [][]byte{[]byte("no content")}},
+		{ExtIDs: [][]byte{{}, []byte("empty extid")}, Content: bytes.Repeat([]byte{0xff}, 10000)},
+		{Content: []byte("no extids")},
+	}
+	for i, e := range chains {
+		c := NewChain(e)
+		data, err := c.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c2 := new(Chain)
+		if err := c2.UnmarshalBinary(data); err != nil {
+			t.Errorf("chain %d: %v", i, err)
+			continue
+		}
+		if c2.ChainID != c.ChainID || !bytes.Equal(c2.FirstEntry.Hash(), e.Hash()) {
+			t.Errorf("chain %d: got chain %s", i, c2.ChainID)
+		}
+		data2, err := c2.MarshalBinary()
+		if err != nil || !bytes.Equal(data, data2) {
+			t.Errorf("chain %d: round trip gave %x, expecting %x", i, data2, data)
+		}
+	}
+
+	// an entry that is not the first entry of its chain
+	e := &Entry{ChainID: chains[0].ChainID, ExtIDs: [][]byte{[]byte("other")}}
+	data, _ := e.MarshalBinary()
+	if err := new(Chain).UnmarshalBinary(data); err == nil {
+		t.Error("expected an error for a chainid that does not match the ExtIDs")
+	}
+}
+
+func TestChainIDFromExtIDs(t *testing.T) {
+	ids := StringExtIDs("This is the first extid.", "This is the second extid.")
+	if id := ChainIDFromExtIDs(ids); id != "5a402200c5cf278e47905ce52d7d64529a0291829a7bd230072c5468be709069" {
+		t.Errorf("chainid %s", id)
+	}
+}
+
+func TestChainMarshalJSON(t *testing.T) {
+	ent := new(Entry)
+	ent.Content = []byte("This is a test Entry.")
+	ent.ExtIDs = append(ent.ExtIDs, []byte("This is the first extid."))
+	ent.ExtIDs = append(ent.ExtIDs, []byte("This is the second extid."))
+	c := &Chain{FirstEntry: ent}
+
+	expected := `{"chainid":"5a402200c5cf278e47905ce52d7d64529a0291829a7bd230072c5468be709069","firstentry":{"chainid":"","extids":["54686973206973207468652066697273742065787469642e","5468697320697320746865207365636f6e642065787469642e"],"content":"546869732069732061207465737420456e7472792e"}}`
+	p, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(p) != expected {
+		t.Errorf("got %s\nexpecting %s", p, expected)
+	}
+
+	// the chainids are derived from the ExtIDs of the First Entry
+	c2 := new(Chain)
+	if err := json.Unmarshal(p, c2); err != nil {
+		t.Fatal(err)
+	}
+	if c2.ChainID != c.ID() || c2.FirstEntry.ChainID != c2.ChainID {
+		t.Errorf("chainids %s %s", c2.ChainID, c2.FirstEntry.ChainID)
+	}
+	if !bytes.Equal(c2.FirstEntry.Hash(), NewChain(ent).FirstEntry.Hash()) {
+		t.Error("First Entry changed")
+	}
+}
+
+func TestChainID(t *testing.T) {
+	ent := new(Entry)
+	ent.Content = []byte("This is a test Entry.")
+	ent.ExtIDs = append(ent.ExtIDs, []byte("This is the first extid."))
+	ent.ExtIDs = append(ent.ExtIDs, []byte("This is the second extid."))
+
+	c := NewChain(ent)
+	expectedID := "5a402200c5cf278e47905ce52d7d64529a0291829a7bd230072c5468be709069"
+	if id := c.ID(); id != expectedID {
+		t.Errorf("chainid = %s expecting %s", id, expectedID)
+	}
+
+	// changing an ExtID in place must invalidate the cached chainid
+	ent.ExtIDs[1][0] = 't'
+	if id := c.ID(); id == expectedID {
+		t.Errorf("chainid was not recomputed after the ExtIDs changed")
+	}
+	ent.ExtIDs[1][0] = 'T'
+	if id := c.ID(); id != expectedID {
+		t.Errorf("chainid = %s expecting %s", id, expectedID)
+	}
+
+	// replacing the first entry must invalidate the cached chainid
+	c.FirstEntry = &Entry{ExtIDs: [][]byte{[]byte("other")}}
+	if id := c.ID(); id == expectedID {
+		t.Errorf("chainid was not recomputed after the first entry changed")
+	}
+}
+
 func TestIfExists(t *testing.T) {
 	simlatedFactomdResponse := `{
   "jsonrpc": "2.0",
@@ -122,6 +224,32 @@ func TestComposeChainCommit(t *testing.T) {
 	}
 }
 
+func TestChainHash(t *testing.T) {
+	ent := new(Entry)
+	ent.Content = []byte("test!")
+	ent.ExtIDs = append(ent.ExtIDs, []byte("test"))
+	newChain := NewChain(ent)
+
+	// the hashes signed in TestComposeChainCommit
+	h, err := newChain.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprintf("%x", h.ChainIDHash) != "516870d4c0e1ee2d5f0d415e51fc10ae6b8d895561e9314afdc33048194d76f0" {
+		t.Errorf("chainid hash %x", h.ChainIDHash)
+	}
+	if fmt.Sprintf("%x", h.Weld) != "7cc61c8a81aea23d76ff6447689757dc1e36af66e300ce3e06b8d816c79acfd2" {
+		t.Errorf("weld %x", h.Weld)
+	}
+	if fmt.Sprintf("%x", h.EntryHash) != "285ed45081d5b8819a678d13c7c2d04f704b34c74e8aaecd9bd34609bee04720" {
+		t.Errorf("entry hash %x", h.EntryHash)
+	}
+
+	if _, err := new(Chain).Hash(); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected a validation error for a chain without entry, got %v", err)
+	}
+}
+
 func TestComposeChainReveal(t *testing.T) {
 
 	ent := new(Entry)
@@ -214,3 +342,58 @@ func TestRevealChain(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestSubmitChain(t *testing.T) {
+	failing := ""
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(JSON2Request)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+		if req.Method == failing {
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "error": {"code": -32011, "message": "Repeated Commit"}}`)
+			return
+		}
+		switch req.Method {
+		case "commit-chain":
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"message": "Chain Commit Success", "txid": "76e123d133a841fe3e08c5e3f3d392f8431f2d7668890c03f003f541efa8fc61"}}`)
+		case "reveal-chain":
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"message": "Entry Reveal Success", "entryhash": "f5c956749fc3eba4acc60fd485fb100e601070a44fcce54ff358d60669854734"}}`)
+		}
+	}))
+	defer ts.Close()
+	SetFactomdServer(ts.URL[7:])
+
+	ent := new(Entry)
+	ent.Content = []byte("test!")
+	ent.ExtIDs = append(ent.ExtIDs, []byte("test"))
+	newChain := NewChain(ent)
+	ecAddr, _ := GetECAddress("Es2Rf7iM6PdsqfYCo3D1tnAR65SkLENyWJG1deUzpRMQmbh9F3eG")
+
+	s, err := SubmitChain(newChain, ecAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ChainID != newChain.ChainID || s.TxID != "76e123d133a841fe3e08c5e3f3d392f8431f2d7668890c03f003f541efa8fc61" ||
+		s.EntryHash != "f5c956749fc3eba4acc60fd485fb100e601070a44fcce54ff358d60669854734" {
+		t.Errorf("got %+v", s)
+	}
+
+	// the error says which call failed and keeps the factomd error
+	for _, method := range []string{"commit-chain", "reveal-chain"} {
+		failing = method
+		_, err := SubmitChain(newChain, ecAddr)
+		var serr *SubmitError
+		if !errors.As(err, &serr) || serr.Method != method {
+			t.Errorf("%s: got %v", method, err)
+			continue
+		}
+		if !errors.Is(err, &JSONError{Code: -32011}) {
+			t.Errorf("%s: expected the factomd error, got %v", method, serr.Err)
+		}
+		if (serr.TxID != "") != (method == "reveal-chain") {
+			t.Errorf("%s: txid = %q", method, serr.TxID)
+		}
+	}
+}
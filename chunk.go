@@ -0,0 +1,276 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+var (
+	// ChunkExtID is the first ExtID of an Entry written by a ChunkWriter
+	// that holds a part of the data. The second ExtID is the 8 byte
+	// sequence number of the part, starting at 0.
+	ChunkExtID = []byte("factom-chunk")
+
+	// ChunkManifestExtID is the first ExtID of the manifest Entry written
+	// by ChunkWriter.Close. It is followed by the 8 byte total length and
+	// the sha256 of the data, and the Content is the 32 byte Entry Hashes
+	// of the parts in order.
+	ChunkManifestExtID = []byte("factom-chunk-manifest")
+)
+
+const (
+	// ChunkSize is the size of the Content of every part written by a
+	// ChunkWriter but the last, what fits in an Entry next to the ExtIDs.
+	ChunkSize = MaxEntryPayload - (2 + 12) - (2 + 8)
+
+	// MaxChunks is the number of parts whose Entry Hashes fit in a
+	// manifest, which limits the data of a ChunkWriter to a little over
+	// 3MB.
+	MaxChunks = (MaxEntryPayload - (2 + 21) - (2 + 8) - (2 + 32)) / 32
+)
+
+// ChunkWriter writes data larger than one Entry to a Chain. The data is
+// split in parts of ChunkSize that are committed and revealed as they fill
+// up, and Close writes the last part and a manifest Entry that lists them.
+// The data is read back from the Entry Hash of the manifest.
+//
+// Once a commit or reveal has failed every call returns the error, and the
+// data must be written again with a new ChunkWriter.
+type ChunkWriter struct {
+	ctx     context.Context
+	chainID string
+	ec      *ECAddress
+
+	buf      []byte
+	chunks   [][]byte
+	size     uint64
+	sum      hash.Hash
+	manifest string
+	err      error
+}
+
+// NewChunkWriter returns a ChunkWriter that writes to the existing Chain
+// chainID, paying with the Entry Credit address ec.
+func NewChunkWriter(chainID string, ec *ECAddress) *ChunkWriter {
+	return NewChunkWriterWithContext(context.Background(), chainID, ec)
+}
+
+// NewChunkWriterWithContext is like NewChunkWriter but cancels its requests
+// when ctx is done.
+func NewChunkWriterWithContext(ctx context.Context, chainID string, ec *ECAddress) *ChunkWriter {
+	return &ChunkWriter{ctx: ctx, chainID: chainID, ec: ec, sum: sha256.New()}
+}
+
+// Write adds p to the data, submitting an Entry for every part that is
+// filled.
+func (w *ChunkWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.manifest != "" {
+		return 0, validationErrorf("write to a closed ChunkWriter")
+	}
+
+	n := 0
+	for len(p) > 0 {
+		k := ChunkSize - len(w.buf)
+		if k > len(p) {
+			k = len(p)
+		}
+		w.buf = append(w.buf, p[:k]...)
+		w.sum.Write(p[:k])
+		w.size += uint64(k)
+		n += k
+		p = p[k:]
+
+		if len(w.buf) == ChunkSize {
+			if err := w.flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// flush submits the buffered part.
+func (w *ChunkWriter) flush() error {
+	if len(w.chunks) == MaxChunks {
+		w.err = validationErrorf("data is larger than %d parts", MaxChunks)
+		return w.err
+	}
+	e := &Entry{
+		ChainID: w.chainID,
+		ExtIDs:  [][]byte{ChunkExtID, Uint64ExtID(uint64(len(w.chunks)))},
+		Content: w.buf,
+	}
+	if _, _, err := SubmitEntryWithContext(w.ctx, e, w.ec); err != nil {
+		w.err = err
+		return err
+	}
+	w.chunks = append(w.chunks, e.Hash())
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// Close submits the last part and the manifest. Closing again does nothing.
+func (w *ChunkWriter) Close() error {
+	if w.manifest != "" {
+		return nil
+	}
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.buf) > 0 {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+
+	m := &Entry{
+		ChainID: w.chainID,
+		ExtIDs:  [][]byte{ChunkManifestExtID, Uint64ExtID(w.size), w.sum.Sum(nil)},
+		Content: bytes.Join(w.chunks, nil),
+	}
+	if _, _, err := SubmitEntryWithContext(w.ctx, m, w.ec); err != nil {
+		w.err = err
+		return err
+	}
+	w.manifest = hex.EncodeToString(m.Hash())
+	return nil
+}
+
+// Manifest returns the Entry Hash of the manifest once Close has succeeded,
+// or "" before.
+func (w *ChunkWriter) Manifest() string {
+	return w.manifest
+}
+
+// ChunkReader reads back the data written by a ChunkWriter. Every part is
+// checked against its Entry Hash in the manifest as it is fetched, and the
+// length and sha256 of the whole data are checked before Read returns
+// io.EOF. Errors from bad parts match ErrValidation.
+type ChunkReader struct {
+	ctx     context.Context
+	chainID string
+
+	chunks [][]byte
+	size   uint64
+	want   []byte
+
+	next int
+	buf  []byte
+	read uint64
+	sum  hash.Hash
+	err  error
+}
+
+// NewChunkReader fetches the manifest Entry with the Entry Hash manifest from
+// the Chain chainID and returns a ChunkReader for its data.
+func NewChunkReader(chainID, manifest string) (*ChunkReader, error) {
+	return NewChunkReaderWithContext(context.Background(), chainID, manifest)
+}
+
+// NewChunkReaderWithContext is like NewChunkReader but cancels its requests
+// when ctx is done.
+func NewChunkReaderWithContext(ctx context.Context, chainID, manifest string) (*ChunkReader, error) {
+	m, err := getChunkEntry(ctx, chainID, manifest)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.ExtIDs) != 3 || !bytes.Equal(m.ExtIDs[0], ChunkManifestExtID) ||
+		len(m.ExtIDs[2]) != sha256.Size || len(m.Content)%32 != 0 {
+		return nil, validationErrorf("entry %s is not a chunk manifest", manifest)
+	}
+	size, err := m.ExtIDUint64(1)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &ChunkReader{ctx: ctx, chainID: chainID, size: size, want: m.ExtIDs[2], sum: sha256.New()}
+	for p := m.Content; len(p) > 0; p = p[32:] {
+		r.chunks = append(r.chunks, p[:32])
+	}
+	return r, nil
+}
+
+// Size returns the length of the data given by the manifest.
+func (r *ChunkReader) Size() uint64 {
+	return r.size
+}
+
+// Read reads the data, fetching the parts in order as they are needed.
+func (r *ChunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.next == len(r.chunks) {
+			r.err = r.verify()
+			continue
+		}
+		r.err = r.fetch()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// fetch reads the next part into buf.
+func (r *ChunkReader) fetch() error {
+	hash := hex.EncodeToString(r.chunks[r.next])
+	e, err := getChunkEntry(r.ctx, r.chainID, hash)
+	if err != nil {
+		return err
+	}
+	if len(e.ExtIDs) != 2 || !bytes.Equal(e.ExtIDs[0], ChunkExtID) {
+		return validationErrorf("entry %s is not a chunk", hash)
+	}
+	if n, err := e.ExtIDUint64(1); err != nil || n != uint64(r.next) {
+		return validationErrorf("entry %s is not part %d", hash, r.next)
+	}
+
+	r.read += uint64(len(e.Content))
+	if r.read > r.size {
+		return validationErrorf("chunks are longer than the %d bytes of the manifest", r.size)
+	}
+	r.sum.Write(e.Content)
+	r.buf = e.Content
+	r.next++
+	return nil
+}
+
+// verify checks the whole data once every part has been read. It returns
+// io.EOF if the data is the one described by the manifest.
+func (r *ChunkReader) verify() error {
+	if r.read != r.size {
+		return validationErrorf("chunks are %d bytes, the manifest gives %d", r.read, r.size)
+	}
+	if !bytes.Equal(r.sum.Sum(nil), r.want) {
+		return validationErrorf("chunks do not match the sha256 of the manifest")
+	}
+	return io.EOF
+}
+
+// getChunkEntry fetches the Entry with the Entry Hash hash and checks that it
+// has that hash and belongs to the Chain chainID.
+func getChunkEntry(ctx context.Context, chainID, hash string) (*Entry, error) {
+	e, err := GetEntryWithContext(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if hex.EncodeToString(e.Hash()) != hash {
+		return nil, validationErrorf("entry does not match its Entry Hash %s", hash)
+	}
+	if e.ChainID != chainID {
+		return nil, validationErrorf("entry %s is in chain %s, not %s", hash, e.ChainID, chainID)
+	}
+	return e, nil
+}
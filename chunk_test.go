@@ -0,0 +1,205 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/FactomProject/factom"
+)
+
+// chunkFactomd is a factomd that keeps the revealed entries by Entry Hash.
+type chunkFactomd struct {
+	*httptest.Server
+	entries  map[string]*Entry
+	revealed []*Entry
+	fail     bool
+}
+
+func newChunkFactomd(t *testing.T) *chunkFactomd {
+	f := &chunkFactomd{entries: make(map[string]*Entry)}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(JSON2Request)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+		params := make(map[string]string)
+		json.Unmarshal(req.Params, &params)
+
+		switch req.Method {
+		case "commit-entry":
+			if f.fail {
+				fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "error": {"code": -32011, "message": "Repeated Commit"}}`)
+				return
+			}
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"message": "Entry Commit Success", "txid": "bf12150038699f678ac2314e9fa2d4786dc8984d9b8c67dab8cd7c2f2e83372c"}}`)
+		case "reveal-entry":
+			p, _ := hex.DecodeString(params["entry"])
+			e := new(Entry)
+			if err := e.UnmarshalBinary(p); err != nil {
+				t.Error(err)
+			}
+			hash := hex.EncodeToString(e.Hash())
+			f.entries[hash] = e
+			f.revealed = append(f.revealed, e)
+			fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 0, "result": {"message": "Entry Reveal Success", "entryhash": "%s"}}`, hash)
+		case "entry":
+			e, ok := f.entries[params["hash"]]
+			if !ok {
+				fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "error": {"code": -32009, "message": "Missing Chain Head"}}`)
+				return
+			}
+			p, _ := json.Marshal(e)
+			fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 0, "result": %s}`, p)
+		}
+	}))
+	return f
+}
+
+func TestChunkWriter(t *testing.T) {
+	f := newChunkFactomd(t)
+	defer f.Close()
+	SetFactomdServer(f.URL[7:])
+
+	chainID := "954d5a49fd70d9b8bcdb35d252267829957f7ef7fa6c74f88419bdc5e82209f4"
+	ecAddr, _ := GetECAddress("Es2Rf7iM6PdsqfYCo3D1tnAR65SkLENyWJG1deUzpRMQmbh9F3eG")
+	data := bytes.Repeat([]byte("0123456789"), 2500)
+
+	w := NewChunkWriter(chainID, ecAddr)
+	for p := data; len(p) > 0; p = p[1000:] {
+		if _, err := w.Write(p[:1000]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if w.Manifest() != "" {
+		t.Error("manifest before Close")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// three parts and the manifest
+	if len(f.revealed) != 4 {
+		t.Fatalf("revealed %d entries", len(f.revealed))
+	}
+	var joined []byte
+	for i, e := range f.revealed[:3] {
+		if e.ChainID != chainID || !bytes.Equal(e.ExtIDs[0], ChunkExtID) || binary.BigEndian.Uint64(e.ExtIDs[1]) != uint64(i) {
+			t.Errorf("part %d: %v", i, e)
+		}
+		if err := e.Valid(); err != nil {
+			t.Errorf("part %d: %v", i, err)
+		}
+		joined = append(joined, e.Content...)
+	}
+	if !bytes.Equal(joined, data) || len(f.revealed[0].Content) != ChunkSize {
+		t.Error("the parts do not hold the data")
+	}
+
+	m := f.revealed[3]
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(m.Hash()) != w.Manifest() || !bytes.Equal(m.ExtIDs[0], ChunkManifestExtID) {
+		t.Errorf("manifest %s: %v", w.Manifest(), m)
+	}
+	if binary.BigEndian.Uint64(m.ExtIDs[1]) != uint64(len(data)) || !bytes.Equal(m.ExtIDs[2], sum[:]) {
+		t.Error("wrong manifest length or hash")
+	}
+	if !bytes.Equal(m.Content[32:64], f.revealed[1].Hash()) || len(m.Content) != 3*32 {
+		t.Error("wrong manifest parts")
+	}
+
+	if err := w.Close(); err != nil || len(f.revealed) != 4 {
+		t.Errorf("closing again: %v", err)
+	}
+	if _, err := w.Write(data); err == nil {
+		t.Error("expected an error writing after Close")
+	}
+
+	// a failed commit stops the writer
+	f.fail = true
+	w = NewChunkWriter(chainID, ecAddr)
+	if _, err := w.Write(data); err == nil {
+		t.Error("expected the commit to fail")
+	}
+	if err := w.Close(); err == nil || w.Manifest() != "" {
+		t.Errorf("Close after a failed commit: %v", err)
+	}
+}
+
+func TestChunkReader(t *testing.T) {
+	f := newChunkFactomd(t)
+	defer f.Close()
+	SetFactomdServer(f.URL[7:])
+
+	chainID := "954d5a49fd70d9b8bcdb35d252267829957f7ef7fa6c74f88419bdc5e82209f4"
+	ecAddr, _ := GetECAddress("Es2Rf7iM6PdsqfYCo3D1tnAR65SkLENyWJG1deUzpRMQmbh9F3eG")
+	data := bytes.Repeat([]byte("0123456789"), 2500)
+
+	w := NewChunkWriter(chainID, ecAddr)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewChunkReader(chainID, w.Manifest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Size() != uint64(len(data)) {
+		t.Errorf("size = %d", r.Size())
+	}
+	p, err := ioutil.ReadAll(r)
+	if err != nil || !bytes.Equal(p, data) {
+		t.Errorf("read %d bytes: %v", len(p), err)
+	}
+
+	// the manifest must be in the chain
+	if _, err := NewChunkReader("0000000000000000000000000000000000000000000000000000000000000000", w.Manifest()); !errors.Is(err, ErrValidation) {
+		t.Errorf("other chain: got %v", err)
+	}
+	// a part is not a manifest
+	if _, err := NewChunkReader(chainID, hex.EncodeToString(f.revealed[0].Hash())); !errors.Is(err, ErrValidation) {
+		t.Errorf("not a manifest: got %v", err)
+	}
+
+	// a part that does not match its Entry Hash is an error
+	f.entries[hex.EncodeToString(f.revealed[1].Hash())] = &Entry{
+		ChainID: chainID,
+		ExtIDs:  f.revealed[1].ExtIDs,
+		Content: []byte("tampered"),
+	}
+	r, _ = NewChunkReader(chainID, w.Manifest())
+	if _, err := ioutil.ReadAll(r); !errors.Is(err, ErrValidation) {
+		t.Errorf("tampered part: got %v", err)
+	}
+
+	// so is a manifest with the wrong sha256 of the data
+	m := &Entry{
+		ChainID: chainID,
+		ExtIDs:  [][]byte{ChunkManifestExtID, Uint64ExtID(uint64(len(f.revealed[0].Content))), make([]byte, 32)},
+		Content: f.revealed[0].Hash(),
+	}
+	f.entries[hex.EncodeToString(m.Hash())] = m
+	r, err = NewChunkReader(chainID, hex.EncodeToString(m.Hash()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioutil.ReadAll(r); !errors.Is(err, ErrValidation) {
+		t.Errorf("wrong sha256: got %v", err)
+	}
+}
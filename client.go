@@ -0,0 +1,246 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	// DefaultFactomdTimeout is the timeout used for factomd api calls when no
+	// other timeout has been configured.
+	DefaultFactomdTimeout = time.Second * 30
+)
+
+// Client holds the settings used to reach the factomd and factom-walletd apis.
+// The package level api functions use the default Client, which reads its
+// settings from RpcConfig unless SetDefaultClient has been called.
+type Client struct {
+	Config         *RPCConfig
+	FactomdTimeout time.Duration
+	WalletTimeout  time.Duration
+	Logger         *log.Logger
+
+	// FactomdRetry retries the factomd calls that fail because of a network
+	// error or an unavailable server. Calls are not retried when it is nil.
+	FactomdRetry *RetryPolicy
+
+	// ECBudget limits the Entry Credits spent by CommitEntry and
+	// CommitChain. There is no limit when it is nil.
+	ECBudget *ECBudget
+
+	// FactomdCache keeps the results of factomd calls for Entries and
+	// blocks. Nothing is cached when it is nil.
+	FactomdCache *Cache
+
+	// WalletHMACKeyID and WalletHMACSecret sign every request sent to
+	// factom-walletd when WalletHMACKeyID is set. See SignRequest.
+	WalletHMACKeyID  string
+	WalletHMACSecret []byte
+
+	// WalletToken is sent to factom-walletd as a bearer token in place of
+	// the rpc user and password when it is set.
+	WalletToken string
+}
+
+// Option configures a Client created with NewClient.
+type Option func(*Client)
+
+// NewClient returns a Client configured by the given options. Options that are
+// not given keep their defaults: a localhost factomd and wallet, no
+// credentials, no TLS and DefaultFactomdTimeout for factomd calls.
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		Config: &RPCConfig{
+			FactomdServer: "localhost:8088",
+			WalletServer:  "localhost:8089",
+		},
+		FactomdTimeout: DefaultFactomdTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithFactomdServer sets the host:port (optionally prefixed with a scheme) of
+// the factomd api.
+func WithFactomdServer(s string) Option {
+	return func(c *Client) {
+		c.Config.FactomdServer = s
+	}
+}
+
+// WithFactomdRPCCredentials sets the username and password sent to factomd.
+func WithFactomdRPCCredentials(user, password string) Option {
+	return func(c *Client) {
+		c.Config.FactomdRPCUser = user
+		c.Config.FactomdRPCPassword = password
+	}
+}
+
+// WithFactomdTLS enables TLS for the factomd api using the certificate in
+// certFile. certFile may hold a bundle of CA certificates, or be empty to use
+// the CAs of the system.
+func WithFactomdTLS(certFile string) Option {
+	return func(c *Client) {
+		c.Config.FactomdTLSEnable = true
+		c.Config.FactomdTLSCertFile = certFile
+	}
+}
+
+// WithFactomdClientCert presents the certificate in certFile, with the key
+// in keyFile, to factomd nodes that require client certificates. It only has
+// an effect with TLS enabled.
+func WithFactomdClientCert(certFile, keyFile string) Option {
+	return func(c *Client) {
+		c.Config.FactomdTLSClientCertFile = certFile
+		c.Config.FactomdTLSClientKeyFile = keyFile
+	}
+}
+
+// WithFactomdPins enables TLS for the factomd api and only accepts a server
+// certificate with one of the given SHA-256 fingerprints. The fingerprints
+// are hex, with or without colons. Without a CA file from WithFactomdTLS the
+// pins replace the usual certificate checks.
+func WithFactomdPins(fingerprints ...string) Option {
+	return func(c *Client) {
+		c.Config.FactomdTLSEnable = true
+		c.Config.FactomdTLSPins = fingerprints
+	}
+}
+
+// WithWalletServer sets the host:port of the factom-walletd api.
+func WithWalletServer(s string) Option {
+	return func(c *Client) {
+		c.Config.WalletServer = s
+	}
+}
+
+// WithWalletRPCCredentials sets the username and password sent to
+// factom-walletd.
+func WithWalletRPCCredentials(user, password string) Option {
+	return func(c *Client) {
+		c.Config.WalletRPCUser = user
+		c.Config.WalletRPCPassword = password
+	}
+}
+
+// WithWalletTLS enables TLS for the factom-walletd api using the certificate
+// in certFile.
+func WithWalletTLS(certFile string) Option {
+	return func(c *Client) {
+		c.Config.WalletTLSEnable = true
+		c.Config.WalletTLSCertFile = certFile
+	}
+}
+
+// WithWalletHMAC signs every request sent to factom-walletd with the shared
+// secret registered in the wallet under keyID.
+func WithWalletHMAC(keyID string, secret []byte) Option {
+	return func(c *Client) {
+		c.WalletHMACKeyID = keyID
+		c.WalletHMACSecret = secret
+	}
+}
+
+// WithWalletToken authenticates to factom-walletd with an api token.
+func WithWalletToken(token string) Option {
+	return func(c *Client) {
+		c.WalletToken = token
+	}
+}
+
+// WithFactomdTimeout sets the timeout for factomd api calls. A zero duration
+// means no timeout.
+func WithFactomdTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.FactomdTimeout = d
+	}
+}
+
+// WithWalletTimeout sets the timeout for factom-walletd api calls. A zero
+// duration means no timeout.
+func WithWalletTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.WalletTimeout = d
+	}
+}
+
+// WithFactomdRetry retries failed factomd calls according to p.
+func WithFactomdRetry(p *RetryPolicy) Option {
+	return func(c *Client) {
+		c.FactomdRetry = p
+	}
+}
+
+// WithFactomdCache keeps the Entries and blocks fetched from factomd in c.
+func WithFactomdCache(c *Cache) Option {
+	return func(cl *Client) {
+		cl.FactomdCache = c
+	}
+}
+
+// WithECBudget limits the Entry Credits spent by commits to b.
+func WithECBudget(b *ECBudget) Option {
+	return func(c *Client) {
+		c.ECBudget = b
+	}
+}
+
+// WithLogger logs every api request made by the Client to l.
+func WithLogger(l *log.Logger) Option {
+	return func(c *Client) {
+		c.Logger = l
+	}
+}
+
+// defaultClient is used by the package level api functions. When it is nil the
+// settings are read from RpcConfig.
+var defaultClient *Client
+
+// SetDefaultClient makes the package level api functions use c. Passing nil
+// restores the default behavior of reading the settings from RpcConfig.
+func SetDefaultClient(c *Client) {
+	defaultClient = c
+}
+
+// DefaultClient returns the Client used by the package level api functions.
+func DefaultClient() *Client {
+	if defaultClient != nil {
+		return defaultClient
+	}
+	return &Client{Config: RpcConfig, FactomdTimeout: DefaultFactomdTimeout, FactomdRetry: factomdRetry, FactomdCache: factomdCache, ECBudget: ecBudget}
+}
+
+// FactomdRequest sends a json object to the factomd api of the Client.
+func (c *Client) FactomdRequest(req *JSON2Request) (*JSON2Response, error) {
+	return c.factomdRequest(context.Background(), req)
+}
+
+// FactomdRequestWithContext is like FactomdRequest but cancels the request
+// when ctx is done.
+func (c *Client) FactomdRequestWithContext(ctx context.Context, req *JSON2Request) (*JSON2Response, error) {
+	return c.factomdRequest(ctx, req)
+}
+
+// WalletRequest sends a json object to the factom-walletd api of the Client.
+func (c *Client) WalletRequest(req *JSON2Request) (*JSON2Response, error) {
+	return c.walletRequest(context.Background(), req)
+}
+
+// WalletRequestWithContext is like WalletRequest but cancels the request
+// when ctx is done.
+func (c *Client) WalletRequestWithContext(ctx context.Context, req *JSON2Request) (*JSON2Response, error) {
+	return c.walletRequest(ctx, req)
+}
+
+func (c *Client) logf(format string, v ...interface{}) {
+	if c.Logger != nil {
+		c.Logger.Printf(format, v...)
+	}
+}
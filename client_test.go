@@ -0,0 +1,205 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestNewClient(t *testing.T) {
+	c := NewClient()
+	if c.Config.FactomdServer != "localhost:8088" {
+		t.Errorf("default factomd server = %s", c.Config.FactomdServer)
+	}
+	if c.FactomdTimeout != DefaultFactomdTimeout {
+		t.Errorf("default factomd timeout = %s", c.FactomdTimeout)
+	}
+
+	c = NewClient(
+		WithFactomdServer("factomd:8088"),
+		WithFactomdRPCCredentials("user", "pass"),
+		WithWalletServer("walletd:8089"),
+		WithFactomdTimeout(time.Second),
+	)
+	if c.Config.FactomdServer != "factomd:8088" {
+		t.Errorf("factomd server = %s", c.Config.FactomdServer)
+	}
+	if c.Config.FactomdRPCUser != "user" || c.Config.FactomdRPCPassword != "pass" {
+		t.Errorf("wrong factomd credentials %s %s", c.Config.FactomdRPCUser, c.Config.FactomdRPCPassword)
+	}
+	if c.Config.WalletServer != "walletd:8089" {
+		t.Errorf("wallet server = %s", c.Config.WalletServer)
+	}
+	if c.FactomdTimeout != time.Second {
+		t.Errorf("factomd timeout = %s", c.FactomdTimeout)
+	}
+}
+
+func TestSetDefaultClient(t *testing.T) {
+	simlatedFactomdResponse := `{
+  "jsonrpc": "2.0",
+  "id": 0,
+  "result": {
+    "rate": 95369
+  }
+}`
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, simlatedFactomdResponse)
+	}))
+	defer ts.Close()
+
+	SetDefaultClient(NewClient(
+		WithFactomdServer(ts.URL[7:]),
+		WithFactomdRPCCredentials("user", "pass"),
+	))
+	defer SetDefaultClient(nil)
+
+	response, err := GetRate()
+	if err != nil {
+		t.Error(err)
+	}
+	if response != 95369 {
+		t.Errorf("rate = %d expecting %d", response, 95369)
+	}
+}
+
+func TestWithWalletToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"height": 10}}`)
+	}))
+	defer ts.Close()
+
+	SetDefaultClient(NewClient(
+		WithWalletServer(ts.URL[7:]),
+		WithWalletToken("secret-token"),
+	))
+	defer SetDefaultClient(nil)
+
+	height, err := GetWalletHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height != 10 {
+		t.Errorf("height = %d expecting 10", height)
+	}
+}
+
+func TestFactomdRpcConfig(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"rate": 1000}}`)
+	}))
+	defer ts.Close()
+
+	SetFactomdServer(ts.URL[7:])
+	SetFactomdRpcConfig("user", "pass")
+	defer SetFactomdRpcConfig("", "")
+
+	if rate, err := GetRate(); err != nil || rate != 1000 {
+		t.Errorf("GetRate = %d, %v", rate, err)
+	}
+}
+
+func TestGetRateWithContext(t *testing.T) {
+	// factomd does not answer until the test is over
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer ts.Close()
+	defer close(done)
+	SetDefaultClient(NewClient(WithFactomdServer(ts.URL[7:])))
+	defer SetDefaultClient(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := GetRateWithContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+}
+
+func TestWithFactomdRetry(t *testing.T) {
+	// factomd is unavailable for the first two calls
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"rate": 1000}}`)
+	}))
+	defer ts.Close()
+
+	p := &RetryPolicy{MaxAttempts: 3, Delay: time.Millisecond, Backoff: 2, Jitter: 0.5}
+	SetDefaultClient(NewClient(WithFactomdServer(ts.URL[7:]), WithFactomdRetry(p)))
+	defer SetDefaultClient(nil)
+
+	if rate, err := GetRate(); err != nil || rate != 1000 || calls != 3 {
+		t.Errorf("GetRate = %d, %v after %d calls", rate, err, calls)
+	}
+
+	// the error of the last attempt is returned when they all fail
+	calls = -10
+	if _, err := GetRate(); !errors.Is(err, ErrNetwork) || calls != -7 {
+		t.Errorf("got %v after %d calls, expecting a network error", err, calls+10)
+	}
+
+	// Retryable decides which errors are retried
+	p.Retryable = func(err error) bool { return false }
+	calls = 0
+	if _, err := GetRate(); err == nil || calls != 1 {
+		t.Errorf("got %v after %d calls, expecting 1 call", err, calls)
+	}
+}
+
+func TestFactomdRetrySkipsSubmits(t *testing.T) {
+	// factomd applies the transaction but answers too late
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"message": "Successfully submitted the transaction", "txid": "00"}}`)
+	}))
+	defer ts.Close()
+
+	p := &RetryPolicy{MaxAttempts: 3, Delay: time.Millisecond}
+	SetDefaultClient(NewClient(WithFactomdServer(ts.URL[7:]), WithFactomdRetry(p),
+		WithFactomdTimeout(10*time.Millisecond)))
+	defer SetDefaultClient(nil)
+
+	req := NewJSON2Request("factoid-submit", APICounter(), map[string]string{"transaction": "00"})
+	if _, err := SendFactomdRequest(req); !errors.Is(err, ErrNetwork) || atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("got %v after %d calls, expecting 1 call", err, atomic.LoadInt32(&calls))
+	}
+
+	// reads are still retried
+	atomic.StoreInt32(&calls, 0)
+	if _, err := GetRate(); !errors.Is(err, ErrNetwork) || atomic.LoadInt32(&calls) != 3 {
+		t.Errorf("got %v after %d calls, expecting 3 calls", err, atomic.LoadInt32(&calls))
+	}
+}
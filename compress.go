@@ -0,0 +1,84 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+)
+
+// GzipExtID is the last ExtID of an Entry whose Content has been compressed
+// by CompressContent.
+var GzipExtID = []byte("content-encoding: gzip")
+
+// maxDecompressedContent limits the size of decompressed Content, so that a
+// crafted Entry can not exhaust memory.
+const maxDecompressedContent = 16 << 20
+
+// CompressContent gzips the Content of the Entry and appends GzipExtID to its
+// ExtIDs, so that more data fits in an Entry. The First Entry of a Chain must
+// be compressed before NewChain, since the marker changes the chainid.
+func (e *Entry) CompressContent() error {
+	if e.IsCompressed() {
+		return validationErrorf("entry content is already compressed")
+	}
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(e.Content); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	e.Content = buf.Bytes()
+	e.ExtIDs = append(e.ExtIDs, append([]byte(nil), GzipExtID...))
+	return nil
+}
+
+// IsCompressed reports whether the last ExtID of the Entry is GzipExtID.
+func (e *Entry) IsCompressed() bool {
+	return len(e.ExtIDs) > 0 && bytes.Equal(e.ExtIDs[len(e.ExtIDs)-1], GzipExtID)
+}
+
+// ReadContent returns the Content of the Entry, decompressed if it was
+// compressed by CompressContent.
+func (e *Entry) ReadContent() ([]byte, error) {
+	if !e.IsCompressed() {
+		return e.Content, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(e.Content))
+	if err != nil {
+		return nil, validationErrorf("entry content is not gzip compressed: %s", err)
+	}
+	p, err := ioutil.ReadAll(io.LimitReader(r, maxDecompressedContent+1))
+	if err != nil {
+		return nil, validationErrorf("entry content can not be decompressed: %s", err)
+	}
+	if len(p) > maxDecompressedContent {
+		return nil, validationErrorf("decompressed entry content is larger than %d bytes", maxDecompressedContent)
+	}
+	return p, nil
+}
+
+// DecompressContent replaces the compressed Content of the Entry with the
+// original and removes GzipExtID, undoing CompressContent. It does nothing
+// to an Entry that is not compressed.
+func (e *Entry) DecompressContent() error {
+	if !e.IsCompressed() {
+		return nil
+	}
+	p, err := e.ReadContent()
+	if err != nil {
+		return err
+	}
+	e.Content = p
+	e.ExtIDs = e.ExtIDs[:len(e.ExtIDs)-1]
+	return nil
+}
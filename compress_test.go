@@ -0,0 +1,60 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestCompressContent(t *testing.T) {
+	content := bytes.Repeat([]byte(`{"invoice": 42, "paid": true}`), 1000)
+	e := &Entry{
+		ChainID: "5a402200c5cf278e47905ce52d7d64529a0291829a7bd230072c5468be709069",
+		ExtIDs:  StringExtIDs("invoices"),
+		Content: content,
+	}
+	if err := e.Valid(); err == nil {
+		t.Fatal("expected the uncompressed content to be too large")
+	}
+
+	if err := e.CompressContent(); err != nil {
+		t.Fatal(err)
+	}
+	if !e.IsCompressed() || len(e.ExtIDs) != 2 {
+		t.Errorf("expected the marker ExtID, got %q", e.ExtIDs)
+	}
+	if err := e.Valid(); err != nil {
+		t.Errorf("compressed entry: %v", err)
+	}
+	if err := e.CompressContent(); !errors.Is(err, ErrValidation) {
+		t.Errorf("compressing twice: got %v", err)
+	}
+
+	// the content survives a trip through the network format
+	data, _ := e.MarshalBinary()
+	e2 := new(Entry)
+	if err := e2.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := e2.ReadContent(); err != nil || !bytes.Equal(p, content) {
+		t.Errorf("ReadContent: %v", err)
+	}
+	if err := e2.DecompressContent(); err != nil || e2.IsCompressed() || len(e2.ExtIDs) != 1 || !bytes.Equal(e2.Content, content) {
+		t.Errorf("DecompressContent: %v", err)
+	}
+
+	// uncompressed content is returned as is, and bad content is an error
+	if p, err := e2.ReadContent(); err != nil || !bytes.Equal(p, content) {
+		t.Errorf("ReadContent of an uncompressed entry: %v", err)
+	}
+	bad := &Entry{ExtIDs: [][]byte{GzipExtID}, Content: []byte("not gzip")}
+	if _, err := bad.ReadContent(); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected an error for bad content, got %v", err)
+	}
+}
@@ -38,3 +38,40 @@ func (d *DBlock) String() string {
 type DBHead struct {
 	KeyMR string `json:"keymr"`
 }
+
+// DirectoryBlock is a Directory Block as returned by the block by height
+// calls, see GetDirectoryBlockByHeight.
+type DirectoryBlock struct {
+	Header struct {
+		Version      int    `json:"version"`
+		NetworkID    uint32 `json:"networkid"`
+		BodyMR       string `json:"bodymr"`
+		PrevKeyMR    string `json:"prevkeymr"`
+		PrevFullHash string `json:"prevfullhash"`
+		Timestamp    int64  `json:"timestamp"` // minutes since the epoch
+		DBHeight     int64  `json:"dbheight"`
+		BlockCount   int    `json:"blockcount"`
+		ChainID      string `json:"chainid"`
+	} `json:"header"`
+	DBEntries []struct {
+		ChainID string `json:"chainid"`
+		KeyMR   string `json:"keymr"`
+	} `json:"dbentries"`
+	DBHash string `json:"dbhash"`
+	KeyMR  string `json:"keymr"`
+}
+
+func (d *DirectoryBlock) String() string {
+	var s string
+	s += fmt.Sprintln("KeyMR:", d.KeyMR)
+	s += fmt.Sprintln("PrevKeyMR:", d.Header.PrevKeyMR)
+	s += fmt.Sprintln("Timestamp:", d.Header.Timestamp)
+	s += fmt.Sprintln("DBHeight:", d.Header.DBHeight)
+	for _, v := range d.DBEntries {
+		s += fmt.Sprintln("DBEntry {")
+		s += fmt.Sprintln("	ChainID", v.ChainID)
+		s += fmt.Sprintln("	KeyMR", v.KeyMR)
+		s += fmt.Sprintln("}")
+	}
+	return s
+}
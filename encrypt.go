@@ -0,0 +1,176 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+)
+
+// EncryptedExtID is the last ExtID of an EncryptedEntry.
+var EncryptedExtID = []byte("content-encryption: aes-256-gcm")
+
+// the modes of encryption, the first byte of the encrypted Content
+const (
+	encryptedWithKey   = 1
+	encryptedForX25519 = 2
+)
+
+// EncryptedEntry is an Entry whose Content is encrypted with AES-256-GCM, so
+// that only the holders of the key can read it. The ChainID and ExtIDs are
+// not encrypted, but they are authenticated with the Content, so that it
+// does not decrypt under other ExtIDs or in another Chain. Commit and reveal
+// the embedded Entry as usual; its MarshalBinary and Hash are those of the
+// encrypted Entry. Content should be compressed before it is encrypted, and
+// the First Entry of a Chain must be encrypted without a ChainID before
+// NewChain, since the marker changes the chainid; it is bound to the Chain it
+// starts.
+type EncryptedEntry struct {
+	Entry
+}
+
+// EncryptEntry returns e with its Content encrypted under the 32 byte key.
+func EncryptEntry(e *Entry, key []byte) (*EncryptedEntry, error) {
+	return encryptEntry(e, key, []byte{encryptedWithKey})
+}
+
+// EncryptEntryTo returns e with its Content encrypted for the holder of the
+// X25519 private key of recipient. A new key pair is made for every Entry.
+func EncryptEntryTo(e *Entry, recipient *ecdh.PublicKey) (*EncryptedEntry, error) {
+	eph, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := eph.ECDH(recipient)
+	if err != nil {
+		return nil, err
+	}
+	key := x25519Key(shared, eph.PublicKey(), recipient)
+	header := append([]byte{encryptedForX25519}, eph.PublicKey().Bytes()...)
+	return encryptEntry(e, key, header)
+}
+
+func encryptEntry(e *Entry, key, header []byte) (*EncryptedEntry, error) {
+	aead, err := entryCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	ids := make([][]byte, len(e.ExtIDs), len(e.ExtIDs)+1)
+	copy(ids, e.ExtIDs)
+	ids = append(ids, append([]byte(nil), EncryptedExtID...))
+	sealed := aead.Seal(nil, nonce, e.Content, entryData(e.ChainID, ids))
+	content := append(append(header, nonce...), sealed...)
+	return &EncryptedEntry{Entry{ChainID: e.ChainID, ExtIDs: ids, Content: content}}, nil
+}
+
+// entryData is the additional data the Content of an Entry in the Chain
+// chainID with the ExtIDs ids is encrypted with. An Entry without a ChainID
+// is the First Entry of the Chain its ExtIDs make.
+func entryData(chainID string, ids [][]byte) []byte {
+	if chainID == "" {
+		chainID = ChainIDFromExtIDs(ids)
+	}
+	e := &Entry{ExtIDs: ids}
+	data, _ := e.MarshalExtIDsBinary()
+	return append([]byte(chainID), data...)
+}
+
+// IsEncrypted reports whether the last ExtID of the Entry is EncryptedExtID.
+func (e *Entry) IsEncrypted() bool {
+	return len(e.ExtIDs) > 0 && bytes.Equal(e.ExtIDs[len(e.ExtIDs)-1], EncryptedExtID)
+}
+
+// Decrypt returns the original Entry of an EncryptedEntry made by
+// EncryptEntry with key.
+func (e *EncryptedEntry) Decrypt(key []byte) (*Entry, error) {
+	body, err := e.body(encryptedWithKey)
+	if err != nil {
+		return nil, err
+	}
+	return e.decrypt(key, body)
+}
+
+// DecryptWith returns the original Entry of an EncryptedEntry made by
+// EncryptEntryTo for the public key of priv.
+func (e *EncryptedEntry) DecryptWith(priv *ecdh.PrivateKey) (*Entry, error) {
+	body, err := e.body(encryptedForX25519)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < 32 {
+		return nil, ErrDecrypt
+	}
+	eph, err := ecdh.X25519().NewPublicKey(body[:32])
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	shared, err := priv.ECDH(eph)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return e.decrypt(x25519Key(shared, eph, priv.PublicKey()), body[32:])
+}
+
+// body returns the encrypted Content after its mode byte, which must be
+// mode.
+func (e *EncryptedEntry) body(mode byte) ([]byte, error) {
+	if !e.IsEncrypted() {
+		return nil, validationErrorf("entry content is not encrypted")
+	}
+	if len(e.Content) == 0 || e.Content[0] != mode {
+		return nil, ErrDecrypt
+	}
+	return e.Content[1:], nil
+}
+
+func (e *EncryptedEntry) decrypt(key, body []byte) (*Entry, error) {
+	aead, err := entryCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	n := aead.NonceSize()
+	plain, err := aead.Open(nil, body[:n], body[n:], entryData(e.ChainID, e.ExtIDs))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	ids := make([][]byte, len(e.ExtIDs)-1)
+	copy(ids, e.ExtIDs)
+	return &Entry{ChainID: e.ChainID, ExtIDs: ids, Content: plain}, nil
+}
+
+func entryCipher(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, validationErrorf("entry encryption key is %d bytes, not 32", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// x25519Key derives the AES key of an Entry encrypted for recipient from the
+// shared secret of the exchange with the ephemeral key eph.
+func x25519Key(shared []byte, eph, recipient *ecdh.PublicKey) []byte {
+	h := sha256.New()
+	h.Write([]byte("factom entry encryption"))
+	h.Write(shared)
+	h.Write(eph.Bytes())
+	h.Write(recipient.Bytes())
+	return h.Sum(nil)
+}
@@ -0,0 +1,129 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestEncryptEntry(t *testing.T) {
+	e := &Entry{
+		ChainID: "5a402200c5cf278e47905ce52d7d64529a0291829a7bd230072c5468be709069",
+		ExtIDs:  StringExtIDs("medical", "records"),
+		Content: []byte("blood type: O negative"),
+	}
+	key := bytes.Repeat([]byte{7}, 32)
+
+	ee, err := EncryptEntry(e, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ee.IsEncrypted() || len(ee.ExtIDs) != 3 || len(e.ExtIDs) != 2 {
+		t.Errorf("expected the marker ExtID on the copy only, got %q", ee.ExtIDs)
+	}
+	if bytes.Contains(ee.Content, e.Content) {
+		t.Error("content is not encrypted")
+	}
+
+	// the encrypted entry survives a trip through the network format
+	data, err := ee.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ee2 := new(EncryptedEntry)
+	if err := ee2.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	d, err := ee2.Decrypt(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.ChainID != e.ChainID || !bytes.Equal(d.Content, e.Content) || len(d.ExtIDs) != 2 {
+		t.Errorf("decrypted %v", d)
+	}
+
+	if _, err := ee2.Decrypt(bytes.Repeat([]byte{8}, 32)); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("wrong key: got %v", err)
+	}
+	if _, err := ee2.Decrypt(key[:16]); !errors.Is(err, ErrValidation) {
+		t.Errorf("short key: got %v", err)
+	}
+	if _, err := EncryptEntry(e, key[:16]); !errors.Is(err, ErrValidation) {
+		t.Errorf("short key: got %v", err)
+	}
+	if _, err := (&EncryptedEntry{*e}).Decrypt(key); !errors.Is(err, ErrValidation) {
+		t.Errorf("unencrypted entry: got %v", err)
+	}
+
+	// the content does not decrypt under other ExtIDs or in another Chain
+	moved := &EncryptedEntry{ee2.Entry}
+	moved.ExtIDs = append(StringExtIDs("medical", "billing"), EncryptedExtID)
+	if _, err := moved.Decrypt(key); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("changed ExtID: got %v", err)
+	}
+	moved = &EncryptedEntry{ee2.Entry}
+	moved.ChainID = "0000000000000000000000000000000000000000000000000000000000000001"
+	if _, err := moved.Decrypt(key); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("changed ChainID: got %v", err)
+	}
+}
+
+func TestEncryptFirstEntry(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	ee, err := EncryptEntry(&Entry{ExtIDs: StringExtIDs("new chain"), Content: []byte("secret")}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the First Entry is bound to the Chain it starts
+	c := NewChain(&ee.Entry)
+	if ee.ChainID != c.ChainID {
+		t.Fatalf("first entry has chainid %q, chain %q", ee.ChainID, c.ChainID)
+	}
+	d, err := ee.Decrypt(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(d.Content) != "secret" {
+		t.Errorf("decrypted %q", d.Content)
+	}
+}
+
+func TestEncryptEntryTo(t *testing.T) {
+	e := &Entry{
+		ChainID: "5a402200c5cf278e47905ce52d7d64529a0291829a7bd230072c5468be709069",
+		Content: []byte("for your eyes only"),
+	}
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ee, err := EncryptEntryTo(e, priv.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := ee.DecryptWith(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(d.Content, e.Content) || len(d.ExtIDs) != 0 {
+		t.Errorf("decrypted %v", d)
+	}
+
+	other, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	if _, err := ee.DecryptWith(other); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("wrong key: got %v", err)
+	}
+	if _, err := ee.Decrypt(bytes.Repeat([]byte{7}, 32)); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("symmetric key: got %v", err)
+	}
+}
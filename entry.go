@@ -6,6 +6,7 @@ package factom
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
@@ -18,6 +19,9 @@ type Entry struct {
 	Content []byte   `json:"content"`
 }
 
+// Hash returns the Entry Hash as computed by factomd,
+// sha256(sha512(entry) + entry) of the marshaled Entry. An Entry that can not
+// be marshaled hashes to 32 zero bytes.
 func (e *Entry) Hash() []byte {
 	a, err := e.MarshalBinary()
 	if err != nil {
@@ -61,6 +65,87 @@ func (e *Entry) MarshalBinary() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// entryHeaderSize is the size of the version, the chainid and the ExtIDs size
+// at the start of a marshaled Entry.
+const entryHeaderSize = 35
+
+// UnmarshalBinary reads an Entry written by MarshalBinary. It fails on
+// anything but a complete version 0 Entry: truncated ExtIDs, or ExtIDs that do
+// not fill exactly the size given in the header, are errors.
+func (e *Entry) UnmarshalBinary(data []byte) error {
+	if len(data) < entryHeaderSize {
+		return validationErrorf("entry is %d bytes, shorter than its %d byte header", len(data), entryHeaderSize)
+	}
+	if data[0] != 0 {
+		return validationErrorf("unsupported entry version %d", data[0])
+	}
+	chainID := hex.EncodeToString(data[1:33])
+	size := int(binary.BigEndian.Uint16(data[33:35]))
+	body := data[entryHeaderSize:]
+	if size > len(body) {
+		return validationErrorf("entry ExtIDs size %d exceeds the %d bytes of its body", size, len(body))
+	}
+
+	var ids [][]byte
+	for p := body[:size]; len(p) > 0; {
+		if len(p) < 2 {
+			return validationErrorf("entry ExtID %d is truncated", len(ids))
+		}
+		l := int(binary.BigEndian.Uint16(p))
+		if l > len(p)-2 {
+			return validationErrorf("entry ExtID %d is truncated", len(ids))
+		}
+		ids = append(ids, append([]byte(nil), p[2:2+l]...))
+		p = p[2+l:]
+	}
+
+	e.ChainID = chainID
+	e.ExtIDs = ids
+	e.Content = append([]byte(nil), body[size:]...)
+	return nil
+}
+
+// MaxEntryPayload is the largest size in bytes of the ExtIDs, each with its 2
+// byte length, and the Content of an Entry.
+const MaxEntryPayload = 10240
+
+// payloadSize returns the size of the ExtIDs and Content of the marshaled
+// Entry, which is what Entry Credits pay for.
+func (e *Entry) payloadSize() int {
+	n := len(e.Content)
+	for _, id := range e.ExtIDs {
+		n += 2 + len(id)
+	}
+	return n
+}
+
+// Valid returns an error matching ErrValidation if the Entry can not be
+// committed: its chainid is not 32 hex encoded bytes or its ExtIDs and
+// Content are larger than MaxEntryPayload.
+func (e *Entry) Valid() error {
+	if p, err := hex.DecodeString(e.ChainID); err != nil || len(p) != 32 {
+		return validationErrorf("invalid chainid %q", e.ChainID)
+	}
+	if n := e.payloadSize(); n > MaxEntryPayload {
+		return validationErrorf("Entry cannot be larger than 10KB, it is %d bytes", n)
+	}
+	return nil
+}
+
+// ECCost returns the number of Entry Credits needed to commit the Entry, one
+// for every KB or part of a KB of its ExtIDs and Content and at least one.
+// Committing the Entry as the First Entry of a new Chain costs 10 more.
+func (e *Entry) ECCost() (int8, error) {
+	if err := e.Valid(); err != nil {
+		return 0, err
+	}
+	n := (e.payloadSize() + 1023) / 1024
+	if n < 1 {
+		n = 1
+	}
+	return int8(n), nil
+}
+
 func (e *Entry) MarshalExtIDsBinary() ([]byte, error) {
 	buf := new(bytes.Buffer)
 
@@ -74,6 +159,8 @@ func (e *Entry) MarshalExtIDsBinary() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// MarshalJSON writes the Entry in the form used by the factomd api, with the
+// ExtIDs and Content hex encoded.
 func (e *Entry) MarshalJSON() ([]byte, error) {
 	type js struct {
 		ChainID string   `json:"chainid"`
@@ -84,6 +171,7 @@ func (e *Entry) MarshalJSON() ([]byte, error) {
 	j := new(js)
 
 	j.ChainID = e.ChainID
+	j.ExtIDs = make([]string, 0, len(e.ExtIDs))
 
 	for _, id := range e.ExtIDs {
 		j.ExtIDs = append(j.ExtIDs, hex.EncodeToString(id))
@@ -106,6 +194,8 @@ func (e *Entry) String() string {
 	return s
 }
 
+// UnmarshalJSON reads an Entry written by MarshalJSON. Instead of a chainid
+// the JSON may give the ExtIDs of the First Entry of the Chain as chainname.
 func (e *Entry) UnmarshalJSON(data []byte) error {
 	type js struct {
 		ChainID   string   `json:"chainid"`
@@ -120,6 +210,7 @@ func (e *Entry) UnmarshalJSON(data []byte) error {
 	}
 
 	e.ChainID = j.ChainID
+	e.ExtIDs = nil
 
 	if e.ChainID == "" {
 		n := new(Entry)
@@ -155,6 +246,14 @@ func (e *Entry) UnmarshalJSON(data []byte) error {
 // factomd web api. The request includes the marshaled MessageRequest with the
 // Entry Credit Signature.
 func ComposeEntryCommit(e *Entry, ec *ECAddress) (*JSON2Request, error) {
+	return ComposeEntryCommitSigner(e, ec.PubBytes(), func(msg []byte) ([]byte, error) {
+		return ec.Sign(msg)[:], nil
+	})
+}
+
+// ComposeEntryCommitSigner creates the same request as ComposeEntryCommit
+// for the Entry Credit public key ecPub, using sign to make the signature.
+func ComposeEntryCommitSigner(e *Entry, ecPub []byte, sign CommitSigner) (*JSON2Request, error) {
 	buf := new(bytes.Buffer)
 
 	// 1 byte version
@@ -174,9 +273,15 @@ func ComposeEntryCommit(e *Entry, ec *ECAddress) (*JSON2Request, error) {
 	}
 
 	// 32 byte Entry Credit Address Public Key + 64 byte Signature
-	sig := ec.Sign(buf.Bytes())
-	buf.Write(ec.PubBytes())
-	buf.Write(sig[:])
+	sig, err := sign(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if len(ecPub) != 32 || len(sig) != 64 {
+		return nil, validationErrorf("invalid entry credit public key or signature")
+	}
+	buf.Write(ecPub)
+	buf.Write(sig)
 
 	params := messageRequest{Message: hex.EncodeToString(buf.Bytes())}
 	req := NewJSON2Request("commit-entry", APICounter(), params)
@@ -200,8 +305,15 @@ func ComposeEntryReveal(e *Entry) (*JSON2Request, error) {
 
 // CommitEntry sends the signed Entry Hash and the Entry Credit public key to
 // the factom network. Once the payment is verified and the network is commited
-// to publishing the Entry it may be published with a call to RevealEntry.
+// to publishing the Entry it may be published with a call to RevealEntry. The
+// commit is counted against the ECBudget of the Client, if any.
 func CommitEntry(e *Entry, ec *ECAddress) (string, error) {
+	return CommitEntryWithContext(context.Background(), e, ec)
+}
+
+// CommitEntryWithContext is like CommitEntry but cancels its requests when ctx
+// is done.
+func CommitEntryWithContext(ctx context.Context, e *Entry, ec *ECAddress) (string, error) {
 	type commitResponse struct {
 		Message string `json:"message"`
 		TxID    string `json:"txid"`
@@ -211,24 +323,38 @@ func CommitEntry(e *Entry, ec *ECAddress) (string, error) {
 	if err != nil {
 		return "", err
 	}
-
-	resp, err := factomdRequest(req)
+	cost, err := EntryCost(e)
 	if err != nil {
 		return "", err
 	}
 
-	if resp.Error != nil {
-		return "", resp.Error
-	}
 	r := new(commitResponse)
-	if err := json.Unmarshal(resp.JSONResult(), r); err != nil {
+	err = spendEC(ctx, ec, cost, func() error {
+		resp, err := factomdRequest(ctx, req)
+		if err != nil {
+			return err
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		return json.Unmarshal(resp.JSONResult(), r)
+	})
+	if err != nil {
 		return "", err
 	}
 
 	return r.TxID, nil
 }
 
+// RevealEntry publishes an Entry that has been commited with CommitEntry and
+// returns its Entry Hash.
 func RevealEntry(e *Entry) (string, error) {
+	return RevealEntryWithContext(context.Background(), e)
+}
+
+// RevealEntryWithContext is like RevealEntry but cancels its requests when ctx
+// is done.
+func RevealEntryWithContext(ctx context.Context, e *Entry) (string, error) {
 	type revealResponse struct {
 		Message string `json:"message"`
 		Entry   string `json:"entryhash"`
@@ -239,7 +365,7 @@ func RevealEntry(e *Entry) (string, error) {
 		return "", err
 	}
 
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return "", err
 	}
@@ -253,3 +379,26 @@ func RevealEntry(e *Entry) (string, error) {
 	}
 	return r.Entry, nil
 }
+
+// SubmitEntry commits the Entry, paying with the Entry Credit address ec,
+// and then reveals it. It returns the transaction id of the commit and the
+// Entry Hash. Errors are *SubmitError. If the reveal fails the commit has
+// already been paid for, so the transaction id is returned with the error and
+// the Entry can be revealed again with RevealEntry.
+func SubmitEntry(e *Entry, ec *ECAddress) (txid, hash string, err error) {
+	return SubmitEntryWithContext(context.Background(), e, ec)
+}
+
+// SubmitEntryWithContext is like SubmitEntry but cancels its requests when ctx
+// is done.
+func SubmitEntryWithContext(ctx context.Context, e *Entry, ec *ECAddress) (txid, hash string, err error) {
+	txid, err = CommitEntryWithContext(ctx, e, ec)
+	if err != nil {
+		return "", "", &SubmitError{Method: "commit-entry", Err: err}
+	}
+	hash, err = RevealEntryWithContext(ctx, e)
+	if err != nil {
+		return txid, "", &SubmitError{Method: "reveal-entry", TxID: txid, Err: err}
+	}
+	return txid, hash, nil
+}
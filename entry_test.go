@@ -6,8 +6,11 @@ package factom_test
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -46,6 +49,18 @@ func TestUnmarshalJSON(t *testing.T) {
 	if err := e2.UnmarshalJSON(jsonentry2); err != nil {
 		t.Error(err)
 	}
+
+	// unmarshaling again replaces the ExtIDs
+	if err := e1.UnmarshalJSON(jsonentry1); err != nil || len(e1.ExtIDs) != 2 {
+		t.Errorf("ExtIDs %x, %v", e1.ExtIDs, err)
+	}
+
+	// an Entry without ExtIDs has an empty list
+	p, _ := json.Marshal(&Entry{ChainID: e1.ChainID})
+	e3 := new(Entry)
+	if err := json.Unmarshal(p, e3); err != nil || !bytes.Contains(p, []byte(`"extids":[]`)) || e3.ChainID != e1.ChainID {
+		t.Errorf("%s, %v", p, err)
+	}
 }
 
 func TestEntryPrinting(t *testing.T) {
@@ -96,6 +111,104 @@ func TestMarshalBinary(t *testing.T) {
 	}
 }
 
+func TestEntryHash(t *testing.T) {
+	ent := new(Entry)
+	ent.ChainID = "5a402200c5cf278e47905ce52d7d64529a0291829a7bd230072c5468be709069"
+	ent.Content = []byte("This is a test Entry.")
+	ent.ExtIDs = append(ent.ExtIDs, []byte("This is the first extid."))
+	ent.ExtIDs = append(ent.ExtIDs, []byte("This is the second extid."))
+
+	// the Entry Hash is sha256(sha512(entry) + entry), not a plain sha256
+	data, _ := ent.MarshalBinary()
+	h512 := sha512.Sum512(data)
+	want := sha256.Sum256(append(h512[:], data...))
+	if !bytes.Equal(ent.Hash(), want[:]) {
+		t.Errorf("hash %x, expecting %x", ent.Hash(), want)
+	}
+	if fmt.Sprintf("%x", ent.Hash()) != "52385948ea3ab6fd67b07664ac6a30ae5f6afa94427a547c142517beaa9054d0" {
+		t.Errorf("hash %x", ent.Hash())
+	}
+}
+
+func TestEntryECCost(t *testing.T) {
+	chainID := "5a402200c5cf278e47905ce52d7d64529a0291829a7bd230072c5468be709069"
+	tests := []struct {
+		ent  *Entry
+		cost int8
+	}{
+		{&Entry{ChainID: chainID}, 1},
+		{&Entry{ChainID: chainID, Content: make([]byte, 1024)}, 1},
+		{&Entry{ChainID: chainID, Content: make([]byte, 1025)}, 2},
+		// the 2 byte length of every ExtID is paid for
+		{&Entry{ChainID: chainID, ExtIDs: [][]byte{{}}, Content: make([]byte, 1023)}, 2},
+		{&Entry{ChainID: chainID, ExtIDs: [][]byte{make([]byte, 100)}, Content: make([]byte, MaxEntryPayload-102)}, 10},
+	}
+	for i, tt := range tests {
+		if err := tt.ent.Valid(); err != nil {
+			t.Errorf("entry %d: %v", i, err)
+		}
+		cost, err := tt.ent.ECCost()
+		if err != nil || cost != tt.cost {
+			t.Errorf("entry %d: cost %d, %v, expecting %d", i, cost, err, tt.cost)
+		}
+		if old, _ := EntryCost(tt.ent); old != cost {
+			t.Errorf("entry %d: EntryCost %d, ECCost %d", i, old, cost)
+		}
+	}
+
+	invalid := []*Entry{
+		{ChainID: chainID, Content: make([]byte, MaxEntryPayload+1)},
+		{ChainID: chainID, ExtIDs: [][]byte{{}}, Content: make([]byte, MaxEntryPayload-1)},
+		{ChainID: "5a40"},
+		{ChainID: "not hex"},
+	}
+	for i, e := range invalid {
+		if err := e.Valid(); !errors.Is(err, ErrValidation) {
+			t.Errorf("invalid entry %d: got %v", i, err)
+		}
+		if _, err := e.ECCost(); err == nil {
+			t.Errorf("invalid entry %d: expected an error", i)
+		}
+	}
+}
+
+func TestUnmarshalBinary(t *testing.T) {
+	data, _ := hex.DecodeString("005a402200c5cf278e47905ce52d7d64529a0291829a7bd230072c5468be7090690035001854686973206973207468652066697273742065787469642e00195468697320697320746865207365636f6e642065787469642e546869732069732061207465737420456e7472792e")
+
+	ent := new(Entry)
+	if err := ent.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if ent.ChainID != "5a402200c5cf278e47905ce52d7d64529a0291829a7bd230072c5468be709069" ||
+		len(ent.ExtIDs) != 2 || string(ent.ExtIDs[1]) != "This is the second extid." ||
+		string(ent.Content) != "This is a test Entry." {
+		t.Errorf("wrong entry %s", ent)
+	}
+	if p, _ := ent.MarshalBinary(); !bytes.Equal(p, data) {
+		t.Errorf("round trip gave %x", p)
+	}
+
+	// every truncation that cuts into the header or the ExtIDs fails
+	for i := 0; i < 35+0x35; i++ {
+		if err := new(Entry).UnmarshalBinary(data[:i]); !errors.Is(err, ErrValidation) {
+			t.Errorf("%d bytes: got %v", i, err)
+		}
+	}
+
+	// an ExtID running past the ExtIDs size
+	bad := append([]byte(nil), data...)
+	bad[36] = 0x37
+	if err := new(Entry).UnmarshalBinary(bad); err == nil {
+		t.Error("expected an error for an oversized ExtID")
+	}
+
+	// a version other than 0
+	bad = append([]byte{1}, data[1:]...)
+	if err := new(Entry).UnmarshalBinary(bad); err == nil {
+		t.Error("expected an error for version 1")
+	}
+}
+
 func TestComposeEntryCommit(t *testing.T) {
 	type response struct {
 		Message string `json:"message"`
@@ -224,3 +337,54 @@ func TestReveaEntry(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestSubmitEntry(t *testing.T) {
+	var methods []string
+	revealFails := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(JSON2Request)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+		methods = append(methods, req.Method)
+		switch req.Method {
+		case "commit-entry":
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"message": "Entry Commit Success", "txid": "bf12150038699f678ac2314e9fa2d4786dc8984d9b8c67dab8cd7c2f2e83372c"}}`)
+		case "reveal-entry":
+			if revealFails {
+				fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "error": {"code": -32602, "message": "Invalid params"}}`)
+				return
+			}
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"message": "Entry Reveal Success", "entryhash": "f5c956749fc3eba4acc60fd485fb100e601070a44fcce54ff358d60669854734"}}`)
+		}
+	}))
+	defer ts.Close()
+	SetFactomdServer(ts.URL[7:])
+
+	ent := new(Entry)
+	ent.ChainID = "954d5a49fd70d9b8bcdb35d252267829957f7ef7fa6c74f88419bdc5e82209f4"
+	ent.Content = []byte("test!")
+	ecAddr, _ := GetECAddress("Es2Rf7iM6PdsqfYCo3D1tnAR65SkLENyWJG1deUzpRMQmbh9F3eG")
+
+	txid, hash, err := SubmitEntry(ent, ecAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != "f5c956749fc3eba4acc60fd485fb100e601070a44fcce54ff358d60669854734" {
+		t.Errorf("entry hash = %s", hash)
+	}
+	if len(methods) != 2 || methods[0] != "commit-entry" || methods[1] != "reveal-entry" {
+		t.Errorf("called %v", methods)
+	}
+
+	// a failed reveal still returns the id of the paid commit
+	revealFails = true
+	txid, hash, err = SubmitEntry(ent, ecAddr)
+	if err == nil || hash != "" {
+		t.Errorf("expected the reveal to fail, got %s", hash)
+	}
+	if txid != "bf12150038699f678ac2314e9fa2d4786dc8984d9b8c67dab8cd7c2f2e83372c" {
+		t.Errorf("txid = %s", txid)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by this package match one of these with errors.Is so that
+// callers can tell a bad argument from a failed connection. Errors returned by
+// the factomd or wallet API itself are *JSONError.
+var (
+	// ErrNetwork matches errors from failed connections to factomd or the
+	// wallet.
+	ErrNetwork = errors.New("factom: network error")
+
+	// ErrUnauthorized matches errors caused by rejected rpc credentials.
+	ErrUnauthorized = errors.New("factom: unauthorized")
+
+	// ErrValidation matches errors caused by invalid arguments, such as a
+	// malformed address or an oversized entry.
+	ErrValidation = errors.New("factom: validation error")
+
+	// ErrInvalidAddress matches errors caused by an address that has a bad
+	// checksum or is not of the expected type. It also matches
+	// ErrValidation.
+	ErrInvalidAddress error = &kindError{msg: "factom: invalid address", kind: ErrValidation}
+
+	// ErrDecrypt matches errors from decrypting an EncryptedEntry with the
+	// wrong key. It also matches ErrValidation.
+	ErrDecrypt error = &kindError{msg: "factom: entry content can not be decrypted with this key", kind: ErrValidation}
+
+	// ErrChainPending is returned when a chain has been committed but is not
+	// yet included in a Directory Block.
+	ErrChainPending = errors.New("Chain not yet included in a Directory Block")
+
+	// ErrCertificatePin matches errors from connections to a factomd that
+	// presented a certificate with none of the pinned fingerprints.
+	ErrCertificatePin = errors.New("factom: certificate does not match the pinned fingerprints")
+
+	// ErrBudgetExceeded matches the errors of commits that would spend more
+	// Entry Credits than allowed by the ECBudget of the Client.
+	ErrBudgetExceeded = errors.New("factom: entry credit budget exceeded")
+
+	// ErrWalletLocked matches the error returned by the wallet for requests
+	// that need an encrypted wallet to be unlocked first.
+	ErrWalletLocked = NewJSONError(-32001, "Wallet is locked", nil)
+
+	// ErrApprovalRequired matches the error returned by the wallet when
+	// signing a transaction that must be approved first.
+	ErrApprovalRequired = NewJSONError(-32008, "Approval required", nil)
+
+	// ErrInvalidAmount matches the error returned by the wallet for
+	// transaction amounts that are zero outputs, exceed the supply of
+	// factoids or overflow when summed.
+	ErrInvalidAmount = NewJSONError(-32009, "Invalid amount", nil)
+)
+
+// RequestError is returned when a request to factomd or the wallet could not
+// be completed. It matches ErrNetwork and unwraps to the underlying error.
+type RequestError struct {
+	Server string
+	Method string
+	Err    error
+}
+
+func (e *RequestError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+func (e *RequestError) Is(target error) bool {
+	return target == ErrNetwork
+}
+
+// SubmitError is returned by SubmitEntry and SubmitChain when the commit or
+// the reveal fails. Method is the api call that failed. After a failed reveal
+// TxID is the id of the commit that was paid for, so that the reveal can be
+// sent again. It unwraps to the error of the failed call.
+type SubmitError struct {
+	Method string
+	TxID   string
+	Err    error
+}
+
+func (e *SubmitError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Method, e.Err)
+}
+
+func (e *SubmitError) Unwrap() error {
+	return e.Err
+}
+
+// kindError keeps the message of the original error while matching one of
+// the package sentinel errors.
+type kindError struct {
+	msg  string
+	kind error
+}
+
+func (e *kindError) Error() string {
+	return e.msg
+}
+
+func (e *kindError) Is(target error) bool {
+	return errors.Is(e.kind, target)
+}
+
+func validationErrorf(format string, a ...interface{}) error {
+	return &kindError{msg: fmt.Sprintf(format, a...), kind: ErrValidation}
+}
+
+func invalidAddressErrorf(format string, a ...interface{}) error {
+	return &kindError{msg: fmt.Sprintf(format, a...), kind: ErrInvalidAddress}
+}
+
+func unauthorizedErrorf(format string, a ...interface{}) error {
+	return &kindError{msg: fmt.Sprintf(format, a...), kind: ErrUnauthorized}
+}
+
+func budgetErrorf(format string, a ...interface{}) error {
+	return &kindError{msg: fmt.Sprintf(format, a...), kind: ErrBudgetExceeded}
+}
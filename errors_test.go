@@ -0,0 +1,116 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestValidationErrors(t *testing.T) {
+	if _, err := GetFactoidAddress("Fs1notanaddress"); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+	if _, err := GetECAddress("Es1notanaddress"); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+}
+
+func TestRequestErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _, _ := r.BasicAuth(); user != "user" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "error": {"code": -32009, "message": "Missing Chain Head"}}`)
+	}))
+
+	// bad credentials
+	SetDefaultClient(NewClient(WithFactomdServer(ts.URL[7:])))
+	defer SetDefaultClient(nil)
+	_, err := GetRate()
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected an unauthorized error, got %v", err)
+	}
+
+	// api error
+	SetDefaultClient(NewClient(
+		WithFactomdServer(ts.URL[7:]),
+		WithFactomdRPCCredentials("user", "pass"),
+	))
+	_, err = GetRate()
+	var jerr *JSONError
+	if !errors.As(err, &jerr) || jerr.Code != -32009 {
+		t.Errorf("expected a json error, got %v", err)
+	}
+	if !errors.Is(err, &JSONError{Code: -32009}) {
+		t.Errorf("expected error to match code -32009")
+	}
+
+	// network error
+	ts.Close()
+	_, err = GetRate()
+	if !errors.Is(err, ErrNetwork) {
+		t.Errorf("expected a network error, got %v", err)
+	}
+	var rerr *RequestError
+	if !errors.As(err, &rerr) || rerr.Method != "entry-credit-rate" {
+		t.Errorf("expected a request error, got %v", err)
+	}
+}
+
+func TestWalletLockedError(t *testing.T) {
+	locked := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if locked {
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "error": {"code": -32001, "message": "Wallet is locked"}}`)
+			return
+		}
+		fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"success": true}}`)
+	}))
+	defer ts.Close()
+	SetDefaultClient(NewClient(WithWalletServer(ts.URL[7:])))
+	defer SetDefaultClient(nil)
+
+	if _, err := FetchFactoidAddress("FA1zT4aFpEvcnPqPCigB3fvGu4Q4mTXY22iiuV69DqE1pNhdF2MC"); !errors.Is(err, ErrWalletLocked) {
+		t.Errorf("expected a wallet locked error, got %v", err)
+	}
+	locked = false
+	if err := LockWallet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestApprovalRequiredError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "error": {"code": -32008, "message": "Approval required"}}`)
+	}))
+	defer ts.Close()
+	SetDefaultClient(NewClient(WithWalletServer(ts.URL[7:])))
+	defer SetDefaultClient(nil)
+
+	if _, err := SignTransaction("large", false); !errors.Is(err, ErrApprovalRequired) {
+		t.Errorf("expected an approval required error, got %v", err)
+	}
+}
+
+func TestInvalidAmountError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "error": {"code": -32009, "message": "Invalid amount", "data": "wallet: Output amount must not be zero"}}`)
+	}))
+	defer ts.Close()
+	SetDefaultClient(NewClient(WithWalletServer(ts.URL[7:])))
+	defer SetDefaultClient(nil)
+
+	_, err := AddTransactionOutput("tx", "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q", 0)
+	if !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("expected an invalid amount error, got %v", err)
+	}
+}
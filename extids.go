@@ -0,0 +1,86 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+	"unicode/utf8"
+)
+
+// StringExtIDs returns the ExtIDs holding the bytes of ss.
+func StringExtIDs(ss ...string) [][]byte {
+	ids := make([][]byte, len(ss))
+	for i, s := range ss {
+		ids[i] = []byte(s)
+	}
+	return ids
+}
+
+// HexExtID returns the ExtID encoded in hex by s.
+func HexExtID(s string) ([]byte, error) {
+	p, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, validationErrorf("ExtID %q is not hex: %s", s, err)
+	}
+	return p, nil
+}
+
+// Uint64ExtID returns an 8 byte big endian ExtID holding n. Entry.ExtIDUint64
+// reads it back.
+func Uint64ExtID(n uint64) []byte {
+	p := make([]byte, 8)
+	binary.BigEndian.PutUint64(p, n)
+	return p
+}
+
+// TimeExtID returns an ExtID holding t as 8 byte big endian Unix seconds.
+// Entry.ExtIDTime reads it back.
+func TimeExtID(t time.Time) []byte {
+	return Uint64ExtID(uint64(t.Unix()))
+}
+
+// ExtID returns ExtID i of the Entry, or an error matching ErrValidation if
+// the Entry has no ExtID i.
+func (e *Entry) ExtID(i int) ([]byte, error) {
+	if i < 0 || i >= len(e.ExtIDs) {
+		return nil, validationErrorf("entry has %d ExtIDs, no ExtID %d", len(e.ExtIDs), i)
+	}
+	return e.ExtIDs[i], nil
+}
+
+// ExtIDString returns ExtID i as a string. It must be valid UTF-8.
+func (e *Entry) ExtIDString(i int) (string, error) {
+	p, err := e.ExtID(i)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(p) {
+		return "", validationErrorf("ExtID %d is not UTF-8 text", i)
+	}
+	return string(p), nil
+}
+
+// ExtIDUint64 returns ExtID i as written by Uint64ExtID. It must be 8 bytes.
+func (e *Entry) ExtIDUint64(i int) (uint64, error) {
+	p, err := e.ExtID(i)
+	if err != nil {
+		return 0, err
+	}
+	if len(p) != 8 {
+		return 0, validationErrorf("ExtID %d is %d bytes, not an 8 byte integer", i, len(p))
+	}
+	return binary.BigEndian.Uint64(p), nil
+}
+
+// ExtIDTime returns ExtID i as written by TimeExtID.
+func (e *Entry) ExtIDTime(i int) (time.Time, error) {
+	n, err := e.ExtIDUint64(i)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(int64(n), 0), nil
+}
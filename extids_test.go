@@ -0,0 +1,52 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestExtIDs(t *testing.T) {
+	ts := time.Unix(1487042760, 0)
+	h, err := HexExtID("00ff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &Entry{ExtIDs: append(StringExtIDs("invoice", "42"), Uint64ExtID(1e8), TimeExtID(ts), h)}
+
+	if s, err := e.ExtIDString(0); err != nil || s != "invoice" {
+		t.Errorf("ExtIDString(0) = %q, %v", s, err)
+	}
+	if n, err := e.ExtIDUint64(2); err != nil || n != 1e8 {
+		t.Errorf("ExtIDUint64(2) = %d, %v", n, err)
+	}
+	if tm, err := e.ExtIDTime(3); err != nil || !tm.Equal(ts) {
+		t.Errorf("ExtIDTime(3) = %s, %v", tm, err)
+	}
+	if p, err := e.ExtID(4); err != nil || len(p) != 2 || p[1] != 0xff {
+		t.Errorf("ExtID(4) = %x, %v", p, err)
+	}
+
+	// bounds and types are checked
+	if _, err := e.ExtID(5); !errors.Is(err, ErrValidation) {
+		t.Errorf("ExtID(5): got %v", err)
+	}
+	if _, err := e.ExtIDString(-1); !errors.Is(err, ErrValidation) {
+		t.Errorf("ExtIDString(-1): got %v", err)
+	}
+	if _, err := e.ExtIDString(4); !errors.Is(err, ErrValidation) {
+		t.Errorf("ExtIDString(4): got %v, expecting invalid UTF-8", err)
+	}
+	if _, err := e.ExtIDUint64(1); !errors.Is(err, ErrValidation) {
+		t.Errorf("ExtIDUint64(1): got %v", err)
+	}
+	if _, err := HexExtID("xyz"); !errors.Is(err, ErrValidation) {
+		t.Errorf("HexExtID: got %v", err)
+	}
+}
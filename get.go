@@ -5,21 +5,26 @@
 package factom
 
 import (
+	"context"
 	"encoding/json"
-
-	"fmt"
 )
 
 // GetECBalance returns the balance in factoshi (factoid * 1e8) of a given Entry
 // Credit Public Address.
 func GetECBalance(addr string) (int64, error) {
+	return GetECBalanceWithContext(context.Background(), addr)
+}
+
+// GetECBalanceWithContext is like GetECBalance but cancels its requests when
+// ctx is done.
+func GetECBalanceWithContext(ctx context.Context, addr string) (int64, error) {
 	type balanceResponse struct {
 		Balance int64 `json:"balance"`
 	}
 
 	params := addressRequest{Address: addr}
 	req := NewJSON2Request("entry-credit-balance", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return -1, err
 	}
@@ -38,13 +43,19 @@ func GetECBalance(addr string) (int64, error) {
 // GetFactoidBalance returns the balance in factoshi (factoid * 1e8) of a given
 // Factoid Public Address.
 func GetFactoidBalance(addr string) (int64, error) {
+	return GetFactoidBalanceWithContext(context.Background(), addr)
+}
+
+// GetFactoidBalanceWithContext is like GetFactoidBalance but cancels its
+// requests when ctx is done.
+func GetFactoidBalanceWithContext(ctx context.Context, addr string) (int64, error) {
 	type balanceResponse struct {
 		Balance int64 `json:"balance"`
 	}
 
 	params := addressRequest{Address: addr}
 	req := NewJSON2Request("factoid-balance", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return -1, err
 	}
@@ -64,6 +75,12 @@ func GetFactoidBalance(addr string) (int64, error) {
 // wallet according to the the server acknowledgement and the value saved in the
 // blockchain.
 func GetBalanceTotals() (fSaved, fAcknowledged, eSaved, eAcknowledged int64, err error) {
+	return GetBalanceTotalsWithContext(context.Background())
+}
+
+// GetBalanceTotalsWithContext is like GetBalanceTotals but cancels its requests
+// when ctx is done.
+func GetBalanceTotalsWithContext(ctx context.Context) (fSaved, fAcknowledged, eSaved, eAcknowledged int64, err error) {
 	type multiBalanceResponse struct {
 		FactoidAccountBalances struct {
 			Ack   int64 `json:"ack"`
@@ -76,7 +93,7 @@ func GetBalanceTotals() (fSaved, fAcknowledged, eSaved, eAcknowledged int64, err
 	}
 
 	req := NewJSON2Request("wallet-balances", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return
 	} else if resp.Error != nil {
@@ -100,12 +117,17 @@ func GetBalanceTotals() (fSaved, fAcknowledged, eSaved, eAcknowledged int64, err
 
 // GetRate returns the number of factoshis per entry credit
 func GetRate() (uint64, error) {
+	return GetRateWithContext(context.Background())
+}
+
+// GetRateWithContext is like GetRate but cancels its requests when ctx is done.
+func GetRateWithContext(ctx context.Context) (uint64, error) {
 	type rateResponse struct {
 		Rate uint64 `json:"rate"`
 	}
 
 	req := NewJSON2Request("entry-credit-rate", APICounter(), nil)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return 0, err
 	}
@@ -123,9 +145,15 @@ func GetRate() (uint64, error) {
 
 // GetDBlock requests a Directory Block from factomd by its Key Merkle Root
 func GetDBlock(keymr string) (*DBlock, error) {
+	return GetDBlockWithContext(context.Background(), keymr)
+}
+
+// GetDBlockWithContext is like GetDBlock but cancels its requests when ctx is
+// done.
+func GetDBlockWithContext(ctx context.Context, keymr string) (*DBlock, error) {
 	params := keyMRRequest{KeyMR: keymr}
 	req := NewJSON2Request("directory-block", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -142,8 +170,14 @@ func GetDBlock(keymr string) (*DBlock, error) {
 }
 
 func GetDBlockHead() (string, error) {
+	return GetDBlockHeadWithContext(context.Background())
+}
+
+// GetDBlockHeadWithContext is like GetDBlockHead but cancels its requests when
+// ctx is done.
+func GetDBlockHeadWithContext(ctx context.Context) (string, error) {
 	req := NewJSON2Request("directory-block-head", APICounter(), nil)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return "", err
 	}
@@ -160,8 +194,14 @@ func GetDBlockHead() (string, error) {
 }
 
 func GetHeights() (*HeightsResponse, error) {
+	return GetHeightsWithContext(context.Background())
+}
+
+// GetHeightsWithContext is like GetHeights but cancels its requests when ctx is
+// done.
+func GetHeightsWithContext(ctx context.Context) (*HeightsResponse, error) {
 	req := NewJSON2Request("heights", APICounter(), nil)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -179,9 +219,15 @@ func GetHeights() (*HeightsResponse, error) {
 
 // GetEntry requests an Entry from factomd by its Entry Hash
 func GetEntry(hash string) (*Entry, error) {
+	return GetEntryWithContext(context.Background(), hash)
+}
+
+// GetEntryWithContext is like GetEntry but cancels its requests when ctx is
+// done.
+func GetEntryWithContext(ctx context.Context, hash string) (*Entry, error) {
 	params := hashRequest{Hash: hash}
 	req := NewJSON2Request("entry", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -204,26 +250,42 @@ func GetEntry(hash string) (*Entry, error) {
 //			return an error indicating there is no chainhead found, but it will be created in the
 //			next block.
 func GetChainHead(chainid string) (string, error) {
-	ch, err := getChainHead(chainid)
+	return GetChainHeadWithContext(context.Background(), chainid)
+}
+
+// GetChainHeadWithContext is like GetChainHead but cancels its requests when
+// ctx is done.
+func GetChainHeadWithContext(ctx context.Context, chainid string) (string, error) {
+	ch, err := getChainHead(ctx, chainid)
 	if err != nil {
 		return "", err
 	}
 	return ch.ChainHead, nil
 }
 
-type chainHeadResponse struct {
+// ChainHeadResponse is the head of a chain. ChainHead is empty and
+// ChainInProcessList is set for a chain that is not in a Directory Block yet.
+type ChainHeadResponse struct {
 	ChainHead          string `json:"chainhead"`
 	ChainInProcessList bool   `json:"chaininprocesslist"`
 }
 
-func GetChainHeadAndStatus(chainid string) (*chainHeadResponse, error) {
-	return getChainHead(chainid)
+// GetChainHeadAndStatus requests the head of a chain from factomd, and
+// whether the chain is still waiting for its first Directory Block.
+func GetChainHeadAndStatus(chainid string) (*ChainHeadResponse, error) {
+	return GetChainHeadAndStatusWithContext(context.Background(), chainid)
 }
 
-func getChainHead(chainid string) (*chainHeadResponse, error) {
+// GetChainHeadAndStatusWithContext is like GetChainHeadAndStatus but cancels
+// its requests when ctx is done.
+func GetChainHeadAndStatusWithContext(ctx context.Context, chainid string) (*ChainHeadResponse, error) {
+	return getChainHead(ctx, chainid)
+}
+
+func getChainHead(ctx context.Context, chainid string) (*ChainHeadResponse, error) {
 	params := chainIDRequest{ChainID: chainid}
 	req := NewJSON2Request("chain-head", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -231,7 +293,7 @@ func getChainHead(chainid string) (*chainHeadResponse, error) {
 		return nil, resp.Error
 	}
 
-	head := new(chainHeadResponse)
+	head := new(ChainHeadResponse)
 	if err := json.Unmarshal(resp.JSONResult(), head); err != nil {
 		return nil, err
 	}
@@ -241,15 +303,21 @@ func getChainHead(chainid string) (*chainHeadResponse, error) {
 
 // GetAllEBlockEntries requests every Entry in a specific Entry Block
 func GetAllEBlockEntries(keymr string) ([]*Entry, error) {
+	return GetAllEBlockEntriesWithContext(context.Background(), keymr)
+}
+
+// GetAllEBlockEntriesWithContext is like GetAllEBlockEntries but cancels its
+// requests when ctx is done.
+func GetAllEBlockEntriesWithContext(ctx context.Context, keymr string) ([]*Entry, error) {
 	es := make([]*Entry, 0)
 
-	eb, err := GetEBlock(keymr)
+	eb, err := GetEBlockWithContext(ctx, keymr)
 	if err != nil {
 		return es, err
 	}
 
 	for _, v := range eb.EntryList {
-		e, err := GetEntry(v.EntryHash)
+		e, err := GetEntryWithContext(ctx, v.EntryHash)
 		if err != nil {
 			return es, err
 		}
@@ -261,9 +329,15 @@ func GetAllEBlockEntries(keymr string) ([]*Entry, error) {
 
 // GetEBlock requests an Entry Block from factomd by its Key Merkle Root
 func GetEBlock(keymr string) (*EBlock, error) {
+	return GetEBlockWithContext(context.Background(), keymr)
+}
+
+// GetEBlockWithContext is like GetEBlock but cancels its requests when ctx is
+// done.
+func GetEBlockWithContext(ctx context.Context, keymr string) (*EBlock, error) {
 	params := keyMRRequest{KeyMR: keymr}
 	req := NewJSON2Request("entry-block", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -280,9 +354,14 @@ func GetEBlock(keymr string) (*EBlock, error) {
 }
 
 func GetRaw(keymr string) ([]byte, error) {
+	return GetRawWithContext(context.Background(), keymr)
+}
+
+// GetRawWithContext is like GetRaw but cancels its requests when ctx is done.
+func GetRawWithContext(ctx context.Context, keymr string) ([]byte, error) {
 	params := hashRequest{Hash: keymr}
 	req := NewJSON2Request("raw-data", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -299,23 +378,29 @@ func GetRaw(keymr string) ([]byte, error) {
 }
 
 func GetAllChainEntries(chainid string) ([]*Entry, error) {
+	return GetAllChainEntriesWithContext(context.Background(), chainid)
+}
+
+// GetAllChainEntriesWithContext is like GetAllChainEntries but cancels its
+// requests when ctx is done.
+func GetAllChainEntriesWithContext(ctx context.Context, chainid string) ([]*Entry, error) {
 	es := make([]*Entry, 0)
 
-	head, err := GetChainHeadAndStatus(chainid)
+	head, err := GetChainHeadAndStatusWithContext(ctx, chainid)
 	if err != nil {
 		return es, err
 	}
 
 	if head.ChainHead == "" && head.ChainInProcessList {
-		return nil, fmt.Errorf("Chain not yet included in a Directory Block")
+		return nil, ErrChainPending
 	}
 
 	for ebhash := head.ChainHead; ebhash != ZeroHash; {
-		eb, err := GetEBlock(ebhash)
+		eb, err := GetEBlockWithContext(ctx, ebhash)
 		if err != nil {
 			return es, err
 		}
-		s, err := GetAllEBlockEntries(ebhash)
+		s, err := GetAllEBlockEntriesWithContext(ctx, ebhash)
 		if err != nil {
 			return es, err
 		}
@@ -328,19 +413,25 @@ func GetAllChainEntries(chainid string) ([]*Entry, error) {
 }
 
 func GetAllChainEntriesAtHeight(chainid string, height int64) ([]*Entry, error) {
+	return GetAllChainEntriesAtHeightWithContext(context.Background(), chainid, height)
+}
+
+// GetAllChainEntriesAtHeightWithContext is like GetAllChainEntriesAtHeight but
+// cancels its requests when ctx is done.
+func GetAllChainEntriesAtHeightWithContext(ctx context.Context, chainid string, height int64) ([]*Entry, error) {
 	es := make([]*Entry, 0)
 
-	head, err := GetChainHeadAndStatus(chainid)
+	head, err := GetChainHeadAndStatusWithContext(ctx, chainid)
 	if err != nil {
 		return es, err
 	}
 
 	if head.ChainHead == "" && head.ChainInProcessList {
-		return nil, fmt.Errorf("Chain not yet included in a Directory Block")
+		return nil, ErrChainPending
 	}
 
 	for ebhash := head.ChainHead; ebhash != ZeroHash; {
-		eb, err := GetEBlock(ebhash)
+		eb, err := GetEBlockWithContext(ctx, ebhash)
 		if err != nil {
 			return es, err
 		}
@@ -348,7 +439,7 @@ func GetAllChainEntriesAtHeight(chainid string, height int64) ([]*Entry, error)
 			ebhash = eb.Header.PrevKeyMR
 			continue
 		}
-		s, err := GetAllEBlockEntries(ebhash)
+		s, err := GetAllEBlockEntriesWithContext(ctx, ebhash)
 		if err != nil {
 			return es, err
 		}
@@ -361,34 +452,46 @@ func GetAllChainEntriesAtHeight(chainid string, height int64) ([]*Entry, error)
 }
 
 func GetFirstEntry(chainid string) (*Entry, error) {
+	return GetFirstEntryWithContext(context.Background(), chainid)
+}
+
+// GetFirstEntryWithContext is like GetFirstEntry but cancels its requests when
+// ctx is done.
+func GetFirstEntryWithContext(ctx context.Context, chainid string) (*Entry, error) {
 	e := new(Entry)
 
-	head, err := GetChainHeadAndStatus(chainid)
+	head, err := GetChainHeadAndStatusWithContext(ctx, chainid)
 	if err != nil {
 		return e, err
 	}
 
 	if head.ChainHead == "" && head.ChainInProcessList {
-		return nil, fmt.Errorf("Chain not yet included in a Directory Block")
+		return nil, ErrChainPending
 	}
 
-	eb, err := GetEBlock(head.ChainHead)
+	eb, err := GetEBlockWithContext(ctx, head.ChainHead)
 	if err != nil {
 		return e, err
 	}
 
 	for eb.Header.PrevKeyMR != ZeroHash {
 		ebhash := eb.Header.PrevKeyMR
-		eb, err = GetEBlock(ebhash)
+		eb, err = GetEBlockWithContext(ctx, ebhash)
 		if err != nil {
 			return e, err
 		}
 	}
 
-	return GetEntry(eb.EntryList[0].EntryHash)
+	return GetEntryWithContext(ctx, eb.EntryList[0].EntryHash)
 }
 
 func GetProperties() (string, string, string, string, string, string, string, string) {
+	return GetPropertiesWithContext(context.Background())
+}
+
+// GetPropertiesWithContext is like GetProperties but cancels its requests when
+// ctx is done.
+func GetPropertiesWithContext(ctx context.Context) (string, string, string, string, string, string, string, string) {
 	type propertiesResponse struct {
 		FactomdVersion       string `json:"factomdversion"`
 		FactomdVersionErr    string `json:"factomdversionerr"`
@@ -405,7 +508,7 @@ func GetProperties() (string, string, string, string, string, string, string, st
 	req := NewJSON2Request("properties", APICounter(), nil)
 	wreq := NewJSON2Request("properties", APICounter(), nil)
 
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		props.FactomdVersionErr = err.Error()
 	} else if resp.Error != nil {
@@ -414,7 +517,7 @@ func GetProperties() (string, string, string, string, string, string, string, st
 		props.FactomdVersionErr = jerr.Error()
 	}
 
-	wresp, werr := walletRequest(wreq)
+	wresp, werr := walletRequest(ctx, wreq)
 
 	if werr != nil {
 		wprops.WalletVersionErr = werr.Error()
@@ -428,16 +531,25 @@ func GetProperties() (string, string, string, string, string, string, string, st
 
 }
 
+// GetPendingEntries returns the raw JSON list of the Entries that factomd has
+// acknowledged but not yet written to a block. GetPendingEntryList returns
+// it decoded.
 func GetPendingEntries() (string, error) {
+	return GetPendingEntriesWithContext(context.Background())
+}
+
+// GetPendingEntriesWithContext is like GetPendingEntries but cancels its
+// requests when ctx is done.
+func GetPendingEntriesWithContext(ctx context.Context) (string, error) {
 
 	req := NewJSON2Request("pending-entries", APICounter(), nil)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 
 	if err != nil {
 		return "", err
 	}
 	if resp.Error != nil {
-		return "", err
+		return "", resp.Error
 	}
 
 	rBytes := resp.JSONResult()
@@ -445,16 +557,25 @@ func GetPendingEntries() (string, error) {
 	return string(rBytes), nil
 }
 
+// GetPendingTransactions returns the raw JSON list of the factoid
+// transactions that factomd has acknowledged but not yet written to a block.
+// GetPendingTransactionList returns it decoded.
 func GetPendingTransactions() (string, error) {
+	return GetPendingTransactionsWithContext(context.Background())
+}
+
+// GetPendingTransactionsWithContext is like GetPendingTransactions but cancels
+// its requests when ctx is done.
+func GetPendingTransactionsWithContext(ctx context.Context) (string, error) {
 
 	req := NewJSON2Request("pending-transactions", APICounter(), nil)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 
 	if err != nil {
 		return "", err
 	}
 	if resp.Error != nil {
-		return "", err
+		return "", resp.Error
 	}
 	//fmt.Println("factom resp=", resp)
 	transList := resp.JSONResult()
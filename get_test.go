@@ -275,6 +275,37 @@ hello world
 	}
 }
 
+func TestGetChainHead(t *testing.T) {
+	simlatedFactomdResponse := `{"jsonrpc":"2.0","id":0,"result":{"chainhead":"5117490532e46037f8eb660c4fd49cae2a734fc9096b431b2a9a738d7d278398","chaininprocesslist":false}}`
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, simlatedFactomdResponse)
+	}))
+	defer ts.Close()
+
+	url := ts.URL[7:]
+	SetFactomdServer(url)
+
+	head, err := GetChainHead("df3ade9eec4b08d5379cc64270c30ea7315d8a8a1a69efe2b98a60ecdd69e604")
+	if err != nil {
+		t.Error(err)
+	}
+	if head != "5117490532e46037f8eb660c4fd49cae2a734fc9096b431b2a9a738d7d278398" {
+		t.Errorf("chain head = %s", head)
+	}
+
+	// a chain that is not in a directory block yet has no head
+	simlatedFactomdResponse = `{"jsonrpc":"2.0","id":0,"result":{"chainhead":"","chaininprocesslist":true}}`
+	status, err := GetChainHeadAndStatus("df3ade9eec4b08d5379cc64270c30ea7315d8a8a1a69efe2b98a60ecdd69e604")
+	if err != nil {
+		t.Error(err)
+	}
+	if status.ChainHead != "" || !status.ChainInProcessList {
+		t.Errorf("got %+v", status)
+	}
+}
+
 func TestGetEBlock(t *testing.T) {
 	simlatedFactomdResponse := `{"jsonrpc":"2.0","id":0,"result":{"header":{"blocksequencenumber":35990,"chainid":"df3ade9eec4b08d5379cc64270c30ea7315d8a8a1a69efe2b98a60ecdd69e604","prevkeymr":"7bd1725aa29c988f8f3486512a01976807a0884d4c71ac08d18d1982d905a27a","timestamp":1487042760,"dbheight":75893},"entrylist":[{"entryhash":"cefd9554e9d89132a327e292649031e7b6ccea1cebd80d8a4722e56d0147dd58","timestamp":1487043240},{"entryhash":"61a7f9256f330e50ddf92b296c00fa679588854affc13c380e9945b05fc8e708","timestamp":1487043240}]}}`
 
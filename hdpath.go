@@ -0,0 +1,107 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/FactomProject/go-bip32"
+	"github.com/FactomProject/go-bip39"
+)
+
+// ParseDerivationPath parses a BIP32 derivation path such as
+// m/44'/131'/0'/0/0 into its child indexes. Children marked with ' or h are
+// hardened.
+func ParseDerivationPath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if len(parts) < 2 || parts[0] != "m" {
+		return nil, validationErrorf("invalid derivation path %q", path)
+	}
+
+	children := make([]uint32, 0, len(parts)-1)
+	for _, p := range parts[1:] {
+		var hardened uint32
+		if strings.HasSuffix(p, "'") || strings.HasSuffix(p, "h") {
+			hardened = bip32.FirstHardenedChild
+			p = p[:len(p)-1]
+		}
+		i, err := strconv.ParseUint(p, 10, 32)
+		if err != nil || uint32(i) >= bip32.FirstHardenedChild {
+			return nil, validationErrorf("invalid child %q in derivation path %q", p, path)
+		}
+		children = append(children, uint32(i)+hardened)
+	}
+	return children, nil
+}
+
+// FormatDerivationPath returns the string form of a derivation path, marking
+// hardened children with '.
+func FormatDerivationPath(children []uint32) string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, c := range children {
+		if c >= bip32.FirstHardenedChild {
+			fmt.Fprintf(&b, "/%d'", c-bip32.FirstHardenedChild)
+		} else {
+			fmt.Fprintf(&b, "/%d", c)
+		}
+	}
+	return b.String()
+}
+
+// MakeFactoidAddressFromPath derives the Factoid Address at a derivation
+// path of the mnemonic. MakeBIP44FactoidAddress with account
+// bip32.FirstHardenedChild is the path m/44'/131'/0'/chain/address.
+func MakeFactoidAddressFromPath(mnemonic string, path []uint32) (*FactoidAddress, error) {
+	key, err := deriveKey(mnemonic, path)
+	if err != nil {
+		return nil, err
+	}
+	defer SecureBytes(key).Wipe()
+	return MakeFactoidAddress(key)
+}
+
+// MakeECAddressFromPath derives the Entry Credit Address at a derivation
+// path of the mnemonic. MakeBIP44ECAddress with account
+// bip32.FirstHardenedChild is the path m/44'/132'/0'/chain/address.
+func MakeECAddressFromPath(mnemonic string, path []uint32) (*ECAddress, error) {
+	key, err := deriveKey(mnemonic, path)
+	if err != nil {
+		return nil, err
+	}
+	defer SecureBytes(key).Wipe()
+	return MakeECAddress(key)
+}
+
+// deriveKey returns the private key at the derivation path of the mnemonic.
+// The seed and the keys along the path are wiped; the caller wipes the
+// returned key.
+func deriveKey(mnemonic string, path []uint32) ([]byte, error) {
+	mnemonic, err := ParseAndValidateMnemonic(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, "")
+	if err != nil {
+		return nil, err
+	}
+	defer SecureBytes(seed).Wipe()
+	key, err := bip32.NewMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range path {
+		child, err := key.NewChildKey(c)
+		SecureBytes(key.Key).Wipe()
+		if err != nil {
+			return nil, err
+		}
+		key = child
+	}
+	return key.Key, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/FactomProject/factom"
+	"github.com/FactomProject/go-bip32"
+)
+
+func TestParseDerivationPath(t *testing.T) {
+	p, err := ParseDerivationPath("m/44'/131h/0'/0/7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []uint32{bip32.FirstHardenedChild + 44, bip32.FirstHardenedChild + 131, bip32.FirstHardenedChild, 0, 7}
+	if len(p) != len(want) {
+		t.Fatalf("wrong path %v", p)
+	}
+	for i := range p {
+		if p[i] != want[i] {
+			t.Errorf("wrong child %d: %d", i, p[i])
+		}
+	}
+	if s := FormatDerivationPath(p); s != "m/44'/131'/0'/0/7" {
+		t.Errorf("wrong path string %s", s)
+	}
+
+	for _, bad := range []string{"", "m", "44'/0", "m/x", "m/2147483648"} {
+		if _, err := ParseDerivationPath(bad); !errors.Is(err, ErrValidation) {
+			t.Errorf("expected a validation error for %q, got %v", bad, err)
+		}
+	}
+}
+
+func TestMakeAddressFromPath(t *testing.T) {
+	m := "yellow yellow yellow yellow yellow yellow yellow yellow yellow yellow yellow yellow"
+
+	// the bip44 paths match the bip44 functions
+	p, _ := ParseDerivationPath("m/44'/131'/0'/0/3")
+	f, err := MakeFactoidAddressFromPath(m, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := MakeBIP44FactoidAddress(m, bip32.FirstHardenedChild, 0, 3)
+	if f.String() != want.String() {
+		t.Errorf("got %s, want %s", f, want)
+	}
+
+	p, _ = ParseDerivationPath("m/44'/132'/0'/0/3")
+	e, err := MakeECAddressFromPath(m, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantEC, _ := MakeBIP44ECAddress(m, bip32.FirstHardenedChild, 0, 3)
+	if e.String() != wantEC.String() {
+		t.Errorf("got %s, want %s", e, wantEC)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers used to sign api requests with a shared secret. A signed request
+// carries the id of the key used, the unix time it was signed at, a random
+// nonce, the hex sha256 of its body, and the hex HMAC-SHA256 of the string
+// returned by HMACStringToSign.
+const (
+	HMACKeyIDHeader     = "X-Factom-Key-Id"
+	HMACTimestampHeader = "X-Factom-Timestamp"
+	HMACNonceHeader     = "X-Factom-Nonce"
+	HMACBodyHashHeader  = "X-Factom-Content-Sha256"
+	HMACSignatureHeader = "X-Factom-Signature"
+)
+
+// DefaultHMACMaxSkew is the largest difference allowed between the time a
+// request was signed and the time it is verified.
+const DefaultHMACMaxSkew = 5 * time.Minute
+
+// HMACStringToSign returns the string that is signed for a request. Each part
+// is on its own line: the http method, the request path, the key id, the unix
+// timestamp, the nonce and the hex sha256 of the body.
+func HMACStringToSign(method, path, keyID, timestamp, nonce, bodyHash string) string {
+	return strings.Join([]string{method, path, keyID, timestamp, nonce, bodyHash}, "\n")
+}
+
+// SignRequest adds the HMAC signature headers to r, with a new random nonce.
+// body must be the exact body that will be sent with r.
+func SignRequest(r *http.Request, body []byte, keyID string, secret []byte) {
+	bodyHash := sha256.Sum256(body)
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	n := make([]byte, 16)
+	rand.Read(n)
+	nonce := hex.EncodeToString(n)
+
+	r.Header.Set(HMACKeyIDHeader, keyID)
+	r.Header.Set(HMACTimestampHeader, ts)
+	r.Header.Set(HMACNonceHeader, nonce)
+	r.Header.Set(HMACBodyHashHeader, hex.EncodeToString(bodyHash[:]))
+	r.Header.Set(HMACSignatureHeader, hex.EncodeToString(
+		hmacSum(secret, HMACStringToSign(r.Method, r.URL.Path, keyID, ts, nonce,
+			hex.EncodeToString(bodyHash[:])))))
+}
+
+// VerifyRequestSignature checks the HMAC signature headers of r against body
+// and secret. Requests signed more than maxSkew away from now are refused.
+// The signature does not stop a captured request from being sent again
+// within maxSkew: the caller must also refuse a key id and nonce that it has
+// already accepted in that window. The returned error matches
+// ErrUnauthorized.
+func VerifyRequestSignature(r *http.Request, body []byte, secret []byte, maxSkew time.Duration) error {
+	keyID := r.Header.Get(HMACKeyIDHeader)
+	ts := r.Header.Get(HMACTimestampHeader)
+	nonce := r.Header.Get(HMACNonceHeader)
+	sig, err := hex.DecodeString(r.Header.Get(HMACSignatureHeader))
+	if keyID == "" || ts == "" || nonce == "" || err != nil || len(sig) == 0 {
+		return unauthorizedErrorf("missing or malformed request signature")
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return unauthorizedErrorf("malformed request timestamp")
+	}
+	skew := time.Since(time.Unix(unix, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > maxSkew {
+		return unauthorizedErrorf("request timestamp is outside the allowed window")
+	}
+
+	sum := sha256.Sum256(body)
+	bodyHash := hex.EncodeToString(sum[:])
+	if r.Header.Get(HMACBodyHashHeader) != bodyHash {
+		return unauthorizedErrorf("request body does not match its hash")
+	}
+
+	expected := hmacSum(secret, HMACStringToSign(r.Method, r.URL.Path, keyID, ts, nonce, bodyHash))
+	if !hmac.Equal(sig, expected) {
+		return unauthorizedErrorf("invalid request signature")
+	}
+	return nil
+}
+
+func hmacSum(secret []byte, s string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(s))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,80 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestSignRequest(t *testing.T) {
+	secret := []byte("machine secret")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get(HMACKeyIDHeader) != "machine-1" {
+			t.Errorf("wrong key id %q", r.Header.Get(HMACKeyIDHeader))
+		}
+		if err := VerifyRequestSignature(r, body, secret, DefaultHMACMaxSkew); err != nil {
+			t.Error(err)
+		}
+		if err := VerifyRequestSignature(r, append(body, ' '), secret, DefaultHMACMaxSkew); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("expected a changed body to be refused, got %v", err)
+		}
+		if err := VerifyRequestSignature(r, body, []byte("other"), DefaultHMACMaxSkew); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("expected the wrong secret to be refused, got %v", err)
+		}
+		fmt.Fprint(w, `{"jsonrpc": "2.0", "id": 0, "result": {}}`)
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithWalletServer(ts.URL[7:]), WithWalletHMAC("machine-1", secret))
+	if _, err := c.WalletRequest(NewJSON2Request("properties", APICounter(), nil)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyRequestSignatureSkew(t *testing.T) {
+	secret := []byte("machine secret")
+	body := []byte(`{"jsonrpc": "2.0", "id": 0, "method": "properties"}`)
+
+	r := httptest.NewRequest("POST", "/v2", bytes.NewReader(body))
+	SignRequest(r, body, "machine-1", secret)
+	if err := VerifyRequestSignature(r, body, secret, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	// the nonce is signed
+	nonce := r.Header.Get(HMACNonceHeader)
+	r.Header.Set(HMACNonceHeader, "00")
+	if err := VerifyRequestSignature(r, body, secret, time.Minute); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected a changed nonce to be refused, got %v", err)
+	}
+	r.Header.Set(HMACNonceHeader, nonce)
+
+	r2 := httptest.NewRequest("POST", "/v2", bytes.NewReader(body))
+	SignRequest(r2, body, "machine-1", secret)
+	if r2.Header.Get(HMACNonceHeader) == nonce {
+		t.Error("two requests were signed with the same nonce")
+	}
+
+	r.Header.Set(HMACTimestampHeader, fmt.Sprint(time.Now().Add(-time.Hour).Unix()))
+	if err := VerifyRequestSignature(r, body, secret, time.Minute); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected an old request to be refused, got %v", err)
+	}
+
+	r.Header.Del(HMACSignatureHeader)
+	if err := VerifyRequestSignature(r, body, secret, time.Minute); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected an unsigned request to be refused, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package factom
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -46,7 +47,7 @@ func NewIdentityChain(name []string, keys []string) (*Chain, error) {
 	var publicKeys []string
 	for _, key := range keys {
 		if IdentityKeyStringType(key) != IDPub {
-			return nil, fmt.Errorf("provided key %s is not a valid identity public key", key)
+			return nil, validationErrorf("provided key %s is not a valid identity public key", key)
 		}
 		publicKeys = append(publicKeys, key)
 	}
@@ -60,21 +61,33 @@ func NewIdentityChain(name []string, keys []string) (*Chain, error) {
 // GetActiveIdentityKeys returns the identity's public keys that were/are active at the highest saved block height,
 // along with that blockheight
 func GetActiveIdentityKeys(chainID string) ([]string, int64, error) {
-	heights, err := GetHeights()
+	return GetActiveIdentityKeysWithContext(context.Background(), chainID)
+}
+
+// GetActiveIdentityKeysWithContext is like GetActiveIdentityKeys but cancels
+// its requests when ctx is done.
+func GetActiveIdentityKeysWithContext(ctx context.Context, chainID string) ([]string, int64, error) {
+	heights, err := GetHeightsWithContext(ctx)
 	if err != nil {
 		return nil, -1, err
 	}
-	keys, err := GetActiveIdentityKeysAtHeight(chainID, heights.DirectoryBlockHeight)
+	keys, err := GetActiveIdentityKeysAtHeightWithContext(ctx, chainID, heights.DirectoryBlockHeight)
 	return keys, heights.DirectoryBlockHeight, err
 }
 
 // GetActiveIdentityKeysAtHeight returns the identity's public keys that were active at the specified block height
 func GetActiveIdentityKeysAtHeight(chainID string, height int64) ([]string, error) {
-	if !ChainExists(chainID) {
+	return GetActiveIdentityKeysAtHeightWithContext(context.Background(), chainID, height)
+}
+
+// GetActiveIdentityKeysAtHeightWithContext is like
+// GetActiveIdentityKeysAtHeight but cancels its requests when ctx is done.
+func GetActiveIdentityKeysAtHeightWithContext(ctx context.Context, chainID string, height int64) ([]string, error) {
+	if !ChainExistsWithContext(ctx, chainID) {
 		return nil, fmt.Errorf("chain does not exist")
 	}
 
-	entries, err := GetAllChainEntriesAtHeight(chainID, height)
+	entries, err := GetAllChainEntriesAtHeightWithContext(ctx, chainID, height)
 	if err != nil {
 		return nil, err
 	} else if len(entries) == 0 {
@@ -177,10 +190,10 @@ func GetActiveIdentityKeysAtHeight(chainID string, height int64) ([]string, erro
 // blockchain using the usual factom.CommitEntry(...) and factom.RevealEntry(...) calls.
 func NewIdentityKeyReplacementEntry(chainID string, oldKey string, newKey string, signerKey *IdentityKey) (*Entry, error) {
 	if IdentityKeyStringType(oldKey) != IDPub {
-		return nil, fmt.Errorf("provided key %s is not a valid identity public key", oldKey)
+		return nil, validationErrorf("provided key %s is not a valid identity public key", oldKey)
 	}
 	if IdentityKeyStringType(newKey) != IDPub {
-		return nil, fmt.Errorf("provided key %s is not a valid identity public key", newKey)
+		return nil, validationErrorf("provided key %s is not a valid identity public key", newKey)
 	}
 	message := []byte(chainID + oldKey + newKey)
 	signature := signerKey.Sign(message)
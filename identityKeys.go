@@ -2,7 +2,6 @@ package factom
 
 import (
 	"bytes"
-	"fmt"
 
 	"github.com/FactomProject/btcutil/base58"
 	ed "github.com/FactomProject/ed25519"
@@ -99,7 +98,7 @@ func (k *IdentityKey) UnmarshalBinary(data []byte) error {
 
 func (k *IdentityKey) UnmarshalBinaryData(data []byte) ([]byte, error) {
 	if len(data) < 32 {
-		return nil, fmt.Errorf("secret key portion must be 32 bytes")
+		return nil, validationErrorf("secret key portion must be 32 bytes")
 	}
 
 	if k.Sec == nil {
@@ -119,12 +118,13 @@ func (k *IdentityKey) MarshalBinary() ([]byte, error) {
 // GetIdentityKey takes a private key string and returns an IdentityKey.
 func GetIdentityKey(s string) (*IdentityKey, error) {
 	if !IsValidIdentityKey(s) {
-		return nil, fmt.Errorf("invalid Identity Private Key")
+		return nil, validationErrorf("invalid Identity Private Key")
 	}
 	p := base58.Decode(s)
+	defer SecureBytes(p).Wipe()
 
 	if !bytes.Equal(p[:IDKeyPrefixLength], idSecPrefix) {
-		return nil, fmt.Errorf("invalid Identity Private Key")
+		return nil, validationErrorf("invalid Identity Private Key")
 	}
 
 	return MakeIdentityKey(p[IDKeyPrefixLength:IDKeyBodyLength])
@@ -132,7 +132,7 @@ func GetIdentityKey(s string) (*IdentityKey, error) {
 
 func MakeIdentityKey(sec []byte) (*IdentityKey, error) {
 	if len(sec) != 32 {
-		return nil, fmt.Errorf("secret key portion must be 32 bytes")
+		return nil, validationErrorf("secret key portion must be 32 bytes")
 	}
 
 	k := NewIdentityKey()
@@ -155,6 +155,7 @@ func MakeBIP44IdentityKey(mnemonic string, account, chain, address uint32) (*Ide
 	if err != nil {
 		return nil, err
 	}
+	defer SecureBytes(child.Key).Wipe()
 
 	return MakeIdentityKey(child.Key)
 }
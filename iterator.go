@@ -0,0 +1,217 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"context"
+	"sync"
+)
+
+// ChainIterator walks the Entries of a chain from the First Entry to the
+// newest. Entry Blocks only link back to the previous block, so the Entry
+// Blocks are read from the chain head back to the start before the first
+// Entry is returned. Once the newest Entry has been returned Next checks the
+// chain head again, so an iterator can be used to follow a chain.
+//
+//	it := factom.NewChainIterator(chainID)
+//	for it.Next() {
+//		e := it.Entry()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type ChainIterator struct {
+	// Prefetch is the number of Entries requested at the same time. Values
+	// below 1 fetch one Entry at a time.
+	Prefetch int
+
+	ctx     context.Context
+	chainID string
+
+	// blocks are the Entry Blocks from the one holding the next Entry to
+	// the newest, keymrs their KeyMRs. index is the next Entry in blocks[0].
+	blocks []*EBlock
+	keymrs []string
+	index  int
+
+	buf   []*Entry
+	entry *Entry
+	err   error
+}
+
+// NewChainIterator returns a ChainIterator that starts at the First Entry of
+// the chain chainID.
+func NewChainIterator(chainID string) *ChainIterator {
+	return NewChainIteratorWithContext(context.Background(), chainID)
+}
+
+// NewChainIteratorWithContext is like NewChainIterator but cancels its
+// requests when ctx is done.
+func NewChainIteratorWithContext(ctx context.Context, chainID string) *ChainIterator {
+	return &ChainIterator{ctx: ctx, chainID: chainID}
+}
+
+// Seek moves the iterator to the position returned by Cursor, so that an
+// interrupted walk can be resumed by a new ChainIterator. An empty cursor
+// goes back to the First Entry.
+func (it *ChainIterator) Seek(cursor string) error {
+	it.blocks, it.keymrs, it.index = nil, nil, 0
+	it.buf, it.entry, it.err = nil, nil, nil
+	if cursor == "" {
+		return nil
+	}
+	keymr, index, err := parsePageToken(cursor)
+	if err != nil {
+		return err
+	}
+	it.keymrs, it.index = []string{keymr}, index
+	return nil
+}
+
+// Cursor returns the position of the Entry after the one returned by Entry.
+// It is empty until the Entry Blocks have been read.
+func (it *ChainIterator) Cursor() string {
+	if len(it.keymrs) == 0 {
+		return ""
+	}
+	return newPageToken(it.keymrs[0], it.index)
+}
+
+// Next moves to the next Entry. It returns false when there are no newer
+// Entries in the chain or when a request fails, which Err tells apart.
+func (it *ChainIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if len(it.buf) == 0 {
+		if err := it.fill(); err != nil {
+			it.err = err
+			return false
+		}
+		if len(it.buf) == 0 {
+			return false
+		}
+	}
+
+	it.entry = it.buf[0]
+	it.buf = it.buf[1:]
+	it.index++
+	it.skipRead()
+	return true
+}
+
+// Entry returns the Entry that Next moved to.
+func (it *ChainIterator) Entry() *Entry {
+	return it.entry
+}
+
+// Err returns the error that stopped the iterator, if any.
+func (it *ChainIterator) Err() error {
+	return it.err
+}
+
+// skipRead drops the Entry Blocks that have been read, keeping the newest
+// one so that the position can still be given as a cursor.
+func (it *ChainIterator) skipRead() {
+	for len(it.blocks) > 1 && it.index >= len(it.blocks[0].EntryList) {
+		it.blocks, it.keymrs, it.index = it.blocks[1:], it.keymrs[1:], 0
+	}
+}
+
+// fill fetches the next Entries into buf, reading the Entry Blocks again if
+// all the known Entries have been returned.
+func (it *ChainIterator) fill() error {
+	if len(it.blocks) == 0 || it.index >= len(it.blocks[0].EntryList) {
+		if err := it.load(); err != nil {
+			return err
+		}
+		it.skipRead()
+		if len(it.blocks) == 0 || it.index >= len(it.blocks[0].EntryList) {
+			return nil
+		}
+	}
+
+	n := it.Prefetch
+	if n < 1 {
+		n = 1
+	}
+	var hashes []string
+	for b, i := 0, it.index; b < len(it.blocks) && len(hashes) < n; b, i = b+1, 0 {
+		for ; i < len(it.blocks[b].EntryList) && len(hashes) < n; i++ {
+			hashes = append(hashes, it.blocks[b].EntryList[i].EntryHash)
+		}
+	}
+
+	es := make([]*Entry, len(hashes))
+	errs := make([]error, len(hashes))
+	var wg sync.WaitGroup
+	for i, h := range hashes {
+		wg.Add(1)
+		go func(i int, h string) {
+			defer wg.Done()
+			es[i], errs[i] = GetEntryWithContext(it.ctx, h)
+		}(i, h)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	it.buf = es
+	return nil
+}
+
+// load reads the Entry Blocks from the chain head back to the block of the
+// current position, or to the start of the chain if there is none yet.
+func (it *ChainIterator) load() error {
+	head, err := GetChainHeadAndStatusWithContext(it.ctx, it.chainID)
+	if err != nil {
+		return err
+	}
+	if head.ChainHead == "" && head.ChainInProcessList {
+		return ErrChainPending
+	}
+
+	var from string
+	if len(it.keymrs) > 0 {
+		from = it.keymrs[0]
+	}
+
+	var (
+		blocks []*EBlock
+		keymrs []string
+	)
+	for keymr := head.ChainHead; ; {
+		if keymr == ZeroHash {
+			if from != "" {
+				return validationErrorf("cursor is not for chain %s", it.chainID)
+			}
+			break
+		}
+		eb, err := GetEBlockWithContext(it.ctx, keymr)
+		if err != nil {
+			return err
+		}
+		if eb.Header.ChainID != it.chainID {
+			return validationErrorf("entry block %s is not in chain %s", keymr, it.chainID)
+		}
+		blocks = append(blocks, eb)
+		keymrs = append(keymrs, keymr)
+		if keymr == from {
+			break
+		}
+		keymr = eb.Header.PrevKeyMR
+	}
+
+	// oldest first
+	for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
+		blocks[i], blocks[j] = blocks[j], blocks[i]
+		keymrs[i], keymrs[j] = keymrs[j], keymrs[i]
+	}
+	it.blocks, it.keymrs = blocks, keymrs
+	return nil
+}
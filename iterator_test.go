@@ -0,0 +1,104 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestChainIterator(t *testing.T) {
+	chainid := "df3ade9eec4b08d5379cc64270c30ea7315d8a8a1a69efe2b98a60ecdd69e604"
+	eb1 := strings.Repeat("1", 64)
+	eb2 := strings.Repeat("2", 64)
+	eb3 := strings.Repeat("3", 64)
+
+	// two entry blocks with two and three entries. eb3 is added later.
+	head := eb2
+	eblocks := map[string]string{
+		eb1: fmt.Sprintf(`{"header": {"chainid": "%s", "prevkeymr": "%s"}, "entrylist": [{"entryhash": "e1"}, {"entryhash": "e2"}]}`, chainid, ZeroHash),
+		eb2: fmt.Sprintf(`{"header": {"chainid": "%s", "prevkeymr": "%s"}, "entrylist": [{"entryhash": "e3"}, {"entryhash": "e4"}, {"entryhash": "e5"}]}`, chainid, eb1),
+		eb3: fmt.Sprintf(`{"header": {"chainid": "%s", "prevkeymr": "%s"}, "entrylist": [{"entryhash": "e6"}]}`, chainid, eb2),
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(JSON2Request)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+		params := make(map[string]string)
+		json.Unmarshal(req.Params, &params)
+
+		var result string
+		switch req.Method {
+		case "chain-head":
+			result = fmt.Sprintf(`{"chainhead": "%s"}`, head)
+		case "entry-block":
+			result = eblocks[params["keymr"]]
+		case "entry":
+			result = fmt.Sprintf(`{"chainid": "%s", "content": "%s", "extids": []}`,
+				chainid, hex.EncodeToString([]byte(params["hash"])))
+		}
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 0, "result": %s}`, result)
+	}))
+	defer ts.Close()
+
+	SetFactomdServer(ts.URL[7:])
+
+	walk := func(it *ChainIterator, max int) string {
+		var got []string
+		for len(got) < max && it.Next() {
+			got = append(got, string(it.Entry().Content))
+		}
+		if err := it.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return strings.Join(got, ",")
+	}
+
+	it := NewChainIterator(chainid)
+	it.Prefetch = 3
+	if got := walk(it, 10); got != "e1,e2,e3,e4,e5" {
+		t.Errorf("wrong entries %s", got)
+	}
+
+	// the walk can be resumed from a cursor
+	it = NewChainIterator(chainid)
+	if got := walk(it, 3); got != "e1,e2,e3" {
+		t.Errorf("wrong entries %s", got)
+	}
+	cursor := it.Cursor()
+	it = NewChainIterator(chainid)
+	if err := it.Seek(cursor); err != nil {
+		t.Fatal(err)
+	}
+	if got := walk(it, 10); got != "e4,e5" {
+		t.Errorf("wrong entries after the cursor %s", got)
+	}
+
+	// entries added to the chain are returned by the next call to Next
+	head = eb3
+	if got := walk(it, 10); got != "e6" {
+		t.Errorf("wrong new entries %s", got)
+	}
+
+	if err := it.Seek("bad cursor"); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected a validation error for a bad cursor, got %v", err)
+	}
+	other := NewChainIterator(strings.Repeat("f", 64))
+	other.Seek(cursor)
+	if other.Next() || !errors.Is(other.Err(), ErrValidation) {
+		t.Errorf("expected a validation error for another chain's cursor, got %v", other.Err())
+	}
+}
@@ -6,6 +6,7 @@ package factom
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
@@ -13,7 +14,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
-	"time"
+	"sync/atomic"
 )
 
 type RPCConfig struct {
@@ -29,6 +30,16 @@ type RPCConfig struct {
 	FactomdRPCPassword string
 	FactomdServer      string
 	WalletServer       string
+
+	// FactomdTLSClientCertFile and FactomdTLSClientKeyFile are the
+	// certificate presented to factomd nodes that require client
+	// certificates.
+	FactomdTLSClientCertFile string
+	FactomdTLSClientKeyFile  string
+
+	// FactomdTLSPins are the SHA-256 fingerprints of the certificates that
+	// factomd may present, see CertificateFingerprint.
+	FactomdTLSPins []string
 }
 
 func EncodeJSON(data interface{}) ([]byte, error) {
@@ -69,6 +80,13 @@ func (e *JSONError) Error() string {
 	return s
 }
 
+// Is reports whether target is a *JSONError with the same code, so that
+// errors.Is(err, &JSONError{Code: -32001}) matches any error with that code.
+func (e *JSONError) Is(target error) bool {
+	t, ok := target.(*JSONError)
+	return ok && t.Code == e.Code
+}
+
 type JSON2Request struct {
 	JSONRPC string          `json:"jsonrpc"`
 	ID      interface{}     `json:"id"`
@@ -152,6 +170,18 @@ func GetFactomdEncryption() (bool, string) {
 	return RpcConfig.FactomdTLSEnable, RpcConfig.FactomdTLSCertFile
 }
 
+// SetFactomdClientCert sets the certificate presented to factomd over TLS.
+func SetFactomdClientCert(certFile, keyFile string) {
+	RpcConfig.FactomdTLSClientCertFile = certFile
+	RpcConfig.FactomdTLSClientKeyFile = keyFile
+}
+
+// SetFactomdPins pins the certificate of factomd to the given SHA-256
+// fingerprints. Calling it without fingerprints removes the pins.
+func SetFactomdPins(fingerprints ...string) {
+	RpcConfig.FactomdTLSPins = fingerprints
+}
+
 func SetWalletRpcConfig(user string, password string) {
 	RpcConfig.WalletRPCUser = user
 	RpcConfig.WalletRPCPassword = password
@@ -192,69 +222,111 @@ func WalletServer() string {
 
 // SendFactomdRequest sends a json object to factomd
 func SendFactomdRequest(req *JSON2Request) (*JSON2Response, error) {
-	return factomdRequest(req)
+	return factomdRequest(context.Background(), req)
+}
+
+// SendFactomdRequestWithContext is like SendFactomdRequest but cancels the
+// request when ctx is done.
+func SendFactomdRequestWithContext(ctx context.Context, req *JSON2Request) (*JSON2Response, error) {
+	return factomdRequest(ctx, req)
+}
+
+func factomdRequest(ctx context.Context, req *JSON2Request) (*JSON2Response, error) {
+	return DefaultClient().factomdRequest(ctx, req)
+}
+
+func walletRequest(ctx context.Context, req *JSON2Request) (*JSON2Response, error) {
+	return DefaultClient().walletRequest(ctx, req)
+}
+
+func (c *Client) factomdRequest(ctx context.Context, req *JSON2Request) (*JSON2Response, error) {
+	key, cached := cacheKey(req)
+	cached = cached && c.FactomdCache != nil
+	if cached {
+		if result, ok := c.FactomdCache.get(key); ok {
+			r := NewJSON2Response()
+			r.ID = req.ID
+			r.Result = result
+			return r, nil
+		}
+	}
+
+	retry := c.FactomdRetry
+	if submitMethods[req.Method] {
+		retry = nil
+	}
+
+	var r *JSON2Response
+	err := retry.do(ctx, func() (err error) {
+		r, err = c.factomdAttempt(ctx, req)
+		return err
+	})
+	if err == nil && cached && r.Error == nil && len(r.Result) > 0 && string(r.Result) != "null" {
+		c.FactomdCache.add(key, r.Result)
+	}
+	return r, err
 }
 
-func factomdRequest(req *JSON2Request) (*JSON2Response, error) {
+// factomdAttempt sends req to factomd once.
+func (c *Client) factomdAttempt(ctx context.Context, req *JSON2Request) (*JSON2Response, error) {
+	c.logf("factomd request: %s", req.Method)
 	j, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
 
-	factomdTls, factomdCertPath := GetFactomdEncryption()
-
 	var client *http.Client
 	var scheme, host string
 
-	if factomdTls == true {
-		caCert, err := ioutil.ReadFile(factomdCertPath)
+	if c.Config.FactomdTLSEnable {
+		conf, err := factomdTLSConfig(c.Config)
 		if err != nil {
-			return nil, err
+			return nil, &RequestError{Server: c.Config.FactomdServer, Method: req.Method, Err: err}
 		}
-		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM(caCert)
-		tr := &http.Transport{TLSClientConfig: &tls.Config{RootCAs: caCertPool}}
+		tr := &http.Transport{TLSClientConfig: conf}
 
-		client = &http.Client{Transport: tr, Timeout: time.Second * 30}
+		client = &http.Client{Transport: tr, Timeout: c.FactomdTimeout}
 		scheme = "https"
-		host = RpcConfig.FactomdServer
+		host = c.Config.FactomdServer
 
 	} else {
-		client = &http.Client{Timeout: time.Second * 30}
-		if index := strings.Index(RpcConfig.FactomdServer, "://"); index != -1 {
-			scheme = RpcConfig.FactomdServer[0:index]
-			host = RpcConfig.FactomdServer[index+3:]
+		client = &http.Client{Timeout: c.FactomdTimeout}
+		if index := strings.Index(c.Config.FactomdServer, "://"); index != -1 {
+			scheme = c.Config.FactomdServer[0:index]
+			host = c.Config.FactomdServer[index+3:]
 		} else {
 			scheme = "http"
-			host = RpcConfig.FactomdServer
+			host = c.Config.FactomdServer
 		}
 	}
-	re, err := http.NewRequest("POST",
+	re, err := http.NewRequestWithContext(ctx, "POST",
 		fmt.Sprintf("%s://%s/v2", scheme, host),
 		bytes.NewBuffer(j))
 	if err != nil {
 		return nil, err
 	}
 
-	user, pass := GetFactomdRpcConfig()
-	re.SetBasicAuth(user, pass)
+	re.SetBasicAuth(c.Config.FactomdRPCUser, c.Config.FactomdRPCPassword)
 	re.Header.Add("Content-Type", "application/json")
 	resp, err := client.Do(re)
 	if err != nil {
 		errs := fmt.Sprintf("%s", err)
 		if strings.Contains(errs, "\\x15\\x03\\x01\\x00\\x02\\x02\\x16") {
-			err = fmt.Errorf("Factomd API connection is encrypted. Please specify -factomdtls=true and -factomdcert=factomdAPIpub.cert (%v)", err.Error())
+			err = fmt.Errorf("Factomd API connection is encrypted. Please specify -factomdtls=true and -factomdcert=factomdAPIpub.cert (%w)", err)
 		}
-		return nil, err
+		return nil, &RequestError{Server: c.Config.FactomdServer, Method: req.Method, Err: err}
 	}
 	defer resp.Body.Close()
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, &RequestError{Server: c.Config.FactomdServer, Method: req.Method, Err: err}
 	}
 	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("Factomd username/password incorrect.  Edit factomd.conf or\ncall factom-cli with -factomduser=<user> -factomdpassword=<pass>")
+		return nil, unauthorizedErrorf("Factomd username/password incorrect.  Edit factomd.conf or\ncall factom-cli with -factomduser=<user> -factomdpassword=<pass>")
+	}
+	if unavailable(resp.StatusCode) {
+		return nil, &RequestError{Server: c.Config.FactomdServer, Method: req.Method, Err: fmt.Errorf("factomd returned %s", resp.Status)}
 	}
 	r := NewJSON2Response()
 	if err := json.Unmarshal(body, r); err != nil {
@@ -264,13 +336,14 @@ func factomdRequest(req *JSON2Request) (*JSON2Response, error) {
 	return r, nil
 }
 
-func walletRequest(req *JSON2Request) (*JSON2Response, error) {
+func (c *Client) walletRequest(ctx context.Context, req *JSON2Request) (*JSON2Response, error) {
+	c.logf("wallet request: %s", req.Method)
 	j, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
 
-	walletTls, walletCertPath := GetWalletEncryption()
+	walletTls, walletCertPath := c.Config.WalletTLSEnable, c.Config.WalletTLSCertFile
 
 	var client *http.Client
 	var httpx string
@@ -278,46 +351,52 @@ func walletRequest(req *JSON2Request) (*JSON2Response, error) {
 	if walletTls == true {
 		caCert, err := ioutil.ReadFile(walletCertPath)
 		if err != nil {
-			return nil, err
+			return nil, &RequestError{Server: c.Config.WalletServer, Method: req.Method, Err: err}
 		}
 		caCertPool := x509.NewCertPool()
 		caCertPool.AppendCertsFromPEM(caCert)
 		tr := &http.Transport{TLSClientConfig: &tls.Config{RootCAs: caCertPool}}
 
-		client = &http.Client{Transport: tr}
+		client = &http.Client{Transport: tr, Timeout: c.WalletTimeout}
 		httpx = "https"
 
 	} else {
-		client = &http.Client{}
+		client = &http.Client{Timeout: c.WalletTimeout}
 		httpx = "http"
 	}
 
-	re, err := http.NewRequest("POST",
-		fmt.Sprintf("%s://%s/v2", httpx, RpcConfig.WalletServer),
+	re, err := http.NewRequestWithContext(ctx, "POST",
+		fmt.Sprintf("%s://%s/v2", httpx, c.Config.WalletServer),
 		bytes.NewBuffer(j))
 	if err != nil {
 		return nil, err
 	}
 
-	user, pass := GetWalletRpcConfig()
-	re.SetBasicAuth(user, pass)
+	if c.WalletToken != "" {
+		re.Header.Set("Authorization", "Bearer "+c.WalletToken)
+	} else {
+		re.SetBasicAuth(c.Config.WalletRPCUser, c.Config.WalletRPCPassword)
+	}
 	re.Header.Add("Content-Type", "application/json")
+	if c.WalletHMACKeyID != "" {
+		SignRequest(re, j, c.WalletHMACKeyID, c.WalletHMACSecret)
+	}
 	resp, err := client.Do(re)
 	if err != nil {
 		errs := fmt.Sprintf("%s", err)
 		if strings.Contains(errs, "\\x15\\x03\\x01\\x00\\x02\\x02\\x16") {
-			err = fmt.Errorf("Factom-walletd API connection is encrypted. Please specify -wallettls=true and -walletcert=walletAPIpub.cert (%v)", err.Error())
+			err = fmt.Errorf("Factom-walletd API connection is encrypted. Please specify -wallettls=true and -walletcert=walletAPIpub.cert (%w)", err)
 		}
-		return nil, err
+		return nil, &RequestError{Server: c.Config.WalletServer, Method: req.Method, Err: err}
 	}
 	defer resp.Body.Close()
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, &RequestError{Server: c.Config.WalletServer, Method: req.Method, Err: err}
 	}
 	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("Wallet username/password incorrect.  Edit factomd.conf or\ncall factom-cli with -walletuser=<user> -walletpassword=<pass>")
+		return nil, unauthorizedErrorf("Wallet username/password incorrect.  Edit factomd.conf or\ncall factom-cli with -walletuser=<user> -walletpassword=<pass>")
 	}
 	r := NewJSON2Response()
 	if err := json.Unmarshal(body, r); err != nil {
@@ -327,12 +406,12 @@ func walletRequest(req *JSON2Request) (*JSON2Response, error) {
 	return r, nil
 }
 
-// newCounter is used to generate the ID field for the JSON2Request
+// newCounter is used to generate the ID field for the JSON2Request. It is
+// safe for concurrent use.
 func newCounter() func() int {
-	count := 0
+	var count int64
 	return func() int {
-		count += 1
-		return count
+		return int(atomic.AddInt64(&count, 1))
 	}
 }
 
@@ -0,0 +1,104 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GetChainEntriesPage returns up to limit Entries from a chain, newest first,
+// and a token for the next page. An empty token starts at the chain head. The
+// returned token is empty once the first Entry in the chain has been returned.
+//
+// Tokens point at a position inside an Entry Block and stay valid while new
+// Entries are added to the chain, so a long chain can be read a page at a time
+// and the read resumed later.
+func GetChainEntriesPage(chainid, token string, limit int) ([]*Entry, string, error) {
+	return GetChainEntriesPageWithContext(context.Background(), chainid, token, limit)
+}
+
+// GetChainEntriesPageWithContext is like GetChainEntriesPage but cancels its
+// requests when ctx is done.
+func GetChainEntriesPageWithContext(ctx context.Context, chainid, token string, limit int) ([]*Entry, string, error) {
+	if limit <= 0 {
+		return nil, "", validationErrorf("page limit must be greater than 0")
+	}
+
+	var (
+		keymr string
+		index int
+	)
+	if token == "" {
+		head, err := GetChainHeadAndStatusWithContext(ctx, chainid)
+		if err != nil {
+			return nil, "", err
+		}
+		if head.ChainHead == "" && head.ChainInProcessList {
+			return nil, "", ErrChainPending
+		}
+		keymr, index = head.ChainHead, -1
+	} else {
+		var err error
+		keymr, index, err = parsePageToken(token)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	es := make([]*Entry, 0, limit)
+	for keymr != ZeroHash {
+		eb, err := GetEBlockWithContext(ctx, keymr)
+		if err != nil {
+			return nil, "", err
+		}
+		if eb.Header.ChainID != chainid {
+			return nil, "", validationErrorf("page token is not for chain %s", chainid)
+		}
+		if index < 0 || index >= len(eb.EntryList) {
+			index = len(eb.EntryList) - 1
+		}
+
+		for ; index >= 0; index-- {
+			if len(es) == limit {
+				return es, newPageToken(keymr, index), nil
+			}
+			e, err := GetEntryWithContext(ctx, eb.EntryList[index].EntryHash)
+			if err != nil {
+				return nil, "", err
+			}
+			es = append(es, e)
+		}
+
+		keymr, index = eb.Header.PrevKeyMR, -1
+	}
+
+	return es, "", nil
+}
+
+// newPageToken encodes the position of the next Entry to return.
+func newPageToken(keymr string, index int) string {
+	return base64.RawURLEncoding.EncodeToString(
+		[]byte(fmt.Sprintf("%s:%d", keymr, index)))
+}
+
+func parsePageToken(token string) (string, int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", 0, validationErrorf("invalid page token")
+	}
+	parts := strings.Split(string(b), ":")
+	if len(parts) != 2 || len(parts[0]) != 64 {
+		return "", 0, validationErrorf("invalid page token")
+	}
+	index, err := strconv.Atoi(parts[1])
+	if err != nil || index < 0 {
+		return "", 0, validationErrorf("invalid page token")
+	}
+	return parts[0], index, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestGetChainEntriesPage(t *testing.T) {
+	chainid := "df3ade9eec4b08d5379cc64270c30ea7315d8a8a1a69efe2b98a60ecdd69e604"
+	eb1 := strings.Repeat("1", 64)
+	eb2 := strings.Repeat("2", 64)
+
+	// two entry blocks with two entries each. eb2 is the chain head.
+	eblocks := map[string]string{
+		eb1: fmt.Sprintf(`{"header": {"chainid": "%s", "prevkeymr": "%s"}, "entrylist": [{"entryhash": "e1"}, {"entryhash": "e2"}]}`, chainid, ZeroHash),
+		eb2: fmt.Sprintf(`{"header": {"chainid": "%s", "prevkeymr": "%s"}, "entrylist": [{"entryhash": "e3"}, {"entryhash": "e4"}]}`, chainid, eb1),
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(JSON2Request)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+		params := make(map[string]string)
+		json.Unmarshal(req.Params, &params)
+
+		var result string
+		switch req.Method {
+		case "chain-head":
+			result = fmt.Sprintf(`{"chainhead": "%s"}`, eb2)
+		case "entry-block":
+			result = eblocks[params["keymr"]]
+		case "entry":
+			result = fmt.Sprintf(`{"chainid": "%s", "content": "%s", "extids": []}`,
+				chainid, hex.EncodeToString([]byte(params["hash"])))
+		}
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 0, "result": %s}`, result)
+	}))
+	defer ts.Close()
+
+	SetFactomdServer(ts.URL[7:])
+
+	var got []string
+	token := ""
+	for i := 0; ; i++ {
+		es, next, err := GetChainEntriesPage(chainid, token, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range es {
+			got = append(got, string(e.Content))
+		}
+		if next == "" {
+			break
+		}
+		if i > 2 {
+			t.Fatal("too many pages")
+		}
+		token = next
+	}
+	if strings.Join(got, ",") != "e4,e3,e2,e1" {
+		t.Errorf("wrong entries %v", got)
+	}
+
+	if _, _, err := GetChainEntriesPage(chainid, "bad token", 3); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected a validation error for a bad token, got %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// PendingEntry is an Entry that factomd has acknowledged but not yet written
+// to a block.
+type PendingEntry struct {
+	EntryHash string `json:"entryhash"`
+	ChainID   string `json:"chainid"`
+	Status    string `json:"status"`
+}
+
+// PendingTransaction is a factoid transaction that factomd has acknowledged
+// but not yet written to a block.
+type PendingTransaction struct {
+	TxID      string            `json:"transactionid"`
+	DBHeight  uint32            `json:"dbheight"`
+	Status    string            `json:"status"`
+	Fees      uint64            `json:"fees"`
+	Inputs    []*PendingAddress `json:"inputs"`
+	Outputs   []*PendingAddress `json:"outputs"`
+	ECOutputs []*PendingAddress `json:"ecoutputs"`
+}
+
+// PendingAddress is an input or output of a PendingTransaction. Address is
+// the hex encoded address and UserAddress the same address as FA or EC
+// string.
+type PendingAddress struct {
+	Amount      uint64 `json:"amount"`
+	Address     string `json:"address"`
+	UserAddress string `json:"useraddress"`
+}
+
+// hasAddress reports whether addr, as a hex or user address, is one of the
+// inputs or outputs of the transaction.
+func (tx *PendingTransaction) hasAddress(addr string) bool {
+	for _, l := range [][]*PendingAddress{tx.Inputs, tx.Outputs, tx.ECOutputs} {
+		for _, a := range l {
+			if a.UserAddress == addr || a.Address == addr {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetPendingEntryList returns the Entries that factomd has acknowledged but
+// not yet written to a block.
+func GetPendingEntryList() ([]PendingEntry, error) {
+	return GetPendingEntryListWithContext(context.Background())
+}
+
+// GetPendingEntryListWithContext is like GetPendingEntryList but cancels its
+// requests when ctx is done.
+func GetPendingEntryListWithContext(ctx context.Context) ([]PendingEntry, error) {
+	req := NewJSON2Request("pending-entries", APICounter(), nil)
+	resp, err := factomdRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	var es []PendingEntry
+	if err := json.Unmarshal(resp.JSONResult(), &es); err != nil {
+		return nil, err
+	}
+	return es, nil
+}
+
+// GetPendingTransactionList returns the factoid transactions that factomd
+// has acknowledged but not yet written to a block. If address is not empty
+// only the transactions with an input or output to the address are
+// returned.
+func GetPendingTransactionList(address string) ([]PendingTransaction, error) {
+	return GetPendingTransactionListWithContext(context.Background(), address)
+}
+
+// GetPendingTransactionListWithContext is like GetPendingTransactionList but
+// cancels its requests when ctx is done.
+func GetPendingTransactionListWithContext(ctx context.Context, address string) ([]PendingTransaction, error) {
+	var params interface{}
+	if address != "" {
+		params = addressRequest{Address: address}
+	}
+	req := NewJSON2Request("pending-transactions", APICounter(), params)
+	resp, err := factomdRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	var txs []PendingTransaction
+	if err := json.Unmarshal(resp.JSONResult(), &txs); err != nil {
+		return nil, err
+	}
+	if address == "" {
+		return txs, nil
+	}
+	// older factomd versions ignore the address
+	matched := txs[:0]
+	for _, tx := range txs {
+		if tx.hasAddress(address) {
+			matched = append(matched, tx)
+		}
+	}
+	return matched, nil
+}
+
+// PendingWatcher polls factomd for pending Entries and transactions and calls
+// OnEntry and OnTransaction for each one that it has not seen before in a
+// watched chain or with a watched address.
+type PendingWatcher struct {
+	// Interval is the time between polls.
+	Interval time.Duration
+
+	// OnEntry is called with every new pending Entry in a watched chain.
+	OnEntry func(PendingEntry)
+
+	// OnTransaction is called with every new pending transaction with an
+	// input or output to a watched address.
+	OnTransaction func(PendingTransaction)
+
+	mu        sync.Mutex
+	chains    map[string]bool
+	addresses map[string]bool
+	seen      map[string]bool
+}
+
+// NewPendingWatcher returns a PendingWatcher that polls every interval.
+func NewPendingWatcher(interval time.Duration) *PendingWatcher {
+	return &PendingWatcher{
+		Interval:  interval,
+		chains:    make(map[string]bool),
+		addresses: make(map[string]bool),
+		seen:      make(map[string]bool),
+	}
+}
+
+// WatchChain adds the chain chainID to the watched chains.
+func (w *PendingWatcher) WatchChain(chainID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.chains[chainID] = true
+}
+
+// WatchAddress adds addr, a hex or user address, to the watched addresses.
+func (w *PendingWatcher) WatchAddress(addr string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.addresses[addr] = true
+}
+
+// Run polls until ctx is done or a poll fails, and returns the error.
+func (w *PendingWatcher) Run(ctx context.Context) error {
+	if w.Interval <= 0 {
+		return validationErrorf("poll interval must be greater than 0")
+	}
+	t := time.NewTicker(w.Interval)
+	defer t.Stop()
+	for {
+		if err := w.Poll(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Poll asks factomd for the pending Entries and transactions once and calls
+// the callbacks for the new ones. Items are remembered until they are no
+// longer pending.
+func (w *PendingWatcher) Poll(ctx context.Context) error {
+	w.mu.Lock()
+	chains := make(map[string]bool, len(w.chains))
+	for c := range w.chains {
+		chains[c] = true
+	}
+	addresses := make([]string, 0, len(w.addresses))
+	for a := range w.addresses {
+		addresses = append(addresses, a)
+	}
+	w.mu.Unlock()
+
+	var (
+		es  []PendingEntry
+		txs []PendingTransaction
+	)
+	if len(chains) > 0 {
+		all, err := GetPendingEntryListWithContext(ctx)
+		if err != nil {
+			return err
+		}
+		for _, e := range all {
+			if chains[e.ChainID] {
+				es = append(es, e)
+			}
+		}
+	}
+	if len(addresses) > 0 {
+		all, err := GetPendingTransactionListWithContext(ctx, "")
+		if err != nil {
+			return err
+		}
+		for _, tx := range all {
+			for _, a := range addresses {
+				if tx.hasAddress(a) {
+					txs = append(txs, tx)
+					break
+				}
+			}
+		}
+	}
+
+	// keep only what is new, and forget what is no longer pending
+	w.mu.Lock()
+	seen := make(map[string]bool, len(es)+len(txs))
+	newEs, newTxs := es[:0], txs[:0]
+	for _, e := range es {
+		if !w.seen[e.EntryHash] && !seen[e.EntryHash] {
+			newEs = append(newEs, e)
+		}
+		seen[e.EntryHash] = true
+	}
+	for _, tx := range txs {
+		if !w.seen[tx.TxID] && !seen[tx.TxID] {
+			newTxs = append(newTxs, tx)
+		}
+		seen[tx.TxID] = true
+	}
+	w.seen = seen
+	onEntry, onTransaction := w.OnEntry, w.OnTransaction
+	w.mu.Unlock()
+
+	for _, e := range newEs {
+		if onEntry != nil {
+			onEntry(e)
+		}
+	}
+	for _, tx := range newTxs {
+		if onTransaction != nil {
+			onTransaction(tx)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestPendingWatcher(t *testing.T) {
+	chainid := "df3ade9eec4b08d5379cc64270c30ea7315d8a8a1a69efe2b98a60ecdd69e604"
+	fa := "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"
+
+	entries := `[{"entryhash": "e1", "chainid": "` + chainid + `", "status": "TransactionACK"},
+		{"entryhash": "x1", "chainid": "other", "status": "TransactionACK"}]`
+	txs := `[{"transactionid": "t1", "status": "TransactionACK", "inputs": [{"amount": 10, "address": "ab", "useraddress": "` + fa + `"}]},
+		{"transactionid": "x1", "status": "TransactionACK", "inputs": [{"amount": 10, "address": "cd", "useraddress": "FAother"}]}]`
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(JSON2Request)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+		switch req.Method {
+		case "pending-entries":
+			fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 0, "result": %s}`, entries)
+		case "pending-transactions":
+			fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 0, "result": %s}`, txs)
+		}
+	}))
+	defer ts.Close()
+	SetFactomdServer(ts.URL[7:])
+
+	es, err := GetPendingEntryList()
+	if err != nil || len(es) != 2 || es[0].EntryHash != "e1" || es[0].ChainID != chainid {
+		t.Errorf("GetPendingEntryList = %v, %v", es, err)
+	}
+	list, err := GetPendingTransactionList(fa)
+	if err != nil || len(list) != 1 || list[0].TxID != "t1" || list[0].Inputs[0].Amount != 10 {
+		t.Errorf("GetPendingTransactionList = %v, %v", list, err)
+	}
+
+	var gotEntries, gotTxs []string
+	w := NewPendingWatcher(0)
+	w.OnEntry = func(e PendingEntry) { gotEntries = append(gotEntries, e.EntryHash) }
+	w.OnTransaction = func(tx PendingTransaction) { gotTxs = append(gotTxs, tx.TxID) }
+	w.WatchChain(chainid)
+	w.WatchAddress(fa)
+
+	if err := w.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(gotEntries, gotTxs) != "[e1] [t1]" {
+		t.Errorf("first poll: %v %v", gotEntries, gotTxs)
+	}
+
+	// only new items are reported
+	entries = `[{"entryhash": "e1", "chainid": "` + chainid + `"}, {"entryhash": "e2", "chainid": "` + chainid + `"}]`
+	if err := w.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(gotEntries, gotTxs) != "[e1 e2] [t1]" {
+		t.Errorf("second poll: %v %v", gotEntries, gotTxs)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// EntryState is how far an Entry has gone through an EntryPipeline.
+type EntryState int
+
+const (
+	EntryQueued EntryState = iota
+	EntryCommitted
+	EntryRevealed
+	EntryConfirmed
+)
+
+func (s EntryState) String() string {
+	switch s {
+	case EntryQueued:
+		return "queued"
+	case EntryCommitted:
+		return "committed"
+	case EntryRevealed:
+		return "revealed"
+	case EntryConfirmed:
+		return "confirmed"
+	}
+	return "unknown"
+}
+
+// EntryResult reports what an EntryPipeline did with an Entry. State is the
+// last state the Entry reached and Err the error that stopped it, if any. An
+// Entry that failed after its commit can be revealed again with RevealEntry
+// without paying twice.
+type EntryResult struct {
+	Entry     *Entry
+	TxID      string
+	EntryHash string
+	State     EntryState
+	Err       error
+}
+
+// errRepeatedCommit is returned by factomd for the commit of an Entry that
+// has already been committed, for example by an attempt whose response was
+// lost.
+var errRepeatedCommit = &JSONError{Code: -32011}
+
+// EntryPipeline commits and reveals many Entries concurrently, paying with
+// one Entry Credit address. Entries are given to Submit and one EntryResult
+// per Entry is sent on the channel returned by Start, in the order the
+// Entries are done. The results must be read while Entries are submitted.
+//
+//	p := factom.NewEntryPipeline(ec, 8)
+//	results := p.Start(ctx)
+//	go func() {
+//		for _, e := range entries {
+//			p.Submit(e)
+//		}
+//		p.Close()
+//	}()
+//	for r := range results {
+//		...
+//	}
+type EntryPipeline struct {
+	// Retry retries the commit and the reveal of an Entry when they fail
+	// with a transient error. It is DefaultRetryPolicy unless changed
+	// before Start; nil does not retry.
+	Retry *RetryPolicy
+
+	// Confirm is the status to wait for after the reveal,
+	// AckStatusTransactionACK or AckStatusDBlockConfirmed. Entries are
+	// done once revealed if it is empty.
+	Confirm string
+
+	// PollInterval is how often factomd is asked for the status of an
+	// Entry while waiting for Confirm.
+	PollInterval time.Duration
+
+	ec      *ECAddress
+	workers int
+
+	ctx     context.Context
+	entries chan *Entry
+	results chan *EntryResult
+	once    sync.Once
+}
+
+// NewEntryPipeline returns an EntryPipeline that pays with ec and handles up
+// to workers Entries at the same time.
+func NewEntryPipeline(ec *ECAddress, workers int) *EntryPipeline {
+	if workers < 1 {
+		workers = 1
+	}
+	return &EntryPipeline{
+		Retry:        DefaultRetryPolicy,
+		PollInterval: time.Second,
+		ec:           ec,
+		workers:      workers,
+	}
+}
+
+// Start starts the workers and returns the channel of results, which is
+// closed after Close once every submitted Entry is done. The workers stop
+// when ctx is done, reporting ctx.Err() for the Entries they were handling.
+func (p *EntryPipeline) Start(ctx context.Context) <-chan *EntryResult {
+	p.ctx = ctx
+	p.entries = make(chan *Entry)
+	p.results = make(chan *EntryResult, p.workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range p.entries {
+				p.results <- p.process(e)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(p.results)
+	}()
+	return p.results
+}
+
+// Submit queues e, waiting for a free worker. It returns ctx.Err() if the
+// context given to Start is done first.
+func (p *EntryPipeline) Submit(e *Entry) error {
+	select {
+	case p.entries <- e:
+		return nil
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	}
+}
+
+// Close tells the pipeline that no more Entries will be submitted.
+func (p *EntryPipeline) Close() {
+	p.once.Do(func() {
+		close(p.entries)
+	})
+}
+
+// process commits, reveals and confirms e.
+func (p *EntryPipeline) process(e *Entry) *EntryResult {
+	ctx := p.ctx
+	r := &EntryResult{Entry: e, EntryHash: hex.EncodeToString(e.Hash())}
+
+	r.Err = p.Retry.do(ctx, func() error {
+		txid, err := CommitEntryWithContext(ctx, e, p.ec)
+		if errors.Is(err, errRepeatedCommit) {
+			return nil
+		}
+		r.TxID = txid
+		return err
+	})
+	if r.Err != nil {
+		return r
+	}
+	r.State = EntryCommitted
+
+	r.Err = p.Retry.do(ctx, func() error {
+		_, err := RevealEntryWithContext(ctx, e)
+		return err
+	})
+	if r.Err != nil {
+		return r
+	}
+	r.State = EntryRevealed
+
+	if p.Confirm == "" {
+		return r
+	}
+	if _, r.Err = WaitForConfirmation(ctx, r.EntryHash, e.ChainID, p.Confirm, p.PollInterval); r.Err != nil {
+		return r
+	}
+	r.State = EntryConfirmed
+	return r
+}
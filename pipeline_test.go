@@ -0,0 +1,118 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestEntryPipeline(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		commits int
+		reveals int
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(JSON2Request)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch req.Method {
+		case "commit-entry":
+			commits++
+			// the first commit fails with a transient error and is
+			// retried, the next entry is rejected and the one after is
+			// a repeated commit, as if a response had been lost
+			switch commits {
+			case 1:
+				w.WriteHeader(http.StatusServiceUnavailable)
+			case 3:
+				fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "error": {"code": -32602, "message": "Invalid params"}}`)
+			case 4:
+				fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "error": {"code": -32011, "message": "Repeated Commit"}}`)
+			default:
+				fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"message": "Entry Commit Success", "txid": "bf12"}}`)
+			}
+		case "reveal-entry":
+			reveals++
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"message": "Entry Reveal Success"}}`)
+		case "ack":
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"commitdata": {"status": "TransactionACK"}, "entrydata": {"status": "TransactionACK"}}}`)
+		}
+	}))
+	defer ts.Close()
+	SetFactomdServer(ts.URL[7:])
+
+	ecAddr, _ := GetECAddress("Es2Rf7iM6PdsqfYCo3D1tnAR65SkLENyWJG1deUzpRMQmbh9F3eG")
+	chainID := "954d5a49fd70d9b8bcdb35d252267829957f7ef7fa6c74f88419bdc5e82209f4"
+
+	// one worker handles the entries in order
+	p := NewEntryPipeline(ecAddr, 1)
+	p.Retry = &RetryPolicy{MaxAttempts: 2, Delay: time.Millisecond}
+	p.Confirm = AckStatusTransactionACK
+	p.PollInterval = time.Millisecond
+	results := p.Start(context.Background())
+	go func() {
+		for i := 0; i < 4; i++ {
+			if err := p.Submit(&Entry{ChainID: chainID, Content: []byte{byte(i)}}); err != nil {
+				t.Error(err)
+			}
+		}
+		p.Close()
+	}()
+
+	var got []*EntryResult
+	for r := range results {
+		got = append(got, r)
+	}
+	if len(got) != 4 {
+		t.Fatalf("got %d results", len(got))
+	}
+
+	// the transient failure was retried, the rejected commit was not
+	if got[0].State != EntryConfirmed || got[0].Err != nil || got[0].TxID != "bf12" {
+		t.Errorf("entry 0: %s %v", got[0].State, got[0].Err)
+	}
+	if got[1].State != EntryQueued || !errors.Is(got[1].Err, &JSONError{Code: -32602}) {
+		t.Errorf("entry 1: %s %v", got[1].State, got[1].Err)
+	}
+	if got[2].State != EntryConfirmed || got[2].Err != nil || got[2].TxID != "" {
+		t.Errorf("entry 2: %s %v", got[2].State, got[2].Err)
+	}
+	if got[3].State != EntryConfirmed || got[3].EntryHash == "" || got[3].EntryHash == got[0].EntryHash {
+		t.Errorf("entry 3: %s %v", got[3].State, got[3].Err)
+	}
+	if commits != 5 || reveals != 3 {
+		t.Errorf("%d commits and %d reveals", commits, reveals)
+	}
+
+	// entries are not taken once the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	p = NewEntryPipeline(ecAddr, 2)
+	results = p.Start(ctx)
+	cancel()
+	if err := p.Submit(&Entry{ChainID: chainID}); err == nil {
+		// a worker may have been ready before the cancel was seen
+		if r := <-results; r.Err == nil {
+			t.Errorf("expected the entry to fail, got %s", r.State)
+		}
+	}
+	p.Close()
+	for range results {
+	}
+}
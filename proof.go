@@ -0,0 +1,253 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+)
+
+const (
+	// ProofBundleVersion is the format version written by ExportProofBundle.
+	ProofBundleVersion = 1
+
+	eblockHeaderSize = 140
+	dblockHeaderSize = 113
+)
+
+// ProofNode is one step of a Merkle branch. Top is the sha256 of Left and
+// Right.
+type ProofNode struct {
+	Left  string `json:"left"`
+	Right string `json:"right"`
+	Top   string `json:"top"`
+}
+
+// ProofAnchor points at a transaction on another blockchain that anchors the
+// Directory Block of a proof. Anchors are references only and are not checked
+// by VerifyProofBundle, see VerifyAnchors.
+type ProofAnchor struct {
+	Network   string `json:"network"`
+	TxHash    string `json:"txhash"`
+	BlockHash string `json:"blockhash,omitempty"`
+}
+
+// ProofBundle holds everything needed to prove that an Entry is part of a
+// Directory Block without asking factomd: the Entry, the Merkle branch from
+// the Entry Hash to the Directory Block KeyMR and the headers of the Entry
+// Block and Directory Block along the way. All binary values are hex.
+type ProofBundle struct {
+	Version              int            `json:"version"`
+	EntryHash            string         `json:"entryhash"`
+	Entry                string         `json:"entry"`
+	MerkleBranch         []*ProofNode   `json:"merklebranch"`
+	EntryBlockKeyMR      string         `json:"entryblockkeymr"`
+	EntryBlockHeader     string         `json:"entryblockheader"`
+	DirectoryBlockKeyMR  string         `json:"directoryblockkeymr"`
+	DirectoryBlockHeader string         `json:"directoryblockheader"`
+	Anchors              []*ProofAnchor `json:"anchors,omitempty"`
+}
+
+// GetProofBundle collects the ProofBundle of the Entry with the given hash
+// from factomd. The Entry must be in a Directory Block.
+func GetProofBundle(hash string) (*ProofBundle, error) {
+	return GetProofBundleWithContext(context.Background(), hash)
+}
+
+// GetProofBundleWithContext is like GetProofBundle but cancels its requests
+// when ctx is done.
+func GetProofBundleWithContext(ctx context.Context, hash string) (*ProofBundle, error) {
+	r, err := GetReceiptWithContext(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil || r.DirectoryBlockKeyMR == "" {
+		return nil, validationErrorf("entry %s is not in a directory block yet", hash)
+	}
+
+	entry, err := GetRawWithContext(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	eb, err := GetRawWithContext(ctx, r.EntryBlockKeyMR)
+	if err != nil {
+		return nil, err
+	}
+	db, err := GetRawWithContext(ctx, r.DirectoryBlockKeyMR)
+	if err != nil {
+		return nil, err
+	}
+	if len(eb) < eblockHeaderSize || len(db) < dblockHeaderSize {
+		return nil, validationErrorf("short block returned for entry %s", hash)
+	}
+
+	b := &ProofBundle{
+		Version:              ProofBundleVersion,
+		EntryHash:            hash,
+		Entry:                hex.EncodeToString(entry),
+		EntryBlockKeyMR:      r.EntryBlockKeyMR,
+		EntryBlockHeader:     hex.EncodeToString(eb[:eblockHeaderSize]),
+		DirectoryBlockKeyMR:  r.DirectoryBlockKeyMR,
+		DirectoryBlockHeader: hex.EncodeToString(db[:dblockHeaderSize]),
+	}
+	for _, n := range r.MerkleBranch {
+		b.MerkleBranch = append(b.MerkleBranch, &ProofNode{Left: n.Left, Right: n.Right, Top: n.Top})
+	}
+	if r.BitcoinTransactionHash != "" {
+		b.Anchors = append(b.Anchors, &ProofAnchor{
+			Network:   "bitcoin",
+			TxHash:    r.BitcoinTransactionHash,
+			BlockHash: r.BitcoinBlockHash,
+		})
+	}
+	return b, nil
+}
+
+// ExportProofBundle writes the ProofBundle of the Entry with the given hash
+// to w as json.
+func ExportProofBundle(hash string, w io.Writer) error {
+	return ExportProofBundleWithContext(context.Background(), hash, w)
+}
+
+// ExportProofBundleWithContext is like ExportProofBundle but cancels its
+// requests when ctx is done.
+func ExportProofBundleWithContext(ctx context.Context, hash string, w io.Writer) error {
+	b, err := GetProofBundleWithContext(ctx, hash)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(b)
+}
+
+// ReadProofBundle reads a ProofBundle written by ExportProofBundle.
+func ReadProofBundle(r io.Reader) (*ProofBundle, error) {
+	b := new(ProofBundle)
+	if err := json.NewDecoder(r).Decode(b); err != nil {
+		return nil, validationErrorf("invalid proof bundle: %v", err)
+	}
+	return b, nil
+}
+
+// DirectoryBlockHeight returns the height of the Directory Block from its
+// header. The bundle should be verified first.
+func (b *ProofBundle) DirectoryBlockHeight() (uint32, error) {
+	h, err := decodeProofHex(b.DirectoryBlockHeader, dblockHeaderSize)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(h[105:109]), nil
+}
+
+// VerifyProofBundle checks without any network access that the Entry in b
+// hashes to its Entry Hash, that the Merkle branch leads from the Entry Hash
+// through the Entry Block to the Directory Block KeyMR, that the block
+// headers match the branch, and that the Entry Block is for the chain of the
+// Entry. Errors match ErrValidation.
+func VerifyProofBundle(b *ProofBundle) error {
+	if b.Version != ProofBundleVersion {
+		return validationErrorf("unsupported proof bundle version %d", b.Version)
+	}
+
+	entry, err := decodeProofHex(b.Entry, 0)
+	if err != nil {
+		return err
+	}
+	if len(entry) < 35 {
+		return validationErrorf("proof entry is too short")
+	}
+	if hex.EncodeToString(sha52(entry)) != b.EntryHash {
+		return validationErrorf("proof entry does not hash to %s", b.EntryHash)
+	}
+
+	ebHeader, err := decodeProofHex(b.EntryBlockHeader, eblockHeaderSize)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(ebHeader[:32], entry[1:33]) {
+		return validationErrorf("proof entry block is not for the chain of the entry")
+	}
+	dbHeader, err := decodeProofHex(b.DirectoryBlockHeader, dblockHeaderSize)
+	if err != nil {
+		return err
+	}
+
+	var sawEBlock bool
+	cur := b.EntryHash
+	for i, n := range b.MerkleBranch {
+		if cur != n.Left && cur != n.Right {
+			return validationErrorf("merkle branch is broken at step %d", i)
+		}
+		left, err := decodeProofHex(n.Left, sha256.Size)
+		if err != nil {
+			return err
+		}
+		right, err := decodeProofHex(n.Right, sha256.Size)
+		if err != nil {
+			return err
+		}
+		top := sha256.Sum256(append(left, right...))
+		if hex.EncodeToString(top[:]) != n.Top {
+			return validationErrorf("merkle branch has a bad hash at step %d", i)
+		}
+
+		// the KeyMR of a block is the hash of its header hash and body root
+		switch n.Top {
+		case b.EntryBlockKeyMR:
+			if err := checkHeaderStep(n, ebHeader, ebHeader[32:64], "entry"); err != nil {
+				return err
+			}
+			sawEBlock = true
+		case b.DirectoryBlockKeyMR:
+			if i != len(b.MerkleBranch)-1 {
+				return validationErrorf("merkle branch continues past the directory block")
+			}
+			if err := checkHeaderStep(n, dbHeader, dbHeader[5:37], "directory"); err != nil {
+				return err
+			}
+		}
+		cur = n.Top
+	}
+
+	if !sawEBlock {
+		return validationErrorf("merkle branch does not pass through entry block %s", b.EntryBlockKeyMR)
+	}
+	if cur != b.DirectoryBlockKeyMR {
+		return validationErrorf("merkle branch does not end at directory block %s", b.DirectoryBlockKeyMR)
+	}
+	return nil
+}
+
+// checkHeaderStep checks the branch node that joins a block header to the
+// body Merkle root stored in it.
+func checkHeaderStep(n *ProofNode, header, bodyMR []byte, block string) error {
+	h := sha256.Sum256(header)
+	if n.Left != hex.EncodeToString(h[:]) {
+		return validationErrorf("proof %s block header does not match the merkle branch", block)
+	}
+	if n.Right != hex.EncodeToString(bodyMR) {
+		return validationErrorf("proof %s block body does not match its header", block)
+	}
+	return nil
+}
+
+// decodeProofHex decodes a hex field of a proof and checks its length if
+// size is not 0.
+func decodeProofHex(s string, size int) ([]byte, error) {
+	p, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, validationErrorf("invalid hex in proof: %v", err)
+	}
+	if size != 0 && len(p) != size {
+		return nil, validationErrorf("proof value %s is %d bytes, expected %d", s, len(p), size)
+	}
+	return p, nil
+}
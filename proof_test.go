@@ -0,0 +1,140 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestProofBundle(t *testing.T) {
+	e := &Entry{
+		ChainID: "df3ade9eec4b08d5379cc64270c30ea7315d8a8a1a69efe2b98a60ecdd69e604",
+		ExtIDs:  [][]byte{[]byte("proof")},
+		Content: []byte("hello"),
+	}
+	entry, err := e.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entryHash := hex.EncodeToString(e.Hash())
+	chainID, _ := hex.DecodeString(e.ChainID)
+
+	join := func(l, r []byte) []byte {
+		h := sha256.Sum256(append(append([]byte(nil), l...), r...))
+		return h[:]
+	}
+	node := func(l, r []byte) map[string]string {
+		return map[string]string{
+			"left":  hex.EncodeToString(l),
+			"right": hex.EncodeToString(r),
+			"top":   hex.EncodeToString(join(l, r)),
+		}
+	}
+
+	// an entry block with the entry and one other hash in its body
+	other := bytes.Repeat([]byte{1}, 32)
+	eh := e.Hash()
+	ebBody := join(eh, other)
+	ebHeader := make([]byte, 140)
+	copy(ebHeader, chainID)
+	copy(ebHeader[32:], ebBody)
+	ebHeaderHash := sha256.Sum256(ebHeader)
+	ebKeyMR := join(ebHeaderHash[:], ebBody)
+
+	// a directory block with the entry block and one other block
+	dbBody := join(other, ebKeyMR)
+	dbHeader := make([]byte, 113)
+	copy(dbHeader[5:], dbBody)
+	binary.BigEndian.PutUint32(dbHeader[105:], 1234)
+	dbHeaderHash := sha256.Sum256(dbHeader)
+	dbKeyMR := join(dbHeaderHash[:], dbBody)
+
+	receipt := map[string]interface{}{
+		"entry": map[string]string{"entryhash": entryHash},
+		"merklebranch": []map[string]string{
+			node(eh, other),
+			node(ebHeaderHash[:], ebBody),
+			node(other, ebKeyMR),
+			node(dbHeaderHash[:], dbBody),
+		},
+		"entryblockkeymr":        hex.EncodeToString(ebKeyMR),
+		"directoryblockkeymr":    hex.EncodeToString(dbKeyMR),
+		"bitcointransactionhash": "aa",
+	}
+	raw := map[string][]byte{
+		entryHash:                   entry,
+		hex.EncodeToString(ebKeyMR): append(ebHeader, eh...),
+		hex.EncodeToString(dbKeyMR): append(dbHeader, other...),
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(JSON2Request)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+		params := make(map[string]string)
+		json.Unmarshal(req.Params, &params)
+
+		var result []byte
+		switch req.Method {
+		case "receipt":
+			result, _ = json.Marshal(map[string]interface{}{"receipt": receipt})
+		case "raw-data":
+			result = []byte(fmt.Sprintf(`{"data": "%x"}`, raw[params["hash"]]))
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+		}
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 0, "result": %s}`, result)
+	}))
+	defer ts.Close()
+	SetFactomdServer(ts.URL[7:])
+
+	buf := new(bytes.Buffer)
+	if err := ExportProofBundle(entryHash, buf); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ReadProofBundle(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyProofBundle(b); err != nil {
+		t.Fatal(err)
+	}
+	if h, err := b.DirectoryBlockHeight(); err != nil || h != 1234 {
+		t.Errorf("wrong height %d %v", h, err)
+	}
+	if len(b.Anchors) != 1 || b.Anchors[0].TxHash != "aa" {
+		t.Errorf("wrong anchors %v", b.Anchors)
+	}
+
+	// any change to the bundle must be caught
+	tampered := *b
+	tampered.Entry = hex.EncodeToString(append(entry, 'x'))
+	if err := VerifyProofBundle(&tampered); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected a validation error for a changed entry, got %v", err)
+	}
+	tampered = *b
+	tampered.DirectoryBlockHeader = hex.EncodeToString(make([]byte, 113))
+	if err := VerifyProofBundle(&tampered); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected a validation error for a changed header, got %v", err)
+	}
+	tampered = *b
+	tampered.MerkleBranch = b.MerkleBranch[:3]
+	if err := VerifyProofBundle(&tampered); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected a validation error for a short branch, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+// Package quick wraps the factom client in a few calls for writing and reading
+// chains. The Entry Credit key is looked up in factom-walletd, the commit and
+// reveal are sent to factomd, and the calls return once factomd has
+// acknowledged the entry.
+//
+//	chainID, err := quick.WriteChain(ecPub, []byte("hello"), []byte("my chain"))
+//	hash, err := quick.AppendEntry(ecPub, chainID, []byte("world"))
+//	entries, err := quick.ReadChain(chainID)
+//
+// The factomd and wallet servers are configured with the factom package.
+package quick
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+var (
+	// PollInterval is how often factomd is asked for the status of a new
+	// entry.
+	PollInterval = time.Second
+
+	// AckTimeout is how long to wait for factomd to acknowledge a new entry.
+	AckTimeout = 2 * time.Minute
+)
+
+// ErrAckTimeout is returned when factomd did not acknowledge an entry within
+// AckTimeout. The entry may still be written later.
+var ErrAckTimeout = errors.New("quick: timed out waiting for the entry to be acknowledged")
+
+// WriteChain creates a new chain whose first entry has the given content and
+// external ids, paying with the Entry Credit address ecPub from the wallet. It
+// returns the new chain id.
+func WriteChain(ecPub string, content []byte, extIDs ...[]byte) (string, error) {
+	ec, err := factom.FetchECAddress(ecPub)
+	if err != nil {
+		return "", err
+	}
+
+	e := new(factom.Entry)
+	e.ExtIDs = extIDs
+	e.Content = content
+	c := factom.NewChain(e)
+
+	if _, err := factom.SubmitChain(c, ec); err != nil {
+		return "", err
+	}
+
+	if err := waitForAck(c.FirstEntry); err != nil {
+		return "", err
+	}
+	return c.ChainID, nil
+}
+
+// AppendEntry adds an entry with the given content and external ids to an
+// existing chain, paying with the Entry Credit address ecPub from the wallet.
+// It returns the entry hash.
+func AppendEntry(ecPub, chainID string, content []byte, extIDs ...[]byte) (string, error) {
+	ec, err := factom.FetchECAddress(ecPub)
+	if err != nil {
+		return "", err
+	}
+
+	e := new(factom.Entry)
+	e.ChainID = chainID
+	e.ExtIDs = extIDs
+	e.Content = content
+
+	if _, _, err := factom.SubmitEntry(e, ec); err != nil {
+		return "", err
+	}
+
+	if err := waitForAck(e); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(e.Hash()), nil
+}
+
+// ReadChain returns all of the entries in a chain, oldest first.
+func ReadChain(chainID string) ([]*factom.Entry, error) {
+	return factom.GetAllChainEntries(chainID)
+}
+
+// waitForAck polls factomd until the commit and the entry have both been
+// acknowledged.
+func waitForAck(e *factom.Entry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), AckTimeout)
+	defer cancel()
+
+	hash := hex.EncodeToString(e.Hash())
+	_, err := factom.WaitForConfirmation(ctx, hash, e.ChainID, factom.AckStatusTransactionACK, PollInterval)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrAckTimeout
+	}
+	return err
+}
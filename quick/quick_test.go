@@ -0,0 +1,76 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package quick_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/quick"
+)
+
+func TestWriteChainAndAppendEntry(t *testing.T) {
+	ecPub := "EC2DKSYyRcNWf7RS963VFYgMExoHRYLHVeCfQ9PGPmNzwrcmgm2r"
+	ecSec := "Es2Rf7iM6PdsqfYCo3D1tnAR65SkLENyWJG1deUzpRMQmbh9F3eG"
+
+	// answer both the wallet and the factomd calls. The entry is only
+	// acknowledged on the second ack request.
+	acks := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(factom.JSON2Request)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+
+		var result string
+		switch req.Method {
+		case "address":
+			result = fmt.Sprintf(`{"public": "%s", "secret": "%s"}`, ecPub, ecSec)
+		case "commit-chain", "commit-entry":
+			result = `{"message": "Entry Commit Success", "txid": "76e123d133a841fe3e08c5e3f3d392f8431f2d7668890c03f003f541efa8fc61"}`
+		case "reveal-chain", "reveal-entry":
+			result = `{"message": "Entry Reveal Success", "entryhash": "f5c956749fc3eba4acc60fd485fb100e601070a44fcce54ff358d60669854734"}`
+		case "ack":
+			status := "NotConfirmed"
+			if acks++; acks%2 == 0 {
+				status = "TransactionACK"
+			}
+			result = fmt.Sprintf(`{"commitdata": {"status": "%s"}, "entrydata": {"status": "%s"}}`, status, status)
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+		}
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 0, "result": %s}`, result)
+	}))
+	defer ts.Close()
+
+	factom.SetFactomdServer(ts.URL[7:])
+	factom.SetWalletServer(ts.URL[7:])
+	PollInterval = time.Millisecond
+
+	chainID, err := WriteChain(ecPub, []byte("hello"), []byte("quick"), []byte("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chainID != factom.NewChain(&factom.Entry{ExtIDs: [][]byte{[]byte("quick"), []byte("test")}}).ChainID {
+		t.Errorf("wrong chain id %s", chainID)
+	}
+
+	hash, err := AppendEntry(ecPub, chainID, []byte("world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hash) != 64 {
+		t.Errorf("wrong entry hash %s", hash)
+	}
+	if acks != 4 {
+		t.Errorf("expected 4 ack requests, got %d", acks)
+	}
+}
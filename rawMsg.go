@@ -1,6 +1,7 @@
 package factom
 
 import (
+	"context"
 	"encoding/json"
 )
 
@@ -9,9 +10,15 @@ type SendRawMessageResponse struct {
 }
 
 func SendRawMsg(message string) (*SendRawMessageResponse, error) {
+	return SendRawMsgWithContext(context.Background(), message)
+}
+
+// SendRawMsgWithContext is like SendRawMsg but cancels its requests when ctx is
+// done.
+func SendRawMsgWithContext(ctx context.Context, message string) (*SendRawMessageResponse, error) {
 	param := messageRequest{Message: message}
 	req := NewJSON2Request("send-raw-message", APICounter(), param)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
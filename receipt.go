@@ -5,17 +5,24 @@
 package factom
 
 import (
+	"context"
 	"encoding/json"
 )
 
 func GetReceipt(hash string) (*Receipt, error) {
+	return GetReceiptWithContext(context.Background(), hash)
+}
+
+// GetReceiptWithContext is like GetReceipt but cancels its requests when ctx is
+// done.
+func GetReceiptWithContext(ctx context.Context, hash string) (*Receipt, error) {
 	type receiptResponse struct {
 		Receipt *Receipt `json:"receipt"`
 	}
 
 	params := hashRequest{Hash: hash}
 	req := NewJSON2Request("receipt", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -5,6 +5,7 @@
 package factom
 
 import (
+	"context"
 	"fmt"
 
 	netki "github.com/FactomProject/netki-go-partner-client"
@@ -20,13 +21,19 @@ func ResolveDnsName(addr string) (string, string, error) {
 }
 
 func GetDnsBalance(addr string) (int64, int64, error) {
+	return GetDnsBalanceWithContext(context.Background(), addr)
+}
+
+// GetDnsBalanceWithContext is like GetDnsBalance but cancels its requests when
+// ctx is done.
+func GetDnsBalanceWithContext(ctx context.Context, addr string) (int64, int64, error) {
 	fct, ec, err := ResolveDnsName(addr)
 	if err != nil {
 		return -1, -1, err
 	}
 
-	f, err1 := GetFactoidBalance(fct)
-	e, err2 := GetECBalance(ec)
+	f, err1 := GetFactoidBalanceWithContext(ctx, fct)
+	e, err2 := GetECBalanceWithContext(ctx, ec)
 	if err1 != nil || err2 != nil {
 		return f, e, fmt.Errorf("%s\n%s\n", err1, err2)
 	}
@@ -0,0 +1,135 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how calls to factomd are retried when they fail
+// because of a network error or an unavailable server. Errors returned by the
+// factomd api itself are not retried, and neither are the calls that submit
+// transactions, commits or reveals: factomd may have applied a call whose
+// response was lost, and sending it again would fail as a repeated
+// submission. The same policy may be shared by several clients.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of tries, including the first one.
+	MaxAttempts int
+
+	// Delay is the wait before the first retry.
+	Delay time.Duration
+
+	// Backoff multiplies the delay after every retry. Values below 1 keep
+	// the delay constant.
+	Backoff float64
+
+	// MaxDelay caps the delay between retries if it is not 0.
+	MaxDelay time.Duration
+
+	// Jitter randomizes every delay by up to this fraction of it, so that
+	// clients that failed together do not all retry together. 0.2 waits
+	// between 80% and 120% of the delay.
+	Jitter float64
+
+	// Retryable reports whether a call that failed with err should be
+	// tried again. If it is nil, errors matching ErrNetwork are retried,
+	// except ErrCertificatePin.
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy retries a failed call twice, waiting about one and then
+// two seconds. Calls are not retried until a policy is set, see
+// SetFactomdRetryPolicy and WithFactomdRetry.
+var DefaultRetryPolicy = &RetryPolicy{
+	MaxAttempts: 3,
+	Delay:       time.Second,
+	Backoff:     2,
+	MaxDelay:    10 * time.Second,
+	Jitter:      0.2,
+}
+
+// Do calls f until it succeeds, returns an error that is not retryable, or
+// the policy runs out of attempts. A nil policy calls f once.
+func (p *RetryPolicy) Do(f func() error) error {
+	return p.do(context.Background(), f)
+}
+
+// do is Do that stops waiting for the next attempt when ctx is done.
+func (p *RetryPolicy) do(ctx context.Context, f func() error) error {
+	if p == nil {
+		return f()
+	}
+
+	delay := p.Delay
+	for attempt := 1; ; attempt++ {
+		err := f()
+		if err == nil || ctx.Err() != nil || !p.retryable(err) || attempt >= p.MaxAttempts {
+			return err
+		}
+
+		t := time.NewTimer(p.jitter(delay))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+		if p.Backoff > 1 {
+			delay = time.Duration(float64(delay) * p.Backoff)
+		}
+		if p.MaxDelay > 0 && delay > p.MaxDelay {
+			delay = p.MaxDelay
+		}
+	}
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return errors.Is(err, ErrNetwork) && !errors.Is(err, ErrCertificatePin)
+}
+
+func (p *RetryPolicy) jitter(d time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * (1 + p.Jitter*(2*rand.Float64()-1)))
+}
+
+// unavailable reports whether the http status code means the server, or a
+// proxy in front of it, could not handle the request for now.
+func unavailable(code int) bool {
+	switch code {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// submitMethods are the factomd calls that change the state of the network,
+// which a RetryPolicy does not retry.
+var submitMethods = map[string]bool{
+	"factoid-submit":   true,
+	"commit-chain":     true,
+	"commit-entry":     true,
+	"reveal-chain":     true,
+	"reveal-entry":     true,
+	"send-raw-message": true,
+}
+
+// factomdRetry is the policy of the default Client.
+var factomdRetry *RetryPolicy
+
+// SetFactomdRetryPolicy sets the policy for the calls to factomd made by the
+// package level api functions, unless SetDefaultClient has been called. A nil
+// policy disables retries.
+func SetFactomdRetryPolicy(p *RetryPolicy) {
+	factomdRetry = p
+}
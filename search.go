@@ -0,0 +1,140 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+)
+
+// ExtIDQuery matches the Entries whose ExtID Index is Value, or starts with
+// Value if Prefix is set.
+type ExtIDQuery struct {
+	Index  int
+	Value  []byte
+	Prefix bool
+}
+
+// match reports whether the ExtIDs ids match the query.
+func (q ExtIDQuery) match(ids [][]byte) bool {
+	if q.Index < 0 || q.Index >= len(ids) {
+		return false
+	}
+	if q.Prefix {
+		return bytes.HasPrefix(ids[q.Index], q.Value)
+	}
+	return bytes.Equal(ids[q.Index], q.Value)
+}
+
+// matchAll reports whether the ExtIDs ids match every query.
+func matchAll(ids [][]byte, queries []ExtIDQuery) bool {
+	for _, q := range queries {
+		if !q.match(ids) {
+			return false
+		}
+	}
+	return true
+}
+
+// SearchChain walks the chain chainID from the First Entry and calls fn with
+// every Entry that matches all the queries, as soon as it is read. The search
+// stops at the first error returned by fn, which SearchChain returns.
+func SearchChain(chainID string, fn func(*Entry) error, queries ...ExtIDQuery) error {
+	return SearchChainWithContext(context.Background(), chainID, fn, queries...)
+}
+
+// SearchChainWithContext is like SearchChain but cancels its requests when
+// ctx is done.
+func SearchChainWithContext(ctx context.Context, chainID string, fn func(*Entry) error, queries ...ExtIDQuery) error {
+	it := NewChainIteratorWithContext(ctx, chainID)
+	it.Prefetch = 8
+	for it.Next() {
+		if e := it.Entry(); matchAll(e.ExtIDs, queries) {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+	}
+	return it.Err()
+}
+
+// ExtIDIndex keeps the ExtIDs of the Entries of a chain so that they can be
+// searched without reading the chain again. Update adds the Entries written
+// since the last update, and the index can be saved with Write and loaded
+// again with ReadExtIDIndex.
+type ExtIDIndex struct {
+	ChainID string `json:"chainid"`
+
+	// Cursor is the ChainIterator position of the first Entry that is not
+	// indexed yet.
+	Cursor string `json:"cursor"`
+
+	// Entries are the indexed Entries, oldest first.
+	Entries []*IndexedEntry `json:"entries"`
+}
+
+// IndexedEntry is the Entry Hash and ExtIDs of an Entry in an ExtIDIndex.
+type IndexedEntry struct {
+	EntryHash string   `json:"entryhash"`
+	ExtIDs    [][]byte `json:"extids"`
+}
+
+// NewExtIDIndex returns an empty index of the chain chainID.
+func NewExtIDIndex(chainID string) *ExtIDIndex {
+	return &ExtIDIndex{ChainID: chainID}
+}
+
+// ReadExtIDIndex reads an index written by ExtIDIndex.Write.
+func ReadExtIDIndex(r io.Reader) (*ExtIDIndex, error) {
+	x := new(ExtIDIndex)
+	if err := json.NewDecoder(r).Decode(x); err != nil {
+		return nil, validationErrorf("invalid extid index: %v", err)
+	}
+	return x, nil
+}
+
+// Write writes the index to w as json.
+func (x *ExtIDIndex) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(x)
+}
+
+// Update reads the Entries added to the chain since the last update and adds
+// them to the index. The Entries read before an error are kept.
+func (x *ExtIDIndex) Update() error {
+	return x.UpdateWithContext(context.Background())
+}
+
+// UpdateWithContext is like Update but cancels its requests when ctx is done.
+func (x *ExtIDIndex) UpdateWithContext(ctx context.Context) error {
+	it := NewChainIteratorWithContext(ctx, x.ChainID)
+	it.Prefetch = 8
+	if err := it.Seek(x.Cursor); err != nil {
+		return err
+	}
+	for it.Next() {
+		e := it.Entry()
+		x.Entries = append(x.Entries, &IndexedEntry{
+			EntryHash: hex.EncodeToString(e.Hash()),
+			ExtIDs:    e.ExtIDs,
+		})
+		x.Cursor = it.Cursor()
+	}
+	return it.Err()
+}
+
+// Search returns the Entry Hashes of the indexed Entries that match all the
+// queries, oldest first. The Entries can be fetched with GetEntry.
+func (x *ExtIDIndex) Search(queries ...ExtIDQuery) []string {
+	var hashes []string
+	for _, e := range x.Entries {
+		if matchAll(e.ExtIDs, queries) {
+			hashes = append(hashes, e.EntryHash)
+		}
+	}
+	return hashes
+}
@@ -0,0 +1,116 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestSearchChain(t *testing.T) {
+	chainid := "df3ade9eec4b08d5379cc64270c30ea7315d8a8a1a69efe2b98a60ecdd69e604"
+	eb1 := strings.Repeat("1", 64)
+	eb2 := strings.Repeat("2", 64)
+
+	// the entries e1 to e4 have the ExtIDs "type" and "invoice-<n>" or
+	// "receipt-<n>"
+	extids := map[string][]string{
+		"e1": {"invoice", "invoice-1"},
+		"e2": {"receipt", "receipt-1"},
+		"e3": {"invoice", "invoice-2"},
+		"e4": {"invoice", "invoice-3"},
+	}
+	head := eb1
+	eblocks := map[string]string{
+		eb1: fmt.Sprintf(`{"header": {"chainid": "%s", "prevkeymr": "%s"}, "entrylist": [{"entryhash": "e1"}, {"entryhash": "e2"}, {"entryhash": "e3"}]}`, chainid, ZeroHash),
+		eb2: fmt.Sprintf(`{"header": {"chainid": "%s", "prevkeymr": "%s"}, "entrylist": [{"entryhash": "e4"}]}`, chainid, eb1),
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(JSON2Request)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+		params := make(map[string]string)
+		json.Unmarshal(req.Params, &params)
+
+		var result string
+		switch req.Method {
+		case "chain-head":
+			result = fmt.Sprintf(`{"chainhead": "%s"}`, head)
+		case "entry-block":
+			result = eblocks[params["keymr"]]
+		case "entry":
+			e := &Entry{ChainID: chainid, ExtIDs: StringExtIDs(extids[params["hash"]]...), Content: []byte(params["hash"])}
+			p, _ := json.Marshal(e)
+			result = string(p)
+		}
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 0, "result": %s}`, result)
+	}))
+	defer ts.Close()
+	SetFactomdServer(ts.URL[7:])
+
+	var got []string
+	err := SearchChain(chainid, func(e *Entry) error {
+		got = append(got, string(e.Content))
+		return nil
+	}, ExtIDQuery{Index: 0, Value: []byte("invoice")}, ExtIDQuery{Index: 1, Value: []byte("invoice-"), Prefix: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != "e1,e3" {
+		t.Errorf("found %v", got)
+	}
+
+	// the error of the callback stops the search
+	stop := errors.New("stop")
+	calls := 0
+	err = SearchChain(chainid, func(e *Entry) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("got %v after %d calls", err, calls)
+	}
+
+	// the index is saved and updated with the new entries only
+	x := NewExtIDIndex(chainid)
+	if err := x.Update(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := x.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	x, err = ReadExtIDIndex(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head = eb2
+	if err := x.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if len(x.Entries) != 4 {
+		t.Errorf("indexed %d entries", len(x.Entries))
+	}
+	e4 := &Entry{ChainID: chainid, ExtIDs: StringExtIDs("invoice", "invoice-3"), Content: []byte("e4")}
+	hashes := x.Search(ExtIDQuery{Index: 1, Value: []byte("invoice-3")})
+	if len(hashes) != 1 || hashes[0] != hex.EncodeToString(e4.Hash()) {
+		t.Errorf("found %v", hashes)
+	}
+	if hashes := x.Search(ExtIDQuery{Index: 0, Value: []byte("invoice")}); len(hashes) != 3 {
+		t.Errorf("found %v", hashes)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+// SecureBytes holds secret key material, such as a seed or a private key.
+// Wipe overwrites it with zeros once it is no longer needed so that the
+// secret does not linger in memory until the garbage collector reuses it.
+// SecureBytes never prints its contents.
+//
+// Wiping is best effort and limited to byte slices and arrays: the keys
+// derived from mnemonics, decoded secret keys and the wallet encryption key.
+// Mnemonics are Go strings, which can not be overwritten, and the address
+// types keep their secret keys in arrays of their own that are wiped with
+// their Wipe methods, not with SecureBytes.
+type SecureBytes []byte
+
+// Wipe overwrites b with zeros.
+func (b SecureBytes) Wipe() {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// String hides the secret from fmt and loggers.
+func (b SecureBytes) String() string {
+	return "[secret]"
+}
+
+// GoString hides the secret from %#v.
+func (b SecureBytes) GoString() string {
+	return "[secret]"
+}
+
+// Wipe overwrites the secret key of the address with zeros. The address can
+// not sign afterwards, so it should only be called once the address is no
+// longer used.
+func (a *FactoidAddress) Wipe() {
+	SecureBytes(a.SecBytes()).Wipe()
+}
+
+// Wipe overwrites the secret key of the address with zeros. The address can
+// not sign afterwards, so it should only be called once the address is no
+// longer used.
+func (a *ECAddress) Wipe() {
+	SecureBytes(a.SecBytes()).Wipe()
+}
+
+// Wipe overwrites the secret key with zeros. The key can not sign afterwards,
+// so it should only be called once the key is no longer used.
+func (k *IdentityKey) Wipe() {
+	if k.Sec != nil {
+		SecureBytes(k.Sec[:]).Wipe()
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// factomdTLSConfig returns the TLS config of the connections to factomd. The
+// server certificate is checked against the CAs in FactomdTLSCertFile, or the
+// system CAs if it is empty, and against the pinned fingerprints if there are
+// any. With pins and no CA file, the pins alone authenticate the server, so
+// that a self signed factomd certificate can be trusted without copying it.
+func factomdTLSConfig(c *RPCConfig) (*tls.Config, error) {
+	conf := new(tls.Config)
+	if c.FactomdTLSCertFile != "" {
+		pem, err := ioutil.ReadFile(c.FactomdTLSCertFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", c.FactomdTLSCertFile)
+		}
+		conf.RootCAs = pool
+	}
+
+	if c.FactomdTLSClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.FactomdTLSClientCertFile, c.FactomdTLSClientKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(c.FactomdTLSPins) > 0 {
+		pins := make(map[string]bool)
+		for _, p := range c.FactomdTLSPins {
+			pins[strings.ToLower(strings.Replace(p, ":", "", -1))] = true
+		}
+		conf.InsecureSkipVerify = c.FactomdTLSCertFile == ""
+		conf.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 || !pins[CertificateFingerprint(cs.PeerCertificates[0])] {
+				return ErrCertificatePin
+			}
+			return nil
+		}
+	}
+	return conf, nil
+}
+
+// CertificateFingerprint returns the hex encoded SHA-256 hash of cert, the
+// form of the fingerprints given to WithFactomdPins. It is the fingerprint
+// printed by openssl x509 -noout -fingerprint -sha256, without the colons.
+func CertificateFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
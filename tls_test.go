@@ -0,0 +1,89 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestFactomdTLS(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the rate is the number of client certificates
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 0, "result": {"rate": %d}}`, len(r.TLS.PeerCertificates))
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	ts.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	ts.StartTLS()
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "factomd-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the test server's certificate is also used as the client certificate
+	cert := ts.Certificate()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	der, err := x509.MarshalPKCS8PrivateKey(ts.TLS.Certificates[0].PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fp := CertificateFingerprint(cert)
+	server := ts.URL[len("https://"):]
+
+	tests := map[string]struct {
+		opts  []Option
+		rate  uint64
+		match error
+	}{
+		"ca file":      {[]Option{WithFactomdTLS(certFile)}, 0, nil},
+		"client cert":  {[]Option{WithFactomdTLS(certFile), WithFactomdClientCert(certFile, keyFile)}, 1, nil},
+		"unknown ca":   {[]Option{WithFactomdTLS("")}, 0, ErrNetwork},
+		"pin":          {[]Option{WithFactomdPins(fp)}, 0, nil},
+		"pin and ca":   {[]Option{WithFactomdTLS(certFile), WithFactomdPins("00:" + fp[2:])}, 0, ErrCertificatePin},
+		"wrong pin":    {[]Option{WithFactomdPins(fp[2:] + "00")}, 0, ErrCertificatePin},
+		"missing cert": {[]Option{WithFactomdTLS(filepath.Join(dir, "none.pem"))}, 0, ErrNetwork},
+	}
+	for name, tt := range tests {
+		c := NewClient(append(tt.opts, WithFactomdServer(server))...)
+		resp, err := c.FactomdRequest(NewJSON2Request("entry-credit-rate", 0, nil))
+		if tt.match != nil {
+			if !errors.Is(err, tt.match) {
+				t.Errorf("%s: got %v, expecting %v", name, err, tt.match)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		r := new(struct{ Rate uint64 })
+		if err := json.Unmarshal(resp.JSONResult(), r); err != nil || r.Rate != tt.rate {
+			t.Errorf("%s: got %s", name, resp.JSONResult())
+		}
+	}
+}
@@ -5,10 +5,12 @@
 package factom
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 )
 
@@ -31,6 +33,20 @@ type Transaction struct {
 	Outputs        []*TransAddress `json:"outputs"`
 	ECOutputs      []*TransAddress `json:"ecoutputs"`
 	TxID           string          `json:"txid,omitempty"`
+
+	// PendingApproval is set on wallet transactions above the approval
+	// threshold of the wallet that must be approved before they are signed.
+	PendingApproval bool `json:"pendingapproval,omitempty"`
+
+	// LastModified is the time a wallet transaction was last changed.
+	// Unsigned transactions are deleted when they have not been changed for
+	// the TTL of the wallet.
+	LastModified time.Time `json:"lastmodified,omitempty"`
+
+	// Memo and Tags are the note the wallet keeps for the transaction, see
+	// SetTransactionNote.
+	Memo string            `json:"memo,omitempty"`
+	Tags map[string]string `json:"tags,omitempty"`
 }
 
 // String prints the formatted data of a transaction.
@@ -74,6 +90,23 @@ func (tx *Transaction) String() (s string) {
 		s += fmt.Sprintln("FeesRequired:", FactoshiToFactoid(tx.FeesRequired))
 	}
 	s += fmt.Sprintln("Signed:", tx.IsSigned)
+	if tx.PendingApproval {
+		s += fmt.Sprintln("PendingApproval:", tx.PendingApproval)
+	}
+	if !tx.LastModified.IsZero() {
+		s += fmt.Sprintln("LastModified:", tx.LastModified)
+	}
+	if tx.Memo != "" {
+		s += fmt.Sprintln("Memo:", tx.Memo)
+	}
+	tags := make([]string, 0, len(tx.Tags))
+	for k := range tx.Tags {
+		tags = append(tags, k)
+	}
+	sort.Strings(tags)
+	for _, k := range tags {
+		s += fmt.Sprintln("Tag:", k, tx.Tags[k])
+	}
 
 	return s
 }
@@ -94,6 +127,11 @@ func (tx *Transaction) MarshalJSON() ([]byte, error) {
 		Outputs        []*TransAddress `json:"outputs"`
 		ECOutputs      []*TransAddress `json:"ecoutputs"`
 		TxID           string          `json:"txid,omitempty"`
+
+		PendingApproval bool              `json:"pendingapproval,omitempty"`
+		LastModified    int64             `json:"lastmodified,omitempty"`
+		Memo            string            `json:"memo,omitempty"`
+		Tags            map[string]string `json:"tags,omitempty"`
 	}{
 		BlockHeight:    tx.BlockHeight,
 		FeesPaid:       tx.FeesPaid,
@@ -108,6 +146,13 @@ func (tx *Transaction) MarshalJSON() ([]byte, error) {
 		Outputs:        tx.Outputs,
 		ECOutputs:      tx.ECOutputs,
 		TxID:           tx.TxID,
+
+		PendingApproval: tx.PendingApproval,
+		Memo:            tx.Memo,
+		Tags:            tx.Tags,
+	}
+	if !tx.LastModified.IsZero() {
+		tmp.LastModified = tx.LastModified.Unix()
 	}
 
 	return json.Marshal(tmp)
@@ -129,6 +174,11 @@ func (tx *Transaction) UnmarshalJSON(data []byte) error {
 		Outputs        []*TransAddress `json:"outputs"`
 		ECOutputs      []*TransAddress `json:"ecoutputs"`
 		TxID           string          `json:"txid,omitempty"`
+
+		PendingApproval bool              `json:"pendingapproval,omitempty"`
+		LastModified    int64             `json:"lastmodified,omitempty"`
+		Memo            string            `json:"memo,omitempty"`
+		Tags            map[string]string `json:"tags,omitempty"`
 	}
 	tmp := new(jsontx)
 
@@ -149,16 +199,28 @@ func (tx *Transaction) UnmarshalJSON(data []byte) error {
 	tx.Outputs = tmp.Outputs
 	tx.ECOutputs = tmp.ECOutputs
 	tx.TxID = tmp.TxID
+	tx.PendingApproval = tmp.PendingApproval
+	if tmp.LastModified != 0 {
+		tx.LastModified = time.Unix(tmp.LastModified, 0)
+	}
+	tx.Memo = tmp.Memo
+	tx.Tags = tmp.Tags
 
 	return nil
 }
 
 // NewTransaction creates a new temporary Transaction in the wallet
 func NewTransaction(name string) (*Transaction, error) {
+	return NewTransactionWithContext(context.Background(), name)
+}
+
+// NewTransactionWithContext is like NewTransaction but cancels its requests
+// when ctx is done.
+func NewTransactionWithContext(ctx context.Context, name string) (*Transaction, error) {
 	params := transactionRequest{Name: name}
 	req := NewJSON2Request("new-transaction", APICounter(), params)
 
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -174,10 +236,16 @@ func NewTransaction(name string) (*Transaction, error) {
 }
 
 func DeleteTransaction(name string) error {
+	return DeleteTransactionWithContext(context.Background(), name)
+}
+
+// DeleteTransactionWithContext is like DeleteTransaction but cancels its
+// requests when ctx is done.
+func DeleteTransactionWithContext(ctx context.Context, name string) error {
 	params := transactionRequest{Name: name}
 	req := NewJSON2Request("delete-transaction", APICounter(), params)
 
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -189,12 +257,18 @@ func DeleteTransaction(name string) error {
 }
 
 func ListTransactionsAll() ([]*Transaction, error) {
+	return ListTransactionsAllWithContext(context.Background())
+}
+
+// ListTransactionsAllWithContext is like ListTransactionsAll but cancels its
+// requests when ctx is done.
+func ListTransactionsAllWithContext(ctx context.Context) ([]*Transaction, error) {
 	type multiTransactionResponse struct {
 		Transactions []*Transaction `json:"transactions"`
 	}
 
 	req := NewJSON2Request("transactions", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -211,6 +285,12 @@ func ListTransactionsAll() ([]*Transaction, error) {
 }
 
 func ListTransactionsAddress(addr string) ([]*Transaction, error) {
+	return ListTransactionsAddressWithContext(context.Background(), addr)
+}
+
+// ListTransactionsAddressWithContext is like ListTransactionsAddress but
+// cancels its requests when ctx is done.
+func ListTransactionsAddressWithContext(ctx context.Context, addr string) ([]*Transaction, error) {
 	type multiTransactionResponse struct {
 		Transactions []*Transaction `json:"transactions"`
 	}
@@ -222,7 +302,7 @@ func ListTransactionsAddress(addr string) ([]*Transaction, error) {
 	params := txReq{Address: addr}
 
 	req := NewJSON2Request("transactions", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -239,6 +319,12 @@ func ListTransactionsAddress(addr string) ([]*Transaction, error) {
 }
 
 func ListTransactionsID(id string) ([]*Transaction, error) {
+	return ListTransactionsIDWithContext(context.Background(), id)
+}
+
+// ListTransactionsIDWithContext is like ListTransactionsID but cancels its
+// requests when ctx is done.
+func ListTransactionsIDWithContext(ctx context.Context, id string) ([]*Transaction, error) {
 	type multiTransactionResponse struct {
 		Transactions []*Transaction `json:"transactions"`
 	}
@@ -250,7 +336,7 @@ func ListTransactionsID(id string) ([]*Transaction, error) {
 	params := txReq{TxID: id}
 
 	req := NewJSON2Request("transactions", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -267,6 +353,12 @@ func ListTransactionsID(id string) ([]*Transaction, error) {
 }
 
 func ListTransactionsRange(start, end int) ([]*Transaction, error) {
+	return ListTransactionsRangeWithContext(context.Background(), start, end)
+}
+
+// ListTransactionsRangeWithContext is like ListTransactionsRange but cancels
+// its requests when ctx is done.
+func ListTransactionsRangeWithContext(ctx context.Context, start, end int) ([]*Transaction, error) {
 	type multiTransactionResponse struct {
 		Transactions []*Transaction `json:"transactions"`
 	}
@@ -283,7 +375,7 @@ func ListTransactionsRange(start, end int) ([]*Transaction, error) {
 	params.Range.End = end
 
 	req := NewJSON2Request("transactions", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -299,13 +391,70 @@ func ListTransactionsRange(start, end int) ([]*Transaction, error) {
 	return list.Transactions, nil
 }
 
+// ListTransactionHistory returns a page of the confirmed transactions of the
+// wallet addresses, newest first, and the number of transactions that match
+// in total. An empty address selects every wallet address; zero times leave
+// the time range open. The wallet indexes new factoid blocks first.
+func ListTransactionHistory(address string, since, until time.Time, offset, limit int) ([]*Transaction, int, error) {
+	return ListTransactionHistoryWithContext(context.Background(), address, since, until, offset, limit)
+}
+
+// ListTransactionHistoryWithContext is like ListTransactionHistory but cancels
+// its requests when ctx is done.
+func ListTransactionHistoryWithContext(ctx context.Context, address string, since, until time.Time, offset, limit int) ([]*Transaction, int, error) {
+	type historyResponse struct {
+		Transactions []*Transaction `json:"transactions"`
+		Total        int            `json:"total"`
+	}
+
+	type txReq struct {
+		Address string `json:"address,omitempty"`
+		Since   int64  `json:"since,omitempty"`
+		Until   int64  `json:"until,omitempty"`
+		Offset  int    `json:"offset"`
+		Limit   int    `json:"limit"`
+	}
+
+	params := txReq{Address: address, Offset: offset, Limit: limit}
+	if !since.IsZero() {
+		params.Since = since.Unix()
+	}
+	if !until.IsZero() {
+		params.Until = until.Unix()
+	}
+
+	req := NewJSON2Request("transaction-history", APICounter(), params)
+	resp, err := walletRequest(ctx, req)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.Error != nil {
+		return nil, 0, resp.Error
+	}
+
+	list := new(historyResponse)
+	if err := json.Unmarshal(resp.JSONResult(), list); err != nil {
+		return nil, 0, err
+	}
+
+	return list.Transactions, list.Total, nil
+}
+
+// ListTransactionsTmp returns the tmp transactions of the wallet, sorted by
+// name, with their inputs, outputs, totals, fees and whether they are signed.
 func ListTransactionsTmp() ([]*Transaction, error) {
+	return ListTransactionsTmpWithContext(context.Background())
+}
+
+// ListTransactionsTmpWithContext is like ListTransactionsTmp but cancels its
+// requests when ctx is done.
+func ListTransactionsTmpWithContext(ctx context.Context) ([]*Transaction, error) {
 	type multiTransactionResponse struct {
 		Transactions []*Transaction `json:"transactions"`
 	}
 
 	req := NewJSON2Request("tmp-transactions", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -325,8 +474,14 @@ func AddTransactionInput(
 	address string,
 	amount uint64,
 ) (*Transaction, error) {
+	return AddTransactionInputWithContext(context.Background(), name, address, amount)
+}
+
+// AddTransactionInputWithContext is like AddTransactionInput but cancels its
+// requests when ctx is done.
+func AddTransactionInputWithContext(ctx context.Context, name, address string, amount uint64) (*Transaction, error) {
 	if AddressStringType(address) != FactoidPub {
-		return nil, fmt.Errorf("%s is not a Factoid address", address)
+		return nil, validationErrorf("%s is not a Factoid address", address)
 	}
 
 	params := transactionValueRequest{
@@ -335,7 +490,7 @@ func AddTransactionInput(
 		Amount:  amount}
 	req := NewJSON2Request("add-input", APICounter(), params)
 
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -350,13 +505,207 @@ func AddTransactionInput(
 	return tx, nil
 }
 
+// FundTransaction makes the wallet add inputs from its Factoid addresses to
+// the temporary Transaction that cover its outputs and the fee. strategy is
+// "largest-first", "minimize-inputs" or "privacy".
+func FundTransaction(name, strategy string) (*Transaction, error) {
+	return FundTransactionWithContext(context.Background(), name, strategy)
+}
+
+// FundTransactionWithContext is like FundTransaction but cancels its requests
+// when ctx is done.
+func FundTransactionWithContext(ctx context.Context, name, strategy string) (*Transaction, error) {
+	params := transactionValueRequest{Name: name, AutoFund: strategy}
+	req := NewJSON2Request("add-input", APICounter(), params)
+
+	resp, err := walletRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	tx := new(Transaction)
+	if err := json.Unmarshal(resp.JSONResult(), tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// TransactionPreview is the breakdown of a temporary Transaction returned by
+// PreviewTransaction.
+type TransactionPreview struct {
+	Name           string          `json:"tx-name"`
+	Inputs         []*TransAddress `json:"inputs"`
+	Outputs        []*TransAddress `json:"outputs"`
+	ECOutputs      []*TransAddress `json:"ecoutputs"`
+	TotalInputs    uint64          `json:"totalinputs"`
+	TotalOutputs   uint64          `json:"totaloutputs"`
+	TotalECOutputs uint64          `json:"totalecoutputs"`
+	Rate           uint64          `json:"rate"`
+	Fee            uint64          `json:"fee"`
+
+	// Change is what the inputs leave after the outputs and the fee; it is
+	// paid as fee too. It is negative when the inputs fall short.
+	Change int64 `json:"change"`
+	Signed bool  `json:"signed"`
+
+	// Errors are the reasons the wallet would not sign the transaction as
+	// it is.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// PreviewTransaction returns the breakdown of the temporary Transaction with
+// the fee at the current rate and any problems that keep the wallet from
+// signing it. The transaction is not changed.
+func PreviewTransaction(name string) (*TransactionPreview, error) {
+	return PreviewTransactionWithContext(context.Background(), name)
+}
+
+// PreviewTransactionWithContext is like PreviewTransaction but cancels its
+// requests when ctx is done.
+func PreviewTransactionWithContext(ctx context.Context, name string) (*TransactionPreview, error) {
+	params := transactionRequest{Name: name}
+	req := NewJSON2Request("preview-transaction", APICounter(), params)
+
+	resp, err := walletRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	p := new(TransactionPreview)
+	if err := json.Unmarshal(resp.JSONResult(), p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ExportTransaction returns the temporary Transaction with the signatures it
+// has so far as a portable blob that another wallet can import with
+// ImportTransaction, add its own inputs or signatures to and pass on.
+func ExportTransaction(name string) (string, error) {
+	return ExportTransactionWithContext(context.Background(), name)
+}
+
+// ExportTransactionWithContext is like ExportTransaction but cancels its
+// requests when ctx is done.
+func ExportTransactionWithContext(ctx context.Context, name string) (string, error) {
+	params := transactionRequest{Name: name}
+	req := NewJSON2Request("export-transaction", APICounter(), params)
+
+	resp, err := walletRequest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if resp.Error != nil {
+		return "", resp.Error
+	}
+
+	r := new(exportedTransaction)
+	if err := json.Unmarshal(resp.JSONResult(), r); err != nil {
+		return "", err
+	}
+	return r.Transaction, nil
+}
+
+// ImportTransaction stores a transaction exported by ExportTransaction in the
+// wallet as the temporary Transaction name. Signatures the wallet already has
+// for the same transaction are kept.
+func ImportTransaction(name, blob string) (*Transaction, error) {
+	return ImportTransactionWithContext(context.Background(), name, blob)
+}
+
+// ImportTransactionWithContext is like ImportTransaction but cancels its
+// requests when ctx is done.
+func ImportTransactionWithContext(ctx context.Context, name, blob string) (*Transaction, error) {
+	params := exportedTransaction{Name: name, Transaction: blob}
+	req := NewJSON2Request("import-transaction", APICounter(), params)
+
+	resp, err := walletRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	tx := new(Transaction)
+	if err := json.Unmarshal(resp.JSONResult(), tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// SetTransactionNote attaches a memo and tags to a Transaction in the wallet,
+// a temporary Transaction by name or a sent one by TxID, so that it can be
+// told apart later in the transaction listings. An empty memo without tags
+// removes the note.
+func SetTransactionNote(ref, memo string, tags map[string]string) error {
+	return SetTransactionNoteWithContext(context.Background(), ref, memo, tags)
+}
+
+// SetTransactionNoteWithContext is like SetTransactionNote but cancels its
+// requests when ctx is done.
+func SetTransactionNoteWithContext(ctx context.Context, ref, memo string, tags map[string]string) error {
+	params := transactionNoteRequest{Name: ref, Memo: memo, Tags: tags}
+	req := NewJSON2Request("set-transaction-note", APICounter(), params)
+
+	resp, err := walletRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	return nil
+}
+
+// EstimateFee returns the fee in factoshis that the temporary Transaction
+// needs at the current entry credit rate. The transaction is not changed.
+func EstimateFee(name string) (uint64, error) {
+	return EstimateFeeWithContext(context.Background(), name)
+}
+
+// EstimateFeeWithContext is like EstimateFee but cancels its requests when ctx
+// is done.
+func EstimateFeeWithContext(ctx context.Context, name string) (uint64, error) {
+	params := transactionRequest{Name: name}
+	req := NewJSON2Request("estimate-fee", APICounter(), params)
+
+	resp, err := walletRequest(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	if resp.Error != nil {
+		return 0, resp.Error
+	}
+
+	fee := new(struct {
+		Fee uint64 `json:"fee"`
+	})
+	if err := json.Unmarshal(resp.JSONResult(), fee); err != nil {
+		return 0, err
+	}
+	return fee.Fee, nil
+}
+
 func AddTransactionOutput(
 	name,
 	address string,
 	amount uint64,
 ) (*Transaction, error) {
+	return AddTransactionOutputWithContext(context.Background(), name, address, amount)
+}
+
+// AddTransactionOutputWithContext is like AddTransactionOutput but cancels its
+// requests when ctx is done.
+func AddTransactionOutputWithContext(ctx context.Context, name, address string, amount uint64) (*Transaction, error) {
 	if AddressStringType(address) != FactoidPub {
-		return nil, fmt.Errorf("%s is not a Factoid address", address)
+		return nil, validationErrorf("%s is not a Factoid address", address)
 	}
 
 	params := transactionValueRequest{
@@ -365,7 +714,95 @@ func AddTransactionOutput(
 		Amount:  amount}
 	req := NewJSON2Request("add-output", APICounter(), params)
 
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	tx := new(Transaction)
+	if err := json.Unmarshal(resp.JSONResult(), tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// AddTransactionOutputs adds all of outputs to the temporary Transaction in
+// one call. If one of them is invalid none are added.
+func AddTransactionOutputs(name string, outputs []*TransAddress) (*Transaction, error) {
+	return AddTransactionOutputsWithContext(context.Background(), name, outputs)
+}
+
+// AddTransactionOutputsWithContext is like AddTransactionOutputs but cancels
+// its requests when ctx is done.
+func AddTransactionOutputsWithContext(ctx context.Context, name string, outputs []*TransAddress) (*Transaction, error) {
+	for _, out := range outputs {
+		if AddressStringType(out.Address) != FactoidPub {
+			return nil, validationErrorf("%s is not a Factoid address", out.Address)
+		}
+	}
+
+	params := transactionOutputsRequest{Name: name, Outputs: outputs}
+	req := NewJSON2Request("add-outputs", APICounter(), params)
+
+	resp, err := walletRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	tx := new(Transaction)
+	if err := json.Unmarshal(resp.JSONResult(), tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// RemoveTransactionInput removes the input from the Factoid address from the
+// temporary Transaction.
+func RemoveTransactionInput(name, address string) (*Transaction, error) {
+	return RemoveTransactionInputWithContext(context.Background(), name, address)
+}
+
+// RemoveTransactionInputWithContext is like RemoveTransactionInput but cancels
+// its requests when ctx is done.
+func RemoveTransactionInputWithContext(ctx context.Context, name, address string) (*Transaction, error) {
+	return removeTransactionAddress(ctx, "remove-input", name, address)
+}
+
+// RemoveTransactionOutput removes the output to the Factoid address from the
+// temporary Transaction.
+func RemoveTransactionOutput(name, address string) (*Transaction, error) {
+	return RemoveTransactionOutputWithContext(context.Background(), name, address)
+}
+
+// RemoveTransactionOutputWithContext is like RemoveTransactionOutput but
+// cancels its requests when ctx is done.
+func RemoveTransactionOutputWithContext(ctx context.Context, name, address string) (*Transaction, error) {
+	return removeTransactionAddress(ctx, "remove-output", name, address)
+}
+
+// RemoveTransactionECOutput removes the output to the Entry Credit address
+// from the temporary Transaction.
+func RemoveTransactionECOutput(name, address string) (*Transaction, error) {
+	return RemoveTransactionECOutputWithContext(context.Background(), name, address)
+}
+
+// RemoveTransactionECOutputWithContext is like RemoveTransactionECOutput but
+// cancels its requests when ctx is done.
+func RemoveTransactionECOutputWithContext(ctx context.Context, name, address string) (*Transaction, error) {
+	return removeTransactionAddress(ctx, "remove-ec-output", name, address)
+}
+
+func removeTransactionAddress(ctx context.Context, method, name, address string) (*Transaction, error) {
+	params := transactionAddressRequest{Name: name, Address: address}
+	req := NewJSON2Request(method, APICounter(), params)
+
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -385,8 +822,14 @@ func AddTransactionECOutput(
 	address string,
 	amount uint64,
 ) (*Transaction, error) {
+	return AddTransactionECOutputWithContext(context.Background(), name, address, amount)
+}
+
+// AddTransactionECOutputWithContext is like AddTransactionECOutput but cancels
+// its requests when ctx is done.
+func AddTransactionECOutputWithContext(ctx context.Context, name, address string, amount uint64) (*Transaction, error) {
 	if AddressStringType(address) != ECPub {
-		return nil, fmt.Errorf("%s is not an Entry Credit address", address)
+		return nil, validationErrorf("%s is not an Entry Credit address", address)
 	}
 
 	params := transactionValueRequest{
@@ -395,7 +838,7 @@ func AddTransactionECOutput(
 		Amount:  amount}
 	req := NewJSON2Request("add-ec-output", APICounter(), params)
 
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -411,8 +854,14 @@ func AddTransactionECOutput(
 }
 
 func AddTransactionFee(name, address string) (*Transaction, error) {
+	return AddTransactionFeeWithContext(context.Background(), name, address)
+}
+
+// AddTransactionFeeWithContext is like AddTransactionFee but cancels its
+// requests when ctx is done.
+func AddTransactionFeeWithContext(ctx context.Context, name, address string) (*Transaction, error) {
 	if AddressStringType(address) != FactoidPub {
-		return nil, fmt.Errorf("%s is not a Factoid address", address)
+		return nil, validationErrorf("%s is not a Factoid address", address)
 	}
 
 	params := transactionValueRequest{
@@ -420,7 +869,7 @@ func AddTransactionFee(name, address string) (*Transaction, error) {
 		Address: address}
 	req := NewJSON2Request("add-fee", APICounter(), params)
 
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -436,12 +885,46 @@ func AddTransactionFee(name, address string) (*Transaction, error) {
 }
 
 func SubTransactionFee(name, address string) (*Transaction, error) {
+	return SubTransactionFeeWithContext(context.Background(), name, address)
+}
+
+// SubTransactionFeeWithContext is like SubTransactionFee but cancels its
+// requests when ctx is done.
+func SubTransactionFeeWithContext(ctx context.Context, name, address string) (*Transaction, error) {
 	params := transactionValueRequest{
 		Name:    name,
 		Address: address}
 	req := NewJSON2Request("sub-fee", APICounter(), params)
 
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	tx := new(Transaction)
+	if err := json.Unmarshal(resp.JSONResult(), tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// SubTransactionFeeAuto is SubTransactionFee with the output chosen by the
+// wallet: the change back to one of its own addresses if there is any,
+// otherwise the largest output.
+func SubTransactionFeeAuto(name string) (*Transaction, error) {
+	return SubTransactionFeeAutoWithContext(context.Background(), name)
+}
+
+// SubTransactionFeeAutoWithContext is like SubTransactionFeeAuto but cancels
+// its requests when ctx is done.
+func SubTransactionFeeAutoWithContext(ctx context.Context, name string) (*Transaction, error) {
+	params := transactionAddressRequest{Name: name, Auto: true}
+	req := NewJSON2Request("sub-fee", APICounter(), params)
+
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -457,11 +940,75 @@ func SubTransactionFee(name, address string) (*Transaction, error) {
 }
 
 func SignTransaction(name string, force bool) (*Transaction, error) {
+	return SignTransactionWithContext(context.Background(), name, force)
+}
+
+// SignTransactionWithContext is like SignTransaction but cancels its requests
+// when ctx is done.
+func SignTransactionWithContext(ctx context.Context, name string, force bool) (*Transaction, error) {
 	params := transactionRequest{Name: name}
 	params.Force = force
 	req := NewJSON2Request("sign-transaction", APICounter(), params)
 
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	tx := new(Transaction)
+	if err := json.Unmarshal(resp.JSONResult(), tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// SignExportedTransaction has the wallet sign the inputs of a transaction
+// exported by ExportTransaction that it holds keys for, without storing the
+// transaction, and returns it with the signatures added. This lets a wallet
+// kept offline sign for an online wallet, which imports the result with
+// ImportTransaction and sends it.
+func SignExportedTransaction(blob string, force bool) (string, error) {
+	return SignExportedTransactionWithContext(context.Background(), blob, force)
+}
+
+// SignExportedTransactionWithContext is like SignExportedTransaction but
+// cancels its requests when ctx is done.
+func SignExportedTransactionWithContext(ctx context.Context, blob string, force bool) (string, error) {
+	params := transactionRequest{Force: force, Transaction: blob}
+	req := NewJSON2Request("sign-transaction", APICounter(), params)
+
+	resp, err := walletRequest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if resp.Error != nil {
+		return "", resp.Error
+	}
+
+	r := new(exportedTransaction)
+	if err := json.Unmarshal(resp.JSONResult(), r); err != nil {
+		return "", err
+	}
+	return r.Transaction, nil
+}
+
+// ApproveTransaction approves a temporary Transaction in the wallet that is
+// above the approval threshold of the wallet so that it can be signed. The
+// wallet only accepts approvals made with an api token that may approve.
+func ApproveTransaction(name string) (*Transaction, error) {
+	return ApproveTransactionWithContext(context.Background(), name)
+}
+
+// ApproveTransactionWithContext is like ApproveTransaction but cancels its
+// requests when ctx is done.
+func ApproveTransactionWithContext(ctx context.Context, name string) (*Transaction, error) {
+	params := transactionRequest{Name: name}
+	req := NewJSON2Request("approve-transaction", APICounter(), params)
+
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -477,10 +1024,16 @@ func SignTransaction(name string, force bool) (*Transaction, error) {
 }
 
 func ComposeTransaction(name string) ([]byte, error) {
+	return ComposeTransactionWithContext(context.Background(), name)
+}
+
+// ComposeTransactionWithContext is like ComposeTransaction but cancels its
+// requests when ctx is done.
+func ComposeTransactionWithContext(ctx context.Context, name string) ([]byte, error) {
 	params := transactionRequest{Name: name}
 	req := NewJSON2Request("compose-transaction", APICounter(), params)
 
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -492,18 +1045,24 @@ func ComposeTransaction(name string) ([]byte, error) {
 }
 
 func SendTransaction(name string) (*Transaction, error) {
+	return SendTransactionWithContext(context.Background(), name)
+}
+
+// SendTransactionWithContext is like SendTransaction but cancels its requests
+// when ctx is done.
+func SendTransactionWithContext(ctx context.Context, name string) (*Transaction, error) {
 	params := transactionRequest{Name: name}
 
-	tx, err := GetTmpTransaction(name)
+	tx, err := GetTmpTransactionWithContext(ctx, name)
 	if err != nil {
 		return nil, err
 	}
 	if !tx.IsSigned {
-		return nil, fmt.Errorf("Cannot send unsigned transaction")
+		return nil, validationErrorf("Cannot send unsigned transaction")
 	}
 
 	wreq := NewJSON2Request("compose-transaction", APICounter(), params)
-	wresp, err := walletRequest(wreq)
+	wresp, err := walletRequest(ctx, wreq)
 	if err != nil {
 		return nil, err
 	}
@@ -513,14 +1072,14 @@ func SendTransaction(name string) (*Transaction, error) {
 
 	freq := new(JSON2Request)
 	json.Unmarshal(wresp.JSONResult(), freq)
-	fresp, err := factomdRequest(freq)
+	fresp, err := factomdRequest(ctx, freq)
 	if err != nil {
 		return nil, err
 	}
 	if fresp.Error != nil {
 		return nil, fresp.Error
 	}
-	if err := DeleteTransaction(name); err != nil {
+	if err := DeleteTransactionWithContext(ctx, name); err != nil {
 		return nil, err
 	}
 
@@ -528,37 +1087,43 @@ func SendTransaction(name string) (*Transaction, error) {
 }
 
 func SendFactoid(from, to string, amount uint64, force bool) (*Transaction, error) {
+	return SendFactoidWithContext(context.Background(), from, to, amount, force)
+}
+
+// SendFactoidWithContext is like SendFactoid but cancels its requests when ctx
+// is done.
+func SendFactoidWithContext(ctx context.Context, from, to string, amount uint64, force bool) (*Transaction, error) {
 	n := make([]byte, 16)
 	if _, err := rand.Read(n); err != nil {
 		return nil, err
 	}
 	name := hex.EncodeToString(n)
-	if _, err := NewTransaction(name); err != nil {
+	if _, err := NewTransactionWithContext(ctx, name); err != nil {
 		return nil, err
 	}
-	if _, err := AddTransactionInput(name, from, amount); err != nil {
+	if _, err := AddTransactionInputWithContext(ctx, name, from, amount); err != nil {
 		return nil, err
 	}
-	if _, err := AddTransactionOutput(name, to, amount); err != nil {
+	if _, err := AddTransactionOutputWithContext(ctx, name, to, amount); err != nil {
 		return nil, err
 	}
-	balance, err := GetFactoidBalance(from)
+	balance, err := GetFactoidBalanceWithContext(ctx, from)
 	if err != nil {
 		return nil, err
 	}
 	if balance > int64(amount) {
-		if _, err := AddTransactionFee(name, from); err != nil {
+		if _, err := AddTransactionFeeWithContext(ctx, name, from); err != nil {
 			return nil, err
 		}
 	} else {
-		if _, err := SubTransactionFee(name, to); err != nil {
+		if _, err := SubTransactionFeeWithContext(ctx, name, to); err != nil {
 			return nil, err
 		}
 	}
-	if _, err := SignTransaction(name, force); err != nil {
+	if _, err := SignTransactionWithContext(ctx, name, force); err != nil {
 		return nil, err
 	}
-	r, err := SendTransaction(name)
+	r, err := SendTransactionWithContext(ctx, name)
 	if err != nil {
 		return nil, err
 	}
@@ -567,27 +1132,32 @@ func SendFactoid(from, to string, amount uint64, force bool) (*Transaction, erro
 }
 
 func BuyEC(from, to string, amount uint64, force bool) (*Transaction, error) {
+	return BuyECWithContext(context.Background(), from, to, amount, force)
+}
+
+// BuyECWithContext is like BuyEC but cancels its requests when ctx is done.
+func BuyECWithContext(ctx context.Context, from, to string, amount uint64, force bool) (*Transaction, error) {
 	n := make([]byte, 16)
 	if _, err := rand.Read(n); err != nil {
 		return nil, err
 	}
 	name := hex.EncodeToString(n)
-	if _, err := NewTransaction(name); err != nil {
+	if _, err := NewTransactionWithContext(ctx, name); err != nil {
 		return nil, err
 	}
-	if _, err := AddTransactionInput(name, from, amount); err != nil {
+	if _, err := AddTransactionInputWithContext(ctx, name, from, amount); err != nil {
 		return nil, err
 	}
-	if _, err := AddTransactionECOutput(name, to, amount); err != nil {
+	if _, err := AddTransactionECOutputWithContext(ctx, name, to, amount); err != nil {
 		return nil, err
 	}
-	if _, err := AddTransactionFee(name, from); err != nil {
+	if _, err := AddTransactionFeeWithContext(ctx, name, from); err != nil {
 		return nil, err
 	}
-	if _, err := SignTransaction(name, force); err != nil {
+	if _, err := SignTransactionWithContext(ctx, name, force); err != nil {
 		return nil, err
 	}
-	r, err := SendTransaction(name)
+	r, err := SendTransactionWithContext(ctx, name)
 	if err != nil {
 		return nil, err
 	}
@@ -597,7 +1167,13 @@ func BuyEC(from, to string, amount uint64, force bool) (*Transaction, error) {
 
 //Purchases the exact amount of ECs
 func BuyExactEC(from, to string, amount uint64, force bool) (*Transaction, error) {
-	rate, err := GetRate()
+	return BuyExactECWithContext(context.Background(), from, to, amount, force)
+}
+
+// BuyExactECWithContext is like BuyExactEC but cancels its requests when ctx is
+// done.
+func BuyExactECWithContext(ctx context.Context, from, to string, amount uint64, force bool) (*Transaction, error) {
+	rate, err := GetRateWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -608,22 +1184,22 @@ func BuyExactEC(from, to string, amount uint64, force bool) (*Transaction, error
 	}
 	name := hex.EncodeToString(n)
 
-	if _, err := NewTransaction(name); err != nil {
+	if _, err := NewTransactionWithContext(ctx, name); err != nil {
 		return nil, err
 	}
-	if _, err := AddTransactionInput(name, from, amount*rate); err != nil {
+	if _, err := AddTransactionInputWithContext(ctx, name, from, amount*rate); err != nil {
 		return nil, err
 	}
-	if _, err := AddTransactionECOutput(name, to, amount*rate); err != nil {
+	if _, err := AddTransactionECOutputWithContext(ctx, name, to, amount*rate); err != nil {
 		return nil, err
 	}
-	if _, err := AddTransactionFee(name, from); err != nil {
+	if _, err := AddTransactionFeeWithContext(ctx, name, from); err != nil {
 		return nil, err
 	}
-	if _, err := SignTransaction(name, force); err != nil {
+	if _, err := SignTransactionWithContext(ctx, name, force); err != nil {
 		return nil, err
 	}
-	r, err := SendTransaction(name)
+	r, err := SendTransactionWithContext(ctx, name)
 	if err != nil {
 		return nil, err
 	}
@@ -645,9 +1221,15 @@ type TransactionResponse struct {
 }
 
 func GetTransaction(txID string) (*TransactionResponse, error) {
+	return GetTransactionWithContext(context.Background(), txID)
+}
+
+// GetTransactionWithContext is like GetTransaction but cancels its requests
+// when ctx is done.
+func GetTransactionWithContext(ctx context.Context, txID string) (*TransactionResponse, error) {
 	params := hashRequest{Hash: txID}
 	req := NewJSON2Request("transaction", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -665,7 +1247,13 @@ func GetTransaction(txID string) (*TransactionResponse, error) {
 
 // GetTmpTransaction gets a temporary transaction from the wallet
 func GetTmpTransaction(name string) (*Transaction, error) {
-	txs, err := ListTransactionsTmp()
+	return GetTmpTransactionWithContext(context.Background(), name)
+}
+
+// GetTmpTransactionWithContext is like GetTmpTransaction but cancels its
+// requests when ctx is done.
+func GetTmpTransactionWithContext(ctx context.Context, name string) (*Transaction, error) {
+	txs, err := ListTransactionsTmpWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -23,9 +23,17 @@ const (
 )
 
 var (
-	RpcConfig = &RPCConfig{}
+	// RpcConfig holds the settings of the package level api functions. The
+	// factomd server, credentials and TLS are set with SetFactomdServer,
+	// SetFactomdRpcConfig and SetFactomdEncryption.
+	RpcConfig = &RPCConfig{
+		FactomdServer: "localhost:8088",
+		WalletServer:  "localhost:8089",
+	}
 )
 
+// EntryCost returns the number of Entry Credits needed to commit the Entry.
+// See Entry.ECCost, which also checks the chainid.
 func EntryCost(e *Entry) (int8, error) {
 	p, err := e.MarshalBinary()
 	if err != nil {
@@ -33,10 +41,10 @@ func EntryCost(e *Entry) (int8, error) {
 	}
 
 	// caulculate the length exluding the header size 35 for Milestone 1
-	l := len(p) - 35
+	l := len(p) - entryHeaderSize
 
-	if l > 10240 {
-		return 10, fmt.Errorf("Entry cannot be larger than 10KB")
+	if l > MaxEntryPayload {
+		return 10, validationErrorf("Entry cannot be larger than 10KB")
 	}
 
 	// n is the capacity of the entry payment in KB
@@ -93,6 +101,61 @@ func FactoidToFactoshi(amt string) uint64 {
 	return total
 }
 
+// ParseFactoidAmount converts a decimal Factoid amount such as "1.5" or
+// "1.5 FCT" into factoshis. Unlike FactoidToFactoshi it is exact: an amount
+// with more than 8 decimal places can not be paid in factoshis and is an
+// error, as is an amount too large for a uint64.
+func ParseFactoidAmount(amt string) (uint64, error) {
+	s := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(amt), "FCT"))
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if whole+frac == "" || !isDigits(whole) || !isDigits(frac) {
+		return 0, validationErrorf("%q is not a Factoid amount", amt)
+	}
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > 8 {
+		return 0, validationErrorf("%q has more than 8 decimal places and can not be paid in factoshis", amt)
+	}
+
+	var w uint64
+	if whole != "" {
+		var err error
+		if w, err = strconv.ParseUint(whole, 10, 64); err != nil {
+			return 0, validationErrorf("%q is too large", amt)
+		}
+	}
+	f, _ := strconv.ParseUint(frac+strings.Repeat("0", 8-len(frac)), 10, 64)
+	if w > (math.MaxUint64-f)/1e8 {
+		return 0, validationErrorf("%q is too large", amt)
+	}
+	return w*1e8 + f, nil
+}
+
+// ParseECAmount converts an Entry Credit amount such as "100" or "100 EC"
+// into a number of entry credits.
+func ParseECAmount(amt string) (uint64, error) {
+	s := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(amt), "EC"))
+	if s == "" || !isDigits(s) {
+		return 0, validationErrorf("%q is not a whole number of entry credits", amt)
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, validationErrorf("%q is too large", amt)
+	}
+	return n, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 // milliTime returns a 6 byte slice representing the unix time in milliseconds
 func milliTime() (r []byte) {
 	buf := new(bytes.Buffer)
@@ -42,3 +42,30 @@ func TestFactoidToFactoshi(t *testing.T) {
 		t.Errorf("r5=%d expecting %d", r5, e5)
 	}
 }
+
+func TestParseFactoidAmount(t *testing.T) {
+	for s, want := range map[string]uint64{
+		"1.5":                   150000000,
+		"1.5 FCT":               150000000,
+		".00000001":             1,
+		"12":                    1200000000,
+		"0.10000000000":         10000000,
+		"184467440737.09551615": 18446744073709551615,
+	} {
+		if n, err := ParseFactoidAmount(s); err != nil || n != want {
+			t.Errorf("ParseFactoidAmount(%q) = %d, %v; want %d", s, n, err, want)
+		}
+	}
+	for _, s := range []string{"", ".", "1.000000001", "-1", "1e8", "184467440737.09551616", "100 EC"} {
+		if _, err := ParseFactoidAmount(s); err == nil {
+			t.Errorf("ParseFactoidAmount(%q) did not fail", s)
+		}
+	}
+
+	if n, err := ParseECAmount("100 EC"); err != nil || n != 100 {
+		t.Errorf("ParseECAmount = %d, %v; want 100", n, err)
+	}
+	if _, err := ParseECAmount("1.5 EC"); err == nil {
+		t.Error("ParseECAmount accepted a fraction")
+	}
+}
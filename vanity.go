@@ -0,0 +1,117 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// FindVanityChain searches for a nonce that gives a chainid starting with the
+// hex prefix when it is added as the last ExtID of the First Entry e. The
+// search is split over workers goroutines, or one per cpu if workers is 0,
+// and runs until a match is found or ctx is done. The returned Chain has a
+// copy of e with the 8 byte nonce appended to its ExtIDs and can be passed
+// to CommitChain and RevealChain as is.
+//
+// Every hex digit in the prefix makes the search 16 times longer on average.
+func FindVanityChain(ctx context.Context, e *Entry, prefix string, workers int) (*Chain, error) {
+	prefix = strings.ToLower(prefix)
+	if len(prefix) == 0 || len(prefix) > 64 {
+		return nil, validationErrorf("vanity prefix must be 1 to 64 hex digits")
+	}
+	// decode the prefix with a padding digit so that odd lengths can be
+	// matched on the high nibble of the last byte
+	want, err := hex.DecodeString(prefix + strings.Repeat("0", len(prefix)%2))
+	if err != nil {
+		return nil, validationErrorf("vanity prefix %s is not hex", prefix)
+	}
+	return FindChainNonce(ctx, e, func(id []byte) bool {
+		return matchPrefix(id, want, len(prefix))
+	}, workers)
+}
+
+// FindChainNonce searches for a nonce that gives a chainid for which match
+// returns true when it is added as the last ExtID of the First Entry e.
+// match is given the 32 byte chainid and is called concurrently by workers
+// goroutines, or one per cpu if workers is 0. The search runs until a match
+// is found or ctx is done. The returned Chain has a copy of e with the 8 byte
+// nonce appended to its ExtIDs and can be passed to CommitChain and
+// RevealChain as is.
+func FindChainNonce(ctx context.Context, e *Entry, match func(chainID []byte) bool, workers int) (*Chain, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	// the hashes of the fixed ExtIDs are the same for every nonce
+	fixed := make([]byte, 0, (len(e.ExtIDs)+1)*sha256.Size)
+	for _, id := range e.ExtIDs {
+		h := sha256.Sum256(id)
+		fixed = append(fixed, h[:]...)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		nonce []byte
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(n uint64) {
+			defer wg.Done()
+			buf := make([]byte, len(fixed), cap(fixed))
+			copy(buf, fixed)
+			try := make([]byte, 8)
+			for ; ; n += uint64(workers) {
+				// checking ctx on every hash would slow the search down
+				if n%4096 < uint64(workers) && ctx.Err() != nil {
+					return
+				}
+				binary.BigEndian.PutUint64(try, n)
+				h := sha256.Sum256(try)
+				id := sha256.Sum256(append(buf, h[:]...))
+				if match(id[:]) {
+					once.Do(func() {
+						nonce = append([]byte(nil), try...)
+						cancel()
+					})
+					return
+				}
+			}
+		}(uint64(i))
+	}
+	wg.Wait()
+
+	if nonce == nil {
+		return nil, ctx.Err()
+	}
+
+	found := &Entry{Content: e.Content}
+	found.ExtIDs = make([][]byte, 0, len(e.ExtIDs)+1)
+	found.ExtIDs = append(found.ExtIDs, e.ExtIDs...)
+	found.ExtIDs = append(found.ExtIDs, nonce)
+	return NewChain(found), nil
+}
+
+// matchPrefix reports whether the first digits hex digits of id are the
+// digits of want.
+func matchPrefix(id, want []byte, digits int) bool {
+	if !bytes.Equal(id[:digits/2], want[:digits/2]) {
+		return false
+	}
+	if digits%2 == 1 {
+		return id[digits/2]>>4 == want[digits/2]>>4
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestFindVanityChain(t *testing.T) {
+	e := &Entry{
+		ExtIDs:  [][]byte{[]byte("vanity"), []byte("test")},
+		Content: []byte("hello"),
+	}
+
+	c, err := FindVanityChain(context.Background(), e, "AbC", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(c.ChainID, "abc") {
+		t.Errorf("chain id %s does not start with abc", c.ChainID)
+	}
+	if c.ChainID != NewChain(&Entry{ExtIDs: c.FirstEntry.ExtIDs}).ChainID {
+		t.Error("chain id does not match the first entry")
+	}
+	if len(c.FirstEntry.ExtIDs) != 3 || !bytes.Equal(c.FirstEntry.Content, e.Content) {
+		t.Errorf("wrong first entry %v", c.FirstEntry)
+	}
+	if len(e.ExtIDs) != 2 {
+		t.Error("the entry passed in was changed")
+	}
+
+	if _, err := FindVanityChain(context.Background(), e, "xyz", 1); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := FindVanityChain(ctx, e, strings.Repeat("0", 64), 0); err != context.DeadlineExceeded {
+		t.Errorf("expected the search to time out, got %v", err)
+	}
+}
+
+func TestFindChainNonce(t *testing.T) {
+	e := &Entry{ExtIDs: StringExtIDs("nonce", "test")}
+
+	// a chainid ending in a zero byte
+	c, err := FindChainNonce(context.Background(), e, func(id []byte) bool {
+		return id[31] == 0
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(c.ChainID, "00") || c.ChainID != ChainIDFromExtIDs(c.FirstEntry.ExtIDs) {
+		t.Errorf("chain id %s", c.ChainID)
+	}
+	if c.FirstEntry.ChainID != c.ChainID || len(c.FirstEntry.ExtIDs) != 3 {
+		t.Errorf("wrong first entry %v", c.FirstEntry)
+	}
+}
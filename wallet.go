@@ -5,6 +5,7 @@
 package factom
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -12,6 +13,12 @@ import (
 // BackupWallet returns a formatted string with the wallet seed and the secret
 // keys for all of the wallet addresses.
 func BackupWallet() (string, error) {
+	return BackupWalletWithContext(context.Background())
+}
+
+// BackupWalletWithContext is like BackupWallet but cancels its requests when
+// ctx is done.
+func BackupWalletWithContext(ctx context.Context) (string, error) {
 	type walletBackupResponse struct {
 		Seed         string             `json:"wallet-seed"`
 		Addresses    []*addressResponse `json:"addresses"`
@@ -19,7 +26,7 @@ func BackupWallet() (string, error) {
 	}
 
 	req := NewJSON2Request("wallet-backup", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return "", err
 	}
@@ -48,8 +55,14 @@ func BackupWallet() (string, error) {
 }
 
 func GenerateFactoidAddress() (*FactoidAddress, error) {
+	return GenerateFactoidAddressWithContext(context.Background())
+}
+
+// GenerateFactoidAddressWithContext is like GenerateFactoidAddress but cancels
+// its requests when ctx is done.
+func GenerateFactoidAddressWithContext(ctx context.Context) (*FactoidAddress, error) {
 	req := NewJSON2Request("generate-factoid-address", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -70,8 +83,14 @@ func GenerateFactoidAddress() (*FactoidAddress, error) {
 }
 
 func GenerateECAddress() (*ECAddress, error) {
+	return GenerateECAddressWithContext(context.Background())
+}
+
+// GenerateECAddressWithContext is like GenerateECAddress but cancels its
+// requests when ctx is done.
+func GenerateECAddressWithContext(ctx context.Context) (*ECAddress, error) {
 	req := NewJSON2Request("generate-ec-address", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -92,8 +111,14 @@ func GenerateECAddress() (*ECAddress, error) {
 }
 
 func GenerateIdentityKey() (*IdentityKey, error) {
+	return GenerateIdentityKeyWithContext(context.Background())
+}
+
+// GenerateIdentityKeyWithContext is like GenerateIdentityKey but cancels its
+// requests when ctx is done.
+func GenerateIdentityKeyWithContext(ctx context.Context) (*IdentityKey, error) {
 	req := NewJSON2Request("generate-identity-key", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -117,6 +142,15 @@ func ImportAddresses(addrs ...string) (
 	[]*FactoidAddress,
 	[]*ECAddress,
 	error) {
+	return ImportAddressesWithContext(context.Background(), addrs...)
+}
+
+// ImportAddressesWithContext is like ImportAddresses but cancels its requests
+// when ctx is done.
+func ImportAddressesWithContext(ctx context.Context, addrs ...string) (
+	[]*FactoidAddress,
+	[]*ECAddress,
+	error) {
 
 	params := new(importRequest)
 	for _, addr := range addrs {
@@ -124,7 +158,7 @@ func ImportAddresses(addrs ...string) (
 		params.Addresses = append(params.Addresses, s)
 	}
 	req := NewJSON2Request("import-addresses", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -161,11 +195,17 @@ func ImportAddresses(addrs ...string) (
 }
 
 func ImportKoinify(mnemonic string) (*FactoidAddress, error) {
+	return ImportKoinifyWithContext(context.Background(), mnemonic)
+}
+
+// ImportKoinifyWithContext is like ImportKoinify but cancels its requests when
+// ctx is done.
+func ImportKoinifyWithContext(ctx context.Context, mnemonic string) (*FactoidAddress, error) {
 	params := new(importKoinifyRequest)
 	params.Words = mnemonic
 
 	req := NewJSON2Request("import-koinify", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -186,11 +226,17 @@ func ImportKoinify(mnemonic string) (*FactoidAddress, error) {
 }
 
 func RemoveAddress(address string) error {
+	return RemoveAddressWithContext(context.Background(), address)
+}
+
+// RemoveAddressWithContext is like RemoveAddress but cancels its requests when
+// ctx is done.
+func RemoveAddressWithContext(ctx context.Context, address string) error {
 	params := new(addressRequest)
 	params.Address = address
 
 	req := NewJSON2Request("remove-address", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -202,8 +248,19 @@ func RemoveAddress(address string) error {
 }
 
 func FetchAddresses() ([]*FactoidAddress, []*ECAddress, error) {
-	req := NewJSON2Request("all-addresses", APICounter(), nil)
-	resp, err := walletRequest(req)
+	return FetchAddressesWithContext(context.Background())
+}
+
+// FetchAddressesWithContext is like FetchAddresses but cancels its requests
+// when ctx is done.
+func FetchAddressesWithContext(ctx context.Context) ([]*FactoidAddress, []*ECAddress, error) {
+	params := new(struct {
+		Secrets bool `json:"secrets"`
+	})
+	params.Secrets = true
+
+	req := NewJSON2Request("all-addresses", APICounter(), params)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -220,6 +277,10 @@ func FetchAddresses() ([]*FactoidAddress, []*ECAddress, error) {
 	}
 
 	for _, adr := range as.Addresses {
+		// watch only addresses have no secret to return
+		if adr.Secret == "" {
+			continue
+		}
 		switch AddressStringType(adr.Public) {
 		case FactoidPub:
 			f, err := GetFactoidAddress(adr.Secret)
@@ -234,7 +295,7 @@ func FetchAddresses() ([]*FactoidAddress, []*ECAddress, error) {
 			}
 			es = append(es, e)
 		default:
-			return nil, nil, fmt.Errorf("%s is not a valid address", adr.Public)
+			return nil, nil, validationErrorf("%s is not a valid address", adr.Public)
 		}
 	}
 
@@ -242,15 +303,22 @@ func FetchAddresses() ([]*FactoidAddress, []*ECAddress, error) {
 }
 
 func FetchECAddress(ecpub string) (*ECAddress, error) {
+	return FetchECAddressWithContext(context.Background(), ecpub)
+}
+
+// FetchECAddressWithContext is like FetchECAddress but cancels its requests
+// when ctx is done.
+func FetchECAddressWithContext(ctx context.Context, ecpub string) (*ECAddress, error) {
 	if AddressStringType(ecpub) != ECPub {
 		return nil, fmt.Errorf(
 			"%s is not an Entry Credit Public Address", ecpub)
 	}
 	params := new(addressRequest)
 	params.Address = ecpub
+	params.Secrets = true
 
 	req := NewJSON2Request("address", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -267,14 +335,21 @@ func FetchECAddress(ecpub string) (*ECAddress, error) {
 }
 
 func FetchFactoidAddress(fctpub string) (*FactoidAddress, error) {
+	return FetchFactoidAddressWithContext(context.Background(), fctpub)
+}
+
+// FetchFactoidAddressWithContext is like FetchFactoidAddress but cancels its
+// requests when ctx is done.
+func FetchFactoidAddressWithContext(ctx context.Context, fctpub string) (*FactoidAddress, error) {
 	if AddressStringType(fctpub) != FactoidPub {
-		return nil, fmt.Errorf("%s is not a Factoid Address", fctpub)
+		return nil, validationErrorf("%s is not a Factoid Address", fctpub)
 	}
 	params := new(addressRequest)
 	params.Address = fctpub
+	params.Secrets = true
 
 	req := NewJSON2Request("address", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -291,6 +366,12 @@ func FetchFactoidAddress(fctpub string) (*FactoidAddress, error) {
 }
 
 func ImportIdentityKeys(pubs ...string) ([]*IdentityKey, error) {
+	return ImportIdentityKeysWithContext(context.Background(), pubs...)
+}
+
+// ImportIdentityKeysWithContext is like ImportIdentityKeys but cancels its
+// requests when ctx is done.
+func ImportIdentityKeysWithContext(ctx context.Context, pubs ...string) ([]*IdentityKey, error) {
 	params := new(struct {
 		IdentityKeys []secretRequest `json:"keys"`
 	})
@@ -300,7 +381,7 @@ func ImportIdentityKeys(pubs ...string) ([]*IdentityKey, error) {
 	}
 
 	req := NewJSON2Request("import-identity-keys", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -325,13 +406,19 @@ func ImportIdentityKeys(pubs ...string) ([]*IdentityKey, error) {
 }
 
 func FetchIdentityKey(pub string) (*IdentityKey, error) {
+	return FetchIdentityKeyWithContext(context.Background(), pub)
+}
+
+// FetchIdentityKeyWithContext is like FetchIdentityKey but cancels its requests
+// when ctx is done.
+func FetchIdentityKeyWithContext(ctx context.Context, pub string) (*IdentityKey, error) {
 	params := new(struct {
 		Public string `json:"public"`
 	})
 	params.Public = pub
 
 	req := NewJSON2Request("identity-key", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -348,8 +435,14 @@ func FetchIdentityKey(pub string) (*IdentityKey, error) {
 }
 
 func FetchIdentityKeys() ([]*IdentityKey, error) {
+	return FetchIdentityKeysWithContext(context.Background())
+}
+
+// FetchIdentityKeysWithContext is like FetchIdentityKeys but cancels its
+// requests when ctx is done.
+func FetchIdentityKeysWithContext(ctx context.Context) ([]*IdentityKey, error) {
 	req := NewJSON2Request("all-identity-keys", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -372,7 +465,7 @@ func FetchIdentityKeys() ([]*IdentityKey, error) {
 			}
 			keys = append(keys, k)
 		} else {
-			return nil, fmt.Errorf("%s is not a valid public identity key", v.Public)
+			return nil, validationErrorf("%s is not a valid public identity key", v.Public)
 		}
 	}
 
@@ -380,13 +473,19 @@ func FetchIdentityKeys() ([]*IdentityKey, error) {
 }
 
 func RemoveIdentityKey(pub string) error {
+	return RemoveIdentityKeyWithContext(context.Background(), pub)
+}
+
+// RemoveIdentityKeyWithContext is like RemoveIdentityKey but cancels its
+// requests when ctx is done.
+func RemoveIdentityKeyWithContext(ctx context.Context, pub string) error {
 	params := new(struct {
 		Public string `json:"public"`
 	})
 	params.Public = pub
 
 	req := NewJSON2Request("remove-identity-key", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -398,8 +497,14 @@ func RemoveIdentityKey(pub string) error {
 }
 
 func GetWalletHeight() (uint32, error) {
+	return GetWalletHeightWithContext(context.Background())
+}
+
+// GetWalletHeightWithContext is like GetWalletHeight but cancels its requests
+// when ctx is done.
+func GetWalletHeightWithContext(ctx context.Context) (uint32, error) {
 	req := NewJSON2Request("get-height", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return 0, err
 	}
@@ -416,8 +521,14 @@ func GetWalletHeight() (uint32, error) {
 }
 
 func UnlockWallet(passphrase string, seconds int64) (int64, error) {
+	return UnlockWalletWithContext(context.Background(), passphrase, seconds)
+}
+
+// UnlockWalletWithContext is like UnlockWallet but cancels its requests when
+// ctx is done.
+func UnlockWalletWithContext(ctx context.Context, passphrase string, seconds int64) (int64, error) {
 	req := NewJSON2Request("unlock-wallet", APICounter(), &passphraseRequest{Password: passphrase, Timeout: seconds})
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return 0, err
 	}
@@ -433,6 +544,55 @@ func UnlockWallet(passphrase string, seconds int64) (int64, error) {
 	return r.UnlockedUntil, nil
 }
 
+// LockWallet locks an encrypted wallet before the timeout given to
+// UnlockWallet runs out.
+func LockWallet() error {
+	return LockWalletWithContext(context.Background())
+}
+
+// LockWalletWithContext is like LockWallet but cancels its requests when ctx is
+// done.
+func LockWalletWithContext(ctx context.Context) error {
+	req := NewJSON2Request("lock-wallet", APICounter(), nil)
+	resp, err := walletRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	return nil
+}
+
+// ChangeWalletPassphrase re-encrypts an encrypted wallet with a new
+// passphrase.
+func ChangeWalletPassphrase(oldPassphrase, newPassphrase string) error {
+	return ChangeWalletPassphraseWithContext(context.Background(), oldPassphrase, newPassphrase)
+}
+
+// ChangeWalletPassphraseWithContext is like ChangeWalletPassphrase but cancels
+// its requests when ctx is done.
+func ChangeWalletPassphraseWithContext(ctx context.Context, oldPassphrase, newPassphrase string) error {
+	params := new(struct {
+		Old string `json:"oldpassphrase"`
+		New string `json:"newpassphrase"`
+	})
+	params.Old = oldPassphrase
+	params.New = newPassphrase
+
+	req := NewJSON2Request("change-passphrase", APICounter(), params)
+	resp, err := walletRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	return nil
+}
+
 type addressResponse struct {
 	Public string `json:"public"`
 	Secret string `json:"secret"`
@@ -464,13 +624,19 @@ type composeEntryResponse struct {
 }
 
 func WalletComposeChainCommitReveal(chain *Chain, ecPub string, force bool) (*JSON2Request, *JSON2Request, error) {
+	return WalletComposeChainCommitRevealWithContext(context.Background(), chain, ecPub, force)
+}
+
+// WalletComposeChainCommitRevealWithContext is like
+// WalletComposeChainCommitReveal but cancels its requests when ctx is done.
+func WalletComposeChainCommitRevealWithContext(ctx context.Context, chain *Chain, ecPub string, force bool) (*JSON2Request, *JSON2Request, error) {
 	params := new(composeChainRequest)
 	params.Chain = *chain
 	params.ECPub = ecPub
 	params.Force = force
 
 	req := NewJSON2Request("compose-chain", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -487,13 +653,19 @@ func WalletComposeChainCommitReveal(chain *Chain, ecPub string, force bool) (*JS
 }
 
 func WalletComposeEntryCommitReveal(entry *Entry, ecPub string, force bool) (*JSON2Request, *JSON2Request, error) {
+	return WalletComposeEntryCommitRevealWithContext(context.Background(), entry, ecPub, force)
+}
+
+// WalletComposeEntryCommitRevealWithContext is like
+// WalletComposeEntryCommitReveal but cancels its requests when ctx is done.
+func WalletComposeEntryCommitRevealWithContext(ctx context.Context, entry *Entry, ecPub string, force bool) (*JSON2Request, *JSON2Request, error) {
 	params := new(composeEntryRequest)
 	params.Entry = *entry
 	params.ECPub = ecPub
 	params.Force = force
 
 	req := NewJSON2Request("compose-entry", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := walletRequest(ctx, req)
 	if err != nil {
 		return nil, nil, err
 	}
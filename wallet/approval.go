@@ -0,0 +1,162 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/FactomProject/factomd/common/factoid"
+)
+
+// ErrApprovalRequired is returned by SignTransaction for a transaction above
+// the approval threshold that has not been approved.
+var ErrApprovalRequired = errors.New("wallet: The transaction must be approved before it can be signed")
+
+// PendingApprovalError is returned by SendFactoid and BuyEC for a payment
+// above the approval threshold. Its tmp transaction is kept under Name so
+// that it can be approved, then signed and sent with SendTransaction. It
+// matches ErrApprovalRequired.
+type PendingApprovalError struct {
+	Name string
+}
+
+func (e *PendingApprovalError) Error() string {
+	return fmt.Sprintf("%s; it is kept as transaction %s", ErrApprovalRequired, e.Name)
+}
+
+func (e *PendingApprovalError) Is(target error) bool {
+	return target == ErrApprovalRequired
+}
+
+// SetApprovalThreshold makes transactions whose inputs add up to more than
+// factoshis wait for ApproveTransaction before SignTransaction signs them.
+// Zero, the default, turns approvals off.
+func (w *Wallet) SetApprovalThreshold(factoshis uint64) {
+	w.txlock.Lock()
+	defer w.txlock.Unlock()
+
+	w.approvalThreshold = factoshis
+}
+
+// ApprovalThreshold returns the threshold set with SetApprovalThreshold.
+func (w *Wallet) ApprovalThreshold() uint64 {
+	w.txlock.Lock()
+	defer w.txlock.Unlock()
+
+	return w.approvalThreshold
+}
+
+// NeedsApproval reports whether the tmp transaction name is pending
+// approval: it is above the approval threshold and has not been approved
+// since it was last changed.
+func (w *Wallet) NeedsApproval(name string) (bool, error) {
+	tx, err := w.GetTransaction(name)
+	if err != nil {
+		return false, err
+	}
+
+	w.txlock.Lock()
+	defer w.txlock.Unlock()
+
+	return w.needsApproval(tx), nil
+}
+
+// ApproveTransaction approves the tmp transaction name as it is now, so that
+// it can be signed. Changing the transaction afterwards withdraws the
+// approval.
+func (w *Wallet) ApproveTransaction(name string) error {
+	return w.ApproveTransactionAs(name, "")
+}
+
+// ApproveTransactionAs is like ApproveTransaction but records who approved
+// the transaction, see Approver.
+func (w *Wallet) ApproveTransactionAs(name, approver string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	tx, err := w.GetTransaction(name)
+	if err != nil {
+		return err
+	}
+	if err := w.approve(tx, approver); err != nil {
+		return err
+	}
+	w.logf("approved transaction %s", name)
+	return nil
+}
+
+// ApproveExportedTransaction approves the exported transaction blob, so that
+// SignExportedTransaction can sign it. approver is recorded as in
+// ApproveTransactionAs.
+func (w *Wallet) ApproveExportedTransaction(blob, approver string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	p, err := readTxPackage(blob)
+	if err != nil {
+		return err
+	}
+	tx, err := p.transaction()
+	if err != nil {
+		return err
+	}
+	if err := w.approve(tx, approver); err != nil {
+		return err
+	}
+	w.logf("approved exported transaction %s", tx.GetSigHash())
+	return nil
+}
+
+// approve approves tx by its signature hash, which covers every input and
+// output, so that any change to the transaction withdraws the approval.
+func (w *Wallet) approve(tx *factoid.Transaction, approver string) error {
+	if len(tx.GetInputs()) == 0 {
+		return ErrTXNoInputs
+	}
+
+	w.txlock.Lock()
+	defer w.txlock.Unlock()
+
+	if w.approvals == nil {
+		w.approvals = make(map[string]string)
+	}
+	w.approvals[tx.GetSigHash().String()] = approver
+	return nil
+}
+
+// Approver returns who approved the transaction with the signature hash
+// sighash, as given to ApproveTransactionAs, and false if it is not approved.
+func (w *Wallet) Approver(sighash string) (string, bool) {
+	w.txlock.Lock()
+	defer w.txlock.Unlock()
+
+	approver, ok := w.approvals[sighash]
+	return approver, ok
+}
+
+// needsApproval is NeedsApproval for the transaction tx. The caller holds
+// txlock.
+func (w *Wallet) needsApproval(tx *factoid.Transaction) bool {
+	if w.approvalThreshold == 0 {
+		return false
+	}
+	var total uint64
+	for _, in := range tx.GetInputs() {
+		total += in.GetAmount()
+	}
+	if total <= w.approvalThreshold {
+		return false
+	}
+	_, ok := w.approvals[tx.GetSigHash().String()]
+	return !ok
+}
+
+// forgetApproval drops the approval of tx. The caller holds txlock.
+func (w *Wallet) forgetApproval(tx *factoid.Transaction) {
+	if tx != nil && len(w.approvals) > 0 {
+		delete(w.approvals, tx.GetSigHash().String())
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestApproveTransaction(t *testing.T) {
+	w1, err := New(WithMapDB(), WithApprovalThreshold(1e8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	f1, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f2, err := factom.GetFactoidAddress("Fs2GCfAa2HBKaGEUWCtw8eGDkN1CfyS6HhdgLv8783shkrCgvcpJ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// small transactions are signed without approval
+	if err := w1.NewTransaction("small"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddInput("small", f1.String(), 1e8); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddOutput("small", f2.String(), 1e8); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.SignTransaction("small", true); err != nil {
+		t.Error(err)
+	}
+
+	if err := w1.NewTransaction("large"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddInput("large", f1.String(), 5e8); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddOutput("large", f2.String(), 5e8); err != nil {
+		t.Fatal(err)
+	}
+	if pending, err := w1.NeedsApproval("large"); err != nil || !pending {
+		t.Errorf("expected the transaction to need approval, got %v %v", pending, err)
+	}
+	if err := w1.SignTransaction("large", true); err != ErrApprovalRequired {
+		t.Errorf("expected ErrApprovalRequired, got %v", err)
+	}
+
+	if err := w1.ApproveTransaction("large"); err != nil {
+		t.Fatal(err)
+	}
+	if pending, _ := w1.NeedsApproval("large"); pending {
+		t.Error("approved transaction still needs approval")
+	}
+
+	// changing the transaction withdraws the approval
+	if err := w1.AddOutput("large", f2.String(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.SignTransaction("large", true); err != ErrApprovalRequired {
+		t.Errorf("expected ErrApprovalRequired after a change, got %v", err)
+	}
+	if err := w1.ApproveTransaction("large"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.SignTransaction("large", true); err != nil {
+		t.Error(err)
+	}
+
+	if err := w1.ApproveTransaction("no-such-tx"); err != ErrTXNotExists {
+		t.Errorf("expected ErrTXNotExists, got %v", err)
+	}
+
+	w1.SetApprovalThreshold(0)
+	if pending, _ := w1.NeedsApproval("large"); pending {
+		t.Error("transactions need no approval without a threshold")
+	}
+}
+
+func TestApproveExportedTransaction(t *testing.T) {
+	w1, err := New(WithMapDB(), WithApprovalThreshold(1e8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	f1, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f2, err := factom.GetFactoidAddress("Fs2GCfAa2HBKaGEUWCtw8eGDkN1CfyS6HhdgLv8783shkrCgvcpJ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w1.NewTransaction("large"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddInput("large", f1.String(), 5e8); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddOutput("large", f2.String(), 5e8); err != nil {
+		t.Fatal(err)
+	}
+	blob, err := w1.ExportTransaction("large")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sighash, err := ExportedSigHash(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w1.SignExportedTransaction(blob, true); err != ErrApprovalRequired {
+		t.Errorf("expected ErrApprovalRequired, got %v", err)
+	}
+	if err := w1.ApproveExportedTransaction(blob, "approver"); err != nil {
+		t.Fatal(err)
+	}
+	if approver, ok := w1.Approver(sighash); !ok || approver != "approver" {
+		t.Errorf("approver %q %v", approver, ok)
+	}
+	// the tmp transaction is the same transaction and is approved too
+	if pending, _ := w1.NeedsApproval("large"); pending {
+		t.Error("tmp transaction of an approved export still needs approval")
+	}
+
+	if _, err := w1.SignExportedTransaction(blob, true); err != nil {
+		t.Fatal(err)
+	}
+	// the approval is used up by the signature
+	if _, ok := w1.Approver(sighash); ok {
+		t.Error("approval kept after signing")
+	}
+}
+
+func TestPendingApprovalError(t *testing.T) {
+	var err error = &PendingApprovalError{Name: "abc"}
+	if !errors.Is(err, ErrApprovalRequired) {
+		t.Error("PendingApprovalError does not match ErrApprovalRequired")
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/FactomProject/factomd/common/interfaces"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+// maxAuditPage is the most audit entries returned by one call to AuditLog.
+const maxAuditPage = 1000
+
+// AuditEntry is one signing or export operation in the audit log of the
+// wallet. Each entry holds the hash of the entry before it, so an entry that
+// is changed or removed breaks the chain of every entry after it.
+type AuditEntry struct {
+	Seq     uint64 `json:"seq"`
+	Time    int64  `json:"time"`
+	Method  string `json:"method"`
+	Subject string `json:"subject,omitempty"`
+	Prev    string `json:"prev"`
+	Hash    string `json:"hash"`
+}
+
+var _ interfaces.BinaryMarshallableAndCopyable = (*AuditEntry)(nil)
+
+// auditEntryData is AuditEntry without its methods, for gob.
+type auditEntryData AuditEntry
+
+func (e *AuditEntry) New() interfaces.BinaryMarshallableAndCopyable {
+	return new(AuditEntry)
+}
+
+func (e *AuditEntry) MarshalBinary() ([]byte, error) {
+	var data primitives.Buffer
+
+	enc := gob.NewEncoder(&data)
+	if err := enc.Encode(auditEntryData(*e)); err != nil {
+		return nil, err
+	}
+	return data.DeepCopyBytes(), nil
+}
+
+func (e *AuditEntry) UnmarshalBinaryData(data []byte) ([]byte, error) {
+	dec := gob.NewDecoder(primitives.NewBuffer(data))
+	if err := dec.Decode((*auditEntryData)(e)); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (e *AuditEntry) UnmarshalBinary(data []byte) error {
+	_, err := e.UnmarshalBinaryData(data)
+	return err
+}
+
+// hash returns the hash of every field of the entry except Hash itself.
+func (e *AuditEntry) hash() string {
+	h := sha256.New()
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], e.Seq)
+	h.Write(n[:])
+	binary.BigEndian.PutUint64(n[:], uint64(e.Time))
+	h.Write(n[:])
+	for _, s := range []string{e.Method, e.Subject, e.Prev} {
+		binary.BigEndian.PutUint64(n[:], uint64(len(s)))
+		h.Write(n[:])
+		h.Write([]byte(s))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// auditKey is the database key of the audit entry seq. Keys sort in the
+// order of the entries.
+func auditKey(seq uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, seq)
+	return k
+}
+
+// auditTail returns the last entry of the audit log, or nil if it is empty.
+// The caller holds auditLock.
+func (w *Wallet) auditTail() (*AuditEntry, error) {
+	if w.auditHead != nil {
+		return w.auditHead, nil
+	}
+	keys, err := w.DBO.ListAllKeys(auditDBPrefix)
+	if err != nil {
+		return nil, dbError("read", err)
+	}
+	var last []byte
+	for _, k := range keys {
+		if len(k) == 8 && bytes.Compare(k, last) > 0 {
+			last = k
+		}
+	}
+	if last == nil {
+		return nil, nil
+	}
+	data, err := w.DBO.Get(auditDBPrefix, last, new(AuditEntry))
+	if err != nil {
+		return nil, dbError("read", err)
+	}
+	if data == nil {
+		return nil, nil
+	}
+	w.auditHead = data.(*AuditEntry)
+	return w.auditHead, nil
+}
+
+// Audit appends an entry for the operation method on subject, such as a
+// transaction name or an address, to the audit log of the wallet. The audit
+// log is only ever appended to.
+func (w *Wallet) Audit(method, subject string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	w.auditLock.Lock()
+	defer w.auditLock.Unlock()
+
+	tail, err := w.auditTail()
+	if err != nil {
+		return err
+	}
+	e := &AuditEntry{Time: time.Now().Unix(), Method: method, Subject: subject}
+	if tail != nil {
+		e.Seq = tail.Seq + 1
+		e.Prev = tail.Hash
+	}
+	e.Hash = e.hash()
+
+	if err := w.DBO.Put(auditDBPrefix, auditKey(e.Seq), e); err != nil {
+		return dbError("write", err)
+	}
+	w.auditHead = e
+	return nil
+}
+
+// AuditLog returns up to limit entries of the audit log starting with the
+// entry start, oldest first. The page is cut short at the end of the log.
+func (w *Wallet) AuditLog(start uint64, limit int) ([]*AuditEntry, error) {
+	if limit <= 0 || limit > maxAuditPage {
+		limit = maxAuditPage
+	}
+
+	es := make([]*AuditEntry, 0)
+	for seq := start; len(es) < limit; seq++ {
+		data, err := w.DBO.Get(auditDBPrefix, auditKey(seq), new(AuditEntry))
+		if err != nil {
+			return nil, dbError("read", err)
+		}
+		if data == nil {
+			break
+		}
+		es = append(es, data.(*AuditEntry))
+	}
+	return es, nil
+}
+
+// VerifyAuditLog checks the hash chain of the whole audit log and returns an
+// error naming the first entry that was changed, removed or reordered.
+func (w *Wallet) VerifyAuditLog() error {
+	var seq uint64
+	var prev string
+	for {
+		es, err := w.AuditLog(seq, maxAuditPage)
+		if err != nil {
+			return err
+		}
+		for _, e := range es {
+			if e.Seq != seq || e.Prev != prev || e.Hash != e.hash() {
+				return fmt.Errorf("wallet: Audit log entry %d does not match the log", seq)
+			}
+			prev = e.Hash
+			seq++
+		}
+		if len(es) < maxAuditPage {
+			break
+		}
+	}
+
+	// entries after a removed one are not reached by the walk
+	keys, err := w.DBO.ListAllKeys(auditDBPrefix)
+	if err != nil {
+		return dbError("read", err)
+	}
+	if uint64(len(keys)) != seq {
+		return fmt.Errorf("wallet: Audit log entry %d is missing", seq)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"testing"
+
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestAuditLog(t *testing.T) {
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	ops := []string{"sign-transaction", "wallet-backup", "export-keystore"}
+	for _, op := range ops {
+		if err := w1.Audit(op, "subject"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	es, err := w1.AuditLog(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(es) != len(ops) {
+		t.Fatalf("wrong number of audit entries %d", len(es))
+	}
+	for i, e := range es {
+		if e.Seq != uint64(i) || e.Method != ops[i] {
+			t.Errorf("wrong audit entry %v", e)
+		}
+		if i > 0 && e.Prev != es[i-1].Hash {
+			t.Errorf("entry %d is not chained to the one before it", i)
+		}
+	}
+	if err := w1.VerifyAuditLog(); err != nil {
+		t.Error(err)
+	}
+
+	// paging
+	if es, err := w1.AuditLog(1, 1); err != nil {
+		t.Error(err)
+	} else if len(es) != 1 || es[0].Method != ops[1] {
+		t.Errorf("wrong page %v", es)
+	}
+	if es, err := w1.AuditLog(5, 10); err != nil || len(es) != 0 {
+		t.Errorf("expected an empty page past the end, got %v %v", es, err)
+	}
+
+	// an edited entry breaks the chain
+	e := es[1]
+	e.Subject = "edited"
+	key := []byte{0, 0, 0, 0, 0, 0, 0, 1}
+	if err := w1.DBO.Put([]byte("Audit Log"), key, e); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.VerifyAuditLog(); err == nil {
+		t.Error("expected an error for an edited audit entry")
+	}
+
+	// a removed entry too
+	if err := w1.DBO.Delete([]byte("Audit Log"), key); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.VerifyAuditLog(); err == nil {
+		t.Error("expected an error for a removed audit entry")
+	}
+
+	w1.SetReadOnly(true)
+	if err := w1.Audit("sign-data", ""); err != ErrReadOnly {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"errors"
+	"time"
+
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factomd/common/factoid"
+	"github.com/FactomProject/factomd/database/securedb"
+)
+
+var (
+	ErrIncorrectPassphrase = errors.New("wallet: Incorrect passphrase")
+	ErrNotEncrypted        = errors.New("wallet: Cannot unlock non-encrypted wallet. This database is always unlocked")
+	ErrWalletLocked        = errors.New("wallet: The wallet is locked")
+)
+
+// WalletBackend is the set of wallet operations used by the wallet api. It
+// covers key storage and retrieval, building and signing transactions, and
+// unlocking encrypted wallets. *Wallet implements it; other implementations,
+// such as a remote vault or a read only mirror, can be served by wsapi in its
+// place.
+type WalletBackend interface {
+	Close() error
+	GetVersion() string
+	GetApiVersion() string
+
+	// IsLocked reports whether the wallet is encrypted and currently locked.
+	// Only a small set of methods may be used while the wallet is locked.
+	IsLocked() bool
+	Unlock(passphrase string, d time.Duration) (time.Time, error)
+	Lock() error
+
+	// Encrypt encrypts an unencrypted wallet with passphrase and locks it.
+	Encrypt(passphrase string) error
+	Rekey(oldPassphrase, newPassphrase string) error
+
+	// Storage describes the database the wallet is stored in.
+	Storage() StorageInfo
+
+	// ReadOnly reports whether methods that change the wallet or export
+	// secrets are refused with ErrReadOnly.
+	ReadOnly() bool
+
+	// key storage
+	GetSeed() (string, error)
+	RotateSeed() (*RotationReport, error)
+	GetMetadata() (*Metadata, error)
+	SetDerivationPaths(fct, ec string) error
+	DerivationPaths() (fct, ec string, err error)
+	GenerateFCTAddress() (*factom.FactoidAddress, error)
+	GenerateECAddress() (*factom.ECAddress, error)
+	GenerateFCTAddresses(n int) ([]*factom.FactoidAddress, error)
+	GenerateECAddresses(n int) ([]*factom.ECAddress, error)
+	GenerateIdentityKey() (*factom.IdentityKey, error)
+	InsertFCTAddress(*factom.FactoidAddress) error
+	InsertECAddress(*factom.ECAddress) error
+	InsertIdentityKey(*factom.IdentityKey) error
+	GetFCTAddress(string) (*factom.FactoidAddress, error)
+	GetECAddress(string) (*factom.ECAddress, error)
+	GetIdentityKey(string) (*factom.IdentityKey, error)
+	GetAllAddresses() ([]*factom.FactoidAddress, []*factom.ECAddress, error)
+	GetAllIdentityKeys() ([]*factom.IdentityKey, error)
+	RemoveAddress(string) error
+	RemoveFCTAddress(string) (*factom.FactoidAddress, error)
+	RemoveECAddress(string) (*factom.ECAddress, error)
+	RemoveIdentityKey(string) error
+	ImportKoinifyWords(words string) (*factom.FactoidAddress, error)
+	ExportKeystore(pub, passphrase string) (*Keystore, error)
+	ImportKeystore(k *Keystore, passphrase string) (string, error)
+	ImportWatchOnly(string) error
+	ImportWatchOnlyKey(pub []byte) (string, error)
+	IsWatchOnly(string) (bool, error)
+	GetAllWatchOnly() ([]string, error)
+	IsSignerKey(string) (bool, error)
+	GetAllSignerKeys() ([]string, error)
+
+	// namespaces
+	SetNamespace(pub, namespace string) error
+	Namespace(pub string) (string, error)
+	GetAllNamespaces() (map[string]string, error)
+
+	// address labels
+	SetAddressLabel(pub, label string) error
+	AddressLabel(pub string) (string, error)
+	GetAllAddressLabels() (map[string]string, error)
+
+	// transactions and signing
+	NewTransaction(name string) error
+	DeleteTransaction(name string) error
+	GetTransactions() map[string]*factoid.Transaction
+	TransactionModified(name string) (time.Time, error)
+	AddInput(name, address string, amount uint64) error
+	FundTransaction(name string, strategy SelectionStrategy) error
+	AddOutput(name, address string, amount uint64) error
+	AddOutputs(name string, outputs []*factom.TransAddress) error
+	AddECOutput(name, address string, amount uint64) error
+	RemoveInput(name, address string) error
+	RemoveOutput(name, address string) error
+	RemoveECOutput(name, address string) error
+	AddFee(name, address string, rate uint64) error
+	SubFee(name, address string, rate uint64) error
+	SubFeeAuto(name string, rate uint64) (string, error)
+	SignTransaction(name string, force bool) error
+	ApproveTransaction(name string) error
+	ApproveTransactionAs(name, approver string) error
+	ApproveExportedTransaction(blob, approver string) error
+	Approver(sighash string) (string, bool)
+	NeedsApproval(name string) (bool, error)
+	ComposeTransaction(name string) (*factom.JSON2Request, error)
+	SendTransaction(name string) (string, error)
+	SendFactoid(from, to string, amount uint64, force bool) (string, error)
+	BuyEC(from, to string, ecs uint64, force bool) (*ECPurchase, error)
+	ExportTransaction(name string) (string, error)
+	ImportTransaction(name, blob string) error
+	SignExportedTransaction(blob string, force bool) (string, error)
+	SimulateFees(name string, rates ...uint64) ([]*FeeEstimate, error)
+	EstimateFee(name string) (*FeeEstimate, error)
+	PreviewTransaction(name string) (*TransactionPreview, error)
+	SetTransactionNote(ref, memo string, tags map[string]string) error
+	GetTransactionNote(ref string) (*TxNote, error)
+
+	// history of the confirmed transactions of the wallet addresses
+	IndexHistory() (int, error)
+	GetHistory(f HistoryFilter) ([]*HistoryTx, int, error)
+
+	// address book
+	AddContact(name, address string) error
+	GetAllContacts() ([]*Contact, error)
+	RemoveContact(name string) error
+	ResolveAddress(s string) (string, error)
+
+	// chain bookmarks
+	AddBookmark(chainID, label string) error
+	GetAllBookmarks() ([]*Bookmark, error)
+	RemoveBookmark(chainID string) error
+
+	// signing with the wallet keys or a remote Signer
+	PublicKey(pub string) ([]byte, error)
+	Sign(pub string, msg []byte) ([]byte, error)
+	ComposeChainCommit(c *factom.Chain, ecpub string) (*factom.JSON2Request, error)
+	ComposeEntryCommit(e *factom.Entry, ecpub string) (*factom.JSON2Request, error)
+
+	// audit log of signing and export operations
+	Audit(method, subject string) error
+	AuditLog(start uint64, limit int) ([]*AuditEntry, error)
+
+	// Subscribe returns a channel of wallet events and a function that
+	// ends the subscription.
+	Subscribe() (<-chan *Event, func())
+
+	// TXDB returns the local transaction cache or nil if there is none.
+	TXDB() *TXDatabaseOverlay
+}
+
+var _ WalletBackend = (*Wallet)(nil)
+
+// IsLocked reports whether the wallet is encrypted and is either waiting for
+// its passphrase or has been unlocked for a time that has run out.
+func (w *Wallet) IsLocked() bool {
+	if !w.Encrypted {
+		return false
+	}
+	if w.WalletDatabaseOverlay == nil {
+		return true
+	}
+	if db, ok := w.sealedDB(); ok {
+		return db.locked()
+	}
+	encdb, ok := w.encryptedDB()
+	return ok && encdb.UnlockedUntil.Unix() < time.Now().Unix()
+}
+
+// Unlock unlocks an encrypted wallet for the duration d and returns the time
+// it will be locked again. An encrypted wallet opened without its passphrase
+// is opened here.
+func (w *Wallet) Unlock(passphrase string, d time.Duration) (time.Time, error) {
+	// If this isn't the first time booting an encrypted wallet, we postpone creating the database until now
+	if w.WalletDatabaseOverlay == nil {
+		db, err := NewEncryptedBoltDB(w.DBPath, passphrase)
+		if err != nil {
+			return time.Time{}, ErrIncorrectPassphrase
+		}
+		w.WalletDatabaseOverlay = db
+
+		if err := w.InitWallet(); err != nil {
+			return time.Time{}, err
+		}
+		w.DBO.DB.(*securedb.EncryptedDB).Lock()
+		w.observeDB()
+	}
+
+	if db, ok := w.sealedDB(); ok {
+		return db.unlockFor(passphrase, d)
+	}
+
+	encdb, ok := w.encryptedDB()
+	if !ok {
+		return time.Time{}, ErrNotEncrypted
+	}
+
+	if err := encdb.UnlockFor(passphrase, d); err != nil {
+		return time.Time{}, ErrIncorrectPassphrase
+	}
+	return encdb.UnlockedUntil, nil
+}
+
+// Lock locks an unlocked encrypted wallet before its unlock time runs out.
+// The key of a wallet encrypted with Encrypt is overwritten in memory.
+// Locking a locked wallet does nothing.
+func (w *Wallet) Lock() error {
+	if !w.Encrypted {
+		return ErrNotEncrypted
+	}
+	if w.WalletDatabaseOverlay == nil {
+		return nil
+	}
+	if db, ok := w.sealedDB(); ok {
+		db.lock()
+		return nil
+	}
+	if encdb, ok := w.encryptedDB(); ok {
+		encdb.Lock()
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestWalletBackendLocking(t *testing.T) {
+	var w1 WalletBackend
+	w1, err := NewMapDBWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	// an unencrypted wallet is never locked and cannot be unlocked
+	if w1.IsLocked() {
+		t.Errorf("unencrypted wallet reports it is locked")
+	}
+	if _, err := w1.Unlock("password", time.Minute); err != ErrNotEncrypted {
+		t.Errorf("expected ErrNotEncrypted, got %v", err)
+	}
+	if err := w1.Lock(); err != ErrNotEncrypted {
+		t.Errorf("expected ErrNotEncrypted, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"encoding/gob"
+	"encoding/hex"
+	"sort"
+
+	"github.com/FactomProject/factomd/common/interfaces"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+var ErrNoSuchBookmark = validationErrorf("wallet: No such bookmark")
+
+// Bookmark is a chain the user follows, stored in the wallet with a label so
+// that wallet UIs can show its entries next to the wallet balances.
+type Bookmark struct {
+	ChainID string
+	Label   string
+}
+
+var _ interfaces.BinaryMarshallableAndCopyable = (*Bookmark)(nil)
+
+// bookmarkData has the fields of Bookmark without its methods. gob uses the
+// MarshalBinary and UnmarshalBinary methods of a type if it has them, so a
+// Bookmark itself can not be passed to gob.
+type bookmarkData Bookmark
+
+func (b *Bookmark) New() interfaces.BinaryMarshallableAndCopyable {
+	return new(Bookmark)
+}
+
+func (b *Bookmark) MarshalBinary() ([]byte, error) {
+	var data primitives.Buffer
+
+	enc := gob.NewEncoder(&data)
+	if err := enc.Encode(bookmarkData(*b)); err != nil {
+		return nil, err
+	}
+	return data.DeepCopyBytes(), nil
+}
+
+func (b *Bookmark) UnmarshalBinaryData(data []byte) ([]byte, error) {
+	dec := gob.NewDecoder(primitives.NewBuffer(data))
+	if err := dec.Decode((*bookmarkData)(b)); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (b *Bookmark) UnmarshalBinary(data []byte) error {
+	_, err := b.UnmarshalBinaryData(data)
+	return err
+}
+
+// InsertBookmark stores a Bookmark, replacing the label of an existing
+// Bookmark for the same chain.
+func (db *WalletDatabaseOverlay) InsertBookmark(b *Bookmark) error {
+	if b == nil {
+		return nil
+	}
+	return dbError("write", db.DBO.Put(bookmarkDBPrefix, []byte(b.ChainID), b))
+}
+
+func (db *WalletDatabaseOverlay) GetBookmark(chainID string) (*Bookmark, error) {
+	data, err := db.DBO.Get(bookmarkDBPrefix, []byte(chainID), new(Bookmark))
+	if err != nil {
+		return nil, dbError("read", err)
+	}
+	if data == nil {
+		return nil, ErrNoSuchBookmark
+	}
+	return data.(*Bookmark), nil
+}
+
+// GetAllBookmarks returns the stored Bookmarks sorted by label.
+func (db *WalletDatabaseOverlay) GetAllBookmarks() ([]*Bookmark, error) {
+	list, err := db.DBO.FetchAllBlocksFromBucket(bookmarkDBPrefix, new(Bookmark))
+	if err != nil {
+		return nil, dbError("read", err)
+	}
+
+	bs := make([]*Bookmark, len(list))
+	for i, v := range list {
+		bs[i] = v.(*Bookmark)
+	}
+	sort.Slice(bs, func(i, j int) bool {
+		if bs[i].Label != bs[j].Label {
+			return bs[i].Label < bs[j].Label
+		}
+		return bs[i].ChainID < bs[j].ChainID
+	})
+	return bs, nil
+}
+
+func (db *WalletDatabaseOverlay) RemoveBookmark(chainID string) error {
+	if _, err := db.GetBookmark(chainID); err != nil {
+		return err
+	}
+	return dbError("delete", db.DBO.Delete(bookmarkDBPrefix, []byte(chainID)))
+}
+
+// AddBookmark stores a labeled Bookmark for the chain chainID.
+func (w *Wallet) AddBookmark(chainID, label string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	if p, err := hex.DecodeString(chainID); err != nil || len(p) != 32 {
+		return validationErrorf("wallet: Invalid chain id %s", chainID)
+	}
+	return w.InsertBookmark(&Bookmark{ChainID: chainID, Label: label})
+}
+
+// RemoveBookmark deletes the Bookmark for the chain chainID.
+func (w *Wallet) RemoveBookmark(chainID string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	return w.WalletDatabaseOverlay.RemoveBookmark(chainID)
+}
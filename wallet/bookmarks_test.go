@@ -0,0 +1,64 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestBookmarks(t *testing.T) {
+	chain1 := "df3ade9eec4b08d5379cc64270c30ea7315d8a8a1a69efe2b98a60ecdd69e604"
+	chain2 := "888888d027c59579fc47a6fc6c4a5c0409c7c39bc38a86cb5fc0069978493762"
+
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	if err := w1.AddBookmark(chain1, "tests"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddBookmark(chain2, "identity"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddBookmark("not a chain", "bad"); !errors.Is(err, factom.ErrValidation) {
+		t.Errorf("expected a validation error for a bad chain id, got %v", err)
+	}
+
+	bs, err := w1.GetAllBookmarks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bs) != 2 || bs[0].ChainID != chain2 || bs[1].Label != "tests" {
+		t.Errorf("wrong bookmarks %v", bs)
+	}
+
+	// adding the same chain again replaces its label
+	if err := w1.AddBookmark(chain1, "renamed"); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := w1.GetBookmark(chain1); err != nil {
+		t.Error(err)
+	} else if b.Label != "renamed" {
+		t.Errorf("wrong label %s", b.Label)
+	}
+
+	if err := w1.RemoveBookmark(chain1); err != nil {
+		t.Error(err)
+	}
+	if err := w1.RemoveBookmark(chain1); err != ErrNoSuchBookmark {
+		t.Errorf("expected ErrNoSuchBookmark, got %v", err)
+	}
+
+	w1.SetReadOnly(true)
+	if err := w1.AddBookmark(chain1, "tests"); err != ErrReadOnly {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sort"
+
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factomd/common/factoid"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+// SelectionStrategy decides which Factoid addresses of the wallet fund a
+// transaction in FundTransaction.
+type SelectionStrategy string
+
+const (
+	// LargestFirst spends from the addresses with the largest balances
+	// first.
+	LargestFirst SelectionStrategy = "largest-first"
+
+	// MinimizeInputs uses the smallest single address that covers the
+	// transaction, keeping the larger balances whole, and falls back to
+	// LargestFirst.
+	MinimizeInputs SelectionStrategy = "minimize-inputs"
+
+	// Privacy avoids linking addresses: it uses one address picked at random
+	// among those that cover the transaction, never one the transaction pays
+	// to, and falls back to LargestFirst.
+	Privacy SelectionStrategy = "privacy"
+)
+
+var ErrInsufficientFunds = validationErrorf("wallet: The wallet addresses do not hold enough factoids")
+
+// maxFundRounds bounds how often FundTransaction selects again because the
+// fee grew with the inputs it added.
+const maxFundRounds = 4
+
+// fundingCandidate is a Factoid address of the wallet and its balance.
+type fundingCandidate struct {
+	address string
+	balance uint64
+}
+
+// FundTransaction adds inputs from the Factoid addresses of the wallet to the
+// tmp transaction name so that they cover its outputs and the fee at the
+// current rate, chosen with strategy. Inputs already in the transaction are
+// kept and counted. Watch only addresses are never used. Afterwards the
+// inputs add up to exactly the outputs and the fee, so the transaction can be
+// signed as it is.
+func (w *Wallet) FundTransaction(name string, strategy SelectionStrategy) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	switch strategy {
+	case LargestFirst, MinimizeInputs, Privacy:
+	default:
+		return validationErrorf("wallet: Unknown selection strategy %q", strategy)
+	}
+	tx, err := w.GetTransaction(name)
+	if err != nil {
+		return err
+	}
+	rate, err := getRate(w.retry)
+	if err != nil {
+		return err
+	}
+	cands, err := w.fundingCandidates(tx, strategy)
+	if err != nil {
+		return err
+	}
+
+	var fee uint64
+	for round := 0; round < maxFundRounds; round++ {
+		trial, err := copyTransaction(tx)
+		if err != nil {
+			return err
+		}
+		need, err := fundingNeeded(trial, fee)
+		if err != nil {
+			return err
+		}
+		for _, c := range selectInputs(cands, need, strategy) {
+			amount := c.balance
+			if amount > need {
+				amount = need
+			}
+			if err := w.addInput(trial, c.address, amount); err != nil {
+				return err
+			}
+			need -= amount
+		}
+		if need > 0 {
+			return ErrInsufficientFunds
+		}
+
+		// the fee depends on the size of the transaction and so on the
+		// inputs; select again with the larger fee until it is covered
+		f, err := trial.CalculateFee(rate)
+		if err != nil {
+			return err
+		}
+		if f <= fee {
+			return w.saveTransaction(name, trial)
+		}
+		fee = f
+	}
+	return validationErrorf("wallet: Could not settle the fee of the transaction")
+}
+
+// fundingNeeded returns the factoshis that new inputs must add to tx for it
+// to pay its outputs and fee.
+func fundingNeeded(tx *factoid.Transaction, fee uint64) (uint64, error) {
+	ins, err := tx.TotalInputs()
+	if err != nil {
+		return 0, err
+	}
+	outs, err := tx.TotalOutputs()
+	if err != nil {
+		return 0, err
+	}
+	ecs, err := tx.TotalECs()
+	if err != nil {
+		return 0, err
+	}
+	if outs+ecs == 0 {
+		return 0, validationErrorf("wallet: The transaction has no outputs to fund")
+	}
+	if ins >= outs+ecs+fee {
+		return 0, nil
+	}
+	return outs + ecs + fee - ins, nil
+}
+
+// fundingCandidates returns the wallet addresses with a balance that are not
+// inputs of tx yet, largest balance first. With the Privacy strategy the
+// addresses tx pays to are left out as well.
+func (w *Wallet) fundingCandidates(tx *factoid.Transaction, strategy SelectionStrategy) ([]fundingCandidate, error) {
+	fs, err := w.GetAllFCTAddresses()
+	if err != nil {
+		return nil, err
+	}
+
+	skip := make(map[string]bool)
+	for _, in := range tx.GetInputs() {
+		skip[primitives.ConvertFctAddressToUserStr(in.GetAddress())] = true
+	}
+	if strategy == Privacy {
+		for _, out := range tx.GetOutputs() {
+			skip[primitives.ConvertFctAddressToUserStr(out.GetAddress())] = true
+		}
+	}
+
+	var cands []fundingCandidate
+	for _, f := range fs {
+		a := f.String()
+		f.Wipe()
+		if skip[a] {
+			continue
+		}
+		var balance int64
+		if err := w.retry.Do(func() (err error) {
+			balance, err = factom.GetFactoidBalance(a)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		if balance > 0 {
+			cands = append(cands, fundingCandidate{address: a, balance: uint64(balance)})
+		}
+	}
+	sort.Slice(cands, func(i, j int) bool {
+		if cands[i].balance != cands[j].balance {
+			return cands[i].balance > cands[j].balance
+		}
+		return cands[i].address < cands[j].address
+	})
+	return cands, nil
+}
+
+// selectInputs picks the candidates, sorted largest first, that pay need
+// with strategy. The last one picked may only be spent in part. Too few are
+// returned if the candidates do not hold enough.
+func selectInputs(cands []fundingCandidate, need uint64, strategy SelectionStrategy) []fundingCandidate {
+	if need == 0 {
+		return nil
+	}
+
+	var covering []fundingCandidate
+	for _, c := range cands {
+		if c.balance >= need {
+			covering = append(covering, c)
+		}
+	}
+	if len(covering) > 0 {
+		switch strategy {
+		case MinimizeInputs:
+			return covering[len(covering)-1:]
+		case Privacy:
+			i, err := rand.Int(rand.Reader, big.NewInt(int64(len(covering))))
+			if err != nil {
+				break
+			}
+			return covering[i.Int64() : i.Int64()+1]
+		}
+	}
+
+	var picked []fundingCandidate
+	for _, c := range cands {
+		picked = append(picked, c)
+		if c.balance >= need {
+			break
+		}
+		need -= c.balance
+	}
+	return picked
+}
+
+// copyTransaction returns a deep copy of tx.
+func copyTransaction(tx *factoid.Transaction) (*factoid.Transaction, error) {
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	c := new(factoid.Transaction)
+	if err := c.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+func TestFundTransaction(t *testing.T) {
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	balances := make(map[string]int64)
+	var fs []string
+	for _, b := range []int64{5e8, 3e8, 1e8} {
+		f, err := w1.GenerateFCTAddress()
+		if err != nil {
+			t.Fatal(err)
+		}
+		balances[f.String()] = b
+		fs = append(fs, f.String())
+	}
+	to := "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(factom.JSON2Request)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+		params := make(map[string]string)
+		json.Unmarshal(req.Params, &params)
+
+		switch req.Method {
+		case "factoid-balance":
+			fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 0, "result": {"balance": %d}}`, balances[params["address"]])
+		case "entry-credit-rate":
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"rate": 1000}}`)
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+		}
+	}))
+	defer ts.Close()
+	factom.SetFactomdServer(ts.URL[7:])
+
+	// inputs returns the input amounts of the transaction by address and
+	// checks that they pay the outputs and the fee exactly
+	inputs := func(name string) map[string]uint64 {
+		tx, err := w1.GetTransaction(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ins := make(map[string]uint64)
+		for _, in := range tx.GetInputs() {
+			ins[primitives.ConvertFctAddressToUserStr(in.GetAddress())] = in.GetAmount()
+		}
+		total, _ := tx.TotalInputs()
+		outs, _ := tx.TotalOutputs()
+		fee, _ := tx.CalculateFee(1000)
+		if total != outs+fee {
+			t.Errorf("%s: inputs %d do not pay outputs %d and fee %d", name, total, outs, fee)
+		}
+		return ins
+	}
+
+	cases := []struct {
+		name     string
+		amount   uint64
+		strategy SelectionStrategy
+		want     []string
+	}{
+		{"largest", 6e8, LargestFirst, fs[:2]},
+		{"minimize", 2e8, MinimizeInputs, fs[1:2]},
+		{"privacy", 6e8, Privacy, fs[:2]},
+	}
+	for _, c := range cases {
+		if err := w1.NewTransaction(c.name); err != nil {
+			t.Fatal(err)
+		}
+		if err := w1.AddOutput(c.name, to, c.amount); err != nil {
+			t.Fatal(err)
+		}
+		if err := w1.FundTransaction(c.name, c.strategy); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		ins := inputs(c.name)
+		if len(ins) != len(c.want) {
+			t.Errorf("%s: got inputs %v, want %v", c.name, ins, c.want)
+		}
+		for _, a := range c.want {
+			if _, ok := ins[a]; !ok {
+				t.Errorf("%s: %s is not an input", c.name, a)
+			}
+		}
+	}
+
+	if err := w1.NewTransaction("too-much"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddOutput("too-much", to, 9e8); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.FundTransaction("too-much", LargestFirst); err != ErrInsufficientFunds {
+		t.Errorf("expected ErrInsufficientFunds, got %v", err)
+	}
+	if err := w1.FundTransaction("too-much", "biggest"); err == nil {
+		t.Error("unknown strategy was accepted")
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"encoding/gob"
+	"sort"
+
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factomd/common/interfaces"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+var ErrNoSuchContact = validationErrorf("wallet: No such contact")
+
+// Contact is a named Factoid or Entry Credit Address that is not owned by
+// the wallet, such as an exchange deposit address, kept in the address book
+// of the wallet.
+type Contact struct {
+	Name    string
+	Address string
+}
+
+var _ interfaces.BinaryMarshallableAndCopyable = (*Contact)(nil)
+
+// contactData is Contact without its methods, for gob.
+type contactData Contact
+
+func (c *Contact) New() interfaces.BinaryMarshallableAndCopyable {
+	return new(Contact)
+}
+
+func (c *Contact) MarshalBinary() ([]byte, error) {
+	var data primitives.Buffer
+
+	enc := gob.NewEncoder(&data)
+	if err := enc.Encode(contactData(*c)); err != nil {
+		return nil, err
+	}
+	return data.DeepCopyBytes(), nil
+}
+
+func (c *Contact) UnmarshalBinaryData(data []byte) ([]byte, error) {
+	dec := gob.NewDecoder(primitives.NewBuffer(data))
+	if err := dec.Decode((*contactData)(c)); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (c *Contact) UnmarshalBinary(data []byte) error {
+	_, err := c.UnmarshalBinaryData(data)
+	return err
+}
+
+// InsertContact stores a Contact, replacing the address of an existing
+// Contact with the same name.
+func (db *WalletDatabaseOverlay) InsertContact(c *Contact) error {
+	if c == nil {
+		return nil
+	}
+	return dbError("write", db.DBO.Put(contactDBPrefix, []byte(c.Name), c))
+}
+
+func (db *WalletDatabaseOverlay) GetContact(name string) (*Contact, error) {
+	data, err := db.DBO.Get(contactDBPrefix, []byte(name), new(Contact))
+	if err != nil {
+		return nil, dbError("read", err)
+	}
+	if data == nil {
+		return nil, ErrNoSuchContact
+	}
+	return data.(*Contact), nil
+}
+
+// GetAllContacts returns the stored Contacts sorted by name.
+func (db *WalletDatabaseOverlay) GetAllContacts() ([]*Contact, error) {
+	list, err := db.DBO.FetchAllBlocksFromBucket(contactDBPrefix, new(Contact))
+	if err != nil {
+		return nil, dbError("read", err)
+	}
+
+	cs := make([]*Contact, len(list))
+	for i, v := range list {
+		cs[i] = v.(*Contact)
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].Name < cs[j].Name })
+	return cs, nil
+}
+
+func (db *WalletDatabaseOverlay) RemoveContact(name string) error {
+	if _, err := db.GetContact(name); err != nil {
+		return err
+	}
+	return dbError("delete", db.DBO.Delete(contactDBPrefix, []byte(name)))
+}
+
+// AddContact stores the public Factoid or Entry Credit Address address in
+// the address book under name. A name may not itself be an address, so that
+// ResolveAddress is never ambiguous.
+func (w *Wallet) AddContact(name, address string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	if name == "" || factom.AddressStringType(name) != factom.InvalidAddress {
+		return validationErrorf("wallet: Invalid contact name %q", name)
+	}
+	switch factom.AddressStringType(address) {
+	case factom.FactoidPub, factom.ECPub:
+	default:
+		return validationErrorf("wallet: %s is not a public address", address)
+	}
+	return w.InsertContact(&Contact{Name: name, Address: address})
+}
+
+// RemoveContact deletes the Contact with the given name.
+func (w *Wallet) RemoveContact(name string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	return w.WalletDatabaseOverlay.RemoveContact(name)
+}
+
+// ResolveAddress returns s if it is an address, or else the address of the
+// Contact named s.
+func (w *Wallet) ResolveAddress(s string) (string, error) {
+	if factom.AddressStringType(s) != factom.InvalidAddress {
+		return s, nil
+	}
+	c, err := w.GetContact(s)
+	if err != nil {
+		return "", err
+	}
+	return c.Address, nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestContacts(t *testing.T) {
+	fa := "FA1zT4aFpEvcnPqPCigB3fvGu4Q4mTXY22iiuV69DqE1pNhdF2MC"
+	ec := "EC2DKSYyRcNWf7RS963VFYgMExoHRYLHVeCfQ9PGPmNzwrcmgm2r"
+
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	if err := w1.AddContact("exchange", fa); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddContact("entries", ec); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddContact(ec, fa); !errors.Is(err, factom.ErrValidation) {
+		t.Errorf("expected a validation error for an address as a name, got %v", err)
+	}
+	if err := w1.AddContact("bad", "FA1"); !errors.Is(err, factom.ErrValidation) {
+		t.Errorf("expected a validation error for a bad address, got %v", err)
+	}
+
+	cs, err := w1.GetAllContacts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 2 || cs[0].Name != "entries" || cs[1].Address != fa {
+		t.Errorf("wrong contacts %v", cs)
+	}
+
+	if a, err := w1.ResolveAddress("exchange"); err != nil || a != fa {
+		t.Errorf("expected %s, got %s %v", fa, a, err)
+	}
+	if a, err := w1.ResolveAddress(ec); err != nil || a != ec {
+		t.Errorf("expected %s, got %s %v", ec, a, err)
+	}
+
+	if err := w1.RemoveContact("exchange"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w1.ResolveAddress("exchange"); err != ErrNoSuchContact {
+		t.Errorf("expected ErrNoSuchContact, got %v", err)
+	}
+}
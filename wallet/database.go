@@ -6,7 +6,9 @@ package wallet
 
 import (
 	"fmt"
+	"log"
 	"sync"
+	"time"
 
 	"github.com/FactomProject/factom"
 	"github.com/FactomProject/factomd/common/factoid"
@@ -19,10 +21,32 @@ type Wallet struct {
 	DBPath       string
 	txlock       sync.Mutex
 	transactions map[string]*factoid.Transaction
+	txLoaded     bool
+	txModified   map[string]time.Time
+	txTTL        time.Duration
 	txdb         *TXDatabaseOverlay
+	logger       *log.Logger
+	retry        *RetryPolicy
+	readOnly     bool
+	signer       Signer
+	events       eventBus
+	dbObserver   DBObserver
+	dbType       string
+	auditLock    sync.Mutex
+	auditHead    *AuditEntry
+
+	// approvals holds the signature hashes of the approved tmp transactions
+	// by name
+	approvalThreshold uint64
+	approvals         map[string]string
 }
 
 func (w *Wallet) InitWallet() error {
+	// A wallet encrypted with Encrypt already has its seed, which can not be
+	// read until it is unlocked.
+	if sealed, err := w.openSealed(); err != nil || sealed {
+		return err
+	}
 	dbSeed, err := w.GetOrCreateDBSeed()
 	if err != nil {
 		return err
@@ -34,8 +58,7 @@ func (w *Wallet) InitWallet() error {
 }
 
 func NewOrOpenLevelDBWallet(path string) (*Wallet, error) {
-	w := new(Wallet)
-	w.transactions = make(map[string]*factoid.Transaction)
+	w := newWallet()
 	db, err := NewLevelDB(path)
 	if err != nil {
 		return nil, err
@@ -45,12 +68,13 @@ func NewOrOpenLevelDBWallet(path string) (*Wallet, error) {
 	if err != nil {
 		return nil, err
 	}
+	w.dbType = levelDBBackend
+	w.DBPath = path
 	return w, nil
 }
 
 func NewOrOpenBoltDBWallet(path string) (*Wallet, error) {
-	w := new(Wallet)
-	w.transactions = make(map[string]*factoid.Transaction)
+	w := newWallet()
 	db, err := NewBoltDB(path)
 	if err != nil {
 		return nil, err
@@ -60,24 +84,55 @@ func NewOrOpenBoltDBWallet(path string) (*Wallet, error) {
 	if err != nil {
 		return nil, err
 	}
+	w.dbType = boltDBBackend
+	w.DBPath = path
+	return w, nil
+}
+
+// NewOrOpenSQLiteWallet creates or opens a wallet in the SQLite database file
+// at path. See NewSQLiteStore for the driver it needs.
+func NewOrOpenSQLiteWallet(path string) (*Wallet, error) {
+	s, err := NewSQLiteStore(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewOrOpenStoreWallet(s)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	w.dbType = sqliteBackend
+	w.DBPath = path
+	return w, nil
+}
+
+// NewOrOpenStoreWallet creates or opens a wallet kept in s, which is closed
+// with the wallet.
+func NewOrOpenStoreWallet(s WalletStore) (*Wallet, error) {
+	w := newWallet()
+	w.WalletDatabaseOverlay = newStoreOverlay(s)
+	err := w.InitWallet()
+	if err != nil {
+		return nil, err
+	}
+	w.dbType = storeBackend
 	return w, nil
 }
 
 func NewMapDBWallet() (*Wallet, error) {
-	w := new(Wallet)
-	w.transactions = make(map[string]*factoid.Transaction)
+	w := newWallet()
 	db := NewMapDB()
 	w.WalletDatabaseOverlay = db
 	err := w.InitWallet()
 	if err != nil {
 		return nil, err
 	}
+	w.dbType = mapDBBackend
 	return w, nil
 }
 
 func NewEncryptedBoltDBWallet(path, password string) (*Wallet, error) {
-	w := new(Wallet)
-	w.transactions = make(map[string]*factoid.Transaction)
+	w := newWallet()
 	db, err := NewEncryptedBoltDB(path, password)
 	if err != nil {
 		return nil, err
@@ -89,28 +144,50 @@ func NewEncryptedBoltDBWallet(path, password string) (*Wallet, error) {
 	}
 	w.Encrypted = true
 	w.DBPath = path
+	w.dbType = boltDBBackend
 	return w, nil
 }
 
 func NewEncryptedBoltDBWalletAwaitingPassphrase(path string) (*Wallet, error) {
-	w := new(Wallet)
-	w.transactions = make(map[string]*factoid.Transaction)
+	w := newWallet()
 	w.Encrypted = true
 	w.DBPath = path
+	w.dbType = boltDBBackend
 	return w, nil
 }
 
-// Close closes a Factom Wallet Database
+// StorageInfo describes the database a wallet is stored in.
+type StorageInfo struct {
+	// Type is "map", "level", "bolt", "sqlite" or "store" for a wallet
+	// opened with NewOrOpenStoreWallet.
+	Type      string `json:"type"`
+	Path      string `json:"path,omitempty"`
+	Encrypted bool   `json:"encrypted"`
+}
+
+// Storage describes the database of the wallet.
+func (w *Wallet) Storage() StorageInfo {
+	return StorageInfo{Type: w.dbType, Path: w.DBPath, Encrypted: w.Encrypted}
+}
+
+// Close closes a Factom Wallet Database. The key of a wallet encrypted with
+// Encrypt is overwritten first.
 func (w *Wallet) Close() error {
 	if w.WalletDatabaseOverlay == nil {
 		return nil
 	}
+	if db, ok := w.sealedDB(); ok {
+		db.lock()
+	}
 	return w.DBO.Close()
 }
 
 // AddTXDB allows the wallet api to read from a local transaction cashe.
 func (w *Wallet) AddTXDB(t *TXDatabaseOverlay) {
 	w.txdb = t
+	if w.retry != nil {
+		t.SetRetryPolicy(w.retry)
+	}
 }
 
 func (w *Wallet) TXDB() *TXDatabaseOverlay {
@@ -118,20 +195,122 @@ func (w *Wallet) TXDB() *TXDatabaseOverlay {
 }
 
 // GenerateECAddress creates and stores a new Entry Credit Address in the
-// Wallet. The address can be reproduced in the future using the Wallet Seed.
+// Wallet. The address can be reproduced in the future using the Wallet Seed,
+// unless it was created by a KeyGenerator set with SetSigner.
 func (w *Wallet) GenerateECAddress() (*factom.ECAddress, error) {
-	return w.GetNextECAddress()
+	if w.readOnly {
+		return nil, ErrReadOnly
+	}
+	var (
+		a   *factom.ECAddress
+		err error
+	)
+	if g, ok := w.keyGenerator(); ok {
+		var as []*factom.ECAddress
+		if as, err = w.generateSignerECAddresses(g, 1); err == nil {
+			a = as[0]
+		}
+	} else {
+		a, err = w.GetNextECAddress()
+	}
+	if err != nil {
+		return nil, err
+	}
+	w.publish(&Event{Type: EventAddressGenerated, Address: a.PubString()})
+	return a, nil
 }
 
 // GenerateFCTAddress creates and stores a new Factoid Address in the Wallet.
-// The address can be reproduced in the future using the Wallet Seed.
+// The address can be reproduced in the future using the Wallet Seed, unless
+// it was created by a KeyGenerator set with SetSigner.
 func (w *Wallet) GenerateFCTAddress() (*factom.FactoidAddress, error) {
-	return w.GetNextFCTAddress()
+	if w.readOnly {
+		return nil, ErrReadOnly
+	}
+	var (
+		a   *factom.FactoidAddress
+		err error
+	)
+	if g, ok := w.keyGenerator(); ok {
+		var as []*factom.FactoidAddress
+		if as, err = w.generateSignerFCTAddresses(g, 1); err == nil {
+			a = as[0]
+		}
+	} else {
+		a, err = w.GetNextFCTAddress()
+	}
+	if err != nil {
+		return nil, err
+	}
+	w.publish(&Event{Type: EventAddressGenerated, Address: a.String()})
+	return a, nil
+}
+
+// GenerateFCTAddresses creates and stores n new Factoid Addresses in the
+// Wallet in a single database write.
+func (w *Wallet) GenerateFCTAddresses(n int) ([]*factom.FactoidAddress, error) {
+	if w.readOnly {
+		return nil, ErrReadOnly
+	}
+	if n < 1 {
+		return nil, validationErrorf("wallet: Invalid address count %d", n)
+	}
+	var (
+		as  []*factom.FactoidAddress
+		err error
+	)
+	if g, ok := w.keyGenerator(); ok {
+		as, err = w.generateSignerFCTAddresses(g, n)
+	} else {
+		as, err = w.GetNextFCTAddresses(n)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range as {
+		w.publish(&Event{Type: EventAddressGenerated, Address: a.String()})
+	}
+	return as, nil
+}
+
+// GenerateECAddresses creates and stores n new Entry Credit Addresses in the
+// Wallet in a single database write.
+func (w *Wallet) GenerateECAddresses(n int) ([]*factom.ECAddress, error) {
+	if w.readOnly {
+		return nil, ErrReadOnly
+	}
+	if n < 1 {
+		return nil, validationErrorf("wallet: Invalid address count %d", n)
+	}
+	var (
+		as  []*factom.ECAddress
+		err error
+	)
+	if g, ok := w.keyGenerator(); ok {
+		as, err = w.generateSignerECAddresses(g, n)
+	} else {
+		as, err = w.GetNextECAddresses(n)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range as {
+		w.publish(&Event{Type: EventAddressGenerated, Address: a.PubString()})
+	}
+	return as, nil
 }
 
 // GenerateIdentityKey creates and stores a new Identity Key in the Wallet.
 func (w *Wallet) GenerateIdentityKey() (*factom.IdentityKey, error) {
-	return w.GetNextIdentityKey()
+	if w.readOnly {
+		return nil, ErrReadOnly
+	}
+	a, err := w.GetNextIdentityKey()
+	if err != nil {
+		return nil, err
+	}
+	w.publish(&Event{Type: EventAddressGenerated, Address: a.PubString()})
+	return a, nil
 }
 
 // GetAllAddresses retrieves all Entry Credit and Factoid Addresses from the
@@ -154,6 +333,9 @@ func (w *Wallet) GetAllAddresses() ([]*factom.FactoidAddress, []*factom.ECAddres
 // generated by the wallet. Note that Addresses that are imported into the
 // Wallet cannot be regenerated using the Wallet Seed.
 func (w *Wallet) GetSeed() (string, error) {
+	if w.readOnly {
+		return "", ErrReadOnly
+	}
 	seed, err := w.GetDBSeed()
 	if err != nil {
 		return "", err
@@ -10,6 +10,7 @@ import (
 
 	"github.com/FactomProject/factom"
 	. "github.com/FactomProject/factom/wallet"
+	"github.com/FactomProject/go-bip32"
 )
 
 func TestNewWallet(t *testing.T) {
@@ -350,3 +351,97 @@ func TestGetAllIdentityKeys(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestRemoveAddresses(t *testing.T) {
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	f, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := w1.GenerateECAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w1.RemoveFCTAddress(e.String()); err == nil {
+		t.Error("removed an ec address as a factoid address")
+	}
+	rf, err := w1.RemoveFCTAddress(f.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rf.SecString() != f.SecString() {
+		t.Errorf("wrong backup %s", rf.SecString())
+	}
+	re, err := w1.RemoveECAddress(e.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if re.SecString() != e.SecString() {
+		t.Errorf("wrong backup %s", re.SecString())
+	}
+
+	fs, es, err := w1.GetAllAddresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fs) != 0 || len(es) != 0 {
+		t.Errorf("addresses were not removed: %v %v", fs, es)
+	}
+	if _, err := w1.RemoveFCTAddress(f.String()); err != ErrNoSuchAddress {
+		t.Errorf("expected ErrNoSuchAddress, got %v", err)
+	}
+}
+
+func TestGenerateAddresses(t *testing.T) {
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	fs, err := w1.GenerateFCTAddresses(25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	es, err := w1.GenerateECAddresses(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fs) != 25 || len(es) != 3 {
+		t.Fatalf("generated %d factoid and %d ec addresses", len(fs), len(es))
+	}
+	if _, err := w1.GenerateFCTAddresses(0); err == nil {
+		t.Error("expected an error for a count of 0")
+	}
+
+	// the seed continues after the batch
+	seed, err := w1.GetSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := factom.MakeBIP44FactoidAddress(seed, bip32.FirstHardenedChild, 0, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.String() != want.String() {
+		t.Errorf("next address is %s, want %s", next, want)
+	}
+
+	all, _, err := w1.GetAllAddresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 26 {
+		t.Errorf("expected 26 addresses, found %d", len(all))
+	}
+}
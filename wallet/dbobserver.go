@@ -0,0 +1,79 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"time"
+
+	"github.com/FactomProject/factomd/common/interfaces"
+	"github.com/FactomProject/factomd/database/securedb"
+)
+
+// DBObserver is told the name, duration and result of wallet database
+// operations, for example to export them as metrics.
+type DBObserver func(op string, d time.Duration, err error)
+
+// observedDB times the reads and writes of the database it wraps.
+type observedDB struct {
+	interfaces.IDatabase
+	observe DBObserver
+}
+
+func (db *observedDB) Put(bucket, key []byte, data interfaces.BinaryMarshallable) error {
+	start := time.Now()
+	err := db.IDatabase.Put(bucket, key, data)
+	db.observe("put", time.Since(start), err)
+	return err
+}
+
+func (db *observedDB) Get(bucket, key []byte, destination interfaces.BinaryMarshallable) (interfaces.BinaryMarshallable, error) {
+	start := time.Now()
+	data, err := db.IDatabase.Get(bucket, key, destination)
+	db.observe("get", time.Since(start), err)
+	return data, err
+}
+
+func (db *observedDB) Delete(bucket, key []byte) error {
+	start := time.Now()
+	err := db.IDatabase.Delete(bucket, key)
+	db.observe("delete", time.Since(start), err)
+	return err
+}
+
+func (db *observedDB) GetAll(bucket []byte, sample interfaces.BinaryMarshallableAndCopyable) ([]interfaces.BinaryMarshallableAndCopyable, [][]byte, error) {
+	start := time.Now()
+	data, keys, err := db.IDatabase.GetAll(bucket, sample)
+	db.observe("get-all", time.Since(start), err)
+	return data, keys, err
+}
+
+// ObserveDB reports every read and write of the wallet database to f. An
+// encrypted wallet that has not been unlocked yet is observed once it is
+// opened. It should be called before the wallet is in use.
+func (w *Wallet) ObserveDB(f DBObserver) {
+	w.dbObserver = f
+	w.observeDB()
+}
+
+func (w *Wallet) observeDB() {
+	if w.dbObserver == nil || w.WalletDatabaseOverlay == nil {
+		return
+	}
+	if db, ok := w.DBO.DB.(*observedDB); ok {
+		db.observe = w.dbObserver
+		return
+	}
+	w.DBO.DB = &observedDB{IDatabase: w.DBO.DB, observe: w.dbObserver}
+}
+
+// encryptedDB returns the encrypted database of the wallet, if it has one.
+func (w *Wallet) encryptedDB() (*securedb.EncryptedDB, bool) {
+	db := w.DBO.DB
+	if o, ok := db.(*observedDB); ok {
+		db = o.IDatabase
+	}
+	encdb, ok := db.(*securedb.EncryptedDB)
+	return encdb, ok
+}
@@ -0,0 +1,38 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestObserveDB(t *testing.T) {
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	ops := make(map[string]int)
+	w1.ObserveDB(func(op string, d time.Duration, err error) {
+		if err != nil {
+			t.Errorf("%s failed: %v", op, err)
+		}
+		ops[op]++
+	})
+
+	if _, err := w1.GenerateFCTAddress(); err != nil {
+		t.Fatal(err)
+	}
+	if ops["get"] == 0 || ops["put"] == 0 {
+		t.Errorf("database operations were not observed: %v", ops)
+	}
+	if w1.IsLocked() {
+		t.Error("an unencrypted wallet reports being locked")
+	}
+}
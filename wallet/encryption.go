@@ -0,0 +1,488 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/gob"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factomd/common/interfaces"
+	"github.com/FactomProject/factomd/common/primitives"
+	"golang.org/x/crypto/argon2"
+)
+
+// The argon2id parameters of newly encrypted wallets. They are stored in the
+// encryption header so they can be raised without breaking older wallets.
+var (
+	argon2Time    uint32 = 3
+	argon2Memory  uint32 = 64 * 1024
+	argon2Threads uint8  = 4
+)
+
+var (
+	encryptionDBPrefix = []byte("Wallet Encryption")
+	encryptionDBKey    = []byte("header")
+
+	// encryptionCheck is sealed into the header to tell a wrong passphrase
+	// from a damaged record.
+	encryptionCheck = []byte("factom wallet")
+
+	errSealedRecord = errors.New("wallet: A database record could not be decrypted")
+)
+
+// encryptionHeader is stored unencrypted in the wallet database of a wallet
+// encrypted with Encrypt. It holds what is needed to derive the key from the
+// passphrase.
+type encryptionHeader struct {
+	Version int
+	Salt    []byte
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+	Check   []byte
+}
+
+// encryptionHeaderData is encryptionHeader without its methods, for gob.
+type encryptionHeaderData encryptionHeader
+
+func (h *encryptionHeader) MarshalBinary() ([]byte, error) {
+	var data primitives.Buffer
+
+	enc := gob.NewEncoder(&data)
+	if err := enc.Encode(encryptionHeaderData(*h)); err != nil {
+		return nil, err
+	}
+	return data.DeepCopyBytes(), nil
+}
+
+func (h *encryptionHeader) UnmarshalBinaryData(data []byte) ([]byte, error) {
+	dec := gob.NewDecoder(primitives.NewBuffer(data))
+	if err := dec.Decode((*encryptionHeaderData)(h)); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (h *encryptionHeader) UnmarshalBinary(data []byte) error {
+	_, err := h.UnmarshalBinaryData(data)
+	return err
+}
+
+// key derives the key of the header from passphrase.
+func (h *encryptionHeader) key(passphrase string) factom.SecureBytes {
+	return factom.SecureBytes(argon2.IDKey([]byte(passphrase), h.Salt, h.Time, h.Memory, h.Threads, 32))
+}
+
+// newAEAD returns the AES-GCM cipher of key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// newEncryptionHeader returns a header with a new salt for passphrase and the
+// key derived from it, which the caller wipes.
+func newEncryptionHeader(passphrase string) (*encryptionHeader, factom.SecureBytes, error) {
+	h := &encryptionHeader{
+		Version: 1,
+		Salt:    make([]byte, 32),
+		Time:    argon2Time,
+		Memory:  argon2Memory,
+		Threads: argon2Threads,
+	}
+	if _, err := rand.Read(h.Salt); err != nil {
+		return nil, nil, err
+	}
+	key := h.key(passphrase)
+	aead, err := newAEAD(key)
+	if err != nil {
+		key.Wipe()
+		return nil, nil, err
+	}
+	if h.Check, err = seal(aead, encryptionCheck, encryptionDBKey); err != nil {
+		key.Wipe()
+		return nil, nil, err
+	}
+	return h, key, nil
+}
+
+// open returns the key of the header, which the caller wipes, if passphrase
+// is right and ErrIncorrectPassphrase if it is not.
+func (h *encryptionHeader) open(passphrase string) (factom.SecureBytes, error) {
+	key := h.key(passphrase)
+	aead, err := newAEAD(key)
+	if err != nil {
+		key.Wipe()
+		return nil, err
+	}
+	if _, err := open(aead, h.Check, encryptionDBKey); err != nil {
+		key.Wipe()
+		return nil, ErrIncorrectPassphrase
+	}
+	return key, nil
+}
+
+// rawRecord is a database value that is copied without being decoded.
+type rawRecord struct {
+	data []byte
+}
+
+var _ interfaces.BinaryMarshallableAndCopyable = (*rawRecord)(nil)
+
+func (r *rawRecord) New() interfaces.BinaryMarshallableAndCopyable {
+	return new(rawRecord)
+}
+
+func (r *rawRecord) MarshalBinary() ([]byte, error) {
+	return r.data, nil
+}
+
+func (r *rawRecord) UnmarshalBinaryData(data []byte) ([]byte, error) {
+	r.data = append([]byte(nil), data...)
+	return nil, nil
+}
+
+func (r *rawRecord) UnmarshalBinary(data []byte) error {
+	_, err := r.UnmarshalBinaryData(data)
+	return err
+}
+
+// sealedDB encrypts every value written to the database it wraps with
+// AES-GCM, authenticating it with its bucket and key so records can not be
+// swapped. Bucket and key names are stored as they are. Reads and writes
+// fail with ErrWalletLocked until the passphrase is given to unlock.
+//
+// Only the key is kept while the database is unlocked, and lock overwrites it.
+// The AES key schedule of a cipher lives inside crypto/aes where it can not be
+// wiped, so a cipher is made for each operation instead of being kept.
+type sealedDB struct {
+	interfaces.IDatabase
+	header *encryptionHeader
+
+	mu    sync.RWMutex
+	key   factom.SecureBytes
+	until time.Time
+}
+
+// recordData is the additional data a record is sealed with.
+func recordData(bucket, key []byte) []byte {
+	ad := make([]byte, 0, len(bucket)+len(key)+1)
+	ad = append(ad, bucket...)
+	ad = append(ad, 0)
+	return append(ad, key...)
+}
+
+func seal(aead cipher.AEAD, plain, ad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plain, ad), nil
+}
+
+func open(aead cipher.AEAD, sealed, ad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errSealedRecord
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], ad)
+	if err != nil {
+		return nil, errSealedRecord
+	}
+	return plain, nil
+}
+
+// unlocked returns the cipher of the database or ErrWalletLocked. The caller
+// must hold db.mu.
+func (db *sealedDB) unlocked() (cipher.AEAD, error) {
+	if db.key == nil || time.Now().After(db.until) {
+		return nil, ErrWalletLocked
+	}
+	return newAEAD(db.key)
+}
+
+func (db *sealedDB) locked() bool {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	_, err := db.unlocked()
+	return err != nil
+}
+
+// unlockFor checks passphrase against the header and keeps the key for the
+// duration d.
+func (db *sealedDB) unlockFor(passphrase string, d time.Duration) (time.Time, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	key, err := db.header.open(passphrase)
+	if err != nil {
+		return time.Time{}, err
+	}
+	db.key.Wipe()
+	db.key = key
+	db.until = time.Now().Add(d)
+
+	// the key is overwritten when the unlock runs out, unless the database
+	// was unlocked again in the meantime
+	until := db.until
+	time.AfterFunc(d, func() {
+		db.mu.Lock()
+		defer db.mu.Unlock()
+		if db.until.Equal(until) {
+			db.wipe()
+		}
+	})
+	return db.until, nil
+}
+
+// lock overwrites the key of the database and drops it.
+func (db *sealedDB) lock() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wipe()
+}
+
+// wipe is lock. The caller holds db.mu.
+func (db *sealedDB) wipe() {
+	db.key.Wipe()
+	db.key = nil
+	db.until = time.Time{}
+}
+
+func (db *sealedDB) sealRecord(aead cipher.AEAD, bucket, key []byte, data interfaces.BinaryMarshallable) (*rawRecord, error) {
+	plain, err := data.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := seal(aead, plain, recordData(bucket, key))
+	if err != nil {
+		return nil, err
+	}
+	return &rawRecord{data: sealed}, nil
+}
+
+func (db *sealedDB) Put(bucket, key []byte, data interfaces.BinaryMarshallable) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	aead, err := db.unlocked()
+	if err != nil {
+		return err
+	}
+	r, err := db.sealRecord(aead, bucket, key, data)
+	if err != nil {
+		return err
+	}
+	return db.IDatabase.Put(bucket, key, r)
+}
+
+func (db *sealedDB) PutInBatch(records []interfaces.Record) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	aead, err := db.unlocked()
+	if err != nil {
+		return err
+	}
+	sealed := make([]interfaces.Record, len(records))
+	for i, rec := range records {
+		r, err := db.sealRecord(aead, rec.Bucket, rec.Key, rec.Data)
+		if err != nil {
+			return err
+		}
+		sealed[i] = interfaces.Record{Bucket: rec.Bucket, Key: rec.Key, Data: r}
+	}
+	return db.IDatabase.PutInBatch(sealed)
+}
+
+func (db *sealedDB) Get(bucket, key []byte, destination interfaces.BinaryMarshallable) (interfaces.BinaryMarshallable, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	aead, err := db.unlocked()
+	if err != nil {
+		return nil, err
+	}
+	data, err := db.IDatabase.Get(bucket, key, new(rawRecord))
+	if err != nil || data == nil {
+		return nil, err
+	}
+	plain, err := open(aead, data.(*rawRecord).data, recordData(bucket, key))
+	if err != nil {
+		return nil, err
+	}
+	if err := destination.UnmarshalBinary(plain); err != nil {
+		return nil, err
+	}
+	return destination, nil
+}
+
+func (db *sealedDB) GetAll(bucket []byte, sample interfaces.BinaryMarshallableAndCopyable) ([]interfaces.BinaryMarshallableAndCopyable, [][]byte, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	aead, err := db.unlocked()
+	if err != nil {
+		return nil, nil, err
+	}
+	data, keys, err := db.IDatabase.GetAll(bucket, new(rawRecord))
+	if err != nil {
+		return nil, nil, err
+	}
+	all := make([]interfaces.BinaryMarshallableAndCopyable, len(data))
+	for i := range data {
+		plain, err := open(aead, data[i].(*rawRecord).data, recordData(bucket, keys[i]))
+		if err != nil {
+			return nil, nil, err
+		}
+		all[i] = sample.New()
+		if err := all[i].UnmarshalBinary(plain); err != nil {
+			return nil, nil, err
+		}
+	}
+	return all, keys, nil
+}
+
+func (db *sealedDB) Delete(bucket, key []byte) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if _, err := db.unlocked(); err != nil {
+		return err
+	}
+	return db.IDatabase.Delete(bucket, key)
+}
+
+func (db *sealedDB) Clear(bucket []byte) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if _, err := db.unlocked(); err != nil {
+		return err
+	}
+	return db.IDatabase.Clear(bucket)
+}
+
+// readEncryptionHeader returns the encryption header of db or nil if the
+// database is not encrypted.
+func readEncryptionHeader(db interfaces.IDatabase) (*encryptionHeader, error) {
+	data, err := db.Get(encryptionDBPrefix, encryptionDBKey, new(encryptionHeader))
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return data.(*encryptionHeader), nil
+}
+
+// rawDB returns the database of the wallet below any observer.
+func (w *Wallet) rawDB() interfaces.IDatabase {
+	if o, ok := w.DBO.DB.(*observedDB); ok {
+		return o.IDatabase
+	}
+	return w.DBO.DB
+}
+
+// setRawDB replaces the database of the wallet below any observer.
+func (w *Wallet) setRawDB(db interfaces.IDatabase) {
+	if o, ok := w.DBO.DB.(*observedDB); ok {
+		o.IDatabase = db
+		return
+	}
+	w.DBO.DB = db
+}
+
+// sealedDB returns the database of a wallet encrypted with Encrypt.
+func (w *Wallet) sealedDB() (*sealedDB, bool) {
+	if w.WalletDatabaseOverlay == nil {
+		return nil, false
+	}
+	db, ok := w.rawDB().(*sealedDB)
+	return db, ok
+}
+
+// openSealed reports whether the wallet database was encrypted with Encrypt
+// and if so wraps it so it can be unlocked.
+func (w *Wallet) openSealed() (bool, error) {
+	h, err := readEncryptionHeader(w.rawDB())
+	if err != nil {
+		return false, dbError("get", err)
+	}
+	if h == nil {
+		return false, nil
+	}
+
+	// a Rekey may have been interrupted, which can change the header
+	if err := finishRekey(w.rawDB()); err != nil {
+		return false, err
+	}
+	if h, err = readEncryptionHeader(w.rawDB()); err != nil {
+		return false, dbError("get", err)
+	}
+	w.setRawDB(&sealedDB{IDatabase: w.rawDB(), header: h})
+	w.Encrypted = true
+	return true, nil
+}
+
+// Encrypt encrypts every record of an unencrypted wallet database with a key
+// derived from passphrase with argon2id. The records are rewritten in a
+// single batch. The wallet is locked afterwards and must be unlocked with the
+// passphrase before it can be used, also when it is opened again.
+func (w *Wallet) Encrypt(passphrase string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	if w.Encrypted {
+		return validationErrorf("wallet: The wallet is already encrypted")
+	}
+	if passphrase == "" {
+		return validationErrorf("wallet: An encrypted wallet needs a passphrase")
+	}
+
+	h, key, err := newEncryptionHeader(passphrase)
+	if err != nil {
+		return err
+	}
+	defer key.Wipe()
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	raw := w.rawDB()
+	db := &sealedDB{IDatabase: raw, header: h}
+	buckets, err := raw.ListAllBuckets()
+	if err != nil {
+		return dbError("list", err)
+	}
+	var records []interfaces.Record
+	for _, b := range buckets {
+		data, keys, err := raw.GetAll(b, new(rawRecord))
+		if err != nil {
+			return dbError("get-all", err)
+		}
+		for i := range data {
+			r, err := db.sealRecord(aead, b, keys[i], data[i])
+			if err != nil {
+				return err
+			}
+			records = append(records, interfaces.Record{Bucket: b, Key: keys[i], Data: r})
+		}
+	}
+	records = append(records, interfaces.Record{Bucket: encryptionDBPrefix, Key: encryptionDBKey, Data: h})
+	if err := raw.PutInBatch(records); err != nil {
+		return dbError("put-batch", err)
+	}
+
+	w.setRawDB(db)
+	w.Encrypted = true
+	w.logf("encrypted wallet database")
+	return nil
+}
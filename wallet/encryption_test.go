@@ -0,0 +1,147 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestEncrypt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "encrypt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dbpath := filepath.Join(dir, "wallet.db")
+
+	w1, err := New(WithLevelDB(dbpath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed, err := w1.GetSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w1.Encrypt(""); !errors.Is(err, factom.ErrValidation) {
+		t.Errorf("expected a validation error for an empty passphrase, got %v", err)
+	}
+	if err := w1.Encrypt("passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.Encrypt("passphrase"); !errors.Is(err, factom.ErrValidation) {
+		t.Errorf("expected a validation error for an encrypted wallet, got %v", err)
+	}
+	if !w1.IsLocked() || !w1.Storage().Encrypted {
+		t.Errorf("encrypted wallet is not locked")
+	}
+	if _, err := w1.GetSeed(); !errors.Is(err, ErrWalletLocked) {
+		t.Errorf("expected ErrWalletLocked, got %v", err)
+	}
+	if _, err := w1.Unlock("wrong", time.Minute); err != ErrIncorrectPassphrase {
+		t.Errorf("expected ErrIncorrectPassphrase, got %v", err)
+	}
+	w1.Close()
+
+	// the reopened wallet starts locked and decrypts its records once it is
+	// unlocked
+	w2, err := New(WithLevelDB(dbpath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w2.Close()
+	if !w2.IsLocked() {
+		t.Errorf("reopened wallet is not locked")
+	}
+	if _, err := w2.Unlock("passphrase", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if w2.IsLocked() {
+		t.Errorf("unlocked wallet is locked")
+	}
+	if s, err := w2.GetSeed(); err != nil || s != seed {
+		t.Errorf("wrong seed after unlocking %q %v", s, err)
+	}
+	if a, err := w2.GetFCTAddress(f.String()); err != nil || a.SecString() != f.SecString() {
+		t.Errorf("wrong address after unlocking %v %v", a, err)
+	}
+	if _, err := w2.GenerateECAddress(); err != nil {
+		t.Error(err)
+	}
+
+	// locking before the timeout drops the key
+	if err := w2.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	if !w2.IsLocked() {
+		t.Errorf("wallet is not locked after Lock")
+	}
+	if _, err := w2.GetSeed(); !errors.Is(err, ErrWalletLocked) {
+		t.Errorf("expected ErrWalletLocked, got %v", err)
+	}
+
+	// the key dropped when a short unlock runs out is not the key of a later
+	// unlock
+	if _, err := w2.Unlock("passphrase", 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w2.Unlock("passphrase", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if s, err := w2.GetSeed(); err != nil || s != seed {
+		t.Errorf("wrong seed after unlocking again %q %v", s, err)
+	}
+}
+
+func TestRekey(t *testing.T) {
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+	seed, err := w1.GetSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w1.Rekey("old", "new"); err != ErrNotEncrypted {
+		t.Errorf("expected ErrNotEncrypted, got %v", err)
+	}
+	if err := w1.Encrypt("old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.Rekey("wrong", "new"); err != ErrIncorrectPassphrase {
+		t.Errorf("expected ErrIncorrectPassphrase, got %v", err)
+	}
+	if err := w1.Rekey("old", ""); !errors.Is(err, factom.ErrValidation) {
+		t.Errorf("expected a validation error for an empty passphrase, got %v", err)
+	}
+	if err := w1.Rekey("old", "new"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w1.Unlock("old", time.Minute); err != ErrIncorrectPassphrase {
+		t.Errorf("expected ErrIncorrectPassphrase for the old passphrase, got %v", err)
+	}
+	if _, err := w1.Unlock("new", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := w1.GetSeed(); err != nil || s != seed {
+		t.Errorf("wrong seed after rekey %q %v", s, err)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/FactomProject/factom"
+)
+
+// DBError is returned when the wallet database could not be read or written.
+// It unwraps to the error returned by the underlying database.
+type DBError struct {
+	Op  string
+	Err error
+}
+
+func (e *DBError) Error() string {
+	return fmt.Sprintf("wallet: %s: %s", e.Op, e.Err)
+}
+
+func (e *DBError) Unwrap() error {
+	return e.Err
+}
+
+// dbError wraps err from the database operation op in a *DBError. It returns
+// nil if err is nil.
+func dbError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &DBError{Op: op, Err: err}
+}
+
+// validationError is returned for bad input to the wallet, such as an unknown
+// address or transaction name. It matches factom.ErrValidation so that callers
+// can handle input errors from the wallet and the factom package the same way.
+type validationError struct {
+	msg string
+}
+
+func (e *validationError) Error() string {
+	return e.msg
+}
+
+func (e *validationError) Is(target error) bool {
+	return target == factom.ErrValidation
+}
+
+func validationErrorf(format string, a ...interface{}) error {
+	return &validationError{msg: fmt.Sprintf(format, a...)}
+}
@@ -0,0 +1,91 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType is the kind of an Event.
+type EventType string
+
+const (
+	// EventAddressGenerated is sent when a new address or identity key is
+	// generated from the wallet seed.
+	EventAddressGenerated EventType = "address-generated"
+	// EventTransactionSigned is sent when a transaction is signed.
+	EventTransactionSigned EventType = "transaction-signed"
+	// EventTransactionComposed is sent when a transaction is composed for
+	// submission to factomd.
+	EventTransactionComposed EventType = "transaction-composed"
+	// EventTransactionSent is sent when factomd accepts a transaction sent
+	// with SendTransaction.
+	EventTransactionSent EventType = "transaction-sent"
+	// EventBalanceChanged is sent when a Watcher finds a payment to an
+	// address.
+	EventBalanceChanged EventType = "balance-changed"
+)
+
+// eventBuffer is the number of events kept for a subscriber that is not
+// reading. Further events are dropped until it catches up.
+const eventBuffer = 64
+
+// Event is a change in the wallet sent to subscribers.
+type Event struct {
+	Type    EventType `json:"type"`
+	Time    time.Time `json:"time"`
+	Address string    `json:"address,omitempty"`
+	TxName  string    `json:"tx-name,omitempty"`
+	TxID    string    `json:"txid,omitempty"`
+	Amount  uint64    `json:"amount,omitempty"`
+}
+
+// eventBus fans events out to subscribers. The zero value has no
+// subscribers and is ready to use.
+type eventBus struct {
+	lock sync.Mutex
+	subs map[chan *Event]bool
+}
+
+// Subscribe returns a channel that receives the events of the wallet and a
+// function that ends the subscription and closes the channel. Events are
+// dropped for subscribers that fall behind.
+func (w *Wallet) Subscribe() (<-chan *Event, func()) {
+	b := &w.events
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	ch := make(chan *Event, eventBuffer)
+	if b.subs == nil {
+		b.subs = make(map[chan *Event]bool)
+	}
+	b.subs[ch] = true
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.lock.Lock()
+			defer b.lock.Unlock()
+			delete(b.subs, ch)
+			close(ch)
+		})
+	}
+}
+
+// publish sends e to every subscriber without waiting on any of them.
+func (w *Wallet) publish(e *Event) {
+	e.Time = time.Now()
+
+	b := &w.events
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
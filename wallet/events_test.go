@@ -0,0 +1,45 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"testing"
+
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestSubscribe(t *testing.T) {
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	events, cancel := w1.Subscribe()
+
+	f, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-events:
+		if e.Type != EventAddressGenerated || e.Address != f.String() {
+			t.Errorf("wrong event %v", e)
+		}
+	default:
+		t.Fatal("no event for the generated address")
+	}
+
+	cancel()
+	if _, ok := <-events; ok {
+		t.Error("events channel is still open after cancel")
+	}
+	// cancelling twice is harmless
+	cancel()
+
+	if _, err := w1.GenerateECAddress(); err != nil {
+		t.Fatal(err)
+	}
+}
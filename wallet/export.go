@@ -0,0 +1,252 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factomd/common/factoid"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+// txPackageVersion is the version of the txPackage format written by
+// ExportTransaction.
+const txPackageVersion = 1
+
+// txPackage is the portable form of a tmp transaction, with the signatures
+// collected so far, that wallets pass to each other with ExportTransaction
+// and ImportTransaction.
+type txPackage struct {
+	Version   int                    `json:"version"`
+	Timestamp int64                  `json:"timestamp"`
+	Inputs    []*txPackageInput      `json:"inputs"`
+	Outputs   []*factom.TransAddress `json:"outputs"`
+	ECOutputs []*factom.TransAddress `json:"ecoutputs"`
+}
+
+type txPackageInput struct {
+	Address   string `json:"address"`
+	Amount    uint64 `json:"amount"`
+	PublicKey string `json:"publickey"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// ExportTransaction returns the tmp transaction name with the signatures it
+// has so far as a portable blob. Another wallet can import the blob with
+// ImportTransaction, add its inputs or signatures and pass it on, so that
+// inputs held by different wallets can be spent in one transaction.
+func (w *Wallet) ExportTransaction(name string) (string, error) {
+	tx, err := w.GetTransaction(name)
+	if err != nil {
+		return "", err
+	}
+	return exportTx(tx)
+}
+
+// exportTx returns tx in the form written by ExportTransaction.
+func exportTx(tx *factoid.Transaction) (string, error) {
+	p := &txPackage{
+		Version:   txPackageVersion,
+		Timestamp: tx.GetTimestamp().GetTimeMilli(),
+	}
+	sigs := tx.GetSignatureBlocks()
+	for i, in := range tx.GetInputs() {
+		rcd, ok := tx.GetRCDs()[i].(*factoid.RCD_1)
+		if !ok {
+			return "", ErrTXMultisig
+		}
+		pin := &txPackageInput{
+			Address:   primitives.ConvertFctAddressToUserStr(in.GetAddress()),
+			Amount:    in.GetAmount(),
+			PublicKey: hex.EncodeToString(rcd.GetPublicKey()),
+		}
+		if i < len(sigs) && sigs[i] != nil && len(sigs[i].GetSignatures()) > 0 {
+			pin.Signature = hex.EncodeToString(sigs[i].GetSignature(0).Bytes())
+		}
+		p.Inputs = append(p.Inputs, pin)
+	}
+	p.Outputs, p.ECOutputs = txOutputs(tx)
+
+	j, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(j), nil
+}
+
+// ImportTransaction stores the transaction in blob, written by
+// ExportTransaction, as the tmp transaction name. Every signature in the blob
+// is checked. If the wallet already has the same transaction under name, the
+// signatures of both are kept; any other transaction under name is replaced.
+func (w *Wallet) ImportTransaction(name, blob string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	if err := checkTxName(name); err != nil {
+		return err
+	}
+	p, err := readTxPackage(blob)
+	if err != nil {
+		return err
+	}
+	tx, err := p.transaction()
+	if err != nil {
+		return err
+	}
+
+	// keep the signatures this wallet already collected for the transaction
+	if old, err := w.GetTransaction(name); err == nil && old.GetSigHash().IsSameAs(tx.GetSigHash()) {
+		sigs := old.GetSignatureBlocks()
+		for i, in := range p.Inputs {
+			if in.Signature == "" && i < len(sigs) && sigs[i] != nil && len(sigs[i].GetSignatures()) > 0 {
+				tx.SetSignatureBlock(i, sigs[i])
+			}
+		}
+	}
+	return w.saveTransaction(name, tx)
+}
+
+// SignExportedTransaction signs the inputs of the exported transaction blob
+// that the wallet holds keys for and returns it with the signatures added,
+// without storing it in the wallet. It lets a wallet that is kept offline sign
+// transactions built by an online wallet that only watches its addresses, see
+// ImportWatchOnlyKey; the online wallet then imports the result with
+// ImportTransaction and sends it. force is as in SignTransaction; an offline
+// wallet can not check balances and fees. A transaction above the approval
+// threshold must first be approved with ApproveExportedTransaction; the
+// approval is used up by the signature.
+func (w *Wallet) SignExportedTransaction(blob string, force bool) (string, error) {
+	if w.readOnly {
+		return "", ErrReadOnly
+	}
+	p, err := readTxPackage(blob)
+	if err != nil {
+		return "", err
+	}
+	tx, err := p.transaction()
+	if err != nil {
+		return "", err
+	}
+
+	w.txlock.Lock()
+	pending := w.needsApproval(tx)
+	w.txlock.Unlock()
+	if pending {
+		return "", ErrApprovalRequired
+	}
+	if err := w.signTransaction(tx, force); err != nil {
+		return "", err
+	}
+	w.txlock.Lock()
+	w.forgetApproval(tx)
+	w.txlock.Unlock()
+	w.publish(&Event{Type: EventTransactionSigned, TxID: tx.GetSigHash().String()})
+	return exportTx(tx)
+}
+
+// ExportedInputs returns the addresses of the inputs of the exported
+// transaction blob.
+func ExportedInputs(blob string) ([]string, error) {
+	p, err := readTxPackage(blob)
+	if err != nil {
+		return nil, err
+	}
+	as := make([]string, len(p.Inputs))
+	for i, in := range p.Inputs {
+		as[i] = in.Address
+	}
+	return as, nil
+}
+
+// ExportedSigHash returns the signature hash of the exported transaction
+// blob, which identifies its approval.
+func ExportedSigHash(blob string) (string, error) {
+	p, err := readTxPackage(blob)
+	if err != nil {
+		return "", err
+	}
+	tx, err := p.transaction()
+	if err != nil {
+		return "", err
+	}
+	return tx.GetSigHash().String(), nil
+}
+
+// readTxPackage decodes a transaction written by exportTx.
+func readTxPackage(blob string) (*txPackage, error) {
+	j, err := base64.StdEncoding.DecodeString(blob)
+	if err != nil {
+		return nil, validationErrorf("wallet: The transaction is not valid base64")
+	}
+	p := new(txPackage)
+	if err := json.Unmarshal(j, p); err != nil {
+		return nil, validationErrorf("wallet: The transaction can not be read: %s", err)
+	}
+	if p.Version != txPackageVersion {
+		return nil, validationErrorf("wallet: Unsupported transaction version %d", p.Version)
+	}
+	return p, nil
+}
+
+// transaction builds the factoid transaction described by p.
+func (p *txPackage) transaction() (*factoid.Transaction, error) {
+	tx := new(factoid.Transaction)
+	tx.SetTimestamp(primitives.NewTimestampFromMilliseconds(uint64(p.Timestamp)))
+
+	for i, in := range p.Inputs {
+		pub, err := hex.DecodeString(in.PublicKey)
+		if err != nil || len(pub) != 32 {
+			return nil, validationErrorf("wallet: Input %d has an invalid public key", i)
+		}
+		rcd := factoid.NewRCD_1(pub)
+		adr, err := rcd.GetAddress()
+		if err != nil {
+			return nil, err
+		}
+		if primitives.ConvertFctAddressToUserStr(adr) != in.Address {
+			return nil, validationErrorf("wallet: The public key of input %d does not match %s", i, in.Address)
+		}
+		if err := checkAmount(tx, partInputs, adr, in.Amount); err != nil {
+			return nil, err
+		}
+		tx.AddInput(adr, in.Amount)
+		tx.AddRCD(rcd)
+	}
+	for _, out := range p.Outputs {
+		if err := addOutput(tx, out.Address, out.Amount); err != nil {
+			return nil, err
+		}
+	}
+	for _, ec := range p.ECOutputs {
+		if err := addECOutput(tx, ec.Address, ec.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	// the signatures can only be checked once the whole transaction is built
+	for i, in := range p.Inputs {
+		if in.Signature == "" {
+			continue
+		}
+		sig, err := hex.DecodeString(in.Signature)
+		if err != nil {
+			return nil, validationErrorf("wallet: Input %d has an invalid signature", i)
+		}
+		fs := new(factoid.FactoidSignature)
+		if err := fs.SetSignature(sig); err != nil {
+			return nil, validationErrorf("wallet: Input %d has an invalid signature", i)
+		}
+		sb := factoid.NewSignatureBlock()
+		sb.AddSignature(fs)
+		if !tx.GetRCDs()[i].CheckSig(tx, sb) {
+			return nil, validationErrorf("wallet: Input %d has an invalid signature", i)
+		}
+		tx.SetSignatureBlock(i, sb)
+	}
+	return tx, nil
+}
@@ -0,0 +1,185 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestExportImportTransaction(t *testing.T) {
+	// two wallets that each hold one of the inputs
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+	w2, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w2.Close()
+
+	f1, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f2, err := w2.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"
+
+	if err := w1.NewTransaction("joint"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddInput("joint", f1.String(), 1e8); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddOutput("joint", to, 2e8); err != nil {
+		t.Fatal(err)
+	}
+	blob, err := w1.ExportTransaction("joint")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the second wallet adds its input and signs it
+	if err := w2.ImportTransaction("joint", blob); err != nil {
+		t.Fatal(err)
+	}
+	if err := w2.AddInput("joint", f2.String(), 1e8); err != nil {
+		t.Fatal(err)
+	}
+	if err := w2.SignTransaction("joint", true); err != nil {
+		t.Fatal(err)
+	}
+	if blob, err = w2.ExportTransaction("joint"); err != nil {
+		t.Fatal(err)
+	}
+
+	// and the first wallet completes the transaction
+	if err := w1.ImportTransaction("joint", blob); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.SignTransaction("joint", true); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := w1.GetTransaction("joint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.GetInputs()) != 2 {
+		t.Errorf("got %d inputs, want 2", len(tx.GetInputs()))
+	}
+	if err := tx.ValidateSignatures(); err != nil {
+		t.Errorf("transaction is not fully signed: %v", err)
+	}
+
+	// a forged signature is rejected
+	j, err := base64.StdEncoding.DecodeString(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := make(map[string]interface{})
+	if err := json.Unmarshal(j, &p); err != nil {
+		t.Fatal(err)
+	}
+	for _, in := range p["inputs"].([]interface{}) {
+		in := in.(map[string]interface{})
+		if sig, ok := in["signature"].(string); ok {
+			in["signature"] = "00" + sig[2:]
+		}
+	}
+	j, err = json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.ImportTransaction("forged", base64.StdEncoding.EncodeToString(j)); err == nil {
+		t.Error("forged signature was accepted")
+	}
+
+	// a wallet without any of the keys can not sign
+	w3, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w3.Close()
+	if err := w3.ImportTransaction("joint", blob); err != nil {
+		t.Fatal(err)
+	}
+	if err := w3.SignTransaction("joint", true); err == nil {
+		t.Error("transaction was signed without the keys")
+	}
+}
+
+func TestOfflineSigning(t *testing.T) {
+	offline, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer offline.Close()
+	online, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer online.Close()
+
+	f1, err := offline.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := offline.PublicKey(f1.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := online.ImportWatchOnlyKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != f1.String() {
+		t.Fatalf("watch only address %s, want %s", a, f1.String())
+	}
+
+	// the online wallet builds the transaction but can not sign it
+	if err := online.NewTransaction("cold"); err != nil {
+		t.Fatal(err)
+	}
+	if err := online.AddInput("cold", a, 1e8); err != nil {
+		t.Fatal(err)
+	}
+	if err := online.AddOutput("cold", "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q", 1e8); err != nil {
+		t.Fatal(err)
+	}
+	if err := online.SignTransaction("cold", true); err != ErrWatchOnly {
+		t.Errorf("expected ErrWatchOnly, got %v", err)
+	}
+	blob, err := online.ExportTransaction("cold")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := offline.SignExportedTransaction(blob, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(offline.GetTransactions()); n != 0 {
+		t.Errorf("offline wallet stored %d transactions", n)
+	}
+
+	if err := online.ImportTransaction("cold", signed); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := online.GetTransaction("cold")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.ValidateSignatures(); err != nil {
+		t.Errorf("transaction is not signed: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"github.com/FactomProject/factomd/common/interfaces"
+)
+
+// FeeEstimate is the fee in factoshis that a transaction needs at an entry
+// credit rate.
+type FeeEstimate struct {
+	Rate uint64 `json:"rate"`
+	Fee  uint64 `json:"fee"`
+
+	// Current is set for the rate factomd currently reports.
+	Current bool `json:"current,omitempty"`
+}
+
+// FeesAtRates returns the fee tx needs at each of the given entry credit
+// rates.
+func FeesAtRates(tx interfaces.ITransaction, rates ...uint64) ([]*FeeEstimate, error) {
+	fees := make([]*FeeEstimate, 0, len(rates))
+	for _, rate := range rates {
+		if rate == 0 {
+			return nil, validationErrorf("wallet: The entry credit rate must be greater than 0")
+		}
+		fee, err := tx.CalculateFee(rate)
+		if err != nil {
+			return nil, err
+		}
+		fees = append(fees, &FeeEstimate{Rate: rate, Fee: fee})
+	}
+	return fees, nil
+}
+
+// SimulateFees returns the fee the named transaction needs at the current
+// entry credit rate, which is always the first estimate, followed by the fee
+// at each of the what if rates. The transaction is not changed.
+func (w *Wallet) SimulateFees(name string, rates ...uint64) ([]*FeeEstimate, error) {
+	tx, err := w.GetTransaction(name)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := getRate(w.retry)
+	if err != nil {
+		return nil, err
+	}
+
+	fees, err := FeesAtRates(tx, append([]uint64{current}, rates...)...)
+	if err != nil {
+		return nil, err
+	}
+	fees[0].Current = true
+	return fees, nil
+}
+
+// EstimateFee returns the fee the named transaction needs, as it is now, at
+// the current entry credit rate. Unlike AddFee it does not change the
+// transaction.
+func (w *Wallet) EstimateFee(name string) (*FeeEstimate, error) {
+	fees, err := w.SimulateFees(name)
+	if err != nil {
+		return nil, err
+	}
+	return fees[0], nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestFeesAtRates(t *testing.T) {
+	zSec := "Fs1KWJrpLdfucvmYwN2nWrwepLn8ercpMbzXshd1g8zyhKXLVLWj"
+	to := "FA3T1gTkuKGG2MWpAkskSoTnfjxZDKVaAYwziNTC1pAYH5B9A1rh"
+
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	f, err := factom.GetFactoidAddress(zSec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.InsertFCTAddress(f); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := w1.BuildTx().From(f.String(), 1e8).To(to, 1e8).Force().Sign()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fees, err := FeesAtRates(tx, 1000, 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fees) != 2 || fees[0].Rate != 1000 || fees[0].Fee == 0 {
+		t.Fatalf("wrong fees %v", fees)
+	}
+	if fees[1].Fee != 2*fees[0].Fee {
+		t.Errorf("fee at twice the rate is %d, want %d", fees[1].Fee, 2*fees[0].Fee)
+	}
+
+	if _, err := FeesAtRates(tx, 0); err == nil {
+		t.Error("expected an error for a zero rate")
+	}
+}
+
+func TestEstimateFee(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"rate": 1000}}`)
+	}))
+	defer ts.Close()
+	factom.SetFactomdServer(ts.URL[7:])
+
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	f, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.NewTransaction("tx"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddInput("tx", f.String(), 1e8); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddOutput("tx", "FA3T1gTkuKGG2MWpAkskSoTnfjxZDKVaAYwziNTC1pAYH5B9A1rh", 1e8); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := w1.GetTransaction("tx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := tx.GetSigHash().String()
+	want, err := tx.CalculateFee(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fee, err := w1.EstimateFee("tx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee.Rate != 1000 || fee.Fee != want {
+		t.Errorf("got fee %d at rate %d, want %d at 1000", fee.Fee, fee.Rate, want)
+	}
+	if tx.GetSigHash().String() != before {
+		t.Error("EstimateFee changed the transaction")
+	}
+
+	if _, err := w1.EstimateFee("no-such-tx"); err != ErrTXNotExists {
+		t.Errorf("expected ErrTXNotExists, got %v", err)
+	}
+}
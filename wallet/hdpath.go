@@ -0,0 +1,91 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FactomProject/factom"
+)
+
+// The derivation path templates used when a seed has none set. They are
+// the BIP44 paths of Factoid and Entry Credit addresses, with i replaced by
+// the address index.
+const (
+	DefaultFCTPath = "m/44'/131'/0'/0/i"
+	DefaultECPath  = "m/44'/132'/0'/0/i"
+)
+
+// childPath returns the derivation path of the address with index i. The
+// last child of a path template is i, or i' for hardened addresses.
+func childPath(template string, i uint32) ([]uint32, error) {
+	n := strings.LastIndex(template, "/")
+	if n < 0 {
+		return nil, validationErrorf("wallet: Invalid derivation path template %q", template)
+	}
+	switch template[n+1:] {
+	case "i":
+		template = fmt.Sprintf("%s/%d", template[:n], i)
+	case "i'", "ih":
+		template = fmt.Sprintf("%s/%d'", template[:n], i)
+	default:
+		return nil, validationErrorf("wallet: Derivation path template %q does not end with the index i", template)
+	}
+	path, err := factom.ParseDerivationPath(template)
+	if err != nil {
+		return nil, validationErrorf("wallet: %v", err)
+	}
+	return path, nil
+}
+
+// SetDerivationPaths sets the derivation path templates of the addresses
+// generated from the wallet seed, such as m/44'/131'/0'/0'/i' for wallets
+// that derive hardened addresses only. An empty template selects the
+// default. The address indexes are not reset, so the templates should be set
+// before any address is generated.
+func (w *Wallet) SetDerivationPaths(fct, ec string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	for _, t := range []string{fct, ec} {
+		if t == "" {
+			continue
+		}
+		if _, err := childPath(t, 0); err != nil {
+			return err
+		}
+	}
+
+	seed, err := w.GetOrCreateDBSeed()
+	if err != nil {
+		return err
+	}
+	seed.FCTPath, seed.ECPath = fct, ec
+	if fct == DefaultFCTPath {
+		seed.FCTPath = ""
+	}
+	if ec == DefaultECPath {
+		seed.ECPath = ""
+	}
+	return w.InsertDBSeed(seed)
+}
+
+// DerivationPaths returns the derivation path templates of the addresses
+// generated from the wallet seed.
+func (w *Wallet) DerivationPaths() (fct, ec string, err error) {
+	fct, ec = DefaultFCTPath, DefaultECPath
+	seed, err := w.GetDBSeed()
+	if err != nil || seed == nil {
+		return fct, ec, err
+	}
+	if seed.FCTPath != "" {
+		fct = seed.FCTPath
+	}
+	if seed.ECPath != "" {
+		ec = seed.ECPath
+	}
+	return fct, ec, nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestDerivationPaths(t *testing.T) {
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	if fct, ec, err := w1.DerivationPaths(); err != nil || fct != DefaultFCTPath || ec != DefaultECPath {
+		t.Errorf("expected the default paths, got %s %s %v", fct, ec, err)
+	}
+
+	for _, bad := range []string{"m/44'/131'/0'/0", "m/44'/x/i", "i"} {
+		if err := w1.SetDerivationPaths(bad, ""); !errors.Is(err, factom.ErrValidation) {
+			t.Errorf("expected a validation error for %s, got %v", bad, err)
+		}
+	}
+
+	hardened := "m/44'/131'/0'/0'/i'"
+	if err := w1.SetDerivationPaths(hardened, ""); err != nil {
+		t.Fatal(err)
+	}
+	if fct, ec, err := w1.DerivationPaths(); err != nil || fct != hardened || ec != DefaultECPath {
+		t.Errorf("wrong paths %s %s %v", fct, ec, err)
+	}
+
+	seed, err := w1.GetSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		f, err := w1.GenerateFCTAddress()
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, _ := factom.ParseDerivationPath(fmt.Sprintf("m/44'/131'/0'/0'/%d'", i))
+		want, err := factom.MakeFactoidAddressFromPath(seed, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.String() != want.String() {
+			t.Errorf("address %d is %s, want %s", i, f, want)
+		}
+	}
+}
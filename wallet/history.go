@@ -0,0 +1,354 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"encoding/gob"
+	"sort"
+	"time"
+
+	"github.com/FactomProject/factomd/common/factoid"
+	"github.com/FactomProject/factomd/common/interfaces"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+// maxHistoryPage is the most transactions returned by one call to
+// GetHistory.
+const maxHistoryPage = 1000
+
+// HistoryTx is a confirmed transaction that spends from or pays to an
+// address of the wallet, recorded by IndexHistory.
+type HistoryTx struct {
+	TxID        string
+	BlockHeight uint32
+	Time        time.Time
+
+	// Addresses are the addresses of the wallet the transaction touches.
+	Addresses []string
+
+	// Tx is the binary transaction.
+	Tx []byte
+}
+
+var _ interfaces.BinaryMarshallableAndCopyable = (*HistoryTx)(nil)
+
+// historyTxData is HistoryTx without its methods, for gob.
+type historyTxData HistoryTx
+
+func (h *HistoryTx) New() interfaces.BinaryMarshallableAndCopyable {
+	return new(HistoryTx)
+}
+
+func (h *HistoryTx) MarshalBinary() ([]byte, error) {
+	var data primitives.Buffer
+
+	enc := gob.NewEncoder(&data)
+	if err := enc.Encode(historyTxData(*h)); err != nil {
+		return nil, err
+	}
+	return data.DeepCopyBytes(), nil
+}
+
+func (h *HistoryTx) UnmarshalBinaryData(data []byte) ([]byte, error) {
+	dec := gob.NewDecoder(primitives.NewBuffer(data))
+	if err := dec.Decode((*historyTxData)(h)); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (h *HistoryTx) UnmarshalBinary(data []byte) error {
+	_, err := h.UnmarshalBinaryData(data)
+	return err
+}
+
+// Transaction decodes the recorded transaction.
+func (h *HistoryTx) Transaction() (*factoid.Transaction, error) {
+	tx := new(factoid.Transaction)
+	if err := tx.UnmarshalBinary(h.Tx); err != nil {
+		return nil, err
+	}
+	tx.SetBlockHeight(h.BlockHeight)
+	return tx, nil
+}
+
+// historyCursor is the height of the next factoid block IndexHistory scans.
+type historyCursor struct {
+	Next uint32
+}
+
+var _ interfaces.BinaryMarshallableAndCopyable = (*historyCursor)(nil)
+
+// historyCursorData is historyCursor without its methods, for gob.
+type historyCursorData historyCursor
+
+func (c *historyCursor) New() interfaces.BinaryMarshallableAndCopyable {
+	return new(historyCursor)
+}
+
+func (c *historyCursor) MarshalBinary() ([]byte, error) {
+	var data primitives.Buffer
+
+	enc := gob.NewEncoder(&data)
+	if err := enc.Encode(historyCursorData(*c)); err != nil {
+		return nil, err
+	}
+	return data.DeepCopyBytes(), nil
+}
+
+func (c *historyCursor) UnmarshalBinaryData(data []byte) ([]byte, error) {
+	dec := gob.NewDecoder(primitives.NewBuffer(data))
+	if err := dec.Decode((*historyCursorData)(c)); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (c *historyCursor) UnmarshalBinary(data []byte) error {
+	_, err := c.UnmarshalBinaryData(data)
+	return err
+}
+
+func (db *WalletDatabaseOverlay) nextHistoryHeight() (uint32, error) {
+	data, err := db.DBO.Get(historyPosDBKey, historyPosDBKey, new(historyCursor))
+	if err != nil {
+		return 0, dbError("read", err)
+	}
+	if data == nil {
+		return 0, nil
+	}
+	return data.(*historyCursor).Next, nil
+}
+
+func (db *WalletDatabaseOverlay) setNextHistoryHeight(next uint32) error {
+	return dbError("write", db.DBO.Put(historyPosDBKey, historyPosDBKey, &historyCursor{Next: next}))
+}
+
+// historyAddresses returns the public addresses the history is kept for:
+// the Factoid, Entry Credit and watch only addresses of the wallet.
+func (w *Wallet) historyAddresses() (map[string]bool, error) {
+	fs, es, err := w.GetAllAddresses()
+	if err != nil {
+		return nil, err
+	}
+	ws, err := w.GetAllWatchOnly()
+	if err != nil {
+		return nil, err
+	}
+
+	as := make(map[string]bool, len(fs)+len(es)+len(ws))
+	for _, f := range fs {
+		as[f.String()] = true
+		f.Wipe()
+	}
+	for _, e := range es {
+		as[e.PubString()] = true
+		e.Wipe()
+	}
+	for _, a := range ws {
+		as[a] = true
+	}
+	return as, nil
+}
+
+// touches returns the addresses in as that tx spends from or pays to.
+func touches(tx interfaces.ITransaction, as map[string]bool) []string {
+	var found []string
+	seen := make(map[string]bool)
+	add := func(a string) {
+		if as[a] && !seen[a] {
+			seen[a] = true
+			found = append(found, a)
+		}
+	}
+	for _, in := range tx.GetInputs() {
+		add(primitives.ConvertFctAddressToUserStr(in.GetAddress()))
+	}
+	for _, out := range tx.GetOutputs() {
+		add(primitives.ConvertFctAddressToUserStr(out.GetAddress()))
+	}
+	for _, out := range tx.GetECOutputs() {
+		add(primitives.ConvertECAddressToUserStr(out.GetAddress()))
+	}
+	return found
+}
+
+// IndexHistory scans the factoid blocks written to factomd since it was last
+// called and records every transaction that touches an address of the
+// wallet, so that GetHistory can answer without going to factomd. It returns
+// the number of transactions recorded. Progress is saved as it goes, so an
+// interrupted scan continues where it stopped.
+//
+// An address added to the wallet later is only indexed from the block the
+// scan has reached; ResetHistory makes the next scan start over.
+func (w *Wallet) IndexHistory() (int, error) {
+	if w.readOnly {
+		return 0, ErrReadOnly
+	}
+	as, err := w.historyAddresses()
+	if err != nil {
+		return 0, err
+	}
+	next, err := w.nextHistoryHeight()
+	if err != nil {
+		return 0, err
+	}
+
+	var head interfaces.IFBlock
+	if err := w.retry.Do(func() (err error) {
+		head, err = fblockHead()
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	if head == nil {
+		return 0, nil
+	}
+
+	n := 0
+	for h := next; h <= head.GetDatabaseHeight(); h++ {
+		var fblock interfaces.IFBlock
+		if err := w.retry.Do(func() (err error) {
+			fblock, err = getfblockbyheight(h)
+			return err
+		}); err != nil {
+			return n, err
+		}
+
+		found := false
+		for _, tx := range fblock.GetTransactions() {
+			adrs := touches(tx, as)
+			if len(adrs) == 0 {
+				continue
+			}
+			data, err := tx.MarshalBinary()
+			if err != nil {
+				return n, err
+			}
+			r := &HistoryTx{
+				TxID:        tx.GetSigHash().String(),
+				BlockHeight: h,
+				Time:        tx.GetTimestamp().GetTime(),
+				Addresses:   adrs,
+				Tx:          data,
+			}
+			if err := w.DBO.Put(historyDBPrefix, []byte(r.TxID), r); err != nil {
+				return n, dbError("write", err)
+			}
+			found = true
+			n++
+		}
+
+		// save the progress every 500 blocks and whenever something was
+		// recorded; blocks scanned again after an error are recorded under
+		// the same keys
+		if found || h%500 == 0 {
+			if err := w.setNextHistoryHeight(h + 1); err != nil {
+				return n, err
+			}
+		}
+	}
+	if err := w.setNextHistoryHeight(head.GetDatabaseHeight() + 1); err != nil {
+		return n, err
+	}
+	if n > 0 {
+		w.logf("indexed %d transactions up to block %d", n, head.GetDatabaseHeight())
+	}
+	return n, nil
+}
+
+// ResetHistory removes the recorded transactions so that the next call to
+// IndexHistory scans every factoid block again.
+func (w *Wallet) ResetHistory() error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	keys, err := w.DBO.ListAllKeys(historyDBPrefix)
+	if err != nil {
+		return dbError("read", err)
+	}
+	for _, k := range keys {
+		if err := w.DBO.Delete(historyDBPrefix, k); err != nil {
+			return dbError("delete", err)
+		}
+	}
+	return w.setNextHistoryHeight(0)
+}
+
+// HistoryFilter selects the transactions returned by GetHistory. Zero fields
+// select everything.
+type HistoryFilter struct {
+	// Address limits the result to the transactions touching Address.
+	Address string
+
+	// Since and Until limit the result to the transactions with a timestamp
+	// in [Since, Until).
+	Since time.Time
+	Until time.Time
+
+	// Offset skips the first matches. Limit is the most transactions
+	// returned, at most 1000.
+	Offset int
+	Limit  int
+}
+
+// GetHistory returns the recorded transactions selected by f, newest first,
+// and the number of transactions that match f in total.
+func (w *Wallet) GetHistory(f HistoryFilter) ([]*HistoryTx, int, error) {
+	if f.Offset < 0 || f.Limit < 0 {
+		return nil, 0, validationErrorf("wallet: Offset and limit can not be negative")
+	}
+	if f.Limit == 0 || f.Limit > maxHistoryPage {
+		f.Limit = maxHistoryPage
+	}
+
+	list, err := w.DBO.FetchAllBlocksFromBucket(historyDBPrefix, new(HistoryTx))
+	if err != nil {
+		return nil, 0, dbError("read", err)
+	}
+
+	txs := make([]*HistoryTx, 0, len(list))
+	for _, v := range list {
+		h := v.(*HistoryTx)
+		if f.Address != "" && !containsString(h.Addresses, f.Address) {
+			continue
+		}
+		if !f.Since.IsZero() && h.Time.Before(f.Since) {
+			continue
+		}
+		if !f.Until.IsZero() && !h.Time.Before(f.Until) {
+			continue
+		}
+		txs = append(txs, h)
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].BlockHeight != txs[j].BlockHeight {
+			return txs[i].BlockHeight > txs[j].BlockHeight
+		}
+		if !txs[i].Time.Equal(txs[j].Time) {
+			return txs[i].Time.After(txs[j].Time)
+		}
+		return txs[i].TxID < txs[j].TxID
+	})
+
+	total := len(txs)
+	if f.Offset >= total {
+		return []*HistoryTx{}, total, nil
+	}
+	txs = txs[f.Offset:]
+	if len(txs) > f.Limit {
+		txs = txs[:f.Limit]
+	}
+	return txs, total, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestGetHistory(t *testing.T) {
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	a1 := "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"
+	a2 := "FA3cih2o2tjEUsnnFR4jX1tQXPpSXFwsp3rhVp6odL5PNCHWvZV1"
+	start := time.Unix(1500000000, 0)
+
+	// record ten transactions, one per block, alternating between the two
+	// addresses
+	for i := 0; i < 10; i++ {
+		h := &HistoryTx{
+			TxID:        fmt.Sprintf("%064x", i),
+			BlockHeight: uint32(100 + i),
+			Time:        start.Add(time.Duration(i) * time.Minute),
+			Addresses:   []string{a1},
+		}
+		if i%2 == 1 {
+			h.Addresses = []string{a2}
+		}
+		if err := w1.DBO.Put([]byte("Address History"), []byte(h.TxID), h); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all, total, err := w1.GetHistory(HistoryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 10 || len(all) != 10 {
+		t.Fatalf("got %d of %d transactions, want 10", len(all), total)
+	}
+	if all[0].BlockHeight != 109 || all[9].BlockHeight != 100 {
+		t.Error("transactions are not newest first")
+	}
+
+	// paging
+	page, total, err := w1.GetHistory(HistoryFilter{Offset: 8, Limit: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 10 || len(page) != 2 || page[0].BlockHeight != 101 {
+		t.Errorf("wrong page: %d of %d", len(page), total)
+	}
+
+	// address and time range
+	hs, total, err := w1.GetHistory(HistoryFilter{
+		Address: a2,
+		Since:   start.Add(2 * time.Minute),
+		Until:   start.Add(7 * time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || len(hs) != 2 || hs[0].BlockHeight != 105 || hs[1].BlockHeight != 103 {
+		t.Errorf("wrong filtered history %v", hs)
+	}
+
+	if _, _, err := w1.GetHistory(HistoryFilter{Offset: -1}); err == nil {
+		t.Error("negative offset was accepted")
+	}
+
+	w1.SetReadOnly(true)
+	if _, err := w1.IndexHistory(); err != ErrReadOnly {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+}
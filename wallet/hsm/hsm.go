@@ -0,0 +1,286 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+// Package hsm keeps the ed25519 keys of a wallet in a hardware security
+// module through PKCS#11. A *Signer is a wallet.KeyGenerator: set it as the
+// signer of a wallet and new addresses are generated on the token and every
+// signature is made by the token, so the secret keys never leave it.
+//
+//	s, err := hsm.Open("/usr/lib/softhsm/libsofthsm2.so", "factom", pin)
+//	...
+//	w, err := wallet.New(wallet.WithBoltDB(path), wallet.WithSigner(s))
+package hsm
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	ed "github.com/FactomProject/ed25519"
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factom/wallet"
+	"github.com/miekg/pkcs11"
+)
+
+// ed25519Params are the DER encoded CKA_EC_PARAMS of Ed25519 keys, the
+// object identifier 1.3.101.112.
+var ed25519Params = []byte{0x06, 0x03, 0x2b, 0x65, 0x70}
+
+// keyLabel is the CKA_LABEL of the keys created by a Signer.
+const keyLabel = "factom"
+
+// ErrNoSuchKey is returned for an address whose key is not on the token.
+var ErrNoSuchKey = errors.New("hsm: The key is not on the token")
+
+// Signer signs with the Ed25519 keys on a PKCS#11 token.
+type Signer struct {
+	mu      sync.Mutex
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+
+	// keys maps the Factoid and Entry Credit address strings of the keys on
+	// the token to their public keys and CKA_IDs.
+	keys map[string]*tokenKey
+}
+
+type tokenKey struct {
+	pub []byte
+	id  []byte
+}
+
+var _ wallet.KeyGenerator = (*Signer)(nil)
+
+// Open loads the PKCS#11 module at path and logs in to the token labeled
+// tokenLabel with pin.
+func Open(path, tokenLabel, pin string) (*Signer, error) {
+	ctx := pkcs11.New(path)
+	if ctx == nil {
+		return nil, fmt.Errorf("hsm: Could not load the PKCS#11 module %s", path)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("hsm: initialize: %v", err)
+	}
+
+	s := &Signer{ctx: ctx, keys: make(map[string]*tokenKey)}
+	if err := s.open(tokenLabel, pin); err != nil {
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Signer) open(tokenLabel, pin string) error {
+	slots, err := s.ctx.GetSlotList(true)
+	if err != nil {
+		return fmt.Errorf("hsm: slots: %v", err)
+	}
+	slot, found := uint(0), false
+	for _, sl := range slots {
+		info, err := s.ctx.GetTokenInfo(sl)
+		if err != nil {
+			return fmt.Errorf("hsm: token info: %v", err)
+		}
+		if info.Label == tokenLabel {
+			slot, found = sl, true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("hsm: No token labeled %q", tokenLabel)
+	}
+
+	s.session, err = s.ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		return fmt.Errorf("hsm: open session: %v", err)
+	}
+	if err := s.ctx.Login(s.session, pkcs11.CKU_USER, pin); err != nil {
+		s.ctx.CloseSession(s.session)
+		return fmt.Errorf("hsm: login: %v", err)
+	}
+	if err := s.loadKeys(); err != nil {
+		s.ctx.Logout(s.session)
+		s.ctx.CloseSession(s.session)
+		return err
+	}
+	return nil
+}
+
+// Close logs out of the token and unloads the module.
+func (s *Signer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ctx.Logout(s.session)
+	err := s.ctx.CloseSession(s.session)
+	s.ctx.Finalize()
+	s.ctx.Destroy()
+	return err
+}
+
+// loadKeys reads the public keys of the Ed25519 keys on the token.
+func (s *Signer) loadKeys() error {
+	objs, err := s.find([]*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC_EDWARDS),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, keyLabel),
+	})
+	if err != nil {
+		return err
+	}
+	for _, o := range objs {
+		if err := s.addKey(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addKey remembers the public key object o under its addresses.
+func (s *Signer) addKey(o pkcs11.ObjectHandle) error {
+	attrs, err := s.ctx.GetAttributeValue(s.session, o, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, nil),
+	})
+	if err != nil {
+		return fmt.Errorf("hsm: read public key: %v", err)
+	}
+	pub, err := ecPoint(attrs[0].Value)
+	if err != nil {
+		return err
+	}
+	k := &tokenKey{pub: pub, id: attrs[1].Value}
+
+	var p [ed.PublicKeySize]byte
+	copy(p[:], pub)
+	rcd := factom.NewRCD1()
+	rcd.Pub = &p
+	s.keys[(&factom.FactoidAddress{RCD: rcd}).String()] = k
+	s.keys[(&factom.ECAddress{Pub: &p}).PubString()] = k
+	return nil
+}
+
+// ecPoint returns the public key in a CKA_EC_POINT, which tokens give either
+// as a DER octet string or as the raw key.
+func ecPoint(v []byte) ([]byte, error) {
+	if len(v) == ed.PublicKeySize+2 && v[0] == 0x04 && v[1] == ed.PublicKeySize {
+		return v[2:], nil
+	}
+	if len(v) == ed.PublicKeySize {
+		return v, nil
+	}
+	return nil, fmt.Errorf("hsm: Unexpected public key encoding %s", hex.EncodeToString(v))
+}
+
+func (s *Signer) find(template []*pkcs11.Attribute) ([]pkcs11.ObjectHandle, error) {
+	if err := s.ctx.FindObjectsInit(s.session, template); err != nil {
+		return nil, fmt.Errorf("hsm: find: %v", err)
+	}
+	defer s.ctx.FindObjectsFinal(s.session)
+
+	var objs []pkcs11.ObjectHandle
+	for {
+		o, _, err := s.ctx.FindObjects(s.session, 100)
+		if err != nil {
+			return nil, fmt.Errorf("hsm: find: %v", err)
+		}
+		if len(o) == 0 {
+			return objs, nil
+		}
+		objs = append(objs, o...)
+	}
+}
+
+// GenerateKey creates a new Ed25519 key pair on the token. The secret key
+// is marked sensitive and not extractable.
+func (s *Signer) GenerateKey() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	pubTemplate := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, ed25519Params),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, keyLabel),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+	}
+	privTemplate := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, keyLabel),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+	}
+	pub, _, err := s.ctx.GenerateKeyPair(s.session,
+		[]*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_EC_EDWARDS_KEY_PAIR_GEN, nil)},
+		pubTemplate, privTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("hsm: generate key: %v", err)
+	}
+	if err := s.addKey(pub); err != nil {
+		return nil, err
+	}
+	for _, k := range s.keys {
+		if bytes.Equal(k.id, id) {
+			return k.pub, nil
+		}
+	}
+	return nil, ErrNoSuchKey
+}
+
+// PublicKey returns the public key of the Factoid or Entry Credit address
+// pub.
+func (s *Signer) PublicKey(pub string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k, ok := s.keys[pub]
+	if !ok {
+		return nil, ErrNoSuchKey
+	}
+	return k.pub, nil
+}
+
+// Sign signs msg on the token with the secret key of the address pub.
+func (s *Signer) Sign(pub string, msg []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k, ok := s.keys[pub]
+	if !ok {
+		return nil, ErrNoSuchKey
+	}
+	objs, err := s.find([]*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, k.id),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(objs) != 1 {
+		return nil, ErrNoSuchKey
+	}
+
+	mech := []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_EDDSA, nil)}
+	if err := s.ctx.SignInit(s.session, mech, objs[0]); err != nil {
+		return nil, fmt.Errorf("hsm: sign: %v", err)
+	}
+	sig, err := s.ctx.Sign(s.session, msg)
+	if err != nil {
+		return nil, fmt.Errorf("hsm: sign: %v", err)
+	}
+	if len(sig) != ed.SignatureSize {
+		return nil, fmt.Errorf("hsm: Token returned a signature of %d bytes", len(sig))
+	}
+	return sig, nil
+}
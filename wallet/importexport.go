@@ -5,11 +5,11 @@
 package wallet
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/FactomProject/factom"
-	"github.com/FactomProject/factomd/common/factoid"
 )
 
 // ImportWalletFromMnemonic creates a new wallet with a provided Mnemonic seed
@@ -37,8 +37,7 @@ func ImportWalletFromMnemonic(mnemonic, path string) (*Wallet, error) {
 		return nil, err
 	}
 
-	w := new(Wallet)
-	w.transactions = make(map[string]*factoid.Transaction)
+	w := newWallet()
 	w.WalletDatabaseOverlay = db
 
 	return w, nil
@@ -68,3 +67,21 @@ func ExportWallet(path string) (string, []*factom.FactoidAddress, []*factom.ECAd
 	}
 	return m, fs, es, nil
 }
+
+// ImportKoinifyWords derives the Factoid Address of the 12 word mnemonic
+// given to buyers in the Koinify sale and stores it in the wallet.
+func (w *Wallet) ImportKoinifyWords(words string) (*factom.FactoidAddress, error) {
+	if w.readOnly {
+		return nil, ErrReadOnly
+	}
+	f, err := factom.MakeFactoidAddressFromKoinify(words)
+	if errors.Is(err, factom.ErrValidation) {
+		return nil, err
+	} else if err != nil {
+		return nil, validationErrorf("wallet: Invalid Koinify words: %v", err)
+	}
+	if err := w.InsertFCTAddress(f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
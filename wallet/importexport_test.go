@@ -23,3 +23,29 @@ func TestImportWithSpaces(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestImportKoinifyWords(t *testing.T) {
+	w, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	f, err := w.ImportKoinifyWords(" Yellow yellow yellow yellow yellow yellow yellow yellow yellow yellow yellow  yellow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.String() != "FA3cih2o2tjEUsnnFR4jX1tQXPpSXFwsp3rhVp6odL5PNCHWvZV1" {
+		t.Errorf("wrong koinify address %s", f)
+	}
+	if _, err := w.GetFCTAddress(f.String()); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := w.ImportKoinifyWords("yellow yellow yellow"); err == nil {
+		t.Error("no error for a short mnemonic")
+	}
+	if _, err := w.ImportKoinifyWords("yellow yellow yellow yellow yellow yellow yellow yellow yellow yellow yellow asdfasdf"); err == nil {
+		t.Error("no error for a bad word")
+	}
+}
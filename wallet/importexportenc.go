@@ -9,7 +9,6 @@ import (
 	"os"
 
 	"github.com/FactomProject/factom"
-	"github.com/FactomProject/factomd/common/factoid"
 )
 
 // ImportEncryptedWalletFromMnemonic creates a new wallet with a provided Mnemonic seed
@@ -37,8 +36,7 @@ func ImportEncryptedWalletFromMnemonic(mnemonic, path, password string) (*Wallet
 		return nil, err
 	}
 
-	w := new(Wallet)
-	w.transactions = make(map[string]*factoid.Transaction)
+	w := newWallet()
 	w.WalletDatabaseOverlay = db
 
 	return w, nil
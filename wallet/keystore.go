@@ -0,0 +1,266 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/FactomProject/factom"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// KeystoreVersion is the format version written by EncryptKeystore.
+	KeystoreVersion = 1
+
+	keystoreKDF    = "scrypt"
+	keystoreCipher = "aes-256-gcm"
+)
+
+// The scrypt parameters of new keystores.
+var (
+	keystoreScryptN = 1 << 18
+	keystoreScryptR = 8
+	keystoreScryptP = 1
+
+	// maxKeystoreScryptN keeps a crafted keystore from using all memory
+	maxKeystoreScryptN = 1 << 20
+)
+
+// KeystoreScrypt are the scrypt parameters used to derive the encryption key
+// of a Keystore from its passphrase.
+type KeystoreScrypt struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt string `json:"salt"`
+}
+
+// KeystoreCrypto is the encrypted secret of a Keystore.
+type KeystoreCrypto struct {
+	KDF        string         `json:"kdf"`
+	KDFParams  KeystoreScrypt `json:"kdfparams"`
+	Cipher     string         `json:"cipher"`
+	Nonce      string         `json:"nonce"`
+	Ciphertext string         `json:"ciphertext"`
+}
+
+// Keystore holds the secret of a single Factoid or Entry Credit Address
+// encrypted with a passphrase, so the key can be moved between wallets
+// without exposing the Fs or Es string. The public address is authenticated
+// with the secret and can be read without the passphrase.
+type Keystore struct {
+	Version int            `json:"version"`
+	Address string         `json:"address"`
+	Crypto  KeystoreCrypto `json:"crypto"`
+}
+
+// EncryptKeystore encrypts the Factoid or Entry Credit secret key string
+// secret with passphrase.
+func EncryptKeystore(secret, passphrase string) (*Keystore, error) {
+	if passphrase == "" {
+		return nil, validationErrorf("wallet: A keystore needs a passphrase")
+	}
+	pub, err := secretPublic(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &Keystore{
+		Version: KeystoreVersion,
+		Address: pub,
+		Crypto: KeystoreCrypto{
+			KDF: keystoreKDF,
+			KDFParams: KeystoreScrypt{
+				N: keystoreScryptN,
+				R: keystoreScryptR,
+				P: keystoreScryptP,
+			},
+			Cipher: keystoreCipher,
+		},
+	}
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	k.Crypto.KDFParams.Salt = hex.EncodeToString(salt)
+
+	aead, err := k.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	k.Crypto.Nonce = hex.EncodeToString(nonce)
+	k.Crypto.Ciphertext = hex.EncodeToString(aead.Seal(nil, nonce, []byte(secret), []byte(pub)))
+	return k, nil
+}
+
+// DecryptKeystore returns the secret key string of k. A wrong passphrase
+// returns ErrIncorrectPassphrase.
+func DecryptKeystore(k *Keystore, passphrase string) (string, error) {
+	if k.Version != KeystoreVersion {
+		return "", validationErrorf("wallet: Unsupported keystore version %d", k.Version)
+	}
+	if k.Crypto.KDF != keystoreKDF || k.Crypto.Cipher != keystoreCipher {
+		return "", validationErrorf("wallet: Unsupported keystore encryption %s/%s", k.Crypto.KDF, k.Crypto.Cipher)
+	}
+	nonce, err := hex.DecodeString(k.Crypto.Nonce)
+	if err != nil {
+		return "", validationErrorf("wallet: Invalid keystore nonce: %v", err)
+	}
+	ciphertext, err := hex.DecodeString(k.Crypto.Ciphertext)
+	if err != nil {
+		return "", validationErrorf("wallet: Invalid keystore ciphertext: %v", err)
+	}
+
+	aead, err := k.aead(passphrase)
+	if err != nil {
+		return "", err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return "", validationErrorf("wallet: Invalid keystore nonce length %d", len(nonce))
+	}
+	secret, err := aead.Open(nil, nonce, ciphertext, []byte(k.Address))
+	if err != nil {
+		return "", ErrIncorrectPassphrase
+	}
+
+	if pub, err := secretPublic(string(secret)); err != nil || pub != k.Address {
+		return "", validationErrorf("wallet: Keystore secret does not match %s", k.Address)
+	}
+	return string(secret), nil
+}
+
+// aead derives the cipher of k from passphrase.
+func (k *Keystore) aead(passphrase string) (cipher.AEAD, error) {
+	p := k.Crypto.KDFParams
+	if p.N > maxKeystoreScryptN {
+		return nil, validationErrorf("wallet: Keystore scrypt N %d is too large", p.N)
+	}
+	salt, err := hex.DecodeString(p.Salt)
+	if err != nil {
+		return nil, validationErrorf("wallet: Invalid keystore salt: %v", err)
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, p.N, p.R, p.P, 32)
+	if err != nil {
+		return nil, validationErrorf("wallet: Invalid keystore scrypt parameters: %v", err)
+	}
+	defer factom.SecureBytes(key).Wipe()
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// secretPublic returns the public address of a Factoid or Entry Credit
+// secret key string.
+func secretPublic(secret string) (string, error) {
+	switch factom.AddressStringType(secret) {
+	case factom.FactoidSec:
+		f, err := factom.GetFactoidAddress(secret)
+		if err != nil {
+			return "", err
+		}
+		return f.String(), nil
+	case factom.ECSec:
+		e, err := factom.GetECAddress(secret)
+		if err != nil {
+			return "", err
+		}
+		return e.PubString(), nil
+	}
+	return "", validationErrorf("wallet: Not a Factoid or Entry Credit secret key")
+}
+
+// ExportKeystore encrypts the secret of the wallet address pub with
+// passphrase.
+func (w *Wallet) ExportKeystore(pub, passphrase string) (*Keystore, error) {
+	if w.readOnly {
+		return nil, ErrReadOnly
+	}
+
+	var secret string
+	switch factom.AddressStringType(pub) {
+	case factom.FactoidPub:
+		f, err := w.GetFCTAddress(pub)
+		if err != nil {
+			return nil, w.keyError(pub, err)
+		}
+		secret = f.SecString()
+		f.Wipe()
+	case factom.ECPub:
+		e, err := w.GetECAddress(pub)
+		if err != nil {
+			return nil, w.keyError(pub, err)
+		}
+		secret = e.SecString()
+		e.Wipe()
+	default:
+		return nil, validationErrorf("wallet: %s is not a public address", pub)
+	}
+	return EncryptKeystore(secret, passphrase)
+}
+
+// ImportKeystore decrypts k with passphrase and stores its key in the
+// wallet. It returns the public address of the key.
+func (w *Wallet) ImportKeystore(k *Keystore, passphrase string) (string, error) {
+	if w.readOnly {
+		return "", ErrReadOnly
+	}
+	secret, err := DecryptKeystore(k, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	if factom.AddressStringType(secret) == factom.FactoidSec {
+		f, err := factom.GetFactoidAddress(secret)
+		if err != nil {
+			return "", err
+		}
+		if err := w.InsertFCTAddress(f); err != nil {
+			return "", err
+		}
+		return f.String(), nil
+	}
+	e, err := factom.GetECAddress(secret)
+	if err != nil {
+		return "", err
+	}
+	if err := w.InsertECAddress(e); err != nil {
+		return "", err
+	}
+	return e.PubString(), nil
+}
+
+// WriteKeystoreFile writes k to a file at path.
+func WriteKeystoreFile(path string, k *Keystore) error {
+	p, err := json.MarshalIndent(k, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, p, 0600)
+}
+
+// ReadKeystoreFile reads a keystore file written by WriteKeystoreFile.
+func ReadKeystoreFile(path string) (*Keystore, error) {
+	p, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	k := new(Keystore)
+	if err := json.Unmarshal(p, k); err != nil {
+		return nil, validationErrorf("wallet: %s is not a keystore file: %v", path, err)
+	}
+	return k, nil
+}
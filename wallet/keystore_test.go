@@ -0,0 +1,85 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestKeystore(t *testing.T) {
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+	f, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w1.ExportKeystore(f.String(), ""); err == nil {
+		t.Error("expected an error for an empty passphrase")
+	}
+	k, err := w1.ExportKeystore(f.String(), "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Address != f.String() {
+		t.Errorf("wrong keystore address %s", k.Address)
+	}
+
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "key.json")
+	if err := WriteKeystoreFile(path, k); err != nil {
+		t.Fatal(err)
+	}
+	p, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(p), f.SecString()) {
+		t.Error("the keystore file holds the plain secret")
+	}
+	k, err = ReadKeystoreFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w2, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w2.Close()
+	if _, err := w2.ImportKeystore(k, "hunter3"); err != ErrIncorrectPassphrase {
+		t.Errorf("expected ErrIncorrectPassphrase, got %v", err)
+	}
+	pub, err := w2.ImportKeystore(k, "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := w2.GetFCTAddress(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.SecString() != f.SecString() {
+		t.Error("imported the wrong secret")
+	}
+
+	// the address is bound to the ciphertext
+	k.Address = "FA1zT4aFpEvcnPqPCigB3fvGu4Q4mTXY22iiuV69DqE1pNhdF2MC"
+	if _, err := DecryptKeystore(k, "hunter2"); err == nil {
+		t.Error("expected an error for a changed address")
+	}
+}
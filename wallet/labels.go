@@ -0,0 +1,106 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"encoding/gob"
+
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factomd/common/interfaces"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+// labelRecord is the label of one address in the wallet database.
+type labelRecord struct {
+	Address string
+	Label   string
+}
+
+var _ interfaces.BinaryMarshallableAndCopyable = (*labelRecord)(nil)
+
+// labelData is labelRecord without its methods, for gob.
+type labelData labelRecord
+
+func (r *labelRecord) New() interfaces.BinaryMarshallableAndCopyable {
+	return new(labelRecord)
+}
+
+func (r *labelRecord) MarshalBinary() ([]byte, error) {
+	var data primitives.Buffer
+
+	enc := gob.NewEncoder(&data)
+	if err := enc.Encode(labelData(*r)); err != nil {
+		return nil, err
+	}
+	return data.DeepCopyBytes(), nil
+}
+
+func (r *labelRecord) UnmarshalBinaryData(data []byte) ([]byte, error) {
+	dec := gob.NewDecoder(primitives.NewBuffer(data))
+	if err := dec.Decode((*labelData)(r)); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (r *labelRecord) UnmarshalBinary(data []byte) error {
+	_, err := r.UnmarshalBinaryData(data)
+	return err
+}
+
+// SetAddressLabel attaches a human readable label to a Factoid or Entry
+// Credit Address in the wallet. An empty label removes it.
+func (w *Wallet) SetAddressLabel(pub, label string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+
+	var err error
+	switch factom.AddressStringType(pub) {
+	case factom.FactoidPub:
+		_, err = w.GetFCTAddress(pub)
+	case factom.ECPub:
+		_, err = w.GetECAddress(pub)
+	default:
+		return validationErrorf("wallet: %s is not a public address", pub)
+	}
+	if err != nil {
+		return err
+	}
+
+	if label == "" {
+		return dbError("delete", w.DBO.Delete(labelDBPrefix, []byte(pub)))
+	}
+	r := &labelRecord{Address: pub, Label: label}
+	return dbError("write", w.DBO.Put(labelDBPrefix, []byte(pub), r))
+}
+
+// AddressLabel returns the label of the address pub, or "" if it has none.
+func (w *Wallet) AddressLabel(pub string) (string, error) {
+	data, err := w.DBO.Get(labelDBPrefix, []byte(pub), new(labelRecord))
+	if err != nil {
+		return "", dbError("read", err)
+	}
+	if data == nil {
+		return "", nil
+	}
+	return data.(*labelRecord).Label, nil
+}
+
+// GetAllAddressLabels returns the label of every labeled address by public
+// address string.
+func (w *Wallet) GetAllAddressLabels() (map[string]string, error) {
+	list, err := w.DBO.FetchAllBlocksFromBucket(labelDBPrefix, new(labelRecord))
+	if err != nil {
+		return nil, dbError("read", err)
+	}
+
+	labels := make(map[string]string, len(list))
+	for _, v := range list {
+		r := v.(*labelRecord)
+		labels[r.Address] = r.Label
+	}
+	return labels, nil
+}
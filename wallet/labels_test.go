@@ -0,0 +1,68 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestAddressLabels(t *testing.T) {
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	f, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := w1.GenerateECAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l, err := w1.AddressLabel(f.String()); err != nil || l != "" {
+		t.Errorf("expected no label, got %q %v", l, err)
+	}
+	if err := w1.SetAddressLabel(f.String(), "treasury"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.SetAddressLabel(e.String(), "entries"); err != nil {
+		t.Fatal(err)
+	}
+	if l, err := w1.AddressLabel(f.String()); err != nil || l != "treasury" {
+		t.Errorf("expected treasury, got %q %v", l, err)
+	}
+
+	// only addresses in the wallet can be labeled
+	other, _ := factom.GetFactoidAddress("Fs1KWJrpLdfucvmYwN2nWrwepLn8ercpMbzXshd1g8zyhKXLVLWj")
+	if err := w1.SetAddressLabel(other.String(), "x"); !errors.Is(err, factom.ErrValidation) {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+
+	labels, err := w1.GetAllAddressLabels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 2 || labels[e.String()] != "entries" {
+		t.Errorf("wrong labels %v", labels)
+	}
+
+	// an empty label and removing the address both drop the label
+	if err := w1.SetAddressLabel(e.String(), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.RemoveAddress(f.String()); err != nil {
+		t.Fatal(err)
+	}
+	if labels, err := w1.GetAllAddressLabels(); err != nil || len(labels) != 0 {
+		t.Errorf("expected no labels, got %v %v", labels, err)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"encoding/gob"
+
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factomd/common/interfaces"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+// DefaultNamespace holds every key that has not been moved to a namespace.
+const DefaultNamespace = ""
+
+// namespaceRecord is the namespace of one key in the wallet database.
+type namespaceRecord struct {
+	Key       string
+	Namespace string
+}
+
+var _ interfaces.BinaryMarshallableAndCopyable = (*namespaceRecord)(nil)
+
+// namespaceData is namespaceRecord without its methods, for gob.
+type namespaceData namespaceRecord
+
+func (r *namespaceRecord) New() interfaces.BinaryMarshallableAndCopyable {
+	return new(namespaceRecord)
+}
+
+func (r *namespaceRecord) MarshalBinary() ([]byte, error) {
+	var data primitives.Buffer
+
+	enc := gob.NewEncoder(&data)
+	if err := enc.Encode(namespaceData(*r)); err != nil {
+		return nil, err
+	}
+	return data.DeepCopyBytes(), nil
+}
+
+func (r *namespaceRecord) UnmarshalBinaryData(data []byte) ([]byte, error) {
+	dec := gob.NewDecoder(primitives.NewBuffer(data))
+	if err := dec.Decode((*namespaceData)(r)); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (r *namespaceRecord) UnmarshalBinary(data []byte) error {
+	_, err := r.UnmarshalBinaryData(data)
+	return err
+}
+
+// SetNamespace moves the address or identity key with the public string pub
+// into a namespace. Namespaces partition the keys of a wallet so that api
+// clients can be given access to some of them only.
+func (w *Wallet) SetNamespace(pub, namespace string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+
+	var err error
+	switch {
+	case factom.AddressStringType(pub) == factom.FactoidPub:
+		_, err = w.GetFCTAddress(pub)
+	case factom.AddressStringType(pub) == factom.ECPub:
+		_, err = w.GetECAddress(pub)
+	case factom.IdentityKeyStringType(pub) == factom.IDPub:
+		_, err = w.GetIdentityKey(pub)
+	default:
+		return validationErrorf("wallet: %s is not a public key", pub)
+	}
+	if err != nil {
+		return err
+	}
+
+	if namespace == DefaultNamespace {
+		return dbError("delete", w.DBO.Delete(nsDBPrefix, []byte(pub)))
+	}
+	r := &namespaceRecord{Key: pub, Namespace: namespace}
+	return dbError("write", w.DBO.Put(nsDBPrefix, []byte(pub), r))
+}
+
+// Namespace returns the namespace of the key with the public string pub.
+// Keys that are not in the wallet are in the DefaultNamespace.
+func (w *Wallet) Namespace(pub string) (string, error) {
+	data, err := w.DBO.Get(nsDBPrefix, []byte(pub), new(namespaceRecord))
+	if err != nil {
+		return "", dbError("read", err)
+	}
+	if data == nil {
+		return DefaultNamespace, nil
+	}
+	return data.(*namespaceRecord).Namespace, nil
+}
+
+// GetAllNamespaces returns the namespace of every key that is not in the
+// DefaultNamespace, by public key string.
+func (w *Wallet) GetAllNamespaces() (map[string]string, error) {
+	list, err := w.DBO.FetchAllBlocksFromBucket(nsDBPrefix, new(namespaceRecord))
+	if err != nil {
+		return nil, dbError("read", err)
+	}
+
+	ns := make(map[string]string, len(list))
+	for _, v := range list {
+		r := v.(*namespaceRecord)
+		ns[r.Key] = r.Namespace
+	}
+	return ns, nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestNamespaces(t *testing.T) {
+	zSec := "Fs1KWJrpLdfucvmYwN2nWrwepLn8ercpMbzXshd1g8zyhKXLVLWj"
+
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	f, err := factom.GetFactoidAddress(zSec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.InsertFCTAddress(f); err != nil {
+		t.Fatal(err)
+	}
+	e, err := w1.GenerateECAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ns, err := w1.Namespace(f.String()); err != nil || ns != DefaultNamespace {
+		t.Errorf("expected the default namespace, got %q %v", ns, err)
+	}
+
+	if err := w1.SetNamespace(f.String(), "payroll"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.SetNamespace(e.String(), "publishing"); err != nil {
+		t.Fatal(err)
+	}
+	if ns, err := w1.Namespace(f.String()); err != nil || ns != "payroll" {
+		t.Errorf("expected the payroll namespace, got %q %v", ns, err)
+	}
+
+	all, err := w1.GetAllNamespaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[e.String()] != "publishing" {
+		t.Errorf("wrong namespaces %v", all)
+	}
+
+	// keys that are not in the wallet can not be moved
+	if err := w1.SetNamespace("FA3T1gTkuKGG2MWpAkskSoTnfjxZDKVaAYwziNTC1pAYH5B9A1rh", "payroll"); err == nil {
+		t.Error("expected an error for an address that is not in the wallet")
+	}
+	if err := w1.SetNamespace("not a key", "payroll"); !errors.Is(err, factom.ErrValidation) {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+
+	// a removed key leaves its namespace
+	if err := w1.RemoveAddress(f.String()); err != nil {
+		t.Fatal(err)
+	}
+	if ns, err := w1.Namespace(f.String()); err != nil || ns != DefaultNamespace {
+		t.Errorf("expected the default namespace, got %q %v", ns, err)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"encoding/gob"
+	"encoding/hex"
+
+	"github.com/FactomProject/factomd/common/interfaces"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+// TxNote is a memo and tags that the wallet keeps for a transaction, such as
+// an invoice number, so that payments can be matched up later.
+type TxNote struct {
+	Memo string            `json:"memo,omitempty"`
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+var _ interfaces.BinaryMarshallableAndCopyable = (*TxNote)(nil)
+
+// txNoteData is TxNote without its methods, for gob.
+type txNoteData TxNote
+
+func (n *TxNote) New() interfaces.BinaryMarshallableAndCopyable {
+	return new(TxNote)
+}
+
+func (n *TxNote) MarshalBinary() ([]byte, error) {
+	var data primitives.Buffer
+
+	enc := gob.NewEncoder(&data)
+	if err := enc.Encode(txNoteData(*n)); err != nil {
+		return nil, err
+	}
+	return data.DeepCopyBytes(), nil
+}
+
+func (n *TxNote) UnmarshalBinaryData(data []byte) ([]byte, error) {
+	dec := gob.NewDecoder(primitives.NewBuffer(data))
+	if err := dec.Decode((*txNoteData)(n)); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (n *TxNote) UnmarshalBinary(data []byte) error {
+	_, err := n.UnmarshalBinaryData(data)
+	return err
+}
+
+// noteKey returns the key of the note for ref, which is the name of a tmp
+// transaction or the id of a sent transaction, and whether it is a name. The
+// two can not be confused: names are at most 32 characters and ids are 64
+// hex digits.
+func noteKey(ref string) ([]byte, bool, error) {
+	if len(ref) == 64 {
+		if _, err := hex.DecodeString(ref); err == nil {
+			return []byte(ref), false, nil
+		}
+	}
+	if err := checkTxName(ref); err != nil {
+		return nil, false, validationErrorf("wallet: %q is neither a transaction name nor a transaction id", ref)
+	}
+	return tmpNoteKey(ref), true, nil
+}
+
+func tmpNoteKey(name string) []byte {
+	return []byte("tmp " + name)
+}
+
+// SetTransactionNote attaches memo and tags to the tmp transaction or the
+// sent transaction ref, its name or its id, replacing any note it had. The
+// note of a tmp transaction moves to its id when it is sent with
+// SendTransaction. An empty memo without tags removes the note.
+func (w *Wallet) SetTransactionNote(ref, memo string, tags map[string]string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	key, tmp, err := noteKey(ref)
+	if err != nil {
+		return err
+	}
+	if tmp && !w.TransactionExists(ref) {
+		return ErrTXNotExists
+	}
+	if memo == "" && len(tags) == 0 {
+		return dbError("delete", w.DBO.Delete(noteDBPrefix, key))
+	}
+	n := &TxNote{Memo: memo, Tags: tags}
+	return dbError("write", w.DBO.Put(noteDBPrefix, key, n))
+}
+
+// GetTransactionNote returns the note of the transaction ref, a tmp
+// transaction name or a transaction id, or nil if it has none.
+func (w *Wallet) GetTransactionNote(ref string) (*TxNote, error) {
+	key, _, err := noteKey(ref)
+	if err != nil {
+		return nil, err
+	}
+	data, err := w.DBO.Get(noteDBPrefix, key, new(TxNote))
+	if err != nil {
+		return nil, dbError("read", err)
+	}
+	if data == nil {
+		return nil, nil
+	}
+	return data.(*TxNote), nil
+}
+
+// moveNote moves the note of the tmp transaction name, if it has one, to the
+// transaction id txid.
+func (w *Wallet) moveNote(name, txid string) error {
+	n, err := w.GetTransactionNote(name)
+	if err != nil || n == nil {
+		return err
+	}
+	if err := w.SetTransactionNote(txid, n.Memo, n.Tags); err != nil {
+		return err
+	}
+	return w.removeNote(name)
+}
+
+// removeNote removes the note of the tmp transaction name.
+func (w *Wallet) removeNote(name string) error {
+	return dbError("delete", w.DBO.Delete(noteDBPrefix, tmpNoteKey(name)))
+}
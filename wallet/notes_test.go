@@ -0,0 +1,69 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestTransactionNote(t *testing.T) {
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	// a note needs a transaction to belong to
+	if err := w1.SetTransactionNote("tx", "invoice 42", nil); err != ErrTXNotExists {
+		t.Errorf("expected ErrTXNotExists, got %v", err)
+	}
+	if err := w1.NewTransaction("tx"); err != nil {
+		t.Fatal(err)
+	}
+	tags := map[string]string{"invoice": "42", "customer": "acme"}
+	if err := w1.SetTransactionNote("tx", "march rent", tags); err != nil {
+		t.Fatal(err)
+	}
+	n, err := w1.GetTransactionNote("tx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n == nil || n.Memo != "march rent" || n.Tags["invoice"] != "42" || len(n.Tags) != 2 {
+		t.Errorf("wrong note %+v", n)
+	}
+
+	// the note goes with the transaction
+	if err := w1.DeleteTransaction("tx"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.NewTransaction("tx"); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := w1.GetTransactionNote("tx"); err != nil || n != nil {
+		t.Errorf("got note %+v, %v for a new transaction", n, err)
+	}
+
+	// sent transactions are noted by id
+	txid := strings.Repeat("ab", 32)
+	if err := w1.SetTransactionNote(txid, "refund", nil); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := w1.GetTransactionNote(txid); err != nil || n == nil || n.Memo != "refund" {
+		t.Errorf("got note %+v, %v", n, err)
+	}
+	if err := w1.SetTransactionNote(txid, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := w1.GetTransactionNote(txid); err != nil || n != nil {
+		t.Errorf("note %+v was not removed: %v", n, err)
+	}
+
+	if err := w1.SetTransactionNote("not a name!", "memo", nil); err == nil {
+		t.Error("note was set for an invalid name")
+	}
+}
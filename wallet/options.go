@@ -0,0 +1,218 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/FactomProject/factomd/common/factoid"
+)
+
+// storage backends that can be selected with an Option
+const (
+	mapDBBackend   = "map"
+	levelDBBackend = "level"
+	boltDBBackend  = "bolt"
+	sqliteBackend  = "sqlite"
+	storeBackend   = "store"
+)
+
+type options struct {
+	backend   string
+	path      string
+	store     WalletStore
+	encrypted bool
+	password  string
+	txdb      *TXDatabaseOverlay
+	logger    *log.Logger
+	retry     *RetryPolicy
+	readOnly  bool
+	signer    Signer
+	approval  uint64
+	txTTL     time.Duration
+}
+
+// Option configures a Wallet created with New.
+type Option func(*options)
+
+// WithMapDB stores the wallet in memory. The wallet is lost when it is closed.
+func WithMapDB() Option {
+	return func(o *options) {
+		o.backend = mapDBBackend
+		o.path = ""
+	}
+}
+
+// WithLevelDB stores the wallet in a LevelDB database at path.
+func WithLevelDB(path string) Option {
+	return func(o *options) {
+		o.backend = levelDBBackend
+		o.path = path
+	}
+}
+
+// WithBoltDB stores the wallet in a Bolt database file at path.
+func WithBoltDB(path string) Option {
+	return func(o *options) {
+		o.backend = boltDBBackend
+		o.path = path
+	}
+}
+
+// WithSQLite stores the wallet in an SQLite database file at path. See
+// NewSQLiteStore for the driver it needs.
+func WithSQLite(path string) Option {
+	return func(o *options) {
+		o.backend = sqliteBackend
+		o.path = path
+	}
+}
+
+// WithStore stores the wallet in s, which is closed with the wallet.
+func WithStore(s WalletStore) Option {
+	return func(o *options) {
+		o.backend = storeBackend
+		o.path = ""
+		o.store = s
+	}
+}
+
+// WithEncryption encrypts the wallet database with password. Only the Bolt
+// backend supports encryption. An empty password opens the wallet locked; it
+// must be unlocked with the passphrase before it can be used.
+func WithEncryption(password string) Option {
+	return func(o *options) {
+		o.encrypted = true
+		o.password = password
+	}
+}
+
+// WithTXDB attaches a local transaction cache to the wallet.
+func WithTXDB(db *TXDatabaseOverlay) Option {
+	return func(o *options) {
+		o.txdb = db
+	}
+}
+
+// WithRetryPolicy retries the wallet's failed calls to factomd according to
+// p.
+func WithRetryPolicy(p *RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = p
+	}
+}
+
+// WithReadOnly opens the wallet in read only mode. See Wallet.SetReadOnly.
+func WithReadOnly() Option {
+	return func(o *options) {
+		o.readOnly = true
+	}
+}
+
+// WithSigner makes the wallet use s for all of its signatures. See
+// Wallet.SetSigner.
+func WithSigner(s Signer) Option {
+	return func(o *options) {
+		o.signer = s
+	}
+}
+
+// WithApprovalThreshold makes transactions above factoshis wait for
+// approval before they are signed. See Wallet.SetApprovalThreshold.
+func WithApprovalThreshold(factoshis uint64) Option {
+	return func(o *options) {
+		o.approval = factoshis
+	}
+}
+
+// WithTmpTxTTL deletes unsigned tmp transactions that have not been changed
+// for ttl. See Wallet.SetTmpTxTTL.
+func WithTmpTxTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.txTTL = ttl
+	}
+}
+
+// WithLogger logs wallet events to l.
+func WithLogger(l *log.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// New creates or opens a Wallet configured by the given options. Without any
+// options the wallet is kept in memory.
+func New(opts ...Option) (*Wallet, error) {
+	o := &options{backend: mapDBBackend}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.encrypted && o.backend != boltDBBackend {
+		return nil, fmt.Errorf("wallet: encryption is only supported by the bolt backend")
+	}
+	if o.backend == storeBackend && o.store == nil {
+		return nil, fmt.Errorf("wallet: the store backend requires a WalletStore")
+	}
+	if o.backend != mapDBBackend && o.backend != storeBackend && o.path == "" {
+		return nil, fmt.Errorf("wallet: a database path is required for the %s backend", o.backend)
+	}
+
+	var (
+		w   *Wallet
+		err error
+	)
+	switch {
+	case o.encrypted && o.password == "":
+		w, err = NewEncryptedBoltDBWalletAwaitingPassphrase(o.path)
+	case o.encrypted:
+		w, err = NewEncryptedBoltDBWallet(o.path, o.password)
+	case o.backend == boltDBBackend:
+		w, err = NewOrOpenBoltDBWallet(o.path)
+	case o.backend == levelDBBackend:
+		w, err = NewOrOpenLevelDBWallet(o.path)
+	case o.backend == sqliteBackend:
+		w, err = NewOrOpenSQLiteWallet(o.path)
+	case o.backend == storeBackend:
+		w, err = NewOrOpenStoreWallet(o.store)
+	case o.backend == mapDBBackend:
+		w, err = NewMapDBWallet()
+	default:
+		return nil, fmt.Errorf("wallet: unknown storage backend %q", o.backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	w.logger = o.logger
+	w.retry = o.retry
+	w.readOnly = o.readOnly
+	w.signer = o.signer
+	w.approvalThreshold = o.approval
+	w.txTTL = o.txTTL
+	if o.txdb != nil {
+		w.AddTXDB(o.txdb)
+	}
+	w.logf("opened %s wallet %s", o.backend, o.path)
+
+	return w, nil
+}
+
+// newWallet returns a Wallet with its in memory state initialized but no
+// database attached.
+func newWallet() *Wallet {
+	w := new(Wallet)
+	w.transactions = make(map[string]*factoid.Transaction)
+	w.txModified = make(map[string]time.Time)
+	return w
+}
+
+func (w *Wallet) logf(format string, v ...interface{}) {
+	if w.logger != nil {
+		w.logger.Printf(format, v...)
+	}
+}
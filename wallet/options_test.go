@@ -0,0 +1,66 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	w, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	seed, err := w.GetDBSeed()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(seed.MnemonicSeed) == 0 {
+		t.Errorf("stored db seed is empty")
+	}
+}
+
+func TestNewWithLevelDB(t *testing.T) {
+	dbpath := os.TempDir() + "/test_wallet-options"
+	defer os.RemoveAll(dbpath)
+
+	w1, err := New(WithLevelDB(dbpath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed1, err := w1.GetDBSeed()
+	if err != nil {
+		t.Error(err)
+	}
+	w1.Close()
+
+	// reopening the same path should give back the same seed
+	w2, err := New(WithLevelDB(dbpath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w2.Close()
+	seed2, err := w2.GetDBSeed()
+	if err != nil {
+		t.Error(err)
+	}
+	if seed1.MnemonicSeed != seed2.MnemonicSeed {
+		t.Errorf("seed changed after reopening the wallet")
+	}
+}
+
+func TestNewBadOptions(t *testing.T) {
+	if _, err := New(WithLevelDB("")); err == nil {
+		t.Errorf("expected an error for a missing database path")
+	}
+	if _, err := New(WithLevelDB("x"), WithEncryption("pass")); err == nil {
+		t.Errorf("expected an error for encryption with the leveldb backend")
+	}
+}
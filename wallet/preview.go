@@ -0,0 +1,113 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factomd/common/factoid"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+// TransactionPreview is the breakdown of a tmp transaction returned by
+// PreviewTransaction.
+type TransactionPreview struct {
+	Name           string                 `json:"tx-name"`
+	Inputs         []*factom.TransAddress `json:"inputs"`
+	Outputs        []*factom.TransAddress `json:"outputs"`
+	ECOutputs      []*factom.TransAddress `json:"ecoutputs"`
+	TotalInputs    uint64                 `json:"totalinputs"`
+	TotalOutputs   uint64                 `json:"totaloutputs"`
+	TotalECOutputs uint64                 `json:"totalecoutputs"`
+
+	// Fee is the fee the transaction needs at the current entry credit
+	// Rate.
+	Rate uint64 `json:"rate"`
+	Fee  uint64 `json:"fee"`
+
+	// Change is what the inputs leave after the outputs and the fee, or
+	// how much they fall short when it is negative. A Factoid transaction
+	// has no change output: whatever is left is paid as fee.
+	Change int64 `json:"change"`
+
+	Signed bool `json:"signed"`
+
+	// Errors are the reasons the transaction can not be signed and sent as
+	// it is.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// PreviewTransaction returns the breakdown of the tmp transaction name with
+// the fee at the current rate and everything that keeps it from being signed
+// and sent. The transaction is not changed.
+func (w *Wallet) PreviewTransaction(name string) (*TransactionPreview, error) {
+	tx, err := w.GetTransaction(name)
+	if err != nil {
+		return nil, err
+	}
+	rate, err := getRate(w.retry)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &TransactionPreview{Name: name, Rate: rate}
+	for _, in := range tx.GetInputs() {
+		p.Inputs = append(p.Inputs, &factom.TransAddress{
+			Address: primitives.ConvertFctAddressToUserStr(in.GetAddress()),
+			Amount:  in.GetAmount(),
+		})
+	}
+	p.Outputs, p.ECOutputs = txOutputs(tx)
+	if p.TotalInputs, err = tx.TotalInputs(); err != nil {
+		return nil, err
+	}
+	if p.TotalOutputs, err = tx.TotalOutputs(); err != nil {
+		return nil, err
+	}
+	if p.TotalECOutputs, err = tx.TotalECs(); err != nil {
+		return nil, err
+	}
+	if p.Fee, err = tx.CalculateFee(rate); err != nil {
+		return nil, err
+	}
+	p.Change = int64(p.TotalInputs) - int64(p.TotalOutputs) - int64(p.TotalECOutputs) - int64(p.Fee)
+	p.Signed = len(tx.GetSignatureBlocks()) > 0 && tx.ValidateSignatures() == nil
+
+	// the same checks SignTransaction makes without force
+	if len(p.Inputs) == 0 {
+		p.Errors = append(p.Errors, ErrTXNoInputs.Error())
+	}
+	if len(p.Outputs)+len(p.ECOutputs) == 0 {
+		p.Errors = append(p.Errors, "wallet: Transaction has no outputs")
+	}
+	if err := checkCovered(tx, w.retry); err != nil {
+		p.Errors = append(p.Errors, err.Error())
+	}
+	if len(p.Inputs) > 0 {
+		if err := checkFee(tx, w.retry); err != nil {
+			p.Errors = append(p.Errors, err.Error())
+		}
+	}
+	if pending, _ := w.NeedsApproval(name); pending {
+		p.Errors = append(p.Errors, ErrApprovalRequired.Error())
+	}
+	return p, nil
+}
+
+// txOutputs returns the Factoid and Entry Credit outputs of tx.
+func txOutputs(tx *factoid.Transaction) (outs, ecs []*factom.TransAddress) {
+	for _, out := range tx.GetOutputs() {
+		outs = append(outs, &factom.TransAddress{
+			Address: primitives.ConvertFctAddressToUserStr(out.GetAddress()),
+			Amount:  out.GetAmount(),
+		})
+	}
+	for _, ec := range tx.GetECOutputs() {
+		ecs = append(ecs, &factom.TransAddress{
+			Address: primitives.ConvertECAddressToUserStr(ec.GetAddress()),
+			Amount:  ec.GetAmount(),
+		})
+	}
+	return outs, ecs
+}
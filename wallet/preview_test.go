@@ -0,0 +1,86 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestPreviewTransaction(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(factom.JSON2Request)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+		switch req.Method {
+		case "factoid-balance":
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"balance": 100000000}}`)
+		case "entry-credit-rate":
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"rate": 1000}}`)
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+		}
+	}))
+	defer ts.Close()
+	factom.SetFactomdServer(ts.URL[7:])
+
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	f1, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"
+	if err := w1.NewTransaction("tx"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddInput("tx", f1.String(), 5e7); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddOutput("tx", to, 4e7); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := w1.PreviewTransaction("tx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.TotalInputs != 5e7 || p.TotalOutputs != 4e7 || p.Rate != 1000 {
+		t.Errorf("wrong preview %+v", p)
+	}
+	if p.Change != int64(1e7-p.Fee) {
+		t.Errorf("change %d, want %d", p.Change, int64(1e7-p.Fee))
+	}
+	// the input pays far more than the fee
+	if len(p.Errors) != 1 {
+		t.Errorf("got errors %v, want the overpaid fee", p.Errors)
+	}
+
+	// an input that pays the output exactly and then the fee
+	if err := w1.AddInput("tx", f1.String(), 4e7); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddFee("tx", f1.String(), 1000); err != nil {
+		t.Fatal(err)
+	}
+	if p, err = w1.PreviewTransaction("tx"); err != nil {
+		t.Fatal(err)
+	}
+	if p.Change != 0 || len(p.Errors) != 0 || p.Signed {
+		t.Errorf("wrong preview %+v", p)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"errors"
+
+	"github.com/FactomProject/factom"
+)
+
+// ErrReadOnly is returned by methods that change the wallet or export secrets
+// when the wallet is in read only mode.
+var ErrReadOnly = errors.New("wallet: Wallet is read only")
+
+// SetReadOnly turns read only mode on or off. In read only mode the wallet
+// refuses to store, remove, or generate keys, to build or sign transactions,
+// and to export its seed.
+func (w *Wallet) SetReadOnly(readOnly bool) {
+	w.readOnly = readOnly
+}
+
+// ReadOnly reports whether the wallet is in read only mode.
+func (w *Wallet) ReadOnly() bool {
+	return w.readOnly
+}
+
+// InsertFCTAddress stores a Factoid Address in the wallet database.
+func (w *Wallet) InsertFCTAddress(e *factom.FactoidAddress) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	return w.WalletDatabaseOverlay.InsertFCTAddress(e)
+}
+
+// InsertECAddress stores an Entry Credit Address in the wallet database.
+func (w *Wallet) InsertECAddress(e *factom.ECAddress) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	return w.WalletDatabaseOverlay.InsertECAddress(e)
+}
+
+// InsertIdentityKey stores an Identity Key in the wallet database.
+func (w *Wallet) InsertIdentityKey(e *factom.IdentityKey) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	return w.WalletDatabaseOverlay.InsertIdentityKey(e)
+}
+
+// RemoveAddress deletes a Factoid or Entry Credit Address, or a watch only
+// address, from the wallet database.
+func (w *Wallet) RemoveAddress(pubString string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	if ok, err := w.IsWatchOnly(pubString); err != nil {
+		return err
+	} else if ok {
+		return dbError("delete", w.DBO.Delete(watchDBPrefix, []byte(pubString)))
+	}
+	if ok, err := w.IsSignerKey(pubString); err != nil {
+		return err
+	} else if ok {
+		// the key itself stays with the signer
+		return dbError("delete", w.DBO.Delete(signerDBPrefix, []byte(pubString)))
+	}
+	if err := w.WalletDatabaseOverlay.RemoveAddress(pubString); err != nil {
+		return err
+	}
+
+	// the key leaves its namespace and label with it
+	if err := w.DBO.Delete(labelDBPrefix, []byte(pubString)); err != nil {
+		return dbError("delete", err)
+	}
+	return dbError("delete", w.DBO.Delete(nsDBPrefix, []byte(pubString)))
+}
+
+// RemoveFCTAddress deletes a Factoid Address from the wallet database and
+// returns it with its secret so the caller can keep a final backup.
+func (w *Wallet) RemoveFCTAddress(pubString string) (*factom.FactoidAddress, error) {
+	if factom.AddressStringType(pubString) != factom.FactoidPub {
+		return nil, validationErrorf("%s is not a Factoid Address", pubString)
+	}
+	f, err := w.GetFCTAddress(pubString)
+	if err != nil {
+		return nil, w.keyError(pubString, err)
+	}
+	if err := w.RemoveAddress(pubString); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// RemoveECAddress deletes an Entry Credit Address from the wallet database
+// and returns it with its secret so the caller can keep a final backup.
+func (w *Wallet) RemoveECAddress(pubString string) (*factom.ECAddress, error) {
+	if factom.AddressStringType(pubString) != factom.ECPub {
+		return nil, validationErrorf("%s is not an Entry Credit Address", pubString)
+	}
+	e, err := w.GetECAddress(pubString)
+	if err != nil {
+		return nil, w.keyError(pubString, err)
+	}
+	if err := w.RemoveAddress(pubString); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// RemoveIdentityKey deletes an Identity Key from the wallet database.
+func (w *Wallet) RemoveIdentityKey(pubString string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	if err := w.WalletDatabaseOverlay.RemoveIdentityKey(pubString); err != nil {
+		return err
+	}
+
+	// the key leaves its namespace with it
+	return dbError("delete", w.DBO.Delete(nsDBPrefix, []byte(pubString)))
+}
@@ -0,0 +1,53 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"testing"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestReadOnly(t *testing.T) {
+	zSec := "Fs1KWJrpLdfucvmYwN2nWrwepLn8ercpMbzXshd1g8zyhKXLVLWj"
+
+	w1, err := New(WithMapDB(), WithReadOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	if !w1.ReadOnly() {
+		t.Errorf("wallet is not read only")
+	}
+
+	f, err := factom.GetFactoidAddress(zSec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.InsertFCTAddress(f); err != ErrReadOnly {
+		t.Errorf("expected ErrReadOnly from InsertFCTAddress, got %v", err)
+	}
+	if _, err := w1.GenerateFCTAddress(); err != ErrReadOnly {
+		t.Errorf("expected ErrReadOnly from GenerateFCTAddress, got %v", err)
+	}
+	if _, err := w1.GetSeed(); err != ErrReadOnly {
+		t.Errorf("expected ErrReadOnly from GetSeed, got %v", err)
+	}
+	if err := w1.NewTransaction("tx-01"); err != ErrReadOnly {
+		t.Errorf("expected ErrReadOnly from NewTransaction, got %v", err)
+	}
+
+	// reading is still allowed
+	if _, _, err := w1.GetAllAddresses(); err != nil {
+		t.Error(err)
+	}
+
+	w1.SetReadOnly(false)
+	if err := w1.InsertFCTAddress(f); err != nil {
+		t.Error(err)
+	}
+}
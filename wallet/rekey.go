@@ -0,0 +1,165 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+
+	"github.com/FactomProject/factomd/common/interfaces"
+)
+
+var (
+	// rekeyDBPrefix is the staging bucket of the records written by Rekey.
+	// Its keys are the bucket and key of each record joined by recordData.
+	rekeyDBPrefix = []byte("Wallet Rekey")
+
+	// pendingDBKey is the key of the new encryption header while a Rekey is
+	// being committed.
+	pendingDBKey = []byte("pending")
+)
+
+// Rekey changes the passphrase of a wallet encrypted with Encrypt and
+// re-encrypts every record with the key derived from the new passphrase. The
+// wallet stays locked or unlocked as it was.
+//
+// The change is committed in two phases so that an interrupted Rekey leaves
+// the wallet readable with one of the two passphrases. First the re-encrypted
+// records are written to a staging bucket and the new header is stored as the
+// pending header. Then the staged records and the header are copied over the
+// old ones in one batch. A wallet opened with a pending header finishes the
+// copy, while staged records without a pending header are dropped.
+func (w *Wallet) Rekey(oldPassphrase, newPassphrase string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	db, ok := w.sealedDB()
+	if !ok {
+		if w.Encrypted {
+			return validationErrorf("wallet: Only wallets encrypted with Encrypt can change their passphrase")
+		}
+		return ErrNotEncrypted
+	}
+	if newPassphrase == "" {
+		return validationErrorf("wallet: An encrypted wallet needs a passphrase")
+	}
+
+	// writes wait until the records have their new key
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	oldKey, err := db.header.open(oldPassphrase)
+	if err != nil {
+		return err
+	}
+	defer oldKey.Wipe()
+	oldAEAD, err := newAEAD(oldKey)
+	if err != nil {
+		return err
+	}
+	h, key, err := newEncryptionHeader(newPassphrase)
+	if err != nil {
+		return err
+	}
+	// an unlocked wallet keeps the new key, see below
+	kept := false
+	defer func() {
+		if !kept {
+			key.Wipe()
+		}
+	}()
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	raw := db.IDatabase
+	buckets, err := raw.ListAllBuckets()
+	if err != nil {
+		return dbError("list", err)
+	}
+	var staged []interfaces.Record
+	for _, b := range buckets {
+		if bytes.Equal(b, encryptionDBPrefix) || bytes.Equal(b, rekeyDBPrefix) {
+			continue
+		}
+		data, keys, err := raw.GetAll(b, new(rawRecord))
+		if err != nil {
+			return dbError("get-all", err)
+		}
+		for i := range data {
+			ad := recordData(b, keys[i])
+			plain, err := open(oldAEAD, data[i].(*rawRecord).data, ad)
+			if err != nil {
+				return err
+			}
+			sealed, err := seal(aead, plain, ad)
+			if err != nil {
+				return err
+			}
+			staged = append(staged, interfaces.Record{Bucket: rekeyDBPrefix, Key: ad, Data: &rawRecord{data: sealed}})
+		}
+	}
+
+	// phase one: stage the records, then mark the new header as pending
+	if err := raw.PutInBatch(staged); err != nil {
+		return dbError("put-batch", err)
+	}
+	if err := raw.Put(encryptionDBPrefix, pendingDBKey, h); err != nil {
+		return dbError("put", err)
+	}
+
+	// phase two: copy the staged records over the old ones
+	if err := finishRekey(raw); err != nil {
+		return err
+	}
+
+	db.header = h
+	if db.key != nil {
+		db.key.Wipe()
+		db.key = key
+		kept = true
+	}
+	w.logf("changed the wallet passphrase")
+	return nil
+}
+
+// finishRekey completes a Rekey of db that has a pending header and drops the
+// staged records of one that does not. It needs no key, so it is run when an
+// encrypted wallet is opened.
+func finishRekey(db interfaces.IDatabase) error {
+	data, keys, err := db.GetAll(rekeyDBPrefix, new(rawRecord))
+	if err != nil {
+		return dbError("get-all", err)
+	}
+	pending, err := db.Get(encryptionDBPrefix, pendingDBKey, new(encryptionHeader))
+	if err != nil {
+		return dbError("get", err)
+	}
+
+	if pending != nil {
+		records := make([]interfaces.Record, 0, len(data)+1)
+		for i := range data {
+			n := bytes.IndexByte(keys[i], 0)
+			if n < 0 {
+				continue
+			}
+			records = append(records, interfaces.Record{Bucket: keys[i][:n], Key: keys[i][n+1:], Data: data[i]})
+		}
+		records = append(records, interfaces.Record{Bucket: encryptionDBPrefix, Key: encryptionDBKey, Data: pending})
+		if err := db.PutInBatch(records); err != nil {
+			return dbError("put-batch", err)
+		}
+		if err := db.Delete(encryptionDBPrefix, pendingDBKey); err != nil {
+			return dbError("delete", err)
+		}
+	}
+
+	if len(keys) > 0 {
+		if err := db.Clear(rekeyDBPrefix); err != nil {
+			return dbError("clear", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,484 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+// DefaultGapLimit is the number of unused addresses in a row after which a
+// restore stops deriving addresses from the seed.
+const DefaultGapLimit = 20
+
+// Metadata is the wallet data that can not be derived from the seed. It is
+// written to a sidecar file next to a seed backup and read back by Restore.
+type Metadata struct {
+	// IdentityKeys is the number of identity keys derived from the seed.
+	// Identity keys leave no trace on the blockchain, so they can not be
+	// found by a scan.
+	IdentityKeys uint32 `json:"identitykeys,omitempty"`
+
+	// FCTAddresses and ECAddresses are the number of addresses derived from
+	// the seed. When they are set Restore derives exactly these addresses
+	// instead of scanning the blockchain for used ones.
+	FCTAddresses uint32 `json:"fctaddresses,omitempty"`
+	ECAddresses  uint32 `json:"ecaddresses,omitempty"`
+
+	// FCTPath and ECPath are the derivation path templates set with
+	// SetDerivationPaths, if any.
+	FCTPath string `json:"fctpath,omitempty"`
+	ECPath  string `json:"ecpath,omitempty"`
+
+	Bookmarks  []*Bookmark       `json:"bookmarks,omitempty"`
+	Namespaces map[string]string `json:"namespaces,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Scheduled  []*ScheduledTx    `json:"scheduled,omitempty"`
+}
+
+// GetMetadata collects the Metadata of the wallet.
+func (w *Wallet) GetMetadata() (*Metadata, error) {
+	m := new(Metadata)
+
+	seed, err := w.GetDBSeed()
+	if err != nil {
+		return nil, err
+	}
+	if seed != nil {
+		m.IdentityKeys = seed.NextIdentityKeyIndex
+		m.FCTAddresses = seed.NextFactoidAddressIndex
+		m.ECAddresses = seed.NextECAddressIndex
+		m.FCTPath = seed.FCTPath
+		m.ECPath = seed.ECPath
+	}
+
+	if m.Bookmarks, err = w.GetAllBookmarks(); err != nil {
+		return nil, err
+	}
+	if m.Namespaces, err = w.GetAllNamespaces(); err != nil {
+		return nil, err
+	}
+	if m.Labels, err = w.GetAllAddressLabels(); err != nil {
+		return nil, err
+	}
+	if m.Scheduled, err = w.GetAllScheduledTxs(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// WriteMetadata writes the Metadata of the wallet to a sidecar file at path.
+func (w *Wallet) WriteMetadata(path string) error {
+	m, err := w.GetMetadata()
+	if err != nil {
+		return err
+	}
+	p, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, p, 0600)
+}
+
+// ReadMetadata reads a sidecar file written by WriteMetadata.
+func ReadMetadata(path string) (*Metadata, error) {
+	p, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := new(Metadata)
+	if err := json.Unmarshal(p, m); err != nil {
+		return nil, validationErrorf("wallet: %s is not a metadata file: %v", path, err)
+	}
+	return m, nil
+}
+
+// RestoreOptions are the optional parts of a Restore.
+type RestoreOptions struct {
+	// GapLimit is the number of unused addresses in a row after which the
+	// scan stops. 0 uses DefaultGapLimit.
+	GapLimit uint32
+
+	// Secrets are Factoid, Entry Credit and identity secret keys that were
+	// imported into the old wallet and can not be derived from the seed.
+	Secrets []string
+
+	// Metadata is the path of a sidecar file written by WriteMetadata.
+	Metadata string
+}
+
+// RestoredKey is a key recovered by Restore.
+type RestoredKey struct {
+	Public string `json:"public"`
+
+	// Index is the position of the key on its derivation path. It is not
+	// set for imported keys.
+	Index    uint32 `json:"index"`
+	Imported bool   `json:"imported,omitempty"`
+
+	// Balance is the balance of an address when it was scanned.
+	Balance int64 `json:"balance"`
+}
+
+// RestoreReport lists what Restore recovered so it can be checked against
+// the old wallet.
+type RestoreReport struct {
+	FCTAddresses []*RestoredKey `json:"fctaddresses"`
+	ECAddresses  []*RestoredKey `json:"ecaddresses"`
+	IdentityKeys []*RestoredKey `json:"identitykeys"`
+
+	// FCTBalance and ECBalance are the totals of the restored addresses.
+	FCTBalance int64 `json:"fctbalance"`
+	ECBalance  int64 `json:"ecbalance"`
+
+	Bookmarks  int `json:"bookmarks"`
+	Namespaces int `json:"namespaces"`
+	Labels     int `json:"labels"`
+	Scheduled  int `json:"scheduled"`
+
+	// Problems are parts of the metadata that could not be restored, such as
+	// a namespace for a key that is not in the wallet.
+	Problems []string `json:"problems,omitempty"`
+}
+
+// Restore rebuilds an empty wallet from a mnemonic seed. Factoid and Entry
+// Credit addresses are derived from the seed until opts.GapLimit addresses
+// in a row are unused, where an address is used if it has a balance or
+// appears in the transaction database of the wallet. The addresses up to the
+// last used one are stored and the seed is set to continue after them. If
+// the sidecar metadata counts the derived addresses, exactly those are
+// derived instead and no scan is made. The extra secrets and the sidecar
+// metadata are then added to the wallet.
+//
+// All secrets are checked before the wallet is changed. Restore fails if the
+// wallet already has keys.
+func (w *Wallet) Restore(mnemonic string, opts RestoreOptions) (*RestoreReport, error) {
+	if w.readOnly {
+		return nil, ErrReadOnly
+	}
+	mnemonic, err := factom.ParseAndValidateMnemonic(mnemonic)
+	if err != nil {
+		return nil, validationErrorf("wallet: %v", err)
+	}
+	if opts.GapLimit == 0 {
+		opts.GapLimit = DefaultGapLimit
+	}
+
+	fcts, ecs, ids, err := parseSecrets(opts.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	var meta *Metadata
+	if opts.Metadata != "" {
+		if meta, err = ReadMetadata(opts.Metadata); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.checkEmpty(); err != nil {
+		return nil, err
+	}
+
+	seed := &DBSeed{DBSeedBase{MnemonicSeed: mnemonic}}
+	if meta != nil {
+		seed.FCTPath, seed.ECPath = meta.FCTPath, meta.ECPath
+	}
+	deriveFCT := func(i uint32) (string, error) {
+		a, err := seed.FCTAddress(i)
+		if err != nil {
+			return "", err
+		}
+		return a.String(), nil
+	}
+	deriveEC := func(i uint32) (string, error) {
+		a, err := seed.ECAddress(i)
+		if err != nil {
+			return "", err
+		}
+		return a.PubString(), nil
+	}
+
+	r := new(RestoreReport)
+	if meta != nil && (meta.FCTAddresses != 0 || meta.ECAddresses != 0) {
+		// the metadata counts the derived addresses, so there is nothing to
+		// scan for
+		if r.FCTAddresses, err = deriveKeys(meta.FCTAddresses, deriveFCT); err != nil {
+			return nil, err
+		}
+		if r.ECAddresses, err = deriveKeys(meta.ECAddresses, deriveEC); err != nil {
+			return nil, err
+		}
+	} else {
+		used, err := w.usedAddresses()
+		if err != nil {
+			return nil, err
+		}
+		r.FCTAddresses, err = w.scanAddresses(opts.GapLimit, used, deriveFCT, factom.GetFactoidBalance)
+		if err != nil {
+			return nil, err
+		}
+		r.ECAddresses, err = w.scanAddresses(opts.GapLimit, used, deriveEC, factom.GetECBalance)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// replace the random seed of the new wallet and store the keys in the
+	// order they were derived
+	if err := w.InsertDBSeed(seed); err != nil {
+		return nil, err
+	}
+	for range r.FCTAddresses {
+		if _, err := w.GetNextFCTAddress(); err != nil {
+			return nil, err
+		}
+	}
+	for range r.ECAddresses {
+		if _, err := w.GetNextECAddress(); err != nil {
+			return nil, err
+		}
+	}
+	if meta != nil {
+		for i := uint32(0); i < meta.IdentityKeys; i++ {
+			k, err := w.GetNextIdentityKey()
+			if err != nil {
+				return nil, err
+			}
+			r.IdentityKeys = append(r.IdentityKeys, &RestoredKey{Public: k.PubString(), Index: i})
+		}
+	}
+
+	for _, a := range fcts {
+		if err := w.InsertFCTAddress(a); err != nil {
+			return nil, err
+		}
+		k := &RestoredKey{Public: a.String(), Imported: true}
+		if err := w.retry.Do(func() (err error) {
+			k.Balance, err = factom.GetFactoidBalance(k.Public)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		r.FCTAddresses = append(r.FCTAddresses, k)
+	}
+	for _, a := range ecs {
+		if err := w.InsertECAddress(a); err != nil {
+			return nil, err
+		}
+		k := &RestoredKey{Public: a.PubString(), Imported: true}
+		if err := w.retry.Do(func() (err error) {
+			k.Balance, err = factom.GetECBalance(k.Public)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		r.ECAddresses = append(r.ECAddresses, k)
+	}
+	for _, id := range ids {
+		if err := w.InsertIdentityKey(id); err != nil {
+			return nil, err
+		}
+		r.IdentityKeys = append(r.IdentityKeys, &RestoredKey{Public: id.PubString(), Imported: true})
+	}
+
+	for _, k := range r.FCTAddresses {
+		r.FCTBalance += k.Balance
+	}
+	for _, k := range r.ECAddresses {
+		r.ECBalance += k.Balance
+	}
+
+	if meta != nil {
+		if err := w.applyMetadata(meta, r); err != nil {
+			return nil, err
+		}
+	}
+
+	w.logf("restored %d factoid and %d entry credit addresses and %d identity keys",
+		len(r.FCTAddresses), len(r.ECAddresses), len(r.IdentityKeys))
+	return r, nil
+}
+
+// parseSecrets sorts secret key strings into their key types.
+func parseSecrets(secrets []string) (
+	[]*factom.FactoidAddress, []*factom.ECAddress, []*factom.IdentityKey, error) {
+	var (
+		fcts []*factom.FactoidAddress
+		ecs  []*factom.ECAddress
+		ids  []*factom.IdentityKey
+	)
+	for _, s := range secrets {
+		switch {
+		case factom.AddressStringType(s) == factom.FactoidSec:
+			a, err := factom.GetFactoidAddress(s)
+			if err != nil {
+				return nil, nil, nil, validationErrorf("wallet: %v", err)
+			}
+			fcts = append(fcts, a)
+		case factom.AddressStringType(s) == factom.ECSec:
+			a, err := factom.GetECAddress(s)
+			if err != nil {
+				return nil, nil, nil, validationErrorf("wallet: %v", err)
+			}
+			ecs = append(ecs, a)
+		case factom.IdentityKeyStringType(s) == factom.IDSec:
+			k, err := factom.GetIdentityKey(s)
+			if err != nil {
+				return nil, nil, nil, validationErrorf("wallet: %v", err)
+			}
+			ids = append(ids, k)
+		default:
+			return nil, nil, nil, validationErrorf("wallet: Not a secret key: %s", s)
+		}
+	}
+	return fcts, ecs, ids, nil
+}
+
+// checkEmpty returns an error if any keys have been stored in or derived by
+// the wallet.
+func (w *Wallet) checkEmpty() error {
+	seed, err := w.GetOrCreateDBSeed()
+	if err != nil {
+		return err
+	}
+	fs, es, err := w.GetAllAddresses()
+	if err != nil {
+		return err
+	}
+	ids, err := w.GetAllIdentityKeys()
+	if err != nil {
+		return err
+	}
+	if len(fs) > 0 || len(es) > 0 || len(ids) > 0 ||
+		seed.NextFactoidAddressIndex > 0 ||
+		seed.NextECAddressIndex > 0 ||
+		seed.NextIdentityKeyIndex > 0 {
+		return fmt.Errorf("wallet: Can only restore into an empty wallet")
+	}
+	return nil
+}
+
+// usedAddresses returns the addresses that appear in the transaction
+// database, or nil if the wallet has none.
+func (w *Wallet) usedAddresses() (map[string]bool, error) {
+	if w.txdb == nil {
+		return nil, nil
+	}
+	txs, err := w.txdb.GetAllTXs()
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	for _, tx := range txs {
+		for _, in := range tx.GetInputs() {
+			used[primitives.ConvertFctAddressToUserStr(in.GetAddress())] = true
+		}
+		for _, out := range tx.GetOutputs() {
+			used[primitives.ConvertFctAddressToUserStr(out.GetAddress())] = true
+		}
+		for _, out := range tx.GetECOutputs() {
+			used[primitives.ConvertECAddressToUserStr(out.GetAddress())] = true
+		}
+	}
+	return used, nil
+}
+
+// scanAddresses derives addresses until gap of them in a row are unused and
+// returns the addresses up to the last used one.
+func (w *Wallet) scanAddresses(
+	gap uint32,
+	used map[string]bool,
+	derive func(uint32) (string, error),
+	balance func(string) (int64, error),
+) ([]*RestoredKey, error) {
+	var keys []*RestoredKey
+	found := 0
+	for i := uint32(0); i < uint32(found)+gap; i++ {
+		pub, err := derive(i)
+		if err != nil {
+			return nil, err
+		}
+		k := &RestoredKey{Public: pub, Index: i}
+		if err := w.retry.Do(func() (err error) {
+			k.Balance, err = balance(pub)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+		if k.Balance != 0 || used[pub] {
+			found = int(i) + 1
+		}
+	}
+	return keys[:found], nil
+}
+
+// deriveKeys derives the first n keys of a derivation path.
+func deriveKeys(n uint32, derive func(uint32) (string, error)) ([]*RestoredKey, error) {
+	keys := make([]*RestoredKey, 0, n)
+	for i := uint32(0); i < n; i++ {
+		pub, err := derive(i)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, &RestoredKey{Public: pub, Index: i})
+	}
+	return keys, nil
+}
+
+// applyMetadata adds the bookmarks, namespaces, labels and scheduled
+// transactions of m to the wallet. Entries that do not fit the restored keys
+// are listed in the report instead.
+func (w *Wallet) applyMetadata(m *Metadata, r *RestoreReport) error {
+	for _, b := range m.Bookmarks {
+		if err := w.AddBookmark(b.ChainID, b.Label); err != nil {
+			if !errors.Is(err, factom.ErrValidation) {
+				return err
+			}
+			r.Problems = append(r.Problems, err.Error())
+			continue
+		}
+		r.Bookmarks++
+	}
+
+	for pub, ns := range m.Namespaces {
+		if err := w.SetNamespace(pub, ns); err != nil {
+			if !errors.Is(err, factom.ErrValidation) {
+				return err
+			}
+			r.Problems = append(r.Problems, fmt.Sprintf("namespace %s for %s: %v", ns, pub, err))
+			continue
+		}
+		r.Namespaces++
+	}
+
+	for pub, l := range m.Labels {
+		if err := w.SetAddressLabel(pub, l); err != nil {
+			if !errors.Is(err, factom.ErrValidation) {
+				return err
+			}
+			r.Problems = append(r.Problems, fmt.Sprintf("label %q for %s: %v", l, pub, err))
+			continue
+		}
+		r.Labels++
+	}
+
+	for _, s := range m.Scheduled {
+		if _, err := w.GetFCTAddress(s.From); err != nil {
+			r.Problems = append(r.Problems, fmt.Sprintf("scheduled transaction %s: %v", s.Name, err))
+			continue
+		}
+		if err := w.InsertScheduledTx(s); err != nil {
+			return err
+		}
+		r.Scheduled++
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+	"github.com/FactomProject/go-bip32"
+)
+
+func TestRestore(t *testing.T) {
+	mnemonic := "yellow yellow yellow yellow yellow yellow yellow yellow yellow yellow yellow yellow"
+	zSec := "Fs1KWJrpLdfucvmYwN2nWrwepLn8ercpMbzXshd1g8zyhKXLVLWj"
+	chainID := "df3ade9eec4b08d5379cc64270c30ea7315d8a8a1a69efe2b98a60ecdd69e604"
+
+	// only the third factoid address of the seed has a balance
+	funded, err := factom.MakeBIP44FactoidAddress(mnemonic, bip32.FirstHardenedChild, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(factom.JSON2Request)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+		params := make(map[string]string)
+		json.Unmarshal(req.Params, &params)
+
+		var balance int64
+		switch req.Method {
+		case "factoid-balance":
+			if params["address"] == funded.String() {
+				balance = 5e8
+			}
+		case "entry-credit-balance":
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+		}
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 0, "result": {"balance": %d}}`, balance)
+	}))
+	defer ts.Close()
+	factom.SetFactomdServer(ts.URL[7:])
+
+	dir, err := ioutil.TempDir("", "restore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	meta := &Metadata{
+		IdentityKeys: 1,
+		Bookmarks:    []*Bookmark{{ChainID: chainID, Label: "payroll"}},
+		Namespaces: map[string]string{
+			funded.String(): "treasury",
+			"FA3T1gTkuKGG2MWpAkskSoTnfjxZDKVaAYwziNTC1pAYH5B9A1rh": "missing",
+		},
+	}
+	p, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sidecar := filepath.Join(dir, "wallet.json")
+	if err := ioutil.WriteFile(sidecar, p, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	if _, err := w1.Restore(mnemonic, RestoreOptions{Secrets: []string{"not a key"}}); err == nil {
+		t.Error("expected an error for a bad secret")
+	}
+
+	r, err := w1.Restore(mnemonic, RestoreOptions{
+		GapLimit: 3,
+		Secrets:  []string{zSec},
+		Metadata: sidecar,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the three derived addresses up to the funded one and the imported one
+	if len(r.FCTAddresses) != 4 || r.FCTAddresses[2].Public != funded.String() || !r.FCTAddresses[3].Imported {
+		t.Errorf("wrong factoid addresses %v", r.FCTAddresses)
+	}
+	if len(r.ECAddresses) != 0 || len(r.IdentityKeys) != 1 {
+		t.Errorf("wrong ec addresses %v or identity keys %v", r.ECAddresses, r.IdentityKeys)
+	}
+	if r.FCTBalance != 5e8 {
+		t.Errorf("wrong balance %d", r.FCTBalance)
+	}
+	if r.Bookmarks != 1 || r.Namespaces != 1 || len(r.Problems) != 1 {
+		t.Errorf("wrong metadata %d %d %v", r.Bookmarks, r.Namespaces, r.Problems)
+	}
+
+	if ns, err := w1.Namespace(funded.String()); err != nil || ns != "treasury" {
+		t.Errorf("wrong namespace %q %v", ns, err)
+	}
+
+	// the seed continues after the restored addresses
+	next, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := factom.MakeBIP44FactoidAddress(mnemonic, bip32.FirstHardenedChild, 0, 3)
+	if next.String() != want.String() {
+		t.Errorf("next address is %s, want %s", next, want)
+	}
+
+	if _, err := w1.Restore(mnemonic, RestoreOptions{}); err == nil {
+		t.Error("expected an error restoring into a wallet with keys")
+	}
+}
+
+func TestRestoreDerived(t *testing.T) {
+	mnemonic, err := NewSeedMnemonic()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(strings.Fields(mnemonic)); l != 24 {
+		t.Fatalf("expected 24 words, found %d", l)
+	}
+
+	// the old wallet generated some addresses from the seed
+	w0, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w0.Close()
+	if err := w0.InsertDBSeed(&DBSeed{DBSeedBase{MnemonicSeed: mnemonic}}); err != nil {
+		t.Fatal(err)
+	}
+	var fs []string
+	for i := 0; i < 3; i++ {
+		f, err := w0.GenerateFCTAddress()
+		if err != nil {
+			t.Fatal(err)
+		}
+		fs = append(fs, f.String())
+	}
+	e, err := w0.GenerateECAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "restore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sidecar := filepath.Join(dir, "wallet.json")
+	if err := w0.WriteMetadata(sidecar); err != nil {
+		t.Fatal(err)
+	}
+
+	// no factomd is needed when the metadata counts the addresses
+	factom.SetFactomdServer("localhost:1")
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+	r, err := w1.Restore(mnemonic, RestoreOptions{Metadata: sidecar})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.FCTAddresses) != 3 || r.FCTAddresses[2].Public != fs[2] {
+		t.Errorf("wrong factoid addresses %v", r.FCTAddresses)
+	}
+	if len(r.ECAddresses) != 1 || r.ECAddresses[0].Public != e.PubString() {
+		t.Errorf("wrong ec addresses %v", r.ECAddresses)
+	}
+	for _, f := range fs {
+		if _, err := w1.GetFCTAddress(f); err != nil {
+			t.Errorf("%s was not restored: %v", f, err)
+		}
+	}
+}
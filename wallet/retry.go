@@ -0,0 +1,43 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"github.com/FactomProject/factom"
+)
+
+// RetryPolicy controls how the wallet retries calls to factomd that fail
+// because of a network error. It is the policy of the factom client, so the
+// same policy may be shared by wallets, transaction databases and clients.
+type RetryPolicy = factom.RetryPolicy
+
+// DefaultRetryPolicy is factom.DefaultRetryPolicy. Wallets do not retry until
+// a policy is set with SetRetryPolicy or WithRetryPolicy.
+var DefaultRetryPolicy = factom.DefaultRetryPolicy
+
+// getRate fetches the entry credit rate from factomd using the retry policy.
+func getRate(retry *RetryPolicy) (uint64, error) {
+	var rate uint64
+	err := retry.Do(func() (err error) {
+		rate, err = factom.GetRate()
+		return err
+	})
+	return rate, err
+}
+
+// SetRetryPolicy sets the policy used for the wallet's calls to factomd. It
+// is also applied to the transaction database attached to the wallet. A nil
+// policy disables retries.
+func (w *Wallet) SetRetryPolicy(p *RetryPolicy) {
+	w.retry = p
+	if w.txdb != nil {
+		w.txdb.SetRetryPolicy(p)
+	}
+}
+
+// SetRetryPolicy sets the policy used to fetch blocks from factomd.
+func (db *TXDatabaseOverlay) SetRetryPolicy(p *RetryPolicy) {
+	db.retry = p
+}
@@ -0,0 +1,63 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	p := &RetryPolicy{MaxAttempts: 3}
+	netErr := &factom.RequestError{Err: errors.New("connection refused")}
+
+	// network errors are retried until the attempts run out
+	calls := 0
+	err := p.Do(func() error {
+		calls++
+		return netErr
+	})
+	if err != netErr || calls != 3 {
+		t.Errorf("got %v after %d calls, expecting 3 calls", err, calls)
+	}
+
+	// a success stops the retries
+	calls = 0
+	err = p.Do(func() error {
+		calls++
+		if calls < 2 {
+			return netErr
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("got %v after %d calls, expecting 2 calls", err, calls)
+	}
+
+	// other errors are returned right away
+	calls = 0
+	apiErr := factom.NewJSONError(-32009, "Missing Chain Head", nil)
+	err = p.Do(func() error {
+		calls++
+		return apiErr
+	})
+	if err != apiErr || calls != 1 {
+		t.Errorf("got %v after %d calls, expecting 1 call", err, calls)
+	}
+
+	// a nil policy calls once
+	calls = 0
+	var np *RetryPolicy
+	np.Do(func() error {
+		calls++
+		return netErr
+	})
+	if calls != 1 {
+		t.Errorf("nil policy made %d calls", calls)
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/FactomProject/factom"
+)
+
+// SeedMigration is a key derived from the old seed of a wallet and its
+// replacement, derived at the same index from the new seed by RotateSeed.
+type SeedMigration struct {
+	Index   uint32 `json:"index"`
+	Old     string `json:"old"`
+	New     string `json:"new"`
+	Balance int64  `json:"balance,omitempty"`
+
+	// Sweep is the name of the tmp transaction that moves the balance of Old
+	// to New, or "" if there is nothing to move.
+	Sweep string `json:"sweep,omitempty"`
+}
+
+// RotationReport lists the keys replaced by RotateSeed.
+type RotationReport struct {
+	FCTAddresses []*SeedMigration `json:"fctaddresses"`
+	ECAddresses  []*SeedMigration `json:"ecaddresses"`
+	IdentityKeys []*SeedMigration `json:"identitykeys"`
+}
+
+// RotateSeed replaces the seed of the wallet with a new random one, for a
+// wallet whose seed may have been exposed. Every key that was derived from
+// the old seed and is still in the wallet gets a replacement derived at the
+// same index and derivation path from the new seed.
+//
+// The old keys stay in the wallet so that their funds can be moved. For each
+// old Factoid address with a balance an unsigned tmp transaction named
+// rotate-<index> is created that sends the balance, less the fee, to its
+// replacement; it is to be checked, signed and submitted like any other.
+// Entry Credits can not be transferred and are left to be spent from the old
+// addresses. Identity keys have to be replaced in their identity chains.
+//
+// The balances are read from factomd before anything is changed, so an
+// unreachable factomd leaves the wallet as it was. The new seed must be
+// backed up afterwards.
+func (w *Wallet) RotateSeed() (*RotationReport, error) {
+	if w.readOnly {
+		return nil, ErrReadOnly
+	}
+	old, err := w.GetDBSeed()
+	if err != nil {
+		return nil, err
+	}
+	if old == nil {
+		return nil, fmt.Errorf("wallet: The wallet has no seed")
+	}
+
+	mnemonic, err := NewSeedMnemonic()
+	if err != nil {
+		return nil, err
+	}
+	seed := &DBSeed{old.DBSeedBase}
+	seed.MnemonicSeed = mnemonic
+
+	r := new(RotationReport)
+	var (
+		fcts []*factom.FactoidAddress
+		ecs  []*factom.ECAddress
+		ids  []*factom.IdentityKey
+	)
+	for i := uint32(0); i < old.NextFactoidAddressIndex; i++ {
+		a, err := old.FCTAddress(i)
+		if err != nil {
+			return nil, err
+		}
+		a.Wipe()
+		if _, err := w.GetFCTAddress(a.String()); err == ErrNoSuchAddress {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		n, err := seed.FCTAddress(i)
+		if err != nil {
+			return nil, err
+		}
+		m := &SeedMigration{Index: i, Old: a.String(), New: n.String()}
+		if err := w.retry.Do(func() (err error) {
+			m.Balance, err = factom.GetFactoidBalance(m.Old)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		if m.Balance > 0 {
+			m.Sweep = fmt.Sprintf("rotate-%d", i)
+			if w.TransactionExists(m.Sweep) {
+				return nil, ErrTXExists
+			}
+		}
+		fcts = append(fcts, n)
+		r.FCTAddresses = append(r.FCTAddresses, m)
+	}
+	for i := uint32(0); i < old.NextECAddressIndex; i++ {
+		a, err := old.ECAddress(i)
+		if err != nil {
+			return nil, err
+		}
+		a.Wipe()
+		if _, err := w.GetECAddress(a.PubString()); err == ErrNoSuchAddress {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		n, err := seed.ECAddress(i)
+		if err != nil {
+			return nil, err
+		}
+		m := &SeedMigration{Index: i, Old: a.PubString(), New: n.PubString()}
+		if err := w.retry.Do(func() (err error) {
+			m.Balance, err = factom.GetECBalance(m.Old)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		ecs = append(ecs, n)
+		r.ECAddresses = append(r.ECAddresses, m)
+	}
+	for i := uint32(0); i < old.NextIdentityKeyIndex; i++ {
+		k, err := old.IdentityKey(i)
+		if err != nil {
+			return nil, err
+		}
+		k.Wipe()
+		if _, err := w.GetIdentityKey(k.PubString()); err == ErrNoSuchIdentityKey {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		n, err := seed.IdentityKey(i)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, n)
+		r.IdentityKeys = append(r.IdentityKeys, &SeedMigration{Index: i, Old: k.PubString(), New: n.PubString()})
+	}
+
+	var rate uint64
+	for _, m := range r.FCTAddresses {
+		if m.Sweep != "" {
+			if rate, err = getRate(w.retry); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	// store the replacements before the seed, so that an interrupted
+	// rotation keeps the old seed
+	for _, a := range fcts {
+		if err := w.InsertFCTAddress(a); err != nil {
+			return nil, err
+		}
+	}
+	for _, a := range ecs {
+		if err := w.InsertECAddress(a); err != nil {
+			return nil, err
+		}
+	}
+	for _, k := range ids {
+		if err := w.InsertIdentityKey(k); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.InsertDBSeed(seed); err != nil {
+		return nil, err
+	}
+	w.logf("rotated the wallet seed")
+
+	for _, m := range r.FCTAddresses {
+		if m.Sweep != "" && w.sweep(m, rate) != nil {
+			// a balance too small to pay the fee is not worth moving
+			w.DeleteTransaction(m.Sweep)
+			m.Sweep = ""
+		}
+	}
+	return r, nil
+}
+
+// sweep creates the tmp transaction of m that sends the balance of m.Old to
+// m.New, paying the fee at rate from the output.
+func (w *Wallet) sweep(m *SeedMigration, rate uint64) error {
+	if err := w.NewTransaction(m.Sweep); err != nil {
+		return err
+	}
+	if err := w.AddInput(m.Sweep, m.Old, uint64(m.Balance)); err != nil {
+		return err
+	}
+	if err := w.AddOutput(m.Sweep, m.New, uint64(m.Balance)); err != nil {
+		return err
+	}
+	return w.SubFee(m.Sweep, m.New, rate)
+}
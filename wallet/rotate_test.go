@@ -0,0 +1,121 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestRotateSeed(t *testing.T) {
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	funded, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec, err := w1.GenerateECAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldSeed, err := w1.GetSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(factom.JSON2Request)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+		params := make(map[string]string)
+		json.Unmarshal(req.Params, &params)
+
+		switch req.Method {
+		case "factoid-balance":
+			var balance int64
+			if params["address"] == funded.String() {
+				balance = 5e8
+			}
+			fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 0, "result": {"balance": %d}}`, balance)
+		case "entry-credit-balance":
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"balance": 10}}`)
+		case "entry-credit-rate":
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"rate": 1000}}`)
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+		}
+	}))
+	defer ts.Close()
+	factom.SetFactomdServer(ts.URL[7:])
+
+	r, err := w1.RotateSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if seed, err := w1.GetSeed(); err != nil {
+		t.Error(err)
+	} else if seed == oldSeed {
+		t.Error("the seed was not replaced")
+	}
+
+	if len(r.FCTAddresses) != 2 || len(r.ECAddresses) != 1 {
+		t.Fatalf("wrong report %v %v", r.FCTAddresses, r.ECAddresses)
+	}
+	m := r.FCTAddresses[0]
+	if m.Old != funded.String() || m.Balance != 5e8 || m.Sweep != "rotate-0" {
+		t.Errorf("wrong migration %v", m)
+	}
+	if r.FCTAddresses[1].Old != empty.String() || r.FCTAddresses[1].Sweep != "" {
+		t.Errorf("wrong migration %v", r.FCTAddresses[1])
+	}
+	if r.ECAddresses[0].Old != ec.PubString() || r.ECAddresses[0].Sweep != "" {
+		t.Errorf("wrong migration %v", r.ECAddresses[0])
+	}
+
+	// the replacements are in the wallet next to the old keys
+	for _, a := range []string{m.Old, m.New, r.ECAddresses[0].New} {
+		if _, err := w1.PublicKey(a); err != nil {
+			t.Errorf("%s is not in the wallet: %v", a, err)
+		}
+	}
+
+	// the sweep moves the balance less the fee
+	tx, err := w1.GetTransaction(m.Sweep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.GetInputs()) != 1 || tx.GetInputs()[0].GetAmount() != 5e8 {
+		t.Error("wrong sweep input")
+	}
+	if outs := tx.GetOutputs(); len(outs) != 1 || outs[0].GetAmount() >= 5e8 {
+		t.Error("wrong sweep output")
+	}
+
+	// new addresses continue from the next index of the new seed
+	next, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.String() == m.New || next.String() == r.FCTAddresses[1].New {
+		t.Error("a replacement address was generated again")
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schedule gives the run times of a scheduled transaction.
+type schedule interface {
+	// next returns the first run time after t, or the zero time if there
+	// are no more runs.
+	next(t time.Time) time.Time
+}
+
+// NextRun returns the first time after t that a transaction with the given
+// schedule is due. The zero time is returned if it will not run again.
+//
+// A schedule is one of
+//
+//	@once            run a single time at the start time
+//	@every <d>       run every d, where d is a duration such as 24h
+//	m h dom mon dow  a cron expression in the local time zone
+//
+// Cron fields accept *, numbers, ranges such as 1-5, lists such as 1,15 and
+// steps such as */10.
+func NextRun(spec string, t time.Time) (time.Time, error) {
+	s, err := parseSchedule(spec)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return s.next(t), nil
+}
+
+func parseSchedule(spec string) (schedule, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "@once":
+		return onceSchedule{}, nil
+	case strings.HasPrefix(spec, "@every "):
+		d, err := time.ParseDuration(strings.TrimSpace(spec[len("@every "):]))
+		if err != nil || d < time.Minute {
+			return nil, validationErrorf("wallet: Invalid schedule %q: the interval must be at least 1m", spec)
+		}
+		return everySchedule(d), nil
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, validationErrorf("wallet: Invalid schedule %q", spec)
+	}
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+	var c cronSchedule
+	for i, f := range fields {
+		bits, err := parseCronField(f, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, validationErrorf("wallet: Invalid schedule %q: %s", spec, err)
+		}
+		c.fields[i] = bits
+	}
+	c.anyDOM = fields[2] == "*"
+	c.anyDOW = fields[4] == "*"
+	return &c, nil
+}
+
+type onceSchedule struct{}
+
+func (onceSchedule) next(time.Time) time.Time {
+	return time.Time{}
+}
+
+type everySchedule time.Duration
+
+func (s everySchedule) next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
+
+// cronSchedule holds a bit set of the allowed values of each cron field.
+type cronSchedule struct {
+	fields         [5]uint64
+	anyDOM, anyDOW bool
+}
+
+func (c *cronSchedule) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// every schedule matches at least once in 4 years (Feb 29)
+	for end := t.AddDate(4, 0, 1); t.Before(end); t = t.Add(time.Minute) {
+		if c.matches(t) {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c *cronSchedule) matches(t time.Time) bool {
+	has := func(i, v int) bool { return c.fields[i]&(1<<uint(v)) != 0 }
+
+	if !has(0, t.Minute()) || !has(1, t.Hour()) || !has(3, int(t.Month())) {
+		return false
+	}
+
+	// as in cron, a restricted day of month or day of week is enough
+	dom, dow := has(2, t.Day()), has(4, int(t.Weekday()))
+	switch {
+	case c.anyDOM && c.anyDOW:
+		return true
+	case c.anyDOM:
+		return dow
+	case c.anyDOW:
+		return dom
+	}
+	return dom || dow
+}
+
+// parseCronField returns the bit set of the values allowed by the cron field
+// f.
+func parseCronField(f string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(f, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("bad step in %q", part)
+			}
+			part, step = part[:i], s
+		}
+
+		lo, hi := min, max
+		if part != "*" {
+			r := strings.SplitN(part, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(r[0]); err != nil {
+				return 0, fmt.Errorf("bad value %q", part)
+			}
+			switch {
+			case len(r) == 2:
+				if hi, err = strconv.Atoi(r[1]); err != nil {
+					return 0, fmt.Errorf("bad value %q", part)
+				}
+			case step == 1:
+				hi = lo
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q is out of range %d-%d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
@@ -0,0 +1,351 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factomd/common/interfaces"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+// maxScheduledRuns is the number of outcomes kept for each scheduled
+// transaction.
+const maxScheduledRuns = 20
+
+var ErrNoSuchScheduledTx = validationErrorf("wallet: No such scheduled transaction")
+
+// ScheduledOutput is a payment made by a scheduled transaction. Payments to
+// Entry Credit addresses are given in factoshis.
+type ScheduledOutput struct {
+	Address string
+	Amount  uint64
+}
+
+// ScheduledRun is the outcome of one run of a scheduled transaction.
+type ScheduledRun struct {
+	Time time.Time
+	TxID string
+	Err  string
+
+	// Running is set while the run is being sent. A run left running was
+	// interrupted and may or may not have paid; it is not sent again.
+	Running bool
+}
+
+// ScheduledTx is a transaction that the wallet builds and sends to factomd
+// when it is due. The From address pays for the outputs and the fee.
+type ScheduledTx struct {
+	Name     string
+	From     string
+	Outputs  []ScheduledOutput
+	Schedule string
+
+	// Next is the time of the next run. It is zero once a transaction will
+	// not run again.
+	Next time.Time
+
+	// Runs holds the outcomes of the most recent runs, oldest first.
+	Runs []ScheduledRun
+}
+
+var _ interfaces.BinaryMarshallableAndCopyable = (*ScheduledTx)(nil)
+
+// scheduledTxData is ScheduledTx without its methods, so that gob encodes its
+// fields instead of calling back into MarshalBinary.
+type scheduledTxData ScheduledTx
+
+func (s *ScheduledTx) New() interfaces.BinaryMarshallableAndCopyable {
+	return new(ScheduledTx)
+}
+
+func (s *ScheduledTx) MarshalBinary() ([]byte, error) {
+	var data primitives.Buffer
+
+	enc := gob.NewEncoder(&data)
+	if err := enc.Encode(scheduledTxData(*s)); err != nil {
+		return nil, err
+	}
+	return data.DeepCopyBytes(), nil
+}
+
+func (s *ScheduledTx) UnmarshalBinaryData(data []byte) ([]byte, error) {
+	dec := gob.NewDecoder(primitives.NewBuffer(data))
+	if err := dec.Decode((*scheduledTxData)(s)); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (s *ScheduledTx) UnmarshalBinary(data []byte) error {
+	_, err := s.UnmarshalBinaryData(data)
+	return err
+}
+
+func (db *WalletDatabaseOverlay) InsertScheduledTx(s *ScheduledTx) error {
+	if s == nil {
+		return nil
+	}
+	return dbError("write", db.DBO.Put(scheduleDBPrefix, []byte(s.Name), s))
+}
+
+func (db *WalletDatabaseOverlay) GetScheduledTx(name string) (*ScheduledTx, error) {
+	data, err := db.DBO.Get(scheduleDBPrefix, []byte(name), new(ScheduledTx))
+	if err != nil {
+		return nil, dbError("read", err)
+	}
+	if data == nil {
+		return nil, ErrNoSuchScheduledTx
+	}
+	return data.(*ScheduledTx), nil
+}
+
+// GetAllScheduledTxs returns the scheduled transactions sorted by name.
+func (db *WalletDatabaseOverlay) GetAllScheduledTxs() ([]*ScheduledTx, error) {
+	list, err := db.DBO.FetchAllBlocksFromBucket(scheduleDBPrefix, new(ScheduledTx))
+	if err != nil {
+		return nil, dbError("read", err)
+	}
+
+	ss := make([]*ScheduledTx, len(list))
+	for i, v := range list {
+		ss[i] = v.(*ScheduledTx)
+	}
+	sort.Slice(ss, func(i, j int) bool { return ss[i].Name < ss[j].Name })
+	return ss, nil
+}
+
+func (db *WalletDatabaseOverlay) RemoveScheduledTx(name string) error {
+	if _, err := db.GetScheduledTx(name); err != nil {
+		return err
+	}
+	return dbError("delete", db.DBO.Delete(scheduleDBPrefix, []byte(name)))
+}
+
+// ScheduleTx stores a transaction paying outputs from the Factoid address
+// from on the given schedule. The first run is at start; see NextRun for the
+// schedule format. A scheduled transaction with the same name is replaced.
+func (w *Wallet) ScheduleTx(name, from string, outputs []ScheduledOutput, spec string, start time.Time) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	if name == "" {
+		return validationErrorf("wallet: A scheduled transaction needs a name")
+	}
+	if len(outputs) == 0 {
+		return validationErrorf("wallet: A scheduled transaction needs an output")
+	}
+	if factom.AddressStringType(from) != factom.FactoidPub {
+		return validationErrorf("wallet: %s is not a Factoid address", from)
+	}
+	for _, o := range outputs {
+		switch factom.AddressStringType(o.Address) {
+		case factom.FactoidPub, factom.ECPub:
+		default:
+			return validationErrorf("wallet: %s is not a public address", o.Address)
+		}
+	}
+	if _, err := parseSchedule(spec); err != nil {
+		return err
+	}
+
+	return w.InsertScheduledTx(&ScheduledTx{
+		Name:     name,
+		From:     from,
+		Outputs:  outputs,
+		Schedule: spec,
+		Next:     start,
+	})
+}
+
+// RemoveScheduledTx deletes a scheduled transaction and its outcomes.
+func (w *Wallet) RemoveScheduledTx(name string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	return w.WalletDatabaseOverlay.RemoveScheduledTx(name)
+}
+
+// Scheduler sends the scheduled transactions of a wallet when they are due.
+// Transactions that fall due while the wallet is locked are sent once it is
+// unlocked. If several runs were missed only one is made.
+type Scheduler struct {
+	// Interval is the time between checks for due transactions once the
+	// Scheduler is started.
+	Interval time.Duration
+
+	// Allow, if set, is called before a due transaction is sent. If it
+	// returns an error the run is skipped and the error recorded, so that
+	// policies such as spending limits can be enforced.
+	Allow func(*ScheduledTx) error
+
+	wallet *Wallet
+	quit   chan struct{}
+	done   chan struct{}
+}
+
+// NewScheduler returns a Scheduler for the wallet that checks for due
+// transactions every minute.
+func (w *Wallet) NewScheduler() *Scheduler {
+	return &Scheduler{
+		Interval: time.Minute,
+		wallet:   w,
+	}
+}
+
+// RunDue sends every scheduled transaction due at now, records the outcome
+// and moves it to its next run time. It returns the transactions that were
+// run. The next run time is stored before the transaction is sent, so that a
+// payment goes out at most once even if RunDue is interrupted.
+func (s *Scheduler) RunDue(now time.Time) ([]*ScheduledTx, error) {
+	w := s.wallet
+	if w.readOnly {
+		return nil, ErrReadOnly
+	}
+	if w.IsLocked() {
+		return nil, nil
+	}
+
+	all, err := w.GetAllScheduledTxs()
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []*ScheduledTx
+	for _, st := range all {
+		if st.Next.IsZero() || st.Next.After(now) {
+			continue
+		}
+
+		sched, err := parseSchedule(st.Schedule)
+		if err != nil {
+			return ran, err
+		}
+
+		st.Runs = append(st.Runs, ScheduledRun{Time: now, Running: true})
+		if len(st.Runs) > maxScheduledRuns {
+			st.Runs = st.Runs[len(st.Runs)-maxScheduledRuns:]
+		}
+		st.Next = sched.next(now)
+		if err := w.InsertScheduledTx(st); err != nil {
+			return ran, err
+		}
+
+		run := &st.Runs[len(st.Runs)-1]
+		if s.Allow != nil {
+			err = s.Allow(st)
+		}
+		if err == nil {
+			run.TxID, err = w.sendScheduledTx(st)
+		}
+		if err != nil {
+			run.Err = err.Error()
+			w.logf("scheduled transaction %s: %s", st.Name, err)
+		}
+		run.Running = false
+
+		if err := w.InsertScheduledTx(st); err != nil {
+			return ran, err
+		}
+		ran = append(ran, st)
+	}
+	return ran, nil
+}
+
+// sendScheduledTx builds, signs and submits one run of st and returns the
+// transaction id. The submit is not retried, as in SendTransaction; if it
+// fails the transaction may still have reached factomd, so its status is
+// checked before the run is reported as failed.
+func (w *Wallet) sendScheduledTx(st *ScheduledTx) (string, error) {
+	var total uint64
+	for _, o := range st.Outputs {
+		total += o.Amount
+	}
+
+	b := w.BuildTx().From(st.From, total)
+	for _, o := range st.Outputs {
+		if factom.AddressStringType(o.Address) == factom.ECPub {
+			b.ECTo(o.Address, o.Amount)
+		} else {
+			b.To(o.Address, o.Amount)
+		}
+	}
+	tx, err := b.Fee(AutoFee).Sign()
+	if err != nil {
+		return "", err
+	}
+	req, err := composeTransaction(tx)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := factom.SendFactomdRequest(req)
+	if err != nil {
+		txid := tx.GetSigHash().String()
+		data, merr := tx.MarshalBinary()
+		if merr != nil {
+			return txid, err
+		}
+		if w.waitForAck(txid, hex.EncodeToString(data)) == nil {
+			return txid, nil
+		}
+		return txid, err
+	}
+	if resp.Error != nil {
+		return "", resp.Error
+	}
+
+	result := new(struct {
+		TxID string `json:"txid"`
+	})
+	if err := json.Unmarshal(resp.JSONResult(), result); err != nil {
+		return "", err
+	}
+	return result.TxID, nil
+}
+
+// Start checks for due transactions every Interval until Stop is called.
+func (s *Scheduler) Start() {
+	s.quit = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.run()
+}
+
+// Stop stops a started Scheduler.
+func (s *Scheduler) Stop() {
+	if s.quit != nil {
+		close(s.quit)
+		<-s.done
+		s.quit = nil
+	}
+}
+
+func (s *Scheduler) run() {
+	defer close(s.done)
+
+	interval := s.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunDue(time.Now()); err != nil {
+			s.wallet.logf("scheduler: %s", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.quit:
+			return
+		}
+	}
+}
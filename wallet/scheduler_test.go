@@ -0,0 +1,116 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestNextRun(t *testing.T) {
+	// a Wednesday
+	now := time.Date(2019, 5, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"@once", time.Time{}},
+		{"@every 24h", now.Add(24 * time.Hour)},
+		{"* * * * *", now.Add(time.Minute)},
+		{"0 12 * * *", time.Date(2019, 5, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 9 * * *", time.Date(2019, 5, 16, 9, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2019, 5, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 8 * * 1-5", time.Date(2019, 5, 16, 8, 0, 0, 0, time.UTC)},
+		{"0 8 * * 0,6", time.Date(2019, 5, 18, 8, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := NextRun(tt.spec, now)
+		if err != nil {
+			t.Errorf("%s: %s", tt.spec, err)
+		} else if !got.Equal(tt.want) {
+			t.Errorf("%s: got %s, want %s", tt.spec, got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"", "@every 1s", "* * * *", "60 * * * *", "* * * * 7", "*/0 * * * *", "5-1 * * * *"} {
+		if _, err := NextRun(spec, now); !errors.Is(err, factom.ErrValidation) {
+			t.Errorf("%q: expected a validation error, got %v", spec, err)
+		}
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	from := "FA3T1gTkuKGG2MWpAkskSoTnfjxZDKVaAYwziNTC1pAYH5B9A1rh"
+	to := "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"
+	now := time.Date(2019, 5, 15, 10, 30, 0, 0, time.UTC)
+
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	outputs := []ScheduledOutput{{Address: to, Amount: 1e8}}
+	if err := w1.ScheduleTx("rent", from, outputs, "0 0 1 * *", now.Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.ScheduleTx("later", from, outputs, "@once", now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.ScheduleTx("bad", from, outputs, "whenever", now); err == nil {
+		t.Error("expected an error for a bad schedule")
+	}
+
+	// refuse every run so that nothing is sent to factomd
+	s := w1.NewScheduler()
+	s.Allow = func(st *ScheduledTx) error {
+		// the run is stored before it is sent, so that it is not sent
+		// again after a crash
+		stored, err := w1.GetScheduledTx(st.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !stored.Next.After(now) {
+			t.Errorf("next run %s not stored before sending", stored.Next)
+		}
+		if n := len(stored.Runs); n == 0 || !stored.Runs[n-1].Running {
+			t.Errorf("running run not stored before sending: %v", stored.Runs)
+		}
+		return errors.New("over the limit")
+	}
+
+	ran, err := s.RunDue(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ran) != 1 || ran[0].Name != "rent" {
+		t.Fatalf("wrong transactions run %v", ran)
+	}
+
+	st, err := w1.GetScheduledTx("rent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.Runs) != 1 || st.Runs[0].Err != "over the limit" || st.Runs[0].Running {
+		t.Errorf("wrong outcome %v", st.Runs)
+	}
+	if !st.Next.Equal(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("wrong next run %s", st.Next)
+	}
+
+	if err := w1.RemoveScheduledTx("rent"); err != nil {
+		t.Error(err)
+	}
+	if _, err := w1.GetScheduledTx("rent"); err != ErrNoSuchScheduledTx {
+		t.Errorf("expected ErrNoSuchScheduledTx, got %v", err)
+	}
+}
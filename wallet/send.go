@@ -0,0 +1,224 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+var (
+	ErrTXNotSigned = validationErrorf("wallet: Transaction is not signed")
+	ErrTXNotAcked  = errors.New("wallet: The transaction was not acknowledged by factomd")
+)
+
+var (
+	// AckTimeout is how long SendTransaction waits for factomd to
+	// acknowledge a transaction it accepted.
+	AckTimeout = 10 * time.Second
+
+	// AckPollInterval is the time between acknowledgement checks.
+	AckPollInterval = 500 * time.Millisecond
+)
+
+// SendTransaction submits the signed tmp transaction name to factomd, waits
+// until factomd acknowledges it and removes it from the wallet. It returns
+// the transaction id.
+//
+// A transaction that factomd accepted but did not acknowledge within
+// AckTimeout is removed as well, and its id is returned with ErrTXNotAcked;
+// its status can be followed with factom.FactoidACK.
+func (w *Wallet) SendTransaction(name string) (string, error) {
+	if w.readOnly {
+		return "", ErrReadOnly
+	}
+	tx, err := w.GetTransaction(name)
+	if err != nil {
+		return "", err
+	}
+	if len(tx.GetSignatureBlocks()) == 0 || tx.ValidateSignatures() != nil {
+		return "", ErrTXNotSigned
+	}
+
+	req, err := composeTransaction(tx)
+	if err != nil {
+		return "", err
+	}
+	// the submit is not retried: factomd may have taken a transaction whose
+	// response was lost, and a second submit would fail as a duplicate
+	resp, err := factom.SendFactomdRequest(req)
+	if err != nil {
+		return "", err
+	}
+	if resp.Error != nil {
+		return "", resp.Error
+	}
+	result := new(struct {
+		TxID string `json:"txid"`
+	})
+	if err := json.Unmarshal(resp.JSONResult(), result); err != nil {
+		return "", err
+	}
+
+	// factomd has the transaction now, so it must not be sent again; its
+	// note now belongs to the transaction id
+	if err := w.moveNote(name, result.TxID); err != nil {
+		w.logf("can not keep the note of transaction %s: %s", name, err)
+	}
+	if err := w.DeleteTransaction(name); err != nil {
+		return result.TxID, err
+	}
+	w.publish(&Event{Type: EventTransactionSent, TxName: name, TxID: result.TxID})
+
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return result.TxID, err
+	}
+	return result.TxID, w.waitForAck(result.TxID, hex.EncodeToString(data))
+}
+
+// waitForAck polls factomd until the transaction txid is acknowledged or
+// AckTimeout runs out.
+func (w *Wallet) waitForAck(txid, fullTx string) error {
+	deadline := time.Now().Add(AckTimeout)
+	for {
+		var status *factom.FactoidTxStatus
+		if err := w.retry.Do(func() (err error) {
+			status, err = factom.FactoidACK(txid, fullTx)
+			return err
+		}); err != nil {
+			return err
+		}
+		switch status.Status {
+		case "TransactionACK", "DBlockConfirmed":
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrTXNotAcked
+		}
+		time.Sleep(AckPollInterval)
+	}
+}
+
+// SendFactoid pays amount factoshis from the Factoid address from in the
+// wallet to the Factoid address to in one call: it builds a tmp transaction,
+// adds the fee at the current rate to the input, signs and sends it with
+// SendTransaction. The tmp transaction is removed whether or not the payment
+// succeeds, except that a payment above the approval threshold returns a
+// PendingApprovalError and keeps it to be approved, signed and sent by name.
+// force skips the balance and fee checks as in SignTransaction.
+func (w *Wallet) SendFactoid(from, to string, amount uint64, force bool) (string, error) {
+	if w.readOnly {
+		return "", ErrReadOnly
+	}
+	rate, err := getRate(w.retry)
+	if err != nil {
+		return "", err
+	}
+	txid, _, err := w.payFrom(from, amount, rate, force, func(name string) error {
+		return w.AddOutput(name, to, amount)
+	})
+	return txid, err
+}
+
+// ECPurchase is the outcome of BuyEC.
+type ECPurchase struct {
+	TxID string `json:"txid"`
+
+	// ECs is the number of entry credits added to the balance of the Entry
+	// Credit address.
+	ECs uint64 `json:"ecs"`
+
+	// Rate is the price of an entry credit in factoshis and Cost the
+	// factoshis spent from the Factoid address, fee included.
+	Rate uint64 `json:"rate"`
+	Cost uint64 `json:"cost"`
+}
+
+// BuyEC converts factoids from the Factoid address from in the wallet to ecs
+// entry credits for the Entry Credit address to at the current rate, and
+// sends the transaction like SendFactoid.
+func (w *Wallet) BuyEC(from, to string, ecs uint64, force bool) (*ECPurchase, error) {
+	if w.readOnly {
+		return nil, ErrReadOnly
+	}
+	if factom.AddressStringType(to) != factom.ECPub {
+		return nil, validationErrorf("wallet: %s is not an Entry Credit address", to)
+	}
+	if ecs == 0 {
+		return nil, validationErrorf("wallet: At least one entry credit must be bought")
+	}
+	rate, err := getRate(w.retry)
+	if err != nil {
+		return nil, err
+	}
+	amount := ecs * rate
+	if amount/rate != ecs {
+		return nil, validationErrorf("wallet: Too many entry credits")
+	}
+
+	p := &ECPurchase{ECs: ecs, Rate: rate}
+	p.TxID, p.Cost, err = w.payFrom(from, amount, rate, force, func(name string) error {
+		return w.AddECOutput(name, to, amount)
+	})
+	if err != nil && !errors.Is(err, ErrTXNotAcked) {
+		return nil, err
+	}
+	return p, err
+}
+
+// payFrom sends a one off transaction with an input of amount factoshis from
+// the Factoid address from, the outputs added by outputs and the fee at rate
+// paid by the input. It returns the transaction id and the total input. The
+// tmp transaction is removed whether or not it could be sent, unless it is
+// waiting for approval, see PendingApprovalError.
+func (w *Wallet) payFrom(from string, amount, rate uint64, force bool, outputs func(name string) error) (string, uint64, error) {
+	n := make([]byte, 16)
+	if _, err := rand.Read(n); err != nil {
+		return "", 0, err
+	}
+	name := hex.EncodeToString(n)
+	if err := w.NewTransaction(name); err != nil {
+		return "", 0, err
+	}
+	pending := false
+	defer func() {
+		if !pending && w.TransactionExists(name) {
+			w.DeleteTransaction(name)
+		}
+	}()
+
+	if err := w.AddInput(name, from, amount); err != nil {
+		return "", 0, err
+	}
+	if err := outputs(name); err != nil {
+		return "", 0, err
+	}
+	if err := w.AddFee(name, from, rate); err != nil {
+		return "", 0, err
+	}
+	if err := w.SignTransaction(name, force); errors.Is(err, ErrApprovalRequired) {
+		pending = true
+		return "", 0, &PendingApprovalError{Name: name}
+	} else if err != nil {
+		return "", 0, err
+	}
+
+	tx, err := w.GetTransaction(name)
+	if err != nil {
+		return "", 0, err
+	}
+	total, err := tx.TotalInputs()
+	if err != nil {
+		return "", 0, err
+	}
+	txid, err := w.SendTransaction(name)
+	return txid, total, err
+}
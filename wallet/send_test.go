@@ -0,0 +1,188 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+)
+
+// sendServer is a factomd that accepts every transaction and acknowledges it
+// with *status.
+func sendServer(t *testing.T, status *string, submitted *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(factom.JSON2Request)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+		switch req.Method {
+		case "entry-credit-rate":
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"rate": 1000}}`)
+		case "factoid-submit":
+			*submitted++
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"message": "Successfully submitted the transaction", "txid": "a8d4bb0f7bfa8ed2dc3a1c6e0f8d6be5e1e8d1d4f5e3b9e5a4c0a2a0e0e0e0e0"}}`)
+		case "ack":
+			fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 0, "result": {"txid": "a8d4bb0f7bfa8ed2dc3a1c6e0f8d6be5e1e8d1d4f5e3b9e5a4c0a2a0e0e0e0e0", "status": %q}}`, *status)
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+		}
+	}))
+}
+
+func TestSendTransaction(t *testing.T) {
+	status := "TransactionACK"
+	submitted := 0
+	ts := sendServer(t, &status, &submitted)
+	defer ts.Close()
+	factom.SetFactomdServer(ts.URL[7:])
+
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	f1, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f2, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	build := func(name string) {
+		if err := w1.NewTransaction(name); err != nil {
+			t.Fatal(err)
+		}
+		if err := w1.AddInput(name, f1.String(), 1e8); err != nil {
+			t.Fatal(err)
+		}
+		if err := w1.AddOutput(name, f2.String(), 1e8); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// unsigned transactions are not sent
+	build("tx1")
+	if _, err := w1.SendTransaction("tx1"); err != ErrTXNotSigned {
+		t.Errorf("expected ErrTXNotSigned, got %v", err)
+	}
+	if submitted != 0 {
+		t.Error("unsigned transaction was submitted")
+	}
+
+	if err := w1.SignTransaction("tx1", true); err != nil {
+		t.Fatal(err)
+	}
+	txid, err := w1.SendTransaction("tx1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txid != "a8d4bb0f7bfa8ed2dc3a1c6e0f8d6be5e1e8d1d4f5e3b9e5a4c0a2a0e0e0e0e0" {
+		t.Errorf("wrong txid %s", txid)
+	}
+	if w1.TransactionExists("tx1") {
+		t.Error("sent transaction is still in the wallet")
+	}
+
+	// a transaction that is never acknowledged
+	defer func(d time.Duration) { AckTimeout = d }(AckTimeout)
+	AckTimeout = 0
+	status = "NotConfirmed"
+	build("tx2")
+	if err := w1.SignTransaction("tx2", true); err != nil {
+		t.Fatal(err)
+	}
+	if txid, err := w1.SendTransaction("tx2"); err != ErrTXNotAcked || txid == "" {
+		t.Errorf("expected ErrTXNotAcked with the txid, got %q %v", txid, err)
+	}
+}
+
+func TestSendFactoid(t *testing.T) {
+	status := "TransactionACK"
+	submitted := 0
+	ts := sendServer(t, &status, &submitted)
+	defer ts.Close()
+	factom.SetFactomdServer(ts.URL[7:])
+
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	f1, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f2, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w1.SendFactoid(f1.String(), f2.String(), 1e8, true); err != nil {
+		t.Fatal(err)
+	}
+	if submitted != 1 {
+		t.Errorf("submitted %d transactions, want 1", submitted)
+	}
+
+	// a payment that fails leaves no tmp transaction behind
+	if _, err := w1.SendFactoid(f1.String(), "not an address", 1e8, true); err == nil {
+		t.Error("payment to an invalid address was sent")
+	}
+	if n := len(w1.GetTransactions()); n != 0 {
+		t.Errorf("%d tmp transactions left in the wallet", n)
+	}
+}
+
+func TestBuyEC(t *testing.T) {
+	status := "TransactionACK"
+	submitted := 0
+	ts := sendServer(t, &status, &submitted)
+	defer ts.Close()
+	factom.SetFactomdServer(ts.URL[7:])
+
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	f1, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e1, err := w1.GenerateECAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := w1.BuyEC(f1.String(), e1.PubString(), 50, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ECs != 50 || p.Rate != 1000 {
+		t.Errorf("wrong purchase %+v", p)
+	}
+	if p.Cost <= 50*1000 {
+		t.Errorf("cost %d does not include the fee", p.Cost)
+	}
+
+	if _, err := w1.BuyEC(f1.String(), f1.String(), 50, true); err == nil {
+		t.Error("entry credits were bought for a Factoid address")
+	}
+	if submitted != 1 {
+		t.Errorf("submitted %d transactions, want 1", submitted)
+	}
+}
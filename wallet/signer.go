@@ -0,0 +1,348 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	ed "github.com/FactomProject/ed25519"
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factomd/common/factoid"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+// Signer signs messages with the secret keys of Factoid addresses, Entry
+// Credit addresses and identity keys, which are named by their public
+// strings. A Wallet is a Signer for its own keys.
+type Signer interface {
+	// PublicKey returns the ed25519 public key of pub.
+	PublicKey(pub string) ([]byte, error)
+
+	// Sign returns the ed25519 signature of msg by the secret key of pub.
+	Sign(pub string, msg []byte) ([]byte, error)
+}
+
+var _ Signer = (*Wallet)(nil)
+
+// KeyGenerator is a Signer that creates keys of its own and never reveals
+// their secrets, such as a hardware security module. A wallet whose signer
+// is a KeyGenerator creates its new Factoid and Entry Credit addresses with
+// it instead of deriving them from the wallet seed.
+type KeyGenerator interface {
+	Signer
+
+	// GenerateKey creates a new ed25519 key and returns its public key.
+	GenerateKey() ([]byte, error)
+}
+
+// SetSigner makes the wallet use s for all of its signatures: transactions,
+// entry and chain commits, and raw data. The keys then only need to be known
+// to s, so a wallet serving the api can hold no secrets at all. Identity
+// entries are still signed with the identity keys in the wallet. Passing nil
+// signs with the keys in the wallet again.
+func (w *Wallet) SetSigner(s Signer) {
+	w.signer = s
+}
+
+// PublicKey returns the ed25519 public key of a Factoid address, Entry Credit
+// address or identity key.
+func (w *Wallet) PublicKey(pub string) ([]byte, error) {
+	if w.signer != nil {
+		return w.signer.PublicKey(pub)
+	}
+
+	switch {
+	case factom.AddressStringType(pub) == factom.FactoidPub:
+		f, err := w.GetFCTAddress(pub)
+		if errors.Is(err, ErrNoSuchAddress) {
+			if k := w.watchOnlyKey(pub); k != nil {
+				return k, nil
+			}
+		}
+		if err != nil {
+			return nil, w.keyError(pub, err)
+		}
+		defer f.Wipe()
+		return f.PubBytes(), nil
+	case factom.AddressStringType(pub) == factom.ECPub:
+		e, err := w.GetECAddress(pub)
+		if err != nil {
+			return nil, w.keyError(pub, err)
+		}
+		defer e.Wipe()
+		return e.PubBytes(), nil
+	case factom.IdentityKeyStringType(pub) == factom.IDPub:
+		k, err := w.GetIdentityKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		defer k.Wipe()
+		return k.PubBytes(), nil
+	}
+	return nil, validationErrorf("wallet: %s is not a public key", pub)
+}
+
+// Sign signs msg with the secret key of a Factoid address, Entry Credit
+// address or identity key. The secret key is wiped from memory once msg is
+// signed.
+func (w *Wallet) Sign(pub string, msg []byte) ([]byte, error) {
+	if w.readOnly {
+		return nil, ErrReadOnly
+	}
+	if w.signer != nil {
+		return w.signer.Sign(pub, msg)
+	}
+
+	switch {
+	case factom.AddressStringType(pub) == factom.FactoidPub:
+		f, err := w.GetFCTAddress(pub)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Wipe()
+		return ed.Sign(f.SecFixed(), msg)[:], nil
+	case factom.AddressStringType(pub) == factom.ECPub:
+		e, err := w.GetECAddress(pub)
+		if err != nil {
+			return nil, err
+		}
+		defer e.Wipe()
+		return e.Sign(msg)[:], nil
+	case factom.IdentityKeyStringType(pub) == factom.IDPub:
+		k, err := w.GetIdentityKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		defer k.Wipe()
+		return k.Sign(msg)[:], nil
+	}
+	return nil, validationErrorf("wallet: %s is not a public key", pub)
+}
+
+// ComposeChainCommit returns the commit-chain request for c paid for by the
+// Entry Credit address ecpub.
+func (w *Wallet) ComposeChainCommit(c *factom.Chain, ecpub string) (*factom.JSON2Request, error) {
+	pub, err := w.PublicKey(ecpub)
+	if err != nil {
+		return nil, err
+	}
+	return factom.ComposeChainCommitSigner(c, pub, func(msg []byte) ([]byte, error) {
+		return w.Sign(ecpub, msg)
+	})
+}
+
+// ComposeEntryCommit returns the commit-entry request for e paid for by the
+// Entry Credit address ecpub.
+func (w *Wallet) ComposeEntryCommit(e *factom.Entry, ecpub string) (*factom.JSON2Request, error) {
+	pub, err := w.PublicKey(ecpub)
+	if err != nil {
+		return nil, err
+	}
+	return factom.ComposeEntryCommitSigner(e, pub, func(msg []byte) ([]byte, error) {
+		return w.Sign(ecpub, msg)
+	})
+}
+
+// inputRCD returns the RCD that spends from the Factoid address.
+func (w *Wallet) inputRCD(address string) (*factoid.RCD_1, error) {
+	pub, err := w.PublicKey(address)
+	if err != nil {
+		return nil, err
+	}
+	rcd := factoid.NewRCD_1(pub)
+
+	// a remote signer must not be able to swap in a key of its own
+	adr, err := rcd.GetAddress()
+	if err != nil {
+		return nil, err
+	}
+	if primitives.ConvertFctAddressToUserStr(adr) != address {
+		return nil, fmt.Errorf("wallet: signer returned the wrong public key for %s", address)
+	}
+	return rcd, nil
+}
+
+// signatureBlock signs data for the input from the Factoid address.
+func (w *Wallet) signatureBlock(address string, data []byte) (*factoid.SignatureBlock, error) {
+	if w.signer == nil {
+		f, err := w.GetFCTAddress(address)
+		if err != nil {
+			return nil, w.keyError(address, err)
+		}
+		defer f.Wipe()
+		return factoid.NewSingleSignatureBlock(f.SecBytes(), data), nil
+	}
+
+	sig, err := w.signer.Sign(address, data)
+	if err != nil {
+		return nil, err
+	}
+	fs := new(factoid.FactoidSignature)
+	if err := fs.SetSignature(sig); err != nil {
+		return nil, err
+	}
+	sb := factoid.NewSignatureBlock()
+	sb.AddSignature(fs)
+	return sb, nil
+}
+
+// signer protocol messages
+type signerRequest struct {
+	Public string `json:"public"`
+	Data   string `json:"data,omitempty"`
+}
+
+type signerResponse struct {
+	PublicKey string `json:"publickey,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// RemoteSigner is a Signer that forwards every request to a signer service,
+// such as one served by NewSignerHandler. Requests are signed with
+// factom.SignRequest using KeyID and Secret.
+type RemoteSigner struct {
+	URL    string
+	KeyID  string
+	Secret []byte
+	Client *http.Client
+}
+
+// NewRemoteSigner returns a RemoteSigner for the signer service at url.
+func NewRemoteSigner(url, keyID string, secret []byte) *RemoteSigner {
+	return &RemoteSigner{URL: url, KeyID: keyID, Secret: secret}
+}
+
+func (r *RemoteSigner) PublicKey(pub string) ([]byte, error) {
+	resp, err := r.call("/public-key", &signerRequest{Public: pub})
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(resp.PublicKey)
+}
+
+func (r *RemoteSigner) Sign(pub string, msg []byte) ([]byte, error) {
+	resp, err := r.call("/sign", &signerRequest{Public: pub, Data: hex.EncodeToString(msg)})
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(resp.Signature)
+}
+
+func (r *RemoteSigner) call(path string, req *signerRequest) (*signerResponse, error) {
+	j, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	hr, err := http.NewRequest("POST", strings.TrimSuffix(r.URL, "/")+path, bytes.NewReader(j))
+	if err != nil {
+		return nil, err
+	}
+	hr.Header.Set("Content-Type", "application/json")
+	factom.SignRequest(hr, j, r.KeyID, r.Secret)
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	hresp, err := client.Do(hr)
+	if err != nil {
+		return nil, &factom.RequestError{Server: r.URL, Method: path, Err: err}
+	}
+	defer hresp.Body.Close()
+
+	resp := new(signerResponse)
+	if err := json.NewDecoder(hresp.Body).Decode(resp); err != nil {
+		return nil, fmt.Errorf("wallet: bad response from signer: %s", hresp.Status)
+	}
+	switch {
+	case hresp.StatusCode == http.StatusBadRequest:
+		return nil, validationErrorf("wallet: signer: %s", resp.Error)
+	case hresp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("wallet: signer: %s", resp.Error)
+	}
+	return resp, nil
+}
+
+// NewSignerHandler serves s to remote wallets using RemoteSigner. keys holds
+// the shared secrets, by key id, that requests must be signed with.
+func NewSignerHandler(s Signer, keys map[string][]byte) http.Handler {
+	return &signerHandler{signer: s, keys: keys}
+}
+
+type signerHandler struct {
+	signer Signer
+	keys   map[string][]byte
+}
+
+func (h *signerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reply := func(status int, resp *signerResponse) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(resp)
+	}
+
+	if r.Method != "POST" {
+		reply(http.StatusMethodNotAllowed, &signerResponse{Error: "method not allowed"})
+		return
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		reply(http.StatusBadRequest, &signerResponse{Error: err.Error()})
+		return
+	}
+
+	secret, ok := h.keys[r.Header.Get(factom.HMACKeyIDHeader)]
+	if !ok {
+		reply(http.StatusUnauthorized, &signerResponse{Error: "unknown key id"})
+		return
+	}
+	if err := factom.VerifyRequestSignature(r, body, secret, factom.DefaultHMACMaxSkew); err != nil {
+		reply(http.StatusUnauthorized, &signerResponse{Error: err.Error()})
+		return
+	}
+
+	req := new(signerRequest)
+	if err := json.Unmarshal(body, req); err != nil {
+		reply(http.StatusBadRequest, &signerResponse{Error: "invalid request"})
+		return
+	}
+
+	resp := new(signerResponse)
+	switch r.URL.Path {
+	case "/public-key":
+		var pub []byte
+		if pub, err = h.signer.PublicKey(req.Public); err == nil {
+			resp.PublicKey = hex.EncodeToString(pub)
+		}
+	case "/sign":
+		var msg, sig []byte
+		if msg, err = hex.DecodeString(req.Data); err != nil {
+			err = validationErrorf("invalid data")
+		} else if sig, err = h.signer.Sign(req.Public, msg); err == nil {
+			resp.Signature = hex.EncodeToString(sig)
+		}
+	default:
+		reply(http.StatusNotFound, &signerResponse{Error: "not found"})
+		return
+	}
+
+	switch {
+	case errors.Is(err, factom.ErrValidation):
+		reply(http.StatusBadRequest, &signerResponse{Error: err.Error()})
+	case err != nil:
+		reply(http.StatusInternalServerError, &signerResponse{Error: err.Error()})
+	default:
+		reply(http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	ed "github.com/FactomProject/ed25519"
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	secret := []byte("signer secret")
+
+	// the signer service holds the keys
+	keys, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer keys.Close()
+	ec, err := keys.GenerateECAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(NewSignerHandler(keys, map[string][]byte{"walletd": secret}))
+	defer ts.Close()
+
+	// the api facing wallet holds none
+	w1, err := New(WithMapDB(), WithSigner(NewRemoteSigner(ts.URL, "walletd", secret)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	pub, err := w1.PublicKey(ec.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pub, ec.PubBytes()) {
+		t.Errorf("wrong public key %x", pub)
+	}
+
+	msg := []byte("commit")
+	sig, err := w1.Sign(ec.String(), msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p [ed.PublicKeySize]byte
+	var s [ed.SignatureSize]byte
+	copy(p[:], pub)
+	copy(s[:], sig)
+	if !ed.Verify(&p, msg, &s) {
+		t.Error("signature does not verify")
+	}
+
+	if _, err := w1.Sign("not a key", msg); err == nil {
+		t.Error("expected an error signing for a bad key")
+	}
+
+	// requests signed with the wrong secret are refused
+	w1.SetSigner(NewRemoteSigner(ts.URL, "walletd", []byte("wrong")))
+	if _, err := w1.Sign(ec.String(), msg); err == nil {
+		t.Error("expected an error with the wrong secret")
+	}
+
+	w1.SetSigner(nil)
+	if _, err := w1.PublicKey(ec.String()); err == nil {
+		t.Error("expected an error without the signer")
+	}
+
+	e := &factom.Entry{ChainID: "df3ade9eec4b08d5379cc64270c30ea7315d8a8a1a69efe2b98a60ecdd69e604", Content: msg}
+	w1.SetSigner(NewRemoteSigner(ts.URL, "walletd", secret))
+	if _, err := w1.ComposeEntryCommit(e, ec.String()); err != nil {
+		t.Error(err)
+	}
+}
+
+// tokenSigner is a KeyGenerator that keeps its keys in memory.
+type tokenSigner struct {
+	keys map[string]*[ed.PrivateKeySize]byte
+}
+
+func (s *tokenSigner) GenerateKey() ([]byte, error) {
+	pub, sec, err := ed.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	rcd := factom.NewRCD1()
+	rcd.Pub = pub
+	s.keys[(&factom.FactoidAddress{RCD: rcd}).String()] = sec
+	s.keys[(&factom.ECAddress{Pub: pub}).PubString()] = sec
+	return pub[:], nil
+}
+
+func (s *tokenSigner) PublicKey(pub string) ([]byte, error) {
+	sec, ok := s.keys[pub]
+	if !ok {
+		return nil, errors.New("no such key")
+	}
+	return ed.GetPublicKey(sec)[:], nil
+}
+
+func (s *tokenSigner) Sign(pub string, msg []byte) ([]byte, error) {
+	sec, ok := s.keys[pub]
+	if !ok {
+		return nil, errors.New("no such key")
+	}
+	return ed.Sign(sec, msg)[:], nil
+}
+
+func TestKeyGenerator(t *testing.T) {
+	token := &tokenSigner{keys: make(map[string]*[ed.PrivateKeySize]byte)}
+	w1, err := New(WithMapDB(), WithSigner(token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	fa, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec, err := w1.GenerateECAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fa.SecString() != "" || ec.SecString() != "" {
+		t.Error("signer keys should have no secret in the wallet")
+	}
+	if _, ok := token.keys[fa.String()]; !ok {
+		t.Error("factoid key was not created by the signer")
+	}
+
+	keys, err := w1.GetAllSignerKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 {
+		t.Errorf("wrong number of signer keys %v", keys)
+	}
+	if ok, err := w1.IsSignerKey(ec.PubString()); err != nil || !ok {
+		t.Error("expected a signer key", err)
+	}
+
+	msg := []byte("transaction")
+	sig, err := w1.Sign(fa.String(), msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s [ed.SignatureSize]byte
+	copy(s[:], sig)
+	if !ed.Verify(fa.RCD.(*factom.RCD1).Pub, msg, &s) {
+		t.Error("signature does not verify")
+	}
+
+	if err := w1.RemoveAddress(ec.PubString()); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := w1.IsSignerKey(ec.PubString()); ok {
+		t.Error("removed key is still a signer key")
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"encoding/gob"
+	"fmt"
+	"sort"
+
+	ed "github.com/FactomProject/ed25519"
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factomd/common/interfaces"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+// signerKeyRecord is an address whose key was created by the KeyGenerator
+// of the wallet. Only the public address is stored.
+type signerKeyRecord struct {
+	Address string
+}
+
+var _ interfaces.BinaryMarshallableAndCopyable = (*signerKeyRecord)(nil)
+
+// signerKeyData is signerKeyRecord without its methods, for gob.
+type signerKeyData signerKeyRecord
+
+func (r *signerKeyRecord) New() interfaces.BinaryMarshallableAndCopyable {
+	return new(signerKeyRecord)
+}
+
+func (r *signerKeyRecord) MarshalBinary() ([]byte, error) {
+	var data primitives.Buffer
+
+	enc := gob.NewEncoder(&data)
+	if err := enc.Encode(signerKeyData(*r)); err != nil {
+		return nil, err
+	}
+	return data.DeepCopyBytes(), nil
+}
+
+func (r *signerKeyRecord) UnmarshalBinaryData(data []byte) ([]byte, error) {
+	dec := gob.NewDecoder(primitives.NewBuffer(data))
+	if err := dec.Decode((*signerKeyData)(r)); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (r *signerKeyRecord) UnmarshalBinary(data []byte) error {
+	_, err := r.UnmarshalBinaryData(data)
+	return err
+}
+
+// keyGenerator returns the signer of the wallet if it creates its own keys.
+func (w *Wallet) keyGenerator() (KeyGenerator, bool) {
+	g, ok := w.signer.(KeyGenerator)
+	return g, ok
+}
+
+// generateSignerKeys creates n keys with g and returns their public keys.
+func generateSignerKeys(g KeyGenerator, n int) ([]*[ed.PublicKeySize]byte, error) {
+	pubs := make([]*[ed.PublicKeySize]byte, n)
+	for i := range pubs {
+		p, err := g.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		if len(p) != ed.PublicKeySize {
+			return nil, fmt.Errorf("wallet: signer returned a public key of %d bytes", len(p))
+		}
+		pubs[i] = new([ed.PublicKeySize]byte)
+		copy(pubs[i][:], p)
+	}
+	return pubs, nil
+}
+
+// putSignerKeys stores the public addresses of keys held by the signer.
+func (w *Wallet) putSignerKeys(addresses []string) error {
+	records := make([]interfaces.Record, len(addresses))
+	for i, a := range addresses {
+		records[i] = interfaces.Record{Bucket: signerDBPrefix, Key: []byte(a), Data: &signerKeyRecord{Address: a}}
+	}
+	return dbError("write", w.DBO.PutInBatch(records))
+}
+
+// generateSignerFCTAddresses creates n Factoid Addresses with g. The
+// addresses have no secret.
+func (w *Wallet) generateSignerFCTAddresses(g KeyGenerator, n int) ([]*factom.FactoidAddress, error) {
+	pubs, err := generateSignerKeys(g, n)
+	if err != nil {
+		return nil, err
+	}
+	as := make([]*factom.FactoidAddress, n)
+	strs := make([]string, n)
+	for i, p := range pubs {
+		rcd := factom.NewRCD1()
+		rcd.Pub = p
+		as[i] = &factom.FactoidAddress{RCD: rcd}
+		strs[i] = as[i].String()
+	}
+	if err := w.putSignerKeys(strs); err != nil {
+		return nil, err
+	}
+	return as, nil
+}
+
+// generateSignerECAddresses creates n Entry Credit Addresses with g. The
+// addresses have no secret.
+func (w *Wallet) generateSignerECAddresses(g KeyGenerator, n int) ([]*factom.ECAddress, error) {
+	pubs, err := generateSignerKeys(g, n)
+	if err != nil {
+		return nil, err
+	}
+	as := make([]*factom.ECAddress, n)
+	strs := make([]string, n)
+	for i, p := range pubs {
+		as[i] = &factom.ECAddress{Pub: p}
+		strs[i] = as[i].PubString()
+	}
+	if err := w.putSignerKeys(strs); err != nil {
+		return nil, err
+	}
+	return as, nil
+}
+
+// IsSignerKey reports whether the key of the address pub was created by the
+// signer of the wallet.
+func (w *Wallet) IsSignerKey(pub string) (bool, error) {
+	data, err := w.DBO.Get(signerDBPrefix, []byte(pub), new(signerKeyRecord))
+	if err != nil {
+		return false, dbError("read", err)
+	}
+	return data != nil, nil
+}
+
+// GetAllSignerKeys returns the addresses whose keys were created by the
+// signer of the wallet, sorted.
+func (w *Wallet) GetAllSignerKeys() ([]string, error) {
+	list, err := w.DBO.FetchAllBlocksFromBucket(signerDBPrefix, new(signerKeyRecord))
+	if err != nil {
+		return nil, dbError("read", err)
+	}
+
+	as := make([]string, len(list))
+	for i, v := range list {
+		as[i] = v.(*signerKeyRecord).Address
+	}
+	sort.Strings(as)
+	return as, nil
+}
@@ -0,0 +1,349 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"sort"
+
+	"github.com/FactomProject/factomd/common/interfaces"
+)
+
+// WalletStore is a key value store with buckets that a wallet can be kept in.
+// The Bolt, LevelDB and SQLite stores are provided by NewBoltStore,
+// NewLevelStore and NewSQLiteStore, and other databases can be used by
+// implementing it and opening the wallet with NewOrOpenStoreWallet.
+type WalletStore interface {
+	// Get returns the value of key in bucket, or nil if there is none.
+	Get(bucket, key []byte) ([]byte, error)
+	Put(bucket, key, value []byte) error
+	Delete(bucket, key []byte) error
+
+	// PutBatch writes every record or, if it fails, none of them. The
+	// wallet writes records that depend on each other, such as new keys and
+	// the seed index they were derived at, in one batch.
+	PutBatch(records []StoreRecord) error
+
+	// Iterate calls fn with every key and value in bucket, in key order.
+	// It stops at the first error returned by fn, which it returns.
+	Iterate(bucket []byte, fn func(key, value []byte) error) error
+
+	// Buckets returns the names of the buckets that hold any records.
+	Buckets() ([][]byte, error)
+
+	Close() error
+}
+
+// StoreRecord is a record written by WalletStore.PutBatch.
+type StoreRecord struct {
+	Bucket, Key, Value []byte
+}
+
+// storeDB is the database of a wallet kept in a WalletStore.
+type storeDB struct {
+	WalletStore
+}
+
+var _ interfaces.IDatabase = (*storeDB)(nil)
+
+// newStoreOverlay returns the wallet database kept in s. The stores that wrap
+// a factomd database are used directly.
+func newStoreOverlay(s WalletStore) *WalletDatabaseOverlay {
+	if ds, ok := s.(*dbStore); ok {
+		return NewWalletOverlay(ds.db)
+	}
+	return NewWalletOverlay(&storeDB{s})
+}
+
+func (db *storeDB) Put(bucket, key []byte, data interfaces.BinaryMarshallable) error {
+	value, err := data.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return db.WalletStore.Put(bucket, key, value)
+}
+
+func (db *storeDB) PutInBatch(records []interfaces.Record) error {
+	batch := make([]StoreRecord, len(records))
+	for i, r := range records {
+		value, err := r.Data.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		batch[i] = StoreRecord{Bucket: r.Bucket, Key: r.Key, Value: value}
+	}
+	return db.WalletStore.PutBatch(batch)
+}
+
+func (db *storeDB) Get(bucket, key []byte, destination interfaces.BinaryMarshallable) (interfaces.BinaryMarshallable, error) {
+	value, err := db.WalletStore.Get(bucket, key)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	if err := destination.UnmarshalBinary(value); err != nil {
+		return nil, err
+	}
+	return destination, nil
+}
+
+func (db *storeDB) GetAll(bucket []byte, sample interfaces.BinaryMarshallableAndCopyable) ([]interfaces.BinaryMarshallableAndCopyable, [][]byte, error) {
+	var (
+		data []interfaces.BinaryMarshallableAndCopyable
+		keys [][]byte
+	)
+	err := db.Iterate(bucket, func(key, value []byte) error {
+		d := sample.New()
+		if err := d.UnmarshalBinary(value); err != nil {
+			return err
+		}
+		data = append(data, d)
+		keys = append(keys, append([]byte(nil), key...))
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, keys, nil
+}
+
+func (db *storeDB) ListAllKeys(bucket []byte) ([][]byte, error) {
+	var keys [][]byte
+	err := db.Iterate(bucket, func(key, _ []byte) error {
+		keys = append(keys, append([]byte(nil), key...))
+		return nil
+	})
+	return keys, err
+}
+
+func (db *storeDB) ListAllBuckets() ([][]byte, error) {
+	return db.Buckets()
+}
+
+func (db *storeDB) DoesKeyExist(bucket, key []byte) (bool, error) {
+	value, err := db.WalletStore.Get(bucket, key)
+	return value != nil, err
+}
+
+func (db *storeDB) Clear(bucket []byte) error {
+	keys, err := db.ListAllKeys(bucket)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := db.WalletStore.Delete(bucket, k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *storeDB) Trim() {}
+
+// dbStore is a WalletStore kept in a factomd database.
+type dbStore struct {
+	db interfaces.IDatabase
+}
+
+// NewBoltStore opens or creates a Bolt database file at path.
+func NewBoltStore(path string) (WalletStore, error) {
+	db, err := NewBoltDB(path)
+	if err != nil {
+		return nil, err
+	}
+	return &dbStore{db.DBO.DB}, nil
+}
+
+// NewLevelStore opens or creates a LevelDB database at path.
+func NewLevelStore(path string) (WalletStore, error) {
+	db, err := NewLevelDB(path)
+	if err != nil {
+		return nil, err
+	}
+	return &dbStore{db.DBO.DB}, nil
+}
+
+func (s *dbStore) Get(bucket, key []byte) ([]byte, error) {
+	data, err := s.db.Get(bucket, key, new(rawRecord))
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return data.(*rawRecord).data, nil
+}
+
+func (s *dbStore) Put(bucket, key, value []byte) error {
+	return s.db.Put(bucket, key, &rawRecord{data: value})
+}
+
+func (s *dbStore) Delete(bucket, key []byte) error {
+	return s.db.Delete(bucket, key)
+}
+
+func (s *dbStore) PutBatch(records []StoreRecord) error {
+	batch := make([]interfaces.Record, len(records))
+	for i, r := range records {
+		batch[i] = interfaces.Record{Bucket: r.Bucket, Key: r.Key, Data: &rawRecord{data: r.Value}}
+	}
+	return s.db.PutInBatch(batch)
+}
+
+func (s *dbStore) Iterate(bucket []byte, fn func(key, value []byte) error) error {
+	data, keys, err := s.db.GetAll(bucket, new(rawRecord))
+	if err != nil {
+		return err
+	}
+	// not every factomd database returns the keys in order
+	idx := make([]int, len(keys))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(i, j int) bool {
+		return bytes.Compare(keys[idx[i]], keys[idx[j]]) < 0
+	})
+	for _, i := range idx {
+		if err := fn(keys[i], data[i].(*rawRecord).data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *dbStore) Buckets() ([][]byte, error) {
+	return s.db.ListAllBuckets()
+}
+
+func (s *dbStore) Close() error {
+	return s.db.Close()
+}
+
+// sqliteStore is a WalletStore kept in a table of an SQLite database.
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens or creates an SQLite database file at path. The
+// package does not depend on an SQLite driver: the program must register one
+// under the name "sqlite3" with database/sql, for example by importing
+// github.com/mattn/go-sqlite3.
+func NewSQLiteStore(path string) (WalletStore, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, dbError("open", err)
+	}
+	// SQLite allows a single writer, so one connection avoids busy errors
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS records (
+		bucket BLOB NOT NULL,
+		key BLOB NOT NULL,
+		value BLOB NOT NULL,
+		PRIMARY KEY (bucket, key)
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, dbError("open", err)
+	}
+	return &sqliteStore{db}, nil
+}
+
+func (s *sqliteStore) Get(bucket, key []byte) ([]byte, error) {
+	var value []byte
+	err := s.db.QueryRow(`SELECT value FROM records WHERE bucket = ? AND key = ?`, bucket, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// an empty value must still be found
+	if value == nil {
+		value = []byte{}
+	}
+	return value, nil
+}
+
+func (s *sqliteStore) Put(bucket, key, value []byte) error {
+	if value == nil {
+		value = []byte{}
+	}
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO records (bucket, key, value) VALUES (?, ?, ?)`, bucket, key, value)
+	return err
+}
+
+func (s *sqliteStore) Delete(bucket, key []byte) error {
+	_, err := s.db.Exec(`DELETE FROM records WHERE bucket = ? AND key = ?`, bucket, key)
+	return err
+}
+
+// PutBatch writes the records in one SQL transaction.
+func (s *sqliteStore) PutBatch(records []StoreRecord) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		value := r.Value
+		if value == nil {
+			value = []byte{}
+		}
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO records (bucket, key, value) VALUES (?, ?, ?)`, r.Bucket, r.Key, value); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStore) Iterate(bucket []byte, fn func(key, value []byte) error) error {
+	rows, err := s.db.Query(`SELECT key, value FROM records WHERE bucket = ? ORDER BY key`, bucket)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// read every row first so fn may write to the store
+	type record struct{ key, value []byte }
+	var records []record
+	for rows.Next() {
+		var r record
+		if err := rows.Scan(&r.key, &r.value); err != nil {
+			return err
+		}
+		records = append(records, r)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, r := range records {
+		if err := fn(r.key, r.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqliteStore) Buckets() ([][]byte, error) {
+	rows, err := s.db.Query(`SELECT DISTINCT bucket FROM records ORDER BY bucket`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets [][]byte
+	for rows.Next() {
+		var b []byte
+		if err := rows.Scan(&b); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,223 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+
+	. "github.com/FactomProject/factom/wallet"
+)
+
+// memStore is a WalletStore kept in memory, as an application could write
+// for its own database.
+type memStore struct {
+	buckets map[string]map[string][]byte
+	closed  bool
+
+	// failAfter makes batches of more records fail; the records written
+	// before the failure are rolled back.
+	failAfter int
+}
+
+func newMemStore() *memStore {
+	return &memStore{buckets: make(map[string]map[string][]byte)}
+}
+
+func (s *memStore) Get(bucket, key []byte) ([]byte, error) {
+	return s.buckets[string(bucket)][string(key)], nil
+}
+
+func (s *memStore) Put(bucket, key, value []byte) error {
+	b, ok := s.buckets[string(bucket)]
+	if !ok {
+		b = make(map[string][]byte)
+		s.buckets[string(bucket)] = b
+	}
+	b[string(key)] = append([]byte{}, value...)
+	return nil
+}
+
+func (s *memStore) Delete(bucket, key []byte) error {
+	delete(s.buckets[string(bucket)], string(key))
+	return nil
+}
+
+func (s *memStore) PutBatch(records []StoreRecord) error {
+	old := make(map[string]map[string][]byte, len(s.buckets))
+	for name, b := range s.buckets {
+		old[name] = make(map[string][]byte, len(b))
+		for k, v := range b {
+			old[name][k] = v
+		}
+	}
+	for i, r := range records {
+		if s.failAfter > 0 && i == s.failAfter {
+			s.buckets = old
+			return errors.New("batch failed")
+		}
+		s.Put(r.Bucket, r.Key, r.Value)
+	}
+	return nil
+}
+
+func (s *memStore) Iterate(bucket []byte, fn func(key, value []byte) error) error {
+	b := s.buckets[string(bucket)]
+	keys := make([]string, 0, len(b))
+	for k := range b {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := fn([]byte(k), b[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memStore) Buckets() ([][]byte, error) {
+	var buckets [][]byte
+	for name, b := range s.buckets {
+		if len(b) > 0 {
+			buckets = append(buckets, []byte(name))
+		}
+	}
+	return buckets, nil
+}
+
+func (s *memStore) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestNewOrOpenStoreWallet(t *testing.T) {
+	s := newMemStore()
+
+	w1, err := NewOrOpenStoreWallet(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := w1.GenerateECAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.SetAddressLabel(e.PubString(), "fees"); err != nil {
+		t.Error(err)
+	}
+	if err := w1.Close(); err != nil {
+		t.Error(err)
+	}
+	if !s.closed {
+		t.Error("store was not closed with the wallet")
+	}
+
+	// the wallet is read back from the store
+	w2, err := New(WithStore(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w2.Close()
+	if got := w2.Storage().Type; got != "store" {
+		t.Errorf("storage type %q", got)
+	}
+	_, es, err := w2.GetAllAddresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(es) != 1 || es[0].PubString() != e.PubString() {
+		t.Errorf("reopened wallet has ec addresses %v", es)
+	}
+	if label, err := w2.AddressLabel(e.PubString()); err != nil || label != "fees" {
+		t.Errorf("label %q, %v", label, err)
+	}
+
+	if _, err := New(WithStore(nil)); err == nil {
+		t.Error("opened a wallet without a store")
+	}
+}
+
+func TestStoreBatch(t *testing.T) {
+	s := newMemStore()
+	w1, err := NewOrOpenStoreWallet(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+	if _, err := w1.GenerateFCTAddress(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the addresses and the seed are written in one batch, so a batch that
+	// fails partway leaves the store as it was
+	before := newMemStore()
+	for name, b := range s.buckets {
+		for k, v := range b {
+			before.Put([]byte(name), []byte(k), v)
+		}
+	}
+	s.failAfter = 2
+	if _, err := w1.GetNextFCTAddresses(3); err == nil {
+		t.Fatal("batch did not fail")
+	}
+	if !reflect.DeepEqual(s.buckets, before.buckets) {
+		t.Error("failed batch changed the store")
+	}
+
+	s.failAfter = 0
+	if _, err := w1.GetNextFCTAddresses(3); err != nil {
+		t.Fatal(err)
+	}
+	fs, _, err := w1.GetAllAddresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fs) != 4 {
+		t.Errorf("wallet has %d addresses", len(fs))
+	}
+}
+
+func TestBoltStore(t *testing.T) {
+	dbpath := os.TempDir() + "/test_wallet-store.bolt"
+	defer os.Remove(dbpath)
+
+	s, err := NewBoltStore(dbpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	bucket := []byte("bucket")
+	for _, k := range []string{"b", "c", "a"} {
+		if err := s.Put(bucket, []byte(k), []byte("value "+k)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Delete(bucket, []byte("c")); err != nil {
+		t.Error(err)
+	}
+	if v, err := s.Get(bucket, []byte("c")); v != nil || err != nil {
+		t.Errorf("deleted key has value %q, %v", v, err)
+	}
+
+	var keys []string
+	err = s.Iterate(bucket, func(key, value []byte) error {
+		if !bytes.Equal(value, []byte("value "+string(key))) {
+			t.Errorf("key %s has value %q", key, value)
+		}
+		keys = append(keys, string(key))
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Errorf("iterated keys %v", keys)
+	}
+}
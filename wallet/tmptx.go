@@ -0,0 +1,180 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"encoding/gob"
+	"time"
+
+	"github.com/FactomProject/factomd/common/factoid"
+	"github.com/FactomProject/factomd/common/interfaces"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+// tmpTx is a tmp transaction as it is stored in the wallet database, so that
+// transactions being built survive a restart of the wallet.
+type tmpTx struct {
+	Name     string
+	Tx       []byte
+	Modified time.Time
+}
+
+var _ interfaces.BinaryMarshallableAndCopyable = (*tmpTx)(nil)
+
+// tmpTxData is tmpTx without its methods, so that gob encodes its fields
+// instead of calling back into MarshalBinary.
+type tmpTxData tmpTx
+
+func (t *tmpTx) New() interfaces.BinaryMarshallableAndCopyable {
+	return new(tmpTx)
+}
+
+func (t *tmpTx) MarshalBinary() ([]byte, error) {
+	var data primitives.Buffer
+
+	enc := gob.NewEncoder(&data)
+	if err := enc.Encode(tmpTxData(*t)); err != nil {
+		return nil, err
+	}
+	return data.DeepCopyBytes(), nil
+}
+
+func (t *tmpTx) UnmarshalBinaryData(data []byte) ([]byte, error) {
+	dec := gob.NewDecoder(primitives.NewBuffer(data))
+	if err := dec.Decode((*tmpTxData)(t)); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (t *tmpTx) UnmarshalBinary(data []byte) error {
+	_, err := t.UnmarshalBinaryData(data)
+	return err
+}
+
+// InsertTmpTx stores the tmp transaction tx under name with the time it was
+// last modified, replacing the transaction stored under that name before.
+func (db *WalletDatabaseOverlay) InsertTmpTx(name string, tx *factoid.Transaction, modified time.Time) error {
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	t := &tmpTx{Name: name, Tx: data, Modified: modified}
+	return dbError("write", db.DBO.Put(tmpTxDBPrefix, []byte(name), t))
+}
+
+func (db *WalletDatabaseOverlay) getAllTmpTxs() ([]*tmpTx, error) {
+	list, err := db.DBO.FetchAllBlocksFromBucket(tmpTxDBPrefix, new(tmpTx))
+	if err != nil {
+		return nil, dbError("read", err)
+	}
+
+	ts := make([]*tmpTx, len(list))
+	for i, v := range list {
+		ts[i] = v.(*tmpTx)
+	}
+	return ts, nil
+}
+
+func (db *WalletDatabaseOverlay) RemoveTmpTx(name string) error {
+	return dbError("delete", db.DBO.Delete(tmpTxDBPrefix, []byte(name)))
+}
+
+// saveTransaction stores tx as the tmp transaction name, in memory and in the
+// database, and marks it as modified now.
+func (w *Wallet) saveTransaction(name string, tx *factoid.Transaction) error {
+	now := time.Now()
+
+	w.txlock.Lock()
+	defer w.txlock.Unlock()
+
+	if err := w.InsertTmpTx(name, tx, now); err != nil {
+		return err
+	}
+	w.transactions[name] = tx
+	w.txModified[name] = now
+	return nil
+}
+
+// loadTransactions reads the stored tmp transactions into memory the first
+// time the transactions are used. An encrypted wallet can not be read until
+// it is unlocked, so the transactions are read again on the next use until
+// they could be loaded. Transactions already in memory are kept. The caller
+// holds txlock.
+func (w *Wallet) loadTransactions() {
+	if w.txLoaded || w.WalletDatabaseOverlay == nil {
+		return
+	}
+	ts, err := w.getAllTmpTxs()
+	if err != nil {
+		return
+	}
+	for _, t := range ts {
+		if _, exists := w.transactions[t.Name]; exists {
+			continue
+		}
+		tx := new(factoid.Transaction)
+		if err := tx.UnmarshalBinary(t.Tx); err != nil {
+			w.logf("can not load transaction %s: %s", t.Name, err)
+			continue
+		}
+		w.transactions[t.Name] = tx
+
+		// transactions stored without a time expire a full TTL from now
+		w.txModified[t.Name] = t.Modified
+		if t.Modified.IsZero() {
+			w.txModified[t.Name] = time.Now()
+		}
+	}
+	w.txLoaded = true
+}
+
+// SetTmpTxTTL makes the wallet delete unsigned tmp transactions that have not
+// been changed for ttl, so that abandoned transactions do not pile up in a
+// long running wallet. They are removed the next time the tmp transactions
+// are used. Zero, the default, keeps them until they are deleted.
+func (w *Wallet) SetTmpTxTTL(ttl time.Duration) {
+	w.txlock.Lock()
+	defer w.txlock.Unlock()
+
+	w.txTTL = ttl
+}
+
+// TransactionModified returns the time the tmp transaction name was last
+// changed.
+func (w *Wallet) TransactionModified(name string) (time.Time, error) {
+	if !w.TransactionExists(name) {
+		return time.Time{}, ErrTXNotExists
+	}
+
+	w.txlock.Lock()
+	defer w.txlock.Unlock()
+
+	return w.txModified[name], nil
+}
+
+// expireTransactions removes the unsigned tmp transactions that have not been
+// changed for the TTL of the wallet. The caller holds txlock.
+func (w *Wallet) expireTransactions(now time.Time) {
+	if w.txTTL == 0 || w.readOnly {
+		return
+	}
+	for name, tx := range w.transactions {
+		if len(tx.GetSignatureBlocks()) > 0 && tx.ValidateSignatures() == nil {
+			continue
+		}
+		if now.Sub(w.txModified[name]) < w.txTTL {
+			continue
+		}
+		if err := w.RemoveTmpTx(name); err != nil {
+			continue
+		}
+		w.forgetApproval(w.transactions[name])
+		delete(w.transactions, name)
+		delete(w.txModified, name)
+		w.removeNote(name)
+		w.logf("expired transaction %s", name)
+	}
+}
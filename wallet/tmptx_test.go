@@ -0,0 +1,112 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestTmpTxPersistence(t *testing.T) {
+	dbpath := os.TempDir() + "/test_wallet-tmptx"
+	defer os.RemoveAll(dbpath)
+
+	w1, err := New(WithLevelDB(dbpath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f1, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f2, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"half-built", "deleted"} {
+		if err := w1.NewTransaction(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w1.AddInput("half-built", f1.String(), 5e8); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddOutput("half-built", f2.String(), 5e8); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.DeleteTransaction("deleted"); err != nil {
+		t.Fatal(err)
+	}
+	want := w1.GetTransactions()["half-built"].GetSigHash().String()
+	w1.Close()
+
+	// the transaction is still there after the wallet is reopened
+	w2, err := New(WithLevelDB(dbpath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w2.Close()
+
+	tx, err := w2.GetTransaction("half-built")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tx.GetSigHash().String(); got != want {
+		t.Errorf("reloaded transaction differs: got %s want %s", got, want)
+	}
+	if w2.TransactionExists("deleted") {
+		t.Error("deleted transaction was reloaded")
+	}
+
+	// and it can be finished
+	if err := w2.SignTransaction("half-built", true); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTmpTxExpiry(t *testing.T) {
+	w1, err := New(WithMapDB(), WithTmpTxTTL(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	f1, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"abandoned", "signed"} {
+		if err := w1.NewTransaction(name); err != nil {
+			t.Fatal(err)
+		}
+		if err := w1.AddInput(name, f1.String(), 1e8); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w1.SignTransaction("signed", true); err != nil {
+		t.Fatal(err)
+	}
+	modified, err := w1.TransactionModified("abandoned")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(modified) > time.Minute {
+		t.Errorf("wrong modification time %s", modified)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if w1.TransactionExists("abandoned") {
+		t.Error("unsigned transaction did not expire")
+	}
+	if !w1.TransactionExists("signed") {
+		t.Error("signed transaction expired")
+	}
+	if _, err := w1.TransactionModified("abandoned"); err != ErrTXNotExists {
+		t.Errorf("expected ErrTXNotExists, got %v", err)
+	}
+}
@@ -9,30 +9,62 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"time"
 
 	"github.com/FactomProject/btcutil/base58"
 	"github.com/FactomProject/factom"
 	"github.com/FactomProject/factomd/common/factoid"
+	"github.com/FactomProject/factomd/common/interfaces"
 	"github.com/FactomProject/factomd/common/primitives"
 	"github.com/FactomProject/goleveldb/leveldb"
 )
 
 var (
-	ErrFeeTooLow         = errors.New("wallet: Insufficient Fee")
-	ErrNoSuchAddress     = errors.New("wallet: No such address")
-	ErrNoSuchIdentityKey = errors.New("wallet: No such identity key")
-	ErrTXExists          = errors.New("wallet: Transaction name already exists")
-	ErrTXNotExists       = errors.New("wallet: Transaction name was not found")
-	ErrTXNoInputs        = errors.New("wallet: Transaction has no inputs")
-	ErrTXInvalidName     = errors.New("wallet: Transaction name is not valid")
+	ErrFeeTooLow         = validationErrorf("wallet: Insufficient Fee")
+	ErrNoSuchAddress     = validationErrorf("wallet: No such address")
+	ErrNoSuchIdentityKey = validationErrorf("wallet: No such identity key")
+	ErrTXExists          = validationErrorf("wallet: Transaction name already exists")
+	ErrTXNotExists       = validationErrorf("wallet: Transaction name was not found")
+	ErrTXNoInputs        = validationErrorf("wallet: Transaction has no inputs")
+	ErrTXInvalidName     = validationErrorf("wallet: Transaction name is not valid")
+
+	// ErrTXMultisig is returned for inputs with a multisignature (type 2)
+	// RCD. factomd does not validate their signatures, so transactions that
+	// spend from them can not be sent, and the wallet neither creates m of n
+	// RCDs nor collects partial signatures for them.
+	ErrTXMultisig = validationErrorf("wallet: Multisignature inputs are not supported")
+
+	ErrAmountTooLarge = validationErrorf("wallet: Amount exceeds the supply of factoids")
+	ErrAmountOverflow = validationErrorf("wallet: Transaction amounts overflow")
+	ErrZeroOutput     = validationErrorf("wallet: Output amount must not be zero")
 )
 
+// MaxAmount is the largest amount in factoshis that the wallet puts in a
+// transaction, on its own or summed over the inputs or the outputs. It is
+// well above the supply of factoids, so anything larger is a mistake such as
+// a factoid amount given in factoshis twice over.
+const MaxAmount uint64 = 1e8 * 1e8
+
 func (w *Wallet) NewTransaction(name string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
 	if w.TransactionExists(name) {
 		return ErrTXExists
 	}
 
-	// check that the transaction name is valid
+	if err := checkTxName(name); err != nil {
+		return err
+	}
+
+	tx := new(factoid.Transaction)
+	tx.SetTimestamp(primitives.NewTimestampNow())
+
+	return w.saveTransaction(name, tx)
+}
+
+// checkTxName checks that name can be used for a tmp transaction.
+func checkTxName(name string) error {
 	if name == "" {
 		return ErrTXInvalidName
 	}
@@ -44,41 +76,56 @@ func (w *Wallet) NewTransaction(name string) error {
 	} else if match {
 		return ErrTXInvalidName
 	}
-
-	tx := new(factoid.Transaction)
-	tx.SetTimestamp(primitives.NewTimestampNow())
-
-	w.txlock.Lock()
-	defer w.txlock.Unlock()
-
-	w.transactions[name] = tx
 	return nil
 }
 
 func (w *Wallet) DeleteTransaction(name string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
 	if !w.TransactionExists(name) {
 		return ErrTXNotExists
 	}
 
 	w.txlock.Lock()
 	defer w.txlock.Unlock()
+	if err := w.RemoveTmpTx(name); err != nil {
+		return err
+	}
+	w.forgetApproval(w.transactions[name])
 	delete(w.transactions, name)
-	return nil
+	delete(w.txModified, name)
+	return w.removeNote(name)
 }
 
 func (w *Wallet) AddInput(name, address string, amount uint64) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
 	tx, err := w.GetTransaction(name)
 	if err != nil {
 		return err
 	}
+	if err := w.addInput(tx, address, amount); err != nil {
+		return err
+	}
+	return w.saveTransaction(name, tx)
+}
 
-	a, err := w.GetFCTAddress(address)
-	if err == leveldb.ErrNotFound {
+func (w *Wallet) addInput(tx *factoid.Transaction, address string, amount uint64) error {
+	rcd, err := w.inputRCD(address)
+	if errors.Is(err, leveldb.ErrNotFound) {
 		return ErrNoSuchAddress
 	} else if err != nil {
 		return err
 	}
-	adr := factoid.NewAddress(a.RCDHash())
+	adr, err := rcd.GetAddress()
+	if err != nil {
+		return err
+	}
+	if err := checkAmount(tx, partInputs, adr, amount); err != nil {
+		return err
+	}
 
 	// First look if this is really an update
 	for _, input := range tx.GetInputs() {
@@ -90,23 +137,68 @@ func (w *Wallet) AddInput(name, address string, amount uint64) error {
 
 	// Add our new input
 	tx.AddInput(adr, amount)
-	tx.AddRCD(factoid.NewRCD_1(a.PubBytes()))
+	tx.AddRCD(rcd)
 
 	return nil
 }
 
 func (w *Wallet) AddOutput(name, address string, amount uint64) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
 	tx, err := w.GetTransaction(name)
 	if err != nil {
 		return err
 	}
+	if err := addOutput(tx, address, amount); err != nil {
+		return err
+	}
+	return w.saveTransaction(name, tx)
+}
 
+// AddOutputs adds all of outputs to the tmp transaction name. Either all of
+// them are added or, if one is invalid, none. An address may only appear once
+// in outputs; an address that is already an output of the transaction gets
+// the new amount as in AddOutput.
+func (w *Wallet) AddOutputs(name string, outputs []*factom.TransAddress) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	if len(outputs) == 0 {
+		return validationErrorf("wallet: No outputs to add")
+	}
+	tx, err := w.GetTransaction(name)
+	if err != nil {
+		return err
+	}
+
+	trial, err := copyTransaction(tx)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for i, out := range outputs {
+		if seen[out.Address] {
+			return validationErrorf("wallet: Output %d: %s is listed more than once", i, out.Address)
+		}
+		seen[out.Address] = true
+		if err := addOutput(trial, out.Address, out.Amount); err != nil {
+			return fmt.Errorf("wallet: Output %d: %w", i, err)
+		}
+	}
+	return w.saveTransaction(name, trial)
+}
+
+func addOutput(tx *factoid.Transaction, address string, amount uint64) error {
 	// Make sure that this is a valid Factoid output
 	if factom.AddressStringType(address) != factom.FactoidPub {
-		return errors.New("Invalid Factoid Address")
+		return validationErrorf("Invalid Factoid Address")
 	}
 
 	adr := factoid.NewAddress(base58.Decode(address)[2:34])
+	if err := checkAmount(tx, partOutputs, adr, amount); err != nil {
+		return err
+	}
 
 	// First look if this is really an update
 	for _, output := range tx.GetOutputs() {
@@ -122,17 +214,29 @@ func (w *Wallet) AddOutput(name, address string, amount uint64) error {
 }
 
 func (w *Wallet) AddECOutput(name, address string, amount uint64) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
 	tx, err := w.GetTransaction(name)
 	if err != nil {
 		return err
 	}
+	if err := addECOutput(tx, address, amount); err != nil {
+		return err
+	}
+	return w.saveTransaction(name, tx)
+}
 
+func addECOutput(tx *factoid.Transaction, address string, amount uint64) error {
 	// Make sure that this is a valid Entry Credit output
 	if factom.AddressStringType(address) != factom.ECPub {
-		return errors.New("Invalid Entry Credit Address")
+		return validationErrorf("Invalid Entry Credit Address")
 	}
 
 	adr := factoid.NewAddress(base58.Decode(address)[2:34])
+	if err := checkAmount(tx, partECOutputs, adr, amount); err != nil {
+		return err
+	}
 
 	// First look if this is really an update
 	for _, output := range tx.GetECOutputs() {
@@ -147,12 +251,149 @@ func (w *Wallet) AddECOutput(name, address string, amount uint64) error {
 	return nil
 }
 
+// checkAmount checks amount before it is set for adr in part of tx. Outputs
+// must not be zero, and no amount may exceed MaxAmount on its own or summed
+// with the rest of the inputs, or of the Factoid and Entry Credit outputs.
+func checkAmount(tx *factoid.Transaction, part txPart, adr interfaces.IAddress, amount uint64) error {
+	if amount == 0 && part != partInputs {
+		return ErrZeroOutput
+	}
+	if amount > MaxAmount {
+		return ErrAmountTooLarge
+	}
+
+	total := amount
+	add := func(p txPart, io interfaces.ITransAddress) error {
+		// an update replaces the old amount
+		if p == part && io.GetAddress().IsSameAs(adr) {
+			return nil
+		}
+		if total+io.GetAmount() < total {
+			return ErrAmountOverflow
+		}
+		total += io.GetAmount()
+		return nil
+	}
+	if part == partInputs {
+		for _, in := range tx.GetInputs() {
+			if err := add(partInputs, in); err != nil {
+				return err
+			}
+		}
+	} else {
+		for _, out := range tx.GetOutputs() {
+			if err := add(partOutputs, out); err != nil {
+				return err
+			}
+		}
+		for _, ec := range tx.GetECOutputs() {
+			if err := add(partECOutputs, ec); err != nil {
+				return err
+			}
+		}
+	}
+	if total > MaxAmount {
+		return ErrAmountTooLarge
+	}
+	return nil
+}
+
+// txPart is a part of a transaction that addresses are removed from.
+type txPart int
+
+const (
+	partInputs txPart = iota
+	partOutputs
+	partECOutputs
+)
+
+// RemoveInput removes the input from the Factoid address from the tmp
+// transaction name. The amount of an input is changed by adding it again
+// with AddInput. Like any change, removing an input voids the signatures of
+// the transaction.
+func (w *Wallet) RemoveInput(name, address string) error {
+	return w.removeAddress(name, partInputs, address)
+}
+
+// RemoveOutput removes the output to the Factoid address from the tmp
+// transaction name.
+func (w *Wallet) RemoveOutput(name, address string) error {
+	return w.removeAddress(name, partOutputs, address)
+}
+
+// RemoveECOutput removes the output to the Entry Credit address from the tmp
+// transaction name.
+func (w *Wallet) RemoveECOutput(name, address string) error {
+	return w.removeAddress(name, partECOutputs, address)
+}
+
+// removeAddress removes address from part of the tmp transaction name. The
+// transaction is built again without it, and without the signatures that no
+// longer match.
+func (w *Wallet) removeAddress(name string, part txPart, address string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+	if part == partECOutputs && factom.AddressStringType(address) != factom.ECPub {
+		return validationErrorf("Invalid Entry Credit Address")
+	} else if part != partECOutputs && factom.AddressStringType(address) != factom.FactoidPub {
+		return validationErrorf("Invalid Factoid Address")
+	}
+	tx, err := w.GetTransaction(name)
+	if err != nil {
+		return err
+	}
+	adr := factoid.NewAddress(base58.Decode(address)[2:34])
+
+	found := false
+	r := new(factoid.Transaction)
+	r.SetTimestamp(tx.GetTimestamp())
+	rcds := tx.GetRCDs()
+	for i, in := range tx.GetInputs() {
+		if part == partInputs && in.GetAddress().IsSameAs(adr) {
+			found = true
+			continue
+		}
+		r.AddInput(in.GetAddress(), in.GetAmount())
+		if i < len(rcds) {
+			r.AddRCD(rcds[i])
+		}
+	}
+	for _, out := range tx.GetOutputs() {
+		if part == partOutputs && out.GetAddress().IsSameAs(adr) {
+			found = true
+			continue
+		}
+		r.AddOutput(out.GetAddress(), out.GetAmount())
+	}
+	for _, ec := range tx.GetECOutputs() {
+		if part == partECOutputs && ec.GetAddress().IsSameAs(adr) {
+			found = true
+			continue
+		}
+		r.AddECOutput(ec.GetAddress(), ec.GetAmount())
+	}
+	if !found {
+		return validationErrorf("wallet: %s is not in the transaction", address)
+	}
+	return w.saveTransaction(name, r)
+}
+
 func (w *Wallet) AddFee(name, address string, rate uint64) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
 	tx, err := w.GetTransaction(name)
 	if err != nil {
 		return err
 	}
+	if err := w.addFee(tx, address, rate); err != nil {
+		return err
+	}
+	return w.saveTransaction(name, tx)
+}
 
+func (w *Wallet) addFee(tx *factoid.Transaction, address string, rate uint64) error {
 	{
 		ins, err := tx.TotalInputs()
 		if err != nil {
@@ -168,7 +409,7 @@ func (w *Wallet) AddFee(name, address string, rate uint64) error {
 		}
 
 		if ins != outs+ecs {
-			return fmt.Errorf("Inputs and outputs don't add up")
+			return validationErrorf("Inputs and outputs don't add up")
 		}
 	}
 
@@ -181,6 +422,7 @@ func (w *Wallet) AddFee(name, address string, rate uint64) error {
 	if err != nil {
 		return err
 	}
+	a.Wipe()
 	adr := factoid.NewAddress(a.RCDHash())
 
 	for _, input := range tx.GetInputs() {
@@ -193,17 +435,20 @@ func (w *Wallet) AddFee(name, address string, rate uint64) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("%s is not an input to the transaction.", address)
+	return validationErrorf("%s is not an input to the transaction.", address)
 }
 
 func (w *Wallet) SubFee(name, address string, rate uint64) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
 	tx, err := w.GetTransaction(name)
 	if err != nil {
 		return err
 	}
 
 	if !factom.IsValidAddress(address) {
-		return errors.New("Invalid Address")
+		return validationErrorf("Invalid Address")
 	}
 
 	{
@@ -221,7 +466,7 @@ func (w *Wallet) SubFee(name, address string, rate uint64) error {
 		}
 
 		if ins != outs+ecs {
-			return fmt.Errorf("Inputs and outputs don't add up")
+			return validationErrorf("Inputs and outputs don't add up")
 		}
 	}
 
@@ -234,30 +479,96 @@ func (w *Wallet) SubFee(name, address string, rate uint64) error {
 
 	for _, output := range tx.GetOutputs() {
 		if output.GetAddress().IsSameAs(adr) {
+			if output.GetAmount() <= txfee {
+				return validationErrorf("wallet: The output to %s does not cover the fee", address)
+			}
 			output.SetAmount(output.GetAmount() - txfee)
-			return nil
+			return w.saveTransaction(name, tx)
 		}
 	}
-	return fmt.Errorf("%s is not an output to the transaction.", address)
+	return validationErrorf("%s is not an output to the transaction.", address)
+}
+
+// SubFeeAuto is SubFee with the output chosen by the wallet, whose address
+// it returns. The fee comes out of the change, the largest output back to a
+// Factoid address of the wallet, or if there is none out of the largest
+// output.
+func (w *Wallet) SubFeeAuto(name string, rate uint64) (string, error) {
+	if w.readOnly {
+		return "", ErrReadOnly
+	}
+	tx, err := w.GetTransaction(name)
+	if err != nil {
+		return "", err
+	}
+	fs, err := w.GetAllFCTAddresses()
+	if err != nil {
+		return "", err
+	}
+	own := make(map[string]bool)
+	for _, f := range fs {
+		own[f.String()] = true
+		f.Wipe()
+	}
+
+	var largest, change interfaces.IOutAddress
+	for _, out := range tx.GetOutputs() {
+		if largest == nil || out.GetAmount() > largest.GetAmount() {
+			largest = out
+		}
+		a := primitives.ConvertFctAddressToUserStr(out.GetAddress())
+		if own[a] && (change == nil || out.GetAmount() > change.GetAmount()) {
+			change = out
+		}
+	}
+	if change != nil {
+		largest = change
+	}
+	if largest == nil {
+		return "", validationErrorf("wallet: The transaction has no Factoid outputs")
+	}
+
+	address := primitives.ConvertFctAddressToUserStr(largest.GetAddress())
+	return address, w.SubFee(name, address, rate)
 }
 
 // SignTransaction signs a tmp transaction in the wallet with the appropriate
 // keys from the wallet db
 // force=true ignores the existing balance and fee overpayment checks.
+// Transactions above the approval threshold must be approved first.
 func (w *Wallet) SignTransaction(name string, force bool) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
 	tx, err := w.GetTransaction(name)
 	if err != nil {
 		return err
 	}
+	w.txlock.Lock()
+	pending := w.needsApproval(tx)
+	w.txlock.Unlock()
+	if pending {
+		return ErrApprovalRequired
+	}
+	if err := w.signTransaction(tx, force); err != nil {
+		return err
+	}
+	if err := w.saveTransaction(name, tx); err != nil {
+		return err
+	}
+	w.publish(&Event{Type: EventTransactionSigned, TxName: name, TxID: tx.GetSigHash().String()})
+	return nil
+}
 
+func (w *Wallet) signTransaction(tx *factoid.Transaction, force bool) error {
 	if force == false {
 		// check that the address balances are sufficient for the transaction
-		if err := checkCovered(tx); err != nil {
+		if err := checkCovered(tx, w.retry); err != nil {
 			return err
 		}
 
 		// check that the fee is being paid (and not overpaid)
-		if err := checkFee(tx); err != nil {
+		if err := checkFee(tx, w.retry); err != nil {
 			return err
 		}
 	}
@@ -271,18 +582,33 @@ func (w *Wallet) SignTransaction(name string, force bool) error {
 	if len(rcds) == 0 {
 		return ErrTXNoInputs
 	}
+
+	// An imported transaction may spend from addresses of other wallets.
+	// Their inputs are left for those wallets to sign.
+	var keyErr error
+	signed := 0
 	for i, rcd := range rcds {
+		if _, ok := rcd.(*factoid.RCD_1); !ok {
+			return ErrTXMultisig
+		}
 		a, err := rcd.GetAddress()
 		if err != nil {
 			return err
 		}
 
-		f, err := w.GetFCTAddress(primitives.ConvertFctAddressToUserStr(a))
+		sig, err := w.signatureBlock(primitives.ConvertFctAddressToUserStr(a), data)
+		if errors.Is(err, ErrNoSuchAddress) || errors.Is(err, ErrWatchOnly) {
+			keyErr = err
+			continue
+		}
 		if err != nil {
 			return err
 		}
-		sig := factoid.NewSingleSignatureBlock(f.SecBytes(), data)
 		tx.SetSignatureBlock(i, sig)
+		signed++
+	}
+	if signed == 0 {
+		return keyErr
 	}
 
 	return nil
@@ -300,6 +626,11 @@ func (w *Wallet) GetTransaction(name string) (*factoid.Transaction, error) {
 }
 
 func (w *Wallet) GetTransactions() map[string]*factoid.Transaction {
+	w.txlock.Lock()
+	defer w.txlock.Unlock()
+
+	w.loadTransactions()
+	w.expireTransactions(time.Now())
 	return w.transactions
 }
 
@@ -307,6 +638,9 @@ func (w *Wallet) TransactionExists(name string) bool {
 	w.txlock.Lock()
 	defer w.txlock.Unlock()
 
+	w.loadTransactions()
+	w.expireTransactions(time.Now())
+
 	if _, exists := w.transactions[name]; exists {
 		return true
 	}
@@ -314,11 +648,22 @@ func (w *Wallet) TransactionExists(name string) bool {
 }
 
 func (w *Wallet) ComposeTransaction(name string) (*factom.JSON2Request, error) {
+	if w.readOnly {
+		return nil, ErrReadOnly
+	}
 	tx, err := w.GetTransaction(name)
 	if err != nil {
 		return nil, err
 	}
+	req, err := composeTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+	w.publish(&Event{Type: EventTransactionComposed, TxName: name, TxID: tx.GetSigHash().String()})
+	return req, nil
+}
 
+func composeTransaction(tx *factoid.Transaction) (*factom.JSON2Request, error) {
 	type txreq struct {
 		Transaction string `json:"transaction"`
 	}
@@ -337,6 +682,9 @@ func (w *Wallet) ComposeTransaction(name string) (*factom.JSON2Request, error) {
 
 // Hexencoded transaction
 func (w *Wallet) ImportComposedTransaction(name string, hexEncoded string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
 	tx := new(factoid.Transaction)
 	data, err := hex.DecodeString(hexEncoded)
 	if err != nil {
@@ -348,21 +696,21 @@ func (w *Wallet) ImportComposedTransaction(name string, hexEncoded string) error
 		return err
 	}
 
-	w.txlock.Lock()
-	w.transactions[name] = tx
-	w.txlock.Unlock()
-
-	return nil
+	return w.saveTransaction(name, tx)
 }
 
-func checkCovered(tx *factoid.Transaction) error {
+func checkCovered(tx *factoid.Transaction, retry *RetryPolicy) error {
 	for _, in := range tx.GetInputs() {
-		balance, err := factom.GetFactoidBalance(in.GetUserAddress())
+		var balance int64
+		err := retry.Do(func() (err error) {
+			balance, err = factom.GetFactoidBalance(in.GetUserAddress())
+			return err
+		})
 		if err != nil {
 			return err
 		}
 		if uint64(balance) < in.GetAmount() {
-			return fmt.Errorf(
+			return validationErrorf(
 				"Address %s balance is too low. Available: %s Needed: %s",
 				in.GetUserAddress(),
 				factom.FactoshiToFactoid(uint64(balance)),
@@ -373,7 +721,7 @@ func checkCovered(tx *factoid.Transaction) error {
 	return nil
 }
 
-func checkFee(tx *factoid.Transaction) error {
+func checkFee(tx *factoid.Transaction, retry *RetryPolicy) error {
 	ins, err := tx.TotalInputs()
 	if err != nil {
 		return err
@@ -394,7 +742,7 @@ func checkFee(tx *factoid.Transaction) error {
 		return ErrFeeTooLow
 	}
 
-	rate, err := factom.GetRate()
+	rate, err := getRate(retry)
 	if err != nil {
 		return err
 	}
@@ -416,7 +764,7 @@ func checkFee(tx *factoid.Transaction) error {
 
 	// fee is too high (over 10x cfee)
 	if fee >= cfee*10 {
-		return fmt.Errorf(
+		return validationErrorf(
 			"wallet: Overpaying fee by >10x. Paying: %v Requires: %v",
 			factom.FactoshiToFactoid(uint64(fee)),
 			factom.FactoshiToFactoid(uint64(cfee)),
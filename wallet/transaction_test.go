@@ -5,10 +5,13 @@
 package wallet_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/FactomProject/factom"
 	. "github.com/FactomProject/factom/wallet"
+	"github.com/FactomProject/factomd/common/factoid"
+	"github.com/FactomProject/factomd/common/interfaces"
 	"github.com/FactomProject/factomd/common/primitives"
 )
 
@@ -88,6 +91,222 @@ func TestAddInput(t *testing.T) {
 	}
 }
 
+func TestAddOutputs(t *testing.T) {
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	a1 := "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"
+	a2 := "FA3cih2o2tjEUsnnFR4jX1tQXPpSXFwsp3rhVp6odL5PNCHWvZV1"
+	if err := w1.NewTransaction("payroll"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddOutputs("payroll", []*factom.TransAddress{
+		{Address: a1, Amount: 1e8},
+		{Address: a2, Amount: 2e8},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := w1.GetTransaction("payroll")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total, _ := tx.TotalOutputs(); len(tx.GetOutputs()) != 2 || total != 3e8 {
+		t.Errorf("got %d outputs of %d, want 2 of 3e8", len(tx.GetOutputs()), total)
+	}
+
+	// an invalid output leaves the transaction unchanged
+	for _, outs := range [][]*factom.TransAddress{
+		{{Address: a1, Amount: 5e8}, {Address: "not an address", Amount: 1}},
+		{{Address: a1, Amount: 5e8}, {Address: a1, Amount: 6e8}},
+		nil,
+	} {
+		if err := w1.AddOutputs("payroll", outs); err == nil {
+			t.Errorf("invalid outputs %v were accepted", outs)
+		}
+	}
+	tx, err = w1.GetTransaction("payroll")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total, _ := tx.TotalOutputs(); total != 3e8 {
+		t.Errorf("failed batch changed the outputs to %d", total)
+	}
+}
+
+func TestAmountChecks(t *testing.T) {
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	f1, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e1, err := w1.GenerateECAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a1 := "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"
+	a2 := "FA3cih2o2tjEUsnnFR4jX1tQXPpSXFwsp3rhVp6odL5PNCHWvZV1"
+	if err := w1.NewTransaction("tx"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w1.AddOutput("tx", a1, 0); err != ErrZeroOutput {
+		t.Errorf("expected ErrZeroOutput, got %v", err)
+	}
+	if err := w1.AddECOutput("tx", e1.PubString(), 0); err != ErrZeroOutput {
+		t.Errorf("expected ErrZeroOutput, got %v", err)
+	}
+	if err := w1.AddInput("tx", f1.String(), MaxAmount+1); err != ErrAmountTooLarge {
+		t.Errorf("expected ErrAmountTooLarge, got %v", err)
+	}
+
+	// the outputs together may not exceed the supply either
+	if err := w1.AddOutput("tx", a1, MaxAmount); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddECOutput("tx", e1.PubString(), 1); err != ErrAmountTooLarge {
+		t.Errorf("expected ErrAmountTooLarge, got %v", err)
+	}
+	if err := w1.AddOutputs("tx", []*factom.TransAddress{{Address: a2, Amount: MaxAmount}}); !errors.Is(err, ErrAmountTooLarge) {
+		t.Errorf("expected ErrAmountTooLarge, got %v", err)
+	}
+
+	// but an update replaces the old amount
+	if err := w1.AddOutput("tx", a1, 1e8); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddOutput("tx", a2, MaxAmount-1e8); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRemoveTransactionAddresses(t *testing.T) {
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	f1, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f2, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e1, err := w1.GenerateECAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"
+
+	if err := w1.NewTransaction("tx"); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{f1.String(), f2.String()} {
+		if err := w1.AddInput("tx", f, 1e8); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w1.AddOutput("tx", to, 1e8); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddECOutput("tx", e1.PubString(), 1e8); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.SignTransaction("tx", true); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w1.RemoveInput("tx", f1.String()); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.RemoveECOutput("tx", e1.PubString()); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := w1.GetTransaction("tx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.GetInputs()) != 1 || len(tx.GetRCDs()) != 1 || len(tx.GetECOutputs()) != 0 || len(tx.GetOutputs()) != 1 {
+		t.Errorf("wrong transaction after removal: %s", tx)
+	}
+	if len(tx.GetSignatureBlocks()) != 0 {
+		t.Error("signatures were kept")
+	}
+
+	// the remaining input can still be signed
+	if err := w1.SignTransaction("tx", true); err != nil {
+		t.Error(err)
+	}
+
+	if err := w1.RemoveOutput("tx", f1.String()); err == nil {
+		t.Error("removed an output that is not in the transaction")
+	}
+	if err := w1.RemoveOutput("tx", e1.PubString()); err == nil {
+		t.Error("removed a Factoid output by an Entry Credit address")
+	}
+}
+
+func TestSubFeeAuto(t *testing.T) {
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	f1, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	change, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a1 := "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"
+	a2 := "FA3cih2o2tjEUsnnFR4jX1tQXPpSXFwsp3rhVp6odL5PNCHWvZV1"
+
+	build := func(name string, outputs map[string]uint64) {
+		var total uint64
+		if err := w1.NewTransaction(name); err != nil {
+			t.Fatal(err)
+		}
+		for a, amount := range outputs {
+			if err := w1.AddOutput(name, a, amount); err != nil {
+				t.Fatal(err)
+			}
+			total += amount
+		}
+		if err := w1.AddInput(name, f1.String(), total); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the change pays the fee even though another output is larger
+	build("with-change", map[string]uint64{a1: 5e8, change.String(): 1e8})
+	if a, err := w1.SubFeeAuto("with-change", 1000); err != nil {
+		t.Fatal(err)
+	} else if a != change.String() {
+		t.Errorf("fee taken from %s, want the change", a)
+	}
+
+	// otherwise the largest output does
+	build("no-change", map[string]uint64{a1: 5e8, a2: 1e8})
+	if a, err := w1.SubFeeAuto("no-change", 1000); err != nil {
+		t.Fatal(err)
+	} else if a != a1 {
+		t.Errorf("fee taken from %s, want the largest output %s", a, a1)
+	}
+}
+
 func TestComposeTrasnaction(t *testing.T) {
 	f1Sec := "Fs3E9gV6DXsYzf7Fqx1fVBQPQXV695eP3k5XbmHEZVRLkMdD9qCK"
 	//	f1Sec := "Fs1KWJrpLdfucvmYwN2nWrwepLn8ercpMbzXshd1g8zyhKXLVLWj"
@@ -243,3 +462,49 @@ func TestImportComposedTransaction(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestMultisigInput(t *testing.T) {
+	w1, err := NewMapDBWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	f1, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f2, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a 2 of 2 input of keys held by the wallet
+	rcd := &factoid.RCD_2{M: 2, N: 2, N_Addresses: []interfaces.IAddress{
+		factoid.NewAddress(f1.RCDHash()),
+		factoid.NewAddress(f2.RCDHash()),
+	}}
+	adr, err := rcd.GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.NewTransaction("multisig"); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := w1.GetTransaction("multisig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.AddInput(adr, 1e8)
+	tx.AddRCD(rcd)
+	if err := w1.AddOutput("multisig", f1.String(), 1e8); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w1.SignTransaction("multisig", true); err != ErrTXMultisig {
+		t.Errorf("expected ErrTXMultisig from SignTransaction, got %v", err)
+	}
+	if _, err := w1.ExportTransaction("multisig"); err != ErrTXMultisig {
+		t.Errorf("expected ErrTXMultisig from ExportTransaction, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factomd/common/factoid"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+// AutoFee can be passed to TxBuilder.Fee to pay the fee at the current entry
+// credit rate reported by factomd.
+const AutoFee uint64 = 0
+
+// TxBuilder builds a Factoid transaction from the addresses in a Wallet
+// without storing it under a name in the wallet. Calls can be chained and the
+// first error encountered is returned by Sign or Compose.
+//
+//	tx, err := w.BuildTx().From(fa1, 1e8).To(fa2, 1e8).Fee(AutoFee).Sign()
+type TxBuilder struct {
+	w   *Wallet
+	tx  *factoid.Transaction
+	err error
+
+	fee     bool
+	feeRate uint64
+	feeFrom string
+	force   bool
+}
+
+// BuildTx starts a new transaction builder for the wallet.
+func (w *Wallet) BuildTx() *TxBuilder {
+	b := new(TxBuilder)
+	b.w = w
+	b.tx = new(factoid.Transaction)
+	b.tx.SetTimestamp(primitives.NewTimestampNow())
+	return b
+}
+
+// From adds a Factoid input of amount factoshis from a Factoid address in the
+// wallet. The first input pays the transaction fee unless FeeFrom is used.
+func (b *TxBuilder) From(address string, amount uint64) *TxBuilder {
+	if b.err != nil {
+		return b
+	}
+	if b.feeFrom == "" {
+		b.feeFrom = address
+	}
+	b.err = b.w.addInput(b.tx, address, amount)
+	return b
+}
+
+// To adds a Factoid output of amount factoshis.
+func (b *TxBuilder) To(address string, amount uint64) *TxBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.err = addOutput(b.tx, address, amount)
+	return b
+}
+
+// ECTo adds an Entry Credit output worth amount factoshis.
+func (b *TxBuilder) ECTo(address string, amount uint64) *TxBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.err = addECOutput(b.tx, address, amount)
+	return b
+}
+
+// Fee adds the transaction fee at rate factoshis per entry credit to the fee
+// paying input. Use AutoFee to pay at the current rate.
+func (b *TxBuilder) Fee(rate uint64) *TxBuilder {
+	b.fee = true
+	b.feeRate = rate
+	return b
+}
+
+// FeeFrom sets the input address that pays the fee.
+func (b *TxBuilder) FeeFrom(address string) *TxBuilder {
+	b.feeFrom = address
+	return b
+}
+
+// Force skips the balance and fee checks when the transaction is signed.
+func (b *TxBuilder) Force() *TxBuilder {
+	b.force = true
+	return b
+}
+
+// Sign completes the transaction and signs it with the keys from the wallet.
+// Sign refuses a transaction above the approval threshold with
+// ErrApprovalRequired; such payments are made with a tmp transaction, which
+// can be approved by name.
+func (b *TxBuilder) Sign() (*factoid.Transaction, error) {
+	if b.w.readOnly {
+		return nil, ErrReadOnly
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	if b.fee {
+		rate := b.feeRate
+		if rate == AutoFee {
+			r, err := getRate(b.w.retry)
+			if err != nil {
+				return nil, err
+			}
+			rate = r
+		}
+		if err := b.w.addFee(b.tx, b.feeFrom, rate); err != nil {
+			return nil, err
+		}
+	}
+
+	b.w.txlock.Lock()
+	pending := b.w.needsApproval(b.tx)
+	b.w.txlock.Unlock()
+	if pending {
+		return nil, ErrApprovalRequired
+	}
+	if err := b.w.signTransaction(b.tx, b.force); err != nil {
+		return nil, err
+	}
+	b.w.txlock.Lock()
+	b.w.forgetApproval(b.tx)
+	b.w.txlock.Unlock()
+	return b.tx, nil
+}
+
+// Compose signs the transaction and returns the factoid-submit request for
+// it.
+func (b *TxBuilder) Compose() (*factom.JSON2Request, error) {
+	tx, err := b.Sign()
+	if err != nil {
+		return nil, err
+	}
+	return composeTransaction(tx)
+}
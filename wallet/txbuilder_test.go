@@ -0,0 +1,69 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"testing"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestBuildTx(t *testing.T) {
+	zSec := "Fs1KWJrpLdfucvmYwN2nWrwepLn8ercpMbzXshd1g8zyhKXLVLWj"
+	fa2 := "FA3T1gTkuKGG2MWpAkskSoTnfjxZDKVaAYwziNTC1pAYH5B9A1rh"
+	ec1 := "EC1m9mouvUQeEidmqpUYpYtXg8fvTYi6GNHaKg8KMLbdMBrFfmUa"
+
+	// create a new database
+	w1, err := NewMapDBWallet()
+	if err != nil {
+		t.Error(err)
+	}
+	defer w1.Close()
+
+	f, err := factom.GetFactoidAddress(zSec)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if err := w1.InsertFCTAddress(f); err != nil {
+		t.Error(err)
+	}
+
+	tx, err := w1.BuildTx().
+		From(f.String(), 15).
+		To(fa2, 10).
+		ECTo(ec1, 5).
+		Force().
+		Sign()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.GetInputs()) != 1 || len(tx.GetOutputs()) != 1 || len(tx.GetECOutputs()) != 1 {
+		t.Errorf("wrong transaction contents %v", tx)
+	}
+	if err := tx.ValidateSignatures(); err != nil {
+		t.Error(err)
+	}
+
+	// the builder should not store the transaction in the wallet
+	if len(w1.GetTransactions()) != 0 {
+		t.Errorf("wrong number of transactions %v", w1.GetTransactions())
+	}
+
+	// errors are returned from Sign
+	if _, err := w1.BuildTx().From(f.String(), 10).To(ec1, 10).Sign(); err == nil {
+		t.Errorf("expected an error for an ec address as a factoid output")
+	}
+
+	// payments above the approval threshold are refused like tmp transactions
+	w1.SetApprovalThreshold(1e8)
+	if _, err := w1.BuildTx().From(f.String(), 5e8).To(fa2, 5e8).Force().Sign(); err != ErrApprovalRequired {
+		t.Errorf("expected ErrApprovalRequired, got %v", err)
+	}
+	if _, err := w1.BuildTx().From(f.String(), 5e8).To(fa2, 5e8).Force().Compose(); err != ErrApprovalRequired {
+		t.Errorf("expected ErrApprovalRequired from Compose, got %v", err)
+	}
+}
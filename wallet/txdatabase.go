@@ -29,6 +29,8 @@ type TXDatabaseOverlay struct {
 
 	// To indicate to sub processes to quit
 	quit bool
+
+	retry *RetryPolicy
 }
 
 func NewTXOverlay(db interfaces.IDatabase) *TXDatabaseOverlay {
@@ -88,6 +90,12 @@ func (db *TXDatabaseOverlay) Close() error {
 	return db.DBO.Close()
 }
 
+// Quit tells any running update of the transaction cache to save what it has
+// fetched so far and return.
+func (db *TXDatabaseOverlay) Quit() {
+	db.quit = true
+}
+
 // GetAllTXs returns a list of all transactions in the history of Factom. A
 // local database is used to cache the factoid blocks.
 func (db *TXDatabaseOverlay) GetAllTXs() ([]interfaces.ITransaction, error) {
@@ -98,7 +106,7 @@ func (db *TXDatabaseOverlay) GetAllTXs() ([]interfaces.ITransaction, error) {
 	}
 	fblock, err := db.DBO.FetchFBlockHead()
 	if err != nil {
-		return nil, err
+		return nil, dbError("read", err)
 	}
 	if fblock == nil {
 		return nil, fmt.Errorf("FBlock Chain has not finished syncing")
@@ -189,7 +197,7 @@ func (db *TXDatabaseOverlay) GetTXAddress(adr string) (
 			}
 		}
 	} else {
-		return nil, fmt.Errorf("not a valid address")
+		return nil, validationErrorf("not a valid address")
 	}
 
 	return filtered, nil
@@ -198,7 +206,7 @@ func (db *TXDatabaseOverlay) GetTXAddress(adr string) (
 func (db *TXDatabaseOverlay) GetTXRange(start, end int) (
 	[]interfaces.ITransaction, error) {
 	if start < 0 || end < 0 || end < start {
-		return nil, fmt.Errorf("Range cannot have negative numbers")
+		return nil, validationErrorf("Range cannot have negative numbers")
 	}
 
 	// update the database and get the newest fblock
@@ -208,7 +216,7 @@ func (db *TXDatabaseOverlay) GetTXRange(start, end int) (
 	}
 	fblock, err := db.DBO.FetchFBlockHead()
 	if err != nil {
-		return nil, err
+		return nil, dbError("read", err)
 	}
 	if fblock == nil {
 		return nil, fmt.Errorf("FBlock Chain has not finished syncing")
@@ -265,7 +273,7 @@ func (db *TXDatabaseOverlay) GetFBlock(keymr string) (interfaces.IFBlock, error)
 
 	fBlock, err := db.DBO.FetchFBlock(h)
 	if err != nil {
-		return nil, err
+		return nil, dbError("read", err)
 	}
 	return fBlock, nil
 }
@@ -273,7 +281,7 @@ func (db *TXDatabaseOverlay) GetFBlock(keymr string) (interfaces.IFBlock, error)
 func (db *TXDatabaseOverlay) FetchNextFBlockHeight() (uint32, error) {
 	block, err := db.DBO.FetchFBlockHead()
 	if err != nil {
-		return 0, err
+		return 0, dbError("read", err)
 	}
 	if block == nil {
 		return 0, nil
@@ -282,13 +290,17 @@ func (db *TXDatabaseOverlay) FetchNextFBlockHeight() (uint32, error) {
 }
 
 func (db *TXDatabaseOverlay) InsertFBlockHead(fblock interfaces.IFBlock) error {
-	return db.DBO.SaveFactoidBlockHead(fblock)
+	return dbError("write", db.DBO.SaveFactoidBlockHead(fblock))
 }
 
 // update gets all fblocks written since the database was last updated, and
 // returns the most recent fblock keymr.
 func (db *TXDatabaseOverlay) update() (string, error) {
-	newestFBlock, err := fblockHead()
+	var newestFBlock interfaces.IFBlock
+	err := db.retry.Do(func() (err error) {
+		newestFBlock, err = fblockHead()
+		return err
+	})
 	if err != nil {
 		return "", err
 	}
@@ -301,10 +313,14 @@ func (db *TXDatabaseOverlay) update() (string, error) {
 	// Make sure we didn't switch networks
 	genesis, err := db.DBO.FetchFBlockByHeight(0)
 	if err != nil {
-		return "", err
+		return "", dbError("read", err)
 	}
 	if genesis != nil {
-		genesis2, err := getdblockbyheight(0)
+		var genesis2 interfaces.IDirectoryBlock
+		err := db.retry.Do(func() (err error) {
+			genesis2, err = getdblockbyheight(0)
+			return err
+		})
 		if err != nil {
 			return "", err
 		}
@@ -341,7 +357,11 @@ func (db *TXDatabaseOverlay) update() (string, error) {
 				fmt.Printf("Fetching block %v/%v\n", i, newestHeight)
 			}
 		}
-		fblock, err := getfblockbyheight(i)
+		var fblock interfaces.IFBlock
+		err := db.retry.Do(func() (err error) {
+			fblock, err = getfblockbyheight(i)
+			return err
+		})
 		if err != nil {
 			db.DBO.ExecuteMultiBatch()
 			return "", err
@@ -366,7 +386,7 @@ func (db *TXDatabaseOverlay) update() (string, error) {
 
 	// Save the remaining blocks
 	if err = db.DBO.ExecuteMultiBatch(); err != nil {
-		return "", err
+		return "", dbError("write", err)
 	}
 
 	return newestFBlock.GetKeyMR().String(), nil
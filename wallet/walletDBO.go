@@ -27,6 +27,18 @@ var (
 	ecDBPrefix       = []byte("Entry Credits")
 	seedDBKey        = []byte("DB Seed")
 	identityDBPrefix = []byte("Identities")
+	bookmarkDBPrefix = []byte("Bookmarks")
+	scheduleDBPrefix = []byte("Scheduled Transactions")
+	nsDBPrefix       = []byte("Namespaces")
+	labelDBPrefix    = []byte("Address Labels")
+	contactDBPrefix  = []byte("Contacts")
+	watchDBPrefix    = []byte("Watch Only")
+	signerDBPrefix   = []byte("Signer Keys")
+	auditDBPrefix    = []byte("Audit Log")
+	tmpTxDBPrefix    = []byte("Tmp Transactions")
+	historyDBPrefix  = []byte("Address History")
+	historyPosDBKey  = []byte("Address History Cursor")
+	noteDBPrefix     = []byte("Transaction Notes")
 )
 
 type WalletDatabaseOverlay struct {
@@ -54,7 +66,7 @@ func NewLevelDB(ldbpath string) (*WalletDatabaseOverlay, error) {
 		db, err = hybridDB.NewLevelMapHybridDB(ldbpath, true)
 
 		if err != nil {
-			return nil, err
+			return nil, dbError("open", err)
 		}
 	}
 	fmt.Println("Database started from: " + ldbpath)
@@ -79,7 +91,7 @@ func NewBoltDB(boltPath string) (*WalletDatabaseOverlay, error) {
 
 	if err != nil && !os.IsNotExist(err) { //some other error, besides the file not existing
 		fmt.Printf("database error %s\n", err)
-		return nil, err
+		return nil, dbError("open", err)
 	}
 
 	defer func() {
@@ -124,7 +136,7 @@ func CreateEncryptedBoltDB(boltPath string) error {
 
 	if err != nil && !os.IsNotExist(err) { //some other error, besides the file not existing
 		fmt.Printf("database error %s\n", err)
-		return err
+		return dbError("open", err)
 	}
 	return nil
 }
@@ -138,18 +150,25 @@ func OpenEncryptedBoltDB(boltPath, password string) (*WalletDatabaseOverlay, err
 	}()
 	db, err := securedb.NewEncryptedDB(boltPath, "Bolt", password)
 	if err != nil {
-		return nil, err
+		return nil, dbError("open", err)
 	}
 
 	fmt.Println("Encrypted Database started from: " + boltPath)
 	return NewWalletOverlay(db), nil
 }
 
+// DBSeedBase holds the seed of a wallet. MnemonicSeed is a string, so it is
+// not wiped from memory; see factom.SecureBytes.
 type DBSeedBase struct {
 	MnemonicSeed            string
 	NextFactoidAddressIndex uint32
 	NextECAddressIndex      uint32
 	NextIdentityKeyIndex    uint32
+
+	// FCTPath and ECPath are the derivation path templates of the addresses
+	// or "" for the BIP44 defaults.
+	FCTPath string
+	ECPath  string
 }
 
 type DBSeed struct {
@@ -204,12 +223,7 @@ func (e *DBSeed) String() string {
 }
 
 func (e *DBSeed) NextFCTAddress() (*factom.FactoidAddress, error) {
-	add, err := factom.MakeBIP44FactoidAddress(
-		e.MnemonicSeed,
-		bip32.FirstHardenedChild,
-		0,
-		e.NextFactoidAddressIndex,
-	)
+	add, err := e.FCTAddress(e.NextFactoidAddressIndex)
 	if err != nil {
 		return nil, err
 	}
@@ -218,12 +232,7 @@ func (e *DBSeed) NextFCTAddress() (*factom.FactoidAddress, error) {
 }
 
 func (e *DBSeed) NextECAddress() (*factom.ECAddress, error) {
-	add, err := factom.MakeBIP44ECAddress(
-		e.MnemonicSeed,
-		bip32.FirstHardenedChild,
-		0,
-		e.NextECAddressIndex,
-	)
+	add, err := e.ECAddress(e.NextECAddressIndex)
 	if err != nil {
 		return nil, err
 	}
@@ -231,13 +240,34 @@ func (e *DBSeed) NextECAddress() (*factom.ECAddress, error) {
 	return add, nil
 }
 
+// FCTAddress derives the Factoid Address with index i on the FCTPath of the
+// seed.
+func (e *DBSeed) FCTAddress(i uint32) (*factom.FactoidAddress, error) {
+	if e.FCTPath == "" {
+		return factom.MakeBIP44FactoidAddress(e.MnemonicSeed, bip32.FirstHardenedChild, 0, i)
+	}
+	path, err := childPath(e.FCTPath, i)
+	if err != nil {
+		return nil, err
+	}
+	return factom.MakeFactoidAddressFromPath(e.MnemonicSeed, path)
+}
+
+// ECAddress derives the Entry Credit Address with index i on the ECPath of
+// the seed.
+func (e *DBSeed) ECAddress(i uint32) (*factom.ECAddress, error) {
+	if e.ECPath == "" {
+		return factom.MakeBIP44ECAddress(e.MnemonicSeed, bip32.FirstHardenedChild, 0, i)
+	}
+	path, err := childPath(e.ECPath, i)
+	if err != nil {
+		return nil, err
+	}
+	return factom.MakeECAddressFromPath(e.MnemonicSeed, path)
+}
+
 func (e *DBSeed) NextIdentityKey() (*factom.IdentityKey, error) {
-	add, err := factom.MakeBIP44IdentityKey(
-		e.MnemonicSeed,
-		bip32.FirstHardenedChild,
-		0,
-		e.NextIdentityKeyIndex,
-	)
+	add, err := e.IdentityKey(e.NextIdentityKeyIndex)
 	if err != nil {
 		return nil, err
 	}
@@ -245,6 +275,11 @@ func (e *DBSeed) NextIdentityKey() (*factom.IdentityKey, error) {
 	return add, nil
 }
 
+// IdentityKey derives the identity key with index i from the seed.
+func (e *DBSeed) IdentityKey(i uint32) (*factom.IdentityKey, error) {
+	return factom.MakeBIP44IdentityKey(e.MnemonicSeed, bip32.FirstHardenedChild, 0, i)
+}
+
 func NewRandomSeed() (*DBSeed, error) {
 	seed := make([]byte, 16)
 	if n, err := rand.Read(seed); err != nil {
@@ -266,6 +301,16 @@ func NewRandomSeed() (*DBSeed, error) {
 	return dbSeed, nil
 }
 
+// NewSeedMnemonic returns a new random 24 word bip-0039 mnemonic that can be
+// used as a wallet seed with ImportWalletFromMnemonic or Restore.
+func NewSeedMnemonic() (string, error) {
+	seed := make([]byte, 32)
+	if _, err := rand.Read(seed); err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(seed)
+}
+
 func (db *WalletDatabaseOverlay) InsertDBSeed(seed *DBSeed) error {
 	if seed == nil {
 		return nil
@@ -274,13 +319,13 @@ func (db *WalletDatabaseOverlay) InsertDBSeed(seed *DBSeed) error {
 	batch := []interfaces.Record{}
 	batch = append(batch, interfaces.Record{seedDBKey, seedDBKey, seed})
 
-	return db.DBO.PutInBatch(batch)
+	return dbError("write", db.DBO.PutInBatch(batch))
 }
 
 func (db *WalletDatabaseOverlay) GetDBSeed() (*DBSeed, error) {
 	data, err := db.DBO.Get(seedDBKey, seedDBKey, new(DBSeed))
 	if err != nil {
-		return nil, err
+		return nil, dbError("read", err)
 	}
 	if data == nil {
 		return nil, nil
@@ -291,7 +336,7 @@ func (db *WalletDatabaseOverlay) GetDBSeed() (*DBSeed, error) {
 func (db *WalletDatabaseOverlay) GetOrCreateDBSeed() (*DBSeed, error) {
 	data, err := db.DBO.Get(seedDBKey, seedDBKey, new(DBSeed))
 	if err != nil {
-		return nil, err
+		return nil, dbError("read", err)
 	}
 	if data == nil {
 		seed, err := NewRandomSeed()
@@ -347,6 +392,54 @@ func (db *WalletDatabaseOverlay) GetNextFCTAddress() (*factom.FactoidAddress, er
 	return add, nil
 }
 
+// GetNextFCTAddresses derives the next n Factoid Addresses from the seed
+// and stores them with the seed in a single batch.
+func (db *WalletDatabaseOverlay) GetNextFCTAddresses(n int) ([]*factom.FactoidAddress, error) {
+	seed, err := db.GetOrCreateDBSeed()
+	if err != nil {
+		return nil, err
+	}
+	adds := make([]*factom.FactoidAddress, 0, n)
+	batch := make([]interfaces.Record, 0, n+1)
+	for i := 0; i < n; i++ {
+		add, err := seed.NextFCTAddress()
+		if err != nil {
+			return nil, err
+		}
+		adds = append(adds, add)
+		batch = append(batch, interfaces.Record{fcDBPrefix, []byte(add.String()), add})
+	}
+	batch = append(batch, interfaces.Record{seedDBKey, seedDBKey, seed})
+	if err := db.DBO.PutInBatch(batch); err != nil {
+		return nil, dbError("write", err)
+	}
+	return adds, nil
+}
+
+// GetNextECAddresses derives the next n Entry Credit Addresses from the seed
+// and stores them with the seed in a single batch.
+func (db *WalletDatabaseOverlay) GetNextECAddresses(n int) ([]*factom.ECAddress, error) {
+	seed, err := db.GetOrCreateDBSeed()
+	if err != nil {
+		return nil, err
+	}
+	adds := make([]*factom.ECAddress, 0, n)
+	batch := make([]interfaces.Record, 0, n+1)
+	for i := 0; i < n; i++ {
+		add, err := seed.NextECAddress()
+		if err != nil {
+			return nil, err
+		}
+		adds = append(adds, add)
+		batch = append(batch, interfaces.Record{ecDBPrefix, []byte(add.PubString()), add})
+	}
+	batch = append(batch, interfaces.Record{seedDBKey, seedDBKey, seed})
+	if err := db.DBO.PutInBatch(batch); err != nil {
+		return nil, dbError("write", err)
+	}
+	return adds, nil
+}
+
 func (db *WalletDatabaseOverlay) InsertECAddress(e *factom.ECAddress) error {
 	if e == nil {
 		return nil
@@ -355,13 +448,13 @@ func (db *WalletDatabaseOverlay) InsertECAddress(e *factom.ECAddress) error {
 	batch := []interfaces.Record{}
 	batch = append(batch, interfaces.Record{ecDBPrefix, []byte(e.PubString()), e})
 
-	return db.DBO.PutInBatch(batch)
+	return dbError("write", db.DBO.PutInBatch(batch))
 }
 
 func (db *WalletDatabaseOverlay) GetECAddress(pubString string) (*factom.ECAddress, error) {
 	data, err := db.DBO.Get(ecDBPrefix, []byte(pubString), new(factom.ECAddress))
 	if err != nil {
-		return nil, err
+		return nil, dbError("read", err)
 	}
 	if data == nil {
 		return nil, ErrNoSuchAddress
@@ -372,7 +465,7 @@ func (db *WalletDatabaseOverlay) GetECAddress(pubString string) (*factom.ECAddre
 func (db *WalletDatabaseOverlay) GetAllECAddresses() ([]*factom.ECAddress, error) {
 	list, err := db.DBO.FetchAllBlocksFromBucket(ecDBPrefix, new(ECA))
 	if err != nil {
-		return nil, err
+		return nil, dbError("read", err)
 	}
 	return toECList(list), nil
 }
@@ -407,13 +500,13 @@ func (db *WalletDatabaseOverlay) InsertFCTAddress(e *factom.FactoidAddress) erro
 	batch := []interfaces.Record{}
 	batch = append(batch, interfaces.Record{fcDBPrefix, []byte(e.String()), e})
 
-	return db.DBO.PutInBatch(batch)
+	return dbError("write", db.DBO.PutInBatch(batch))
 }
 
 func (db *WalletDatabaseOverlay) GetFCTAddress(str string) (*factom.FactoidAddress, error) {
 	data, err := db.DBO.Get(fcDBPrefix, []byte(str), new(factom.FactoidAddress))
 	if err != nil {
-		return nil, err
+		return nil, dbError("read", err)
 	}
 	if data == nil {
 		return nil, ErrNoSuchAddress
@@ -424,7 +517,7 @@ func (db *WalletDatabaseOverlay) GetFCTAddress(str string) (*factom.FactoidAddre
 func (db *WalletDatabaseOverlay) GetAllFCTAddresses() ([]*factom.FactoidAddress, error) {
 	list, err := db.DBO.FetchAllBlocksFromBucket(fcDBPrefix, new(FA))
 	if err != nil {
-		return nil, err
+		return nil, dbError("read", err)
 	}
 	return toFList(list), nil
 }
@@ -445,7 +538,7 @@ func (db *WalletDatabaseOverlay) RemoveAddress(pubString string) error {
 	if pubString[:1] == "F" {
 		data, err := db.DBO.Get(fcDBPrefix, []byte(pubString), new(factom.FactoidAddress))
 		if err != nil {
-			return err
+			return dbError("read", err)
 		}
 		if data == nil {
 			return ErrNoSuchAddress
@@ -453,14 +546,14 @@ func (db *WalletDatabaseOverlay) RemoveAddress(pubString string) error {
 		err = db.DBO.Delete(fcDBPrefix, []byte(pubString))
 		if err == nil {
 			err := db.DBO.Delete(fcDBPrefix, []byte(pubString)) //delete twice to flush the db file
-			return err
+			return dbError("delete", err)
 		} else {
-			return err
+			return dbError("delete", err)
 		}
 	} else if pubString[:1] == "E" {
 		data, err := db.DBO.Get(ecDBPrefix, []byte(pubString), new(factom.ECAddress))
 		if err != nil {
-			return err
+			return dbError("read", err)
 		}
 		if data == nil {
 			return ErrNoSuchAddress
@@ -468,12 +561,12 @@ func (db *WalletDatabaseOverlay) RemoveAddress(pubString string) error {
 		err = db.DBO.Delete(ecDBPrefix, []byte(pubString))
 		if err == nil {
 			err := db.DBO.Delete(ecDBPrefix, []byte(pubString)) //delete twice to flush the db file
-			return err
+			return dbError("delete", err)
 		} else {
-			return err
+			return dbError("delete", err)
 		}
 	} else {
-		return fmt.Errorf("Unknown address type")
+		return validationErrorf("Unknown address type")
 	}
 
 	return nil
@@ -544,13 +637,13 @@ func (db *WalletDatabaseOverlay) InsertIdentityKey(e *factom.IdentityKey) error
 	batch := []interfaces.Record{}
 	batch = append(batch, interfaces.Record{identityDBPrefix, []byte(e.String()), e})
 
-	return db.DBO.PutInBatch(batch)
+	return dbError("write", db.DBO.PutInBatch(batch))
 }
 
 func (db *WalletDatabaseOverlay) GetIdentityKey(str string) (*factom.IdentityKey, error) {
 	data, err := db.DBO.Get(identityDBPrefix, []byte(str), new(factom.IdentityKey))
 	if err != nil {
-		return nil, err
+		return nil, dbError("read", err)
 	}
 	if data == nil {
 		return nil, ErrNoSuchIdentityKey
@@ -561,7 +654,7 @@ func (db *WalletDatabaseOverlay) GetIdentityKey(str string) (*factom.IdentityKey
 func (db *WalletDatabaseOverlay) GetAllIdentityKeys() ([]*factom.IdentityKey, error) {
 	list, err := db.DBO.FetchAllBlocksFromBucket(identityDBPrefix, new(ID))
 	if err != nil {
-		return nil, err
+		return nil, dbError("read", err)
 	}
 	return toIdentityKeyList(list), nil
 }
@@ -582,7 +675,7 @@ func (db *WalletDatabaseOverlay) RemoveIdentityKey(pubString string) error {
 
 	data, err := db.DBO.Get(identityDBPrefix, []byte(pubString), new(factom.IdentityKey))
 	if err != nil {
-		return err
+		return dbError("read", err)
 	}
 	if data == nil {
 		return ErrNoSuchIdentityKey
@@ -590,9 +683,9 @@ func (db *WalletDatabaseOverlay) RemoveIdentityKey(pubString string) error {
 	err = db.DBO.Delete(identityDBPrefix, []byte(pubString))
 	if err == nil {
 		err := db.DBO.Delete(identityDBPrefix, []byte(pubString)) //delete twice to flush the db file
-		return err
+		return dbError("delete", err)
 	} else {
-		return err
+		return dbError("delete", err)
 	}
 
 	return nil
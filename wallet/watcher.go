@@ -0,0 +1,263 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factomd/common/interfaces"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+// DefaultWatchInterval is the time between polls of a Watcher that has no
+// Interval set. It is about the time between Factom blocks.
+const DefaultWatchInterval = time.Minute
+
+// Deposit is a payment to a watched address found in a Factoid Block. Amount
+// is in factoshis, also for payments to Entry Credit addresses.
+type Deposit struct {
+	TxID    string `json:"txid"`
+	Address string `json:"address"`
+	Amount  uint64 `json:"amount"`
+	Height  uint32 `json:"height"`
+}
+
+// Watcher polls factomd for new Factoid Blocks and reports the payments they
+// contain to the addresses in a wallet and to any watch only addresses added
+// with Watch.
+type Watcher struct {
+	// Interval is the time between polls once the Watcher is started.
+	Interval time.Duration
+
+	// Webhook is a url that every Deposit is POSTed to as json if it is set.
+	Webhook string
+
+	wallet *Wallet
+	client *http.Client
+
+	lock    sync.Mutex
+	watched map[string]bool
+	next    uint32
+
+	events chan *Deposit
+	quit   chan struct{}
+	done   chan struct{}
+}
+
+// NewWatcher returns a Watcher for the addresses in the wallet that starts
+// looking for payments at the block height start. Use the current height to
+// only be told about new payments.
+func (w *Wallet) NewWatcher(start uint32) *Watcher {
+	return &Watcher{
+		Interval: DefaultWatchInterval,
+		wallet:   w,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		watched:  make(map[string]bool),
+		next:     start,
+		events:   make(chan *Deposit, 100),
+	}
+}
+
+// Watch adds Factoid or Entry Credit public addresses that are not in the
+// wallet to the addresses being watched.
+func (wt *Watcher) Watch(addresses ...string) error {
+	for _, a := range addresses {
+		switch factom.AddressStringType(a) {
+		case factom.FactoidPub, factom.ECPub:
+		default:
+			return validationErrorf("wallet: %s is not a public address", a)
+		}
+	}
+
+	wt.lock.Lock()
+	defer wt.lock.Unlock()
+	for _, a := range addresses {
+		wt.watched[a] = true
+	}
+	return nil
+}
+
+// Unwatch stops watching an address added with Watch.
+func (wt *Watcher) Unwatch(address string) {
+	wt.lock.Lock()
+	defer wt.lock.Unlock()
+	delete(wt.watched, address)
+}
+
+// NextHeight returns the height of the next block the Watcher will look at.
+func (wt *Watcher) NextHeight() uint32 {
+	wt.lock.Lock()
+	defer wt.lock.Unlock()
+	return wt.next
+}
+
+// Events returns the channel Deposits are sent on while the Watcher is
+// running. It is closed by Stop.
+func (wt *Watcher) Events() <-chan *Deposit {
+	return wt.events
+}
+
+// Poll looks at every Factoid Block from the next height up to the current
+// block height and returns the payments to watched addresses. If a block can
+// not be fetched the Deposits found before it are returned with the error and
+// the next Poll starts again from that block.
+func (wt *Watcher) Poll() ([]*Deposit, error) {
+	wt.lock.Lock()
+	defer wt.lock.Unlock()
+
+	addrs, err := wt.addresses()
+	if err != nil {
+		return nil, err
+	}
+
+	var heights *factom.HeightsResponse
+	err = wt.wallet.retry.Do(func() (err error) {
+		heights, err = factom.GetHeights()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var deposits []*Deposit
+	for ; int64(wt.next) <= heights.DirectoryBlockHeight; wt.next++ {
+		var fblock interfaces.IFBlock
+		err := wt.wallet.retry.Do(func() (err error) {
+			fblock, err = getfblockbyheight(wt.next)
+			return err
+		})
+		if err != nil {
+			return deposits, err
+		}
+		found := findDeposits(fblock, wt.next, addrs)
+		for _, d := range found {
+			wt.wallet.publish(&Event{
+				Type:    EventBalanceChanged,
+				Address: d.Address,
+				TxID:    d.TxID,
+				Amount:  d.Amount,
+			})
+		}
+		deposits = append(deposits, found...)
+	}
+	return deposits, nil
+}
+
+// addresses returns the public addresses to watch: everything in the wallet
+// plus the watch only addresses.
+func (wt *Watcher) addresses() (map[string]bool, error) {
+	fcs, ecs, err := wt.wallet.GetAllAddresses()
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make(map[string]bool, len(fcs)+len(ecs)+len(wt.watched))
+	for _, f := range fcs {
+		addrs[f.String()] = true
+	}
+	for _, e := range ecs {
+		addrs[e.String()] = true
+	}
+	for a := range wt.watched {
+		addrs[a] = true
+	}
+	return addrs, nil
+}
+
+func findDeposits(fblock interfaces.IFBlock, height uint32, addrs map[string]bool) []*Deposit {
+	var deposits []*Deposit
+	for _, tx := range fblock.GetTransactions() {
+		txid := tx.GetSigHash().String()
+		for _, out := range tx.GetOutputs() {
+			a := primitives.ConvertFctAddressToUserStr(out.GetAddress())
+			if addrs[a] {
+				deposits = append(deposits, &Deposit{TxID: txid, Address: a, Amount: out.GetAmount(), Height: height})
+			}
+		}
+		for _, out := range tx.GetECOutputs() {
+			a := primitives.ConvertECAddressToUserStr(out.GetAddress())
+			if addrs[a] {
+				deposits = append(deposits, &Deposit{TxID: txid, Address: a, Amount: out.GetAmount(), Height: height})
+			}
+		}
+	}
+	return deposits
+}
+
+// Start polls for new blocks every Interval until Stop is called. Deposits
+// are sent on the Events channel and to the Webhook.
+func (wt *Watcher) Start() {
+	wt.quit = make(chan struct{})
+	wt.done = make(chan struct{})
+	go wt.run()
+}
+
+// Stop stops a started Watcher and closes the Events channel.
+func (wt *Watcher) Stop() {
+	if wt.quit != nil {
+		close(wt.quit)
+		<-wt.done
+	}
+	close(wt.events)
+}
+
+func (wt *Watcher) run() {
+	defer close(wt.done)
+
+	interval := wt.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deposits, err := wt.Poll()
+		if err != nil {
+			wt.wallet.logf("watcher: %s", err)
+		}
+		for _, d := range deposits {
+			if wt.Webhook != "" {
+				if err := wt.post(d); err != nil {
+					wt.wallet.logf("watcher: webhook: %s", err)
+				}
+			}
+			select {
+			case wt.events <- d:
+			case <-wt.quit:
+				return
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-wt.quit:
+			return
+		}
+	}
+}
+
+// post sends d to the Webhook.
+func (wt *Watcher) post(d *Deposit) error {
+	j, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	resp, err := wt.client.Post(wt.Webhook, "application/json", bytes.NewReader(j))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", wt.Webhook, resp.Status)
+	}
+	return nil
+}
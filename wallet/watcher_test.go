@@ -0,0 +1,92 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+	"github.com/FactomProject/factomd/common/primitives"
+	"github.com/FactomProject/factomd/testHelper"
+)
+
+func TestWatcherPoll(t *testing.T) {
+	fblock := testHelper.CreateTestFactoidBlock(nil)
+	raw, err := fblock.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// watch the first address paid in the test block
+	var watched string
+	var amount uint64
+	for _, tx := range fblock.GetTransactions() {
+		if outs := tx.GetOutputs(); len(outs) > 0 {
+			watched = primitives.ConvertFctAddressToUserStr(outs[0].GetAddress())
+			amount = outs[0].GetAmount()
+			break
+		}
+	}
+	if watched == "" {
+		t.Skip("test block has no outputs")
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(factom.JSON2Request)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+
+		var result string
+		switch req.Method {
+		case "heights":
+			result = `{"directoryblockheight": 0}`
+		case "fblock-by-height":
+			result = fmt.Sprintf(`{"rawdata": "%s"}`, hex.EncodeToString(raw))
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+		}
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 0, "result": %s}`, result)
+	}))
+	defer ts.Close()
+	factom.SetFactomdServer(ts.URL[7:])
+
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	wt := w1.NewWatcher(0)
+	if err := wt.Watch("not an address"); err == nil {
+		t.Error("expected an error watching a bad address")
+	}
+	if err := wt.Watch(watched); err != nil {
+		t.Fatal(err)
+	}
+
+	deposits, err := wt.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deposits) == 0 || deposits[0].Address != watched || deposits[0].Amount != amount {
+		t.Errorf("wrong deposits %v", deposits)
+	}
+	if wt.NextHeight() != 1 {
+		t.Errorf("wrong next height %d", wt.NextHeight())
+	}
+
+	// the block is not looked at again
+	if deposits, err := wt.Poll(); err != nil || len(deposits) != 0 {
+		t.Errorf("expected no new deposits, got %v %v", deposits, err)
+	}
+}
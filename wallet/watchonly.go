@@ -0,0 +1,161 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"encoding/gob"
+	"errors"
+	"sort"
+
+	ed "github.com/FactomProject/ed25519"
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factomd/common/interfaces"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+var ErrWatchOnly = validationErrorf("wallet: Address is watch only and has no key")
+
+// watchOnlyRecord is a public address kept in the wallet without its secret
+// so that its balance and transactions can be followed.
+type watchOnlyRecord struct {
+	Address string
+
+	// PublicKey is the public key of a Factoid address when it is known.
+	// With it the wallet can add inputs from the address to transactions
+	// that a wallet with the secret then signs.
+	PublicKey []byte
+}
+
+var _ interfaces.BinaryMarshallableAndCopyable = (*watchOnlyRecord)(nil)
+
+// watchOnlyData is watchOnlyRecord without its methods, for gob.
+type watchOnlyData watchOnlyRecord
+
+func (r *watchOnlyRecord) New() interfaces.BinaryMarshallableAndCopyable {
+	return new(watchOnlyRecord)
+}
+
+func (r *watchOnlyRecord) MarshalBinary() ([]byte, error) {
+	var data primitives.Buffer
+
+	enc := gob.NewEncoder(&data)
+	if err := enc.Encode(watchOnlyData(*r)); err != nil {
+		return nil, err
+	}
+	return data.DeepCopyBytes(), nil
+}
+
+func (r *watchOnlyRecord) UnmarshalBinaryData(data []byte) ([]byte, error) {
+	dec := gob.NewDecoder(primitives.NewBuffer(data))
+	if err := dec.Decode((*watchOnlyData)(r)); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (r *watchOnlyRecord) UnmarshalBinary(data []byte) error {
+	_, err := r.UnmarshalBinaryData(data)
+	return err
+}
+
+// ImportWatchOnly stores the public Factoid or Entry Credit Address pub
+// without a secret. Watch only addresses are listed with the wallet
+// addresses but can not be used to sign.
+func (w *Wallet) ImportWatchOnly(pub string) error {
+	if w.readOnly {
+		return ErrReadOnly
+	}
+
+	var err error
+	switch factom.AddressStringType(pub) {
+	case factom.FactoidPub:
+		_, err = w.GetFCTAddress(pub)
+	case factom.ECPub:
+		_, err = w.GetECAddress(pub)
+	default:
+		return validationErrorf("wallet: %s is not a public address", pub)
+	}
+	if err == nil {
+		return validationErrorf("wallet: The key for %s is already in the wallet", pub)
+	} else if err != ErrNoSuchAddress {
+		return err
+	}
+
+	r := &watchOnlyRecord{Address: pub}
+	return dbError("write", w.DBO.Put(watchDBPrefix, []byte(pub), r))
+}
+
+// ImportWatchOnlyKey stores the Factoid address of the public key pub as
+// watch only, like ImportWatchOnly, and returns the address. Because the
+// wallet knows its public key, the address can be used as an input of tmp
+// transactions; they must be signed by the wallet that holds the secret, see
+// SignExportedTransaction.
+func (w *Wallet) ImportWatchOnlyKey(pub []byte) (string, error) {
+	if w.readOnly {
+		return "", ErrReadOnly
+	}
+	if len(pub) != ed.PublicKeySize {
+		return "", validationErrorf("wallet: A public key has %d bytes", ed.PublicKeySize)
+	}
+	rcd := factom.NewRCD1()
+	rcd.Pub = new([ed.PublicKeySize]byte)
+	copy(rcd.Pub[:], pub)
+	a := (&factom.FactoidAddress{RCD: rcd}).String()
+
+	if _, err := w.GetFCTAddress(a); err == nil {
+		return "", validationErrorf("wallet: The key for %s is already in the wallet", a)
+	} else if err != ErrNoSuchAddress {
+		return "", err
+	}
+
+	r := &watchOnlyRecord{Address: a, PublicKey: pub}
+	return a, dbError("write", w.DBO.Put(watchDBPrefix, []byte(a), r))
+}
+
+// watchOnlyKey returns the public key stored for the watch only address pub,
+// or nil if there is none.
+func (w *Wallet) watchOnlyKey(pub string) []byte {
+	data, err := w.DBO.Get(watchDBPrefix, []byte(pub), new(watchOnlyRecord))
+	if err != nil || data == nil {
+		return nil
+	}
+	return data.(*watchOnlyRecord).PublicKey
+}
+
+// IsWatchOnly reports whether pub is a watch only address of the wallet.
+func (w *Wallet) IsWatchOnly(pub string) (bool, error) {
+	data, err := w.DBO.Get(watchDBPrefix, []byte(pub), new(watchOnlyRecord))
+	if err != nil {
+		return false, dbError("read", err)
+	}
+	return data != nil, nil
+}
+
+// GetAllWatchOnly returns the watch only addresses of the wallet, sorted.
+func (w *Wallet) GetAllWatchOnly() ([]string, error) {
+	list, err := w.DBO.FetchAllBlocksFromBucket(watchDBPrefix, new(watchOnlyRecord))
+	if err != nil {
+		return nil, dbError("read", err)
+	}
+
+	as := make([]string, len(list))
+	for i, v := range list {
+		as[i] = v.(*watchOnlyRecord).Address
+	}
+	sort.Strings(as)
+	return as, nil
+}
+
+// keyError replaces ErrNoSuchAddress with ErrWatchOnly when the key that was
+// looked up is for a watch only address.
+func (w *Wallet) keyError(pub string, err error) error {
+	if !errors.Is(err, ErrNoSuchAddress) {
+		return err
+	}
+	if ok, _ := w.IsWatchOnly(pub); ok {
+		return ErrWatchOnly
+	}
+	return err
+}
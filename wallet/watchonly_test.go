@@ -0,0 +1,63 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/wallet"
+)
+
+func TestWatchOnly(t *testing.T) {
+	fa := "FA1zT4aFpEvcnPqPCigB3fvGu4Q4mTXY22iiuV69DqE1pNhdF2MC"
+
+	w1, err := New(WithMapDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+
+	if err := w1.ImportWatchOnly(fa); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.ImportWatchOnly("Fs1KWJrpLdfucvmYwN2nWrwepLn8ercpMbzXshd1g8zyhKXLVLWj"); !errors.Is(err, factom.ErrValidation) {
+		t.Errorf("expected a validation error for a secret, got %v", err)
+	}
+	f, err := w1.GenerateFCTAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.ImportWatchOnly(f.String()); !errors.Is(err, factom.ErrValidation) {
+		t.Errorf("expected a validation error for an owned address, got %v", err)
+	}
+
+	ws, err := w1.GetAllWatchOnly()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ws) != 1 || ws[0] != fa {
+		t.Errorf("wrong watch only addresses %v", ws)
+	}
+	if fs, _, err := w1.GetAllAddresses(); err != nil || len(fs) != 1 {
+		t.Errorf("watch only address is listed as owned: %v %v", fs, err)
+	}
+
+	// the wallet has no key to spend from a watch only address
+	if err := w1.NewTransaction("tx"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w1.AddInput("tx", fa, 100); err != ErrWatchOnly {
+		t.Errorf("expected ErrWatchOnly, got %v", err)
+	}
+
+	if err := w1.RemoveAddress(fa); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := w1.IsWatchOnly(fa); err != nil || ok {
+		t.Errorf("watch only address was not removed: %v %v", ok, err)
+	}
+}
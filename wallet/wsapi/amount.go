@@ -0,0 +1,62 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/FactomProject/factom"
+)
+
+// amount is the amount param of the transaction methods. It is a number of
+// factoshis, or a string of Factoids such as "1.5" or of entry credits such
+// as "100 EC".
+type amount struct {
+	factoshis uint64
+	s         *string
+}
+
+func (a *amount) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		a.s = new(string)
+		return json.Unmarshal(data, a.s)
+	}
+	return json.Unmarshal(data, &a.factoshis)
+}
+
+// Factoshis returns the amount in factoshis. Entry credits are only allowed
+// when ec is set, and are converted at the current rate.
+func (a *amount) Factoshis(ctx context.Context, ec bool) (uint64, *factom.JSONError) {
+	if a.s == nil {
+		return a.factoshis, nil
+	}
+	s := strings.TrimSpace(*a.s)
+	if len(s) < 2 || !strings.EqualFold(s[len(s)-2:], "EC") {
+		n, err := factom.ParseFactoidAmount(s)
+		if err != nil {
+			return 0, newWalletError(err)
+		}
+		return n, nil
+	}
+
+	if !ec {
+		return 0, newCustomInvalidParamsError("Entry credits can only be paid to Entry Credit addresses")
+	}
+	ecs, err := factom.ParseECAmount(s[:len(s)-2] + "EC")
+	if err != nil {
+		return 0, newWalletError(err)
+	}
+	rate, err := factom.GetRateWithContext(ctx)
+	if err != nil {
+		return 0, newCustomInternalError(err.Error())
+	}
+	n := ecs * rate
+	if rate != 0 && n/rate != ecs {
+		return 0, newCustomInvalidParamsError("Too many entry credits")
+	}
+	return n, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestAmountFactoshis(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"rate": 1000}}`)
+	}))
+	defer ts.Close()
+	defer func(s string) { factom.SetFactomdServer(s) }(factom.FactomdServer())
+	factom.SetFactomdServer(ts.URL[7:])
+
+	for _, c := range []struct {
+		param     string
+		ec        bool
+		factoshis uint64
+		valid     bool
+	}{
+		// numbers are factoshis
+		{`0`, false, 0, true},
+		{`150000000`, false, 150000000, true},
+		{`-1`, false, 0, false},
+
+		// strings are Factoids
+		{`"1.5"`, false, 150000000, true},
+		{`"1.5 FCT"`, false, 150000000, true},
+		{`"0.000000001"`, false, 0, false},
+		{`""`, false, 0, false},
+		{`"  "`, false, 0, false},
+		{`"abc"`, false, 0, false},
+
+		// or entry credits at the current rate
+		{`"100 EC"`, true, 100000, true},
+		{`"100 ec"`, true, 100000, true},
+		{`"100Ec"`, true, 100000, true},
+		{`"100 EC"`, false, 0, false},
+		{`"EC"`, true, 0, false},
+		{`"1.5 EC"`, true, 0, false},
+	} {
+		a := new(amount)
+		if err := json.Unmarshal([]byte(c.param), a); err != nil {
+			if c.valid {
+				t.Errorf("%s: %v", c.param, err)
+			}
+			continue
+		}
+		n, jsonError := a.Factoshis(context.Background(), c.ec)
+		if c.valid && (jsonError != nil || n != c.factoshis) {
+			t.Errorf("%s gave %d, %v, want %d", c.param, n, jsonError, c.factoshis)
+		}
+		if !c.valid && jsonError == nil {
+			t.Errorf("%s gave %d, want an error", c.param, n)
+		}
+	}
+}
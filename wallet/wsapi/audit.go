@@ -0,0 +1,89 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/FactomProject/factom"
+)
+
+// auditedRequest holds the params of the audited methods that name what they
+// operate on.
+type auditedRequest struct {
+	Name    string `json:"tx-name"`
+	From    string `json:"from"`
+	Address string `json:"address"`
+	Public  string `json:"public"`
+	Secrets *bool  `json:"secrets"`
+	Backup  bool   `json:"backup"`
+}
+
+// audited reports whether a call of method with req is recorded in the audit
+// log: the methods that sign with the wallet keys, approve, or return
+// secrets, as listed in methodPermissions. The address methods are only
+// recorded when their response includes the secret keys, and remove-address
+// when it backs up the removed key.
+func audited(method string, req *auditedRequest) bool {
+	if methodPermissions[method]&(PermSign|PermExport|PermApprove) == 0 {
+		return false
+	}
+	switch method {
+	case "address", "all-addresses":
+		return includeSecrets(req.Secrets)
+	case "remove-address":
+		return req.Backup
+	}
+	return true
+}
+
+// audit records an attempt to call method with params in the audit log,
+// before the call is checked and served. The entry does not say whether the
+// call succeeded. An error means the attempt could not be recorded and the
+// call must be refused.
+func audit(method string, params []byte) *factom.JSONError {
+	req := new(auditedRequest)
+	if p := bytes.TrimSpace(params); len(p) > 0 && p[0] == '{' {
+		json.Unmarshal(params, req)
+	}
+	if !audited(method, req) {
+		return nil
+	}
+	// a locked wallet can not write to its database
+	if fctWallet.IsLocked() {
+		return newWalletIsLockedError()
+	}
+
+	subject := req.Name
+	if subject == "" {
+		subject = req.From
+	}
+	if subject == "" {
+		subject = req.Address
+	}
+	if subject == "" {
+		subject = req.Public
+	}
+	if err := fctWallet.Audit(method, subject); err != nil {
+		return newWalletError(err)
+	}
+	return nil
+}
+
+func handleAuditLog(params []byte) (interface{}, *factom.JSONError) {
+	req := new(auditLogRequest)
+	if p := bytes.TrimSpace(params); len(p) > 0 && p[0] == '{' {
+		if err := json.Unmarshal(params, req); err != nil {
+			return nil, newInvalidParamsError()
+		}
+	}
+
+	es, err := fctWallet.AuditLog(req.Start, req.Limit)
+	if err != nil {
+		return nil, newWalletError(err)
+	}
+	return &auditLogResponse{Entries: es}, nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factom/wallet"
+)
+
+func TestAuditedMethods(t *testing.T) {
+	yes, no := true, false
+	for _, c := range []struct {
+		method  string
+		req     auditedRequest
+		audited bool
+	}{
+		{"sign-transaction", auditedRequest{}, true},
+		{"send-factoid", auditedRequest{}, true},
+		{"approve-transaction", auditedRequest{}, true},
+		{"compose-chain", auditedRequest{}, true},
+		{"compose-entry", auditedRequest{}, true},
+		{"compose-identity-chain", auditedRequest{}, true},
+		{"generate-factoid-address", auditedRequest{}, true},
+		{"generate-ec-address", auditedRequest{}, true},
+		{"generate-identity-key", auditedRequest{}, true},
+		{"import-addresses", auditedRequest{}, true},
+		{"import-koinify", auditedRequest{}, true},
+		{"wallet-backup", auditedRequest{}, true},
+		{"export-keystore", auditedRequest{}, true},
+		{"address", auditedRequest{Secrets: &yes}, true},
+		{"address", auditedRequest{Secrets: &no}, false},
+		{"all-addresses", auditedRequest{Secrets: &no}, false},
+		{"remove-address", auditedRequest{Backup: true}, true},
+		{"remove-address", auditedRequest{}, false},
+		{"properties", auditedRequest{}, false},
+		{"transactions", auditedRequest{}, false},
+		{"unlock-wallet", auditedRequest{}, false},
+	} {
+		if got := audited(c.method, &c.req); got != c.audited {
+			t.Errorf("%s %+v audited: %v", c.method, c.req, got)
+		}
+	}
+}
+
+// auditBackend records the audit entries of the wallet api. Its other
+// methods are not used by the tests.
+type auditBackend struct {
+	wallet.WalletBackend
+	locked   bool
+	err      error
+	recorded []string
+}
+
+func (b *auditBackend) IsLocked() bool { return b.locked }
+
+func (b *auditBackend) Audit(method, subject string) error {
+	if b.err != nil {
+		return b.err
+	}
+	b.recorded = append(b.recorded, method+" "+subject)
+	return nil
+}
+
+func TestAuditBeforeServing(t *testing.T) {
+	b := new(auditBackend)
+	defer func(w wallet.WalletBackend) { fctWallet = w }(fctWallet)
+	fctWallet = b
+
+	// a denied attempt is recorded
+	j := factom.NewJSON2Request("sign-data", 0, map[string]string{"signer": "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"})
+	if _, jsonError := serveRequest(context.Background(), j, TokenPermissions{}, ""); jsonError == nil ||
+		jsonError.Code != newPermissionDeniedError().Code {
+		t.Errorf("expected the call to be denied, got %v", jsonError)
+	}
+	if len(b.recorded) != 1 || b.recorded[0] != "sign-data " {
+		t.Errorf("recorded %q", b.recorded)
+	}
+
+	// a call that can not be recorded is refused before it is served
+	b.err = errors.New("disk full")
+	if _, jsonError := serveRequest(context.Background(), j, nil, ""); jsonError == nil {
+		t.Error("unrecorded call was served")
+	}
+	b.err = nil
+	b.locked = true
+	if _, jsonError := serveRequest(context.Background(), j, nil, ""); jsonError == nil ||
+		jsonError.Code != newWalletIsLockedError().Code {
+		t.Errorf("expected a locked wallet error, got %v", jsonError)
+	}
+	if len(b.recorded) != 1 {
+		t.Errorf("recorded %q", b.recorded)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"context"
+	"sync"
+
+	"github.com/FactomProject/factom"
+)
+
+// balanceWorkers is the number of balances fetched from factomd at once.
+const balanceWorkers = 8
+
+// addBalances sets the balances of the addresses from factomd.
+func addBalances(ctx context.Context, method string, as []*addressResponse) *factom.JSONError {
+	errs := make([]error, len(as))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < balanceWorkers && w < len(as); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				errs[i] = addBalance(ctx, as[i])
+			}
+		}()
+	}
+	for i := range as {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			factomdFailed(method)
+			return newCustomInternalError(err.Error())
+		}
+	}
+	return nil
+}
+
+// addBalance sets the balance of a Factoid or Entry Credit address.
+func addBalance(ctx context.Context, a *addressResponse) error {
+	var (
+		b   int64
+		err error
+	)
+	switch factom.AddressStringType(a.Public) {
+	case factom.FactoidPub:
+		b, err = factom.GetFactoidBalanceWithContext(ctx, a.Public)
+	case factom.ECPub:
+		b, err = factom.GetECBalanceWithContext(ctx, a.Public)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	a.Balance = &b
+	return nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factom/wallet"
+)
+
+// addrBackend holds one Factoid and one Entry Credit address in the
+// namespace "ops" and audits every call. Its other methods are not used by
+// the tests.
+type addrBackend struct {
+	wallet.WalletBackend
+	fa *factom.FactoidAddress
+	ec *factom.ECAddress
+}
+
+func newAddrBackend(t *testing.T) *addrBackend {
+	fa, err := factom.GetFactoidAddress("Fs2GCfAa2HBKaGEUWCtw8eGDkN1CfyS6HhdgLv8783shkrCgvcpJ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec, err := factom.GetECAddress("Es2Rf7iM6PdsqfYCo3D1tnAR65SkLENyWJG1deUzpRMQmbh9F3eG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &addrBackend{fa: fa, ec: ec}
+}
+
+func (b *addrBackend) IsLocked() bool                     { return false }
+func (b *addrBackend) ReadOnly() bool                     { return false }
+func (b *addrBackend) Audit(method, subject string) error { return nil }
+
+func (b *addrBackend) Namespace(pub string) (string, error) { return "ops", nil }
+
+func (b *addrBackend) GetFCTAddress(string) (*factom.FactoidAddress, error) { return b.fa, nil }
+func (b *addrBackend) GetECAddress(string) (*factom.ECAddress, error)       { return b.ec, nil }
+
+func (b *addrBackend) GetAllAddresses() ([]*factom.FactoidAddress, []*factom.ECAddress, error) {
+	return []*factom.FactoidAddress{b.fa}, []*factom.ECAddress{b.ec}, nil
+}
+
+func (b *addrBackend) GetAllAddressLabels() (map[string]string, error) { return nil, nil }
+func (b *addrBackend) GetAllWatchOnly() ([]string, error)              { return nil, nil }
+func (b *addrBackend) GetAllSignerKeys() ([]string, error)             { return nil, nil }
+
+// callAddresses serves method with params and returns the addresses in the
+// response.
+func callAddresses(t *testing.T, perms TokenPermissions, method, params string) ([]*addressResponse, *factom.JSONError) {
+	j := factom.NewJSON2Request(method, 1, json.RawMessage(params))
+	resp, jsonError := serveRequest(context.Background(), j, perms, "")
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	if method == "address" {
+		a := new(addressResponse)
+		if err := json.Unmarshal(resp.JSONResult(), a); err != nil {
+			t.Fatal(err)
+		}
+		return []*addressResponse{a}, nil
+	}
+	as := new(multiAddressResponse)
+	if err := json.Unmarshal(resp.JSONResult(), as); err != nil {
+		t.Fatal(err)
+	}
+	return as.Addresses, nil
+}
+
+func TestAddressBalances(t *testing.T) {
+	b := newAddrBackend(t)
+	defer func(w wallet.WalletBackend) { fctWallet = w }(fctWallet)
+	fctWallet = b
+
+	var calls int32
+	failing := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		body, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case failing:
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "error": {"code": -32603, "message": "Internal error"}}`)
+		case strings.Contains(string(body), "entry-credit-balance"):
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"balance": 7}}`)
+		default:
+			fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"balance": 100}}`)
+		}
+	}))
+	defer ts.Close()
+	defer func(s string) { factom.SetFactomdServer(s) }(factom.FactomdServer())
+	factom.SetFactomdServer(ts.URL[7:])
+
+	balances := map[string]int64{b.fa.String(): 100, b.ec.String(): 7}
+	for _, c := range []struct {
+		method string
+		params string
+	}{
+		{"address", `{"address": "` + b.fa.String() + `", "balances": true}`},
+		{"address", `{"address": "` + b.ec.String() + `", "balances": true}`},
+		{"all-addresses", `{"balances": true}`},
+	} {
+		as, jsonError := callAddresses(t, nil, c.method, c.params)
+		if jsonError != nil {
+			t.Fatalf("%s: %v", c.method, jsonError)
+		}
+		for _, a := range as {
+			if a.Balance == nil || *a.Balance != balances[a.Public] {
+				t.Errorf("%s: %s has balance %v, want %d", c.method, a.Public, a.Balance, balances[a.Public])
+			}
+		}
+	}
+
+	// balances are only fetched when asked for
+	atomic.StoreInt32(&calls, 0)
+	for _, method := range []string{"address", "all-addresses"} {
+		as, jsonError := callAddresses(t, nil, method, `{"address": "`+b.fa.String()+`"}`)
+		if jsonError != nil {
+			t.Fatalf("%s: %v", method, jsonError)
+		}
+		for _, a := range as {
+			if a.Balance != nil {
+				t.Errorf("%s: %s has balance %d without asking", method, a.Public, *a.Balance)
+			}
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 0 {
+		t.Errorf("factomd was called %d times", n)
+	}
+
+	// the call fails if factomd does
+	failing = true
+	for _, method := range []string{"address", "all-addresses"} {
+		_, jsonError := callAddresses(t, nil, method, `{"address": "`+b.fa.String()+`", "balances": true}`)
+		if jsonError == nil || jsonError.Code != newCustomInternalError("").Code {
+			t.Errorf("%s with a failing factomd: %v", method, jsonError)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/web"
+)
+
+// maxBatchSize is the largest number of requests allowed in a batch.
+const maxBatchSize = 100
+
+// isBatch reports whether body holds a JSON-RPC batch, which is an array of
+// requests.
+func isBatch(body []byte) bool {
+	body = bytes.TrimLeft(body, " \t\r\n")
+	return len(body) > 0 && body[0] == '['
+}
+
+// isNotification reports whether the request raw is a JSON-RPC 2.0
+// notification, which has no id and gets no response.
+func isNotification(raw json.RawMessage) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return false
+	}
+	_, ok := fields["id"]
+	return !ok
+}
+
+// handleBatch serves a JSON-RPC 2.0 batch. The requests are served one after
+// the other in the order of the batch, so that a batch can build a
+// transaction and sign it. The responses are returned in the same order,
+// leaving out the notifications; a batch of notifications gets no response
+// at all. Every request of the
+// batch counts against the rate limits: the first one is paid for by the
+// http request and the others take a token each, failing with a rate limit
+// error when there is none.
+func handleBatch(ctx *web.Context, body []byte, perms TokenPermissions, remoteAddr string) {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(body, &raws); err != nil || len(raws) == 0 {
+		handleV2Error(ctx, nil, newInvalidRequestError())
+		return
+	}
+	if len(raws) > maxBatchSize {
+		handleV2Error(ctx, nil, newCustomInvalidRequestError(
+			fmt.Sprintf("a batch may have at most %d requests", maxBatchSize)))
+		return
+	}
+
+	resps := serveBatch(ctx.Request.Context(), raws, perms, remoteAddr)
+	if len(resps) == 0 {
+		return
+	}
+
+	p, err := json.Marshal(resps)
+	if err != nil {
+		handleV2Error(ctx, nil, newCustomInternalError(err.Error()))
+		return
+	}
+	ctx.Write(p)
+}
+
+// serveBatch serves the requests of a batch in order and returns the
+// responses to those that are not notifications.
+func serveBatch(ctx context.Context, raws []json.RawMessage, perms TokenPermissions, remoteAddr string) []*factom.JSON2Response {
+	var resps []*factom.JSON2Response
+	for i, raw := range raws {
+		resp := handleBatchRequest(ctx, raw, perms, remoteAddr, i > 0)
+		if !isNotification(raw) {
+			resps = append(resps, resp)
+		}
+	}
+	return resps
+}
+
+// handleBatchRequest serves one request of a batch, taking a rate limit
+// token for it if limit is set.
+func handleBatchRequest(ctx context.Context, raw json.RawMessage, perms TokenPermissions, remoteAddr string, limit bool) *factom.JSON2Response {
+	j, err := factom.ParseJSON2Request(string(raw))
+	if err != nil {
+		return newErrorResponse(nil, newInvalidRequestError())
+	}
+	if limit {
+		if ok, wait := checkRateLimit(remoteAddr); !ok {
+			return newErrorResponse(j, newRateLimitedError(wait))
+		}
+	}
+
+	resp, jsonError := serveRequest(ctx, j, perms, remoteAddr)
+	if jsonError != nil {
+		return newErrorResponse(j, jsonError)
+	}
+	return resp
+}
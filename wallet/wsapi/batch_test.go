@@ -0,0 +1,126 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/FactomProject/factom/wallet"
+)
+
+func TestIsBatch(t *testing.T) {
+	for body, batch := range map[string]bool{
+		`[{"jsonrpc": "2.0", "id": 0, "method": "properties"}]`: true,
+		" \n\t[]": true,
+		`{"jsonrpc": "2.0", "id": 0, "method": "properties"}`: false,
+		"":   false,
+		"  ": false,
+	} {
+		if got := isBatch([]byte(body)); got != batch {
+			t.Errorf("isBatch(%q) = %v", body, got)
+		}
+	}
+}
+
+func TestHandleBatchRequest(t *testing.T) {
+	SetMethodPolicy(nil, []string{"properties"})
+	defer SetMethodPolicy(nil, nil)
+
+	// each request of a batch gets its own response with its own id
+	resp := handleBatchRequest(context.Background(), json.RawMessage(`{"jsonrpc": "2.0", "id": 7, "method": "properties"}`), nil, "", false)
+	if resp.Error == nil || resp.Error.Code != newMethodDisabledError().Code {
+		t.Errorf("expected the method to be disabled, got %v", resp.Error)
+	}
+	if id, ok := resp.ID.(float64); !ok || id != 7 {
+		t.Errorf("response has id %v", resp.ID)
+	}
+
+	// a request that can not be parsed does not fail the others
+	resp = handleBatchRequest(context.Background(), json.RawMessage(`{"id": 8`), nil, "", false)
+	if resp.Error == nil || resp.Error.Code != newInvalidRequestError().Code {
+		t.Errorf("expected an invalid request error, got %v", resp.Error)
+	}
+	if resp.ID != nil {
+		t.Errorf("invalid request has id %v", resp.ID)
+	}
+
+	// the permissions of the batch apply to each request
+	resp = handleBatchRequest(context.Background(), json.RawMessage(`{"jsonrpc": "2.0", "id": 9, "method": "unlock-wallet"}`), TokenPermissions{}, "", false)
+	if resp.Error == nil || resp.Error.Code != newPermissionDeniedError().Code {
+		t.Errorf("expected permission denied, got %v", resp.Error)
+	}
+}
+
+func TestIsNotification(t *testing.T) {
+	for raw, notification := range map[string]bool{
+		`{"jsonrpc": "2.0", "method": "properties"}`:             true,
+		`{"jsonrpc": "2.0", "id": 1, "method": "properties"}`:    false,
+		`{"jsonrpc": "2.0", "id": null, "method": "properties"}`: false,
+		`{"jsonrpc": "2.0", "id": 0, "method": "properties"}`:    false,
+		`not json`: false,
+	} {
+		if got := isNotification(json.RawMessage(raw)); got != notification {
+			t.Errorf("isNotification(%s) = %v", raw, got)
+		}
+	}
+}
+
+// orderBackend records the tmp transactions deleted through it. Its other
+// methods are not used by the tests.
+type orderBackend struct {
+	wallet.WalletBackend
+	deleted []string
+}
+
+func (b *orderBackend) ReadOnly() bool { return false }
+func (b *orderBackend) IsLocked() bool { return false }
+
+func (b *orderBackend) DeleteTransaction(name string) error {
+	// give a concurrent request the chance to overtake this one
+	time.Sleep(time.Millisecond)
+	b.deleted = append(b.deleted, name)
+	return nil
+}
+
+func TestServeBatch(t *testing.T) {
+	b := new(orderBackend)
+	defer func(w wallet.WalletBackend) { fctWallet = w }(fctWallet)
+	fctWallet = b
+
+	var raws []json.RawMessage
+	for i := 0; i < 10; i++ {
+		id := fmt.Sprintf(`"id": %d, `, i)
+		if i%3 == 0 {
+			// every third request is a notification
+			id = ""
+		}
+		raws = append(raws, json.RawMessage(fmt.Sprintf(
+			`{"jsonrpc": "2.0", %s"method": "delete-transaction", "params": {"tx-name": "%d"}}`, id, i)))
+	}
+
+	resps := serveBatch(context.Background(), raws, nil, "")
+
+	// the requests are served in the order of the batch
+	if fmt.Sprint(b.deleted) != "[0 1 2 3 4 5 6 7 8 9]" {
+		t.Errorf("requests served in the order %v", b.deleted)
+	}
+	// notifications get no response
+	var ids []interface{}
+	for _, r := range resps {
+		ids = append(ids, r.ID)
+	}
+	if fmt.Sprint(ids) != "[1 2 4 5 7 8]" {
+		t.Errorf("responses have ids %v", ids)
+	}
+
+	// a batch of notifications gets no response at all
+	if resps := serveBatch(context.Background(), []json.RawMessage{raws[0], raws[3]}, nil, ""); len(resps) != 0 {
+		t.Errorf("notifications got responses %v", resps)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/FactomProject/web"
+)
+
+// cacheableMethods are the api methods whose results rarely change. Their
+// responses carry an ETag header so that polling clients can revalidate them
+// and receive a 304 instead of the full payload.
+var cacheableMethods = map[string]bool{
+	"all-addresses": true,
+	"properties":    true,
+}
+
+// resultETag returns the ETag of a method result. It is a hash of the result
+// itself, so it differs for every params and caller that get a different
+// result. There is no Last-Modified header, which would need the time each
+// of those results was first served.
+func resultETag(result []byte) string {
+	sum := sha256.Sum256(result)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// writeCacheHeaders sets the caching headers for a cacheable method result and
+// writes a 304 Not Modified response if the client already has the current
+// result. It returns true if the 304 was written and the response is complete.
+func writeCacheHeaders(ctx *web.Context, result []byte) bool {
+	etag := resultETag(result)
+
+	h := ctx.ResponseWriter.Header()
+	h.Set("ETag", etag)
+	h.Set("Cache-Control", "private, no-cache")
+
+	if inm := ctx.Request.Header.Get("If-None-Match"); inm != "" {
+		for _, t := range strings.Split(inm, ",") {
+			if t = strings.TrimSpace(t); t == etag || t == "*" {
+				ctx.WriteHeader(http.StatusNotModified)
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FactomProject/web"
+)
+
+func TestWriteCacheHeaders(t *testing.T) {
+	result := []byte(`{"fct_addresses":[]}`)
+	etag := resultETag(result)
+	if etag == resultETag([]byte(`{"fct_addresses":[{}]}`)) {
+		t.Fatal("different results have the same ETag")
+	}
+
+	for _, c := range []struct {
+		inm         string
+		notModified bool
+	}{
+		{"", false},
+		{etag, true},
+		{`"other", ` + etag, true},
+		{"*", true},
+		{`"other"`, false},
+	} {
+		r, _ := http.NewRequest("POST", "/v2", nil)
+		if c.inm != "" {
+			r.Header.Set("If-None-Match", c.inm)
+		}
+		w := httptest.NewRecorder()
+		ctx := &web.Context{Request: r, ResponseWriter: w}
+		if got := writeCacheHeaders(ctx, result); got != c.notModified {
+			t.Errorf("If-None-Match %q: not modified %v", c.inm, got)
+		}
+		if c.notModified && w.Code != http.StatusNotModified {
+			t.Errorf("If-None-Match %q: status %d", c.inm, w.Code)
+		}
+		if got := w.Header().Get("ETag"); got != etag {
+			t.Errorf("ETag %q, want %q", got, etag)
+		}
+		if got := w.Header().Get("Last-Modified"); got != "" {
+			t.Errorf("Last-Modified %q", got)
+		}
+	}
+
+	// a client holding another caller's or params' result gets the full
+	// result
+	r, _ := http.NewRequest("POST", "/v2", nil)
+	r.Header.Set("If-None-Match", resultETag([]byte(`{"fct_addresses":[{"secret":"Fs..."}]}`)))
+	if writeCacheHeaders(&web.Context{Request: r, ResponseWriter: httptest.NewRecorder()}, result) {
+		t.Error("304 for a different result")
+	}
+}
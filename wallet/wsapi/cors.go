@@ -0,0 +1,63 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/FactomProject/factom"
+)
+
+// corsOrigins are the origins browsers may call the api from. "*" allows
+// every origin.
+var corsOrigins []string
+
+// corsAllowedHeaders are the request headers a browser may send to the api.
+var corsAllowedHeaders = strings.Join([]string{
+	"Content-Type",
+	"Authorization",
+	factom.HMACKeyIDHeader,
+	factom.HMACTimestampHeader,
+	factom.HMACNonceHeader,
+	factom.HMACBodyHashHeader,
+	factom.HMACSignatureHeader,
+}, ", ")
+
+func originAllowed(origin string) bool {
+	for _, o := range corsOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// setCORSHeaders adds the CORS headers to the response to r if r comes from
+// an allowed origin. It reports whether the origin is allowed.
+func setCORSHeaders(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || !originAllowed(origin) {
+		return false
+	}
+
+	h := w.Header()
+	h.Set("Access-Control-Allow-Origin", origin)
+	h.Add("Vary", "Origin")
+	h.Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	h.Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+	h.Set("Access-Control-Max-Age", "600")
+	return true
+}
+
+// handlePreflight answers the OPTIONS request a browser sends before a cross
+// origin api call.
+func handlePreflight(w http.ResponseWriter, r *http.Request) {
+	if !setCORSHeaders(w, r) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,71 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestSetCORSHeaders(t *testing.T) {
+	defer func(o []string) { corsOrigins = o }(corsOrigins)
+	corsOrigins = []string{"https://app.example.com"}
+
+	for origin, allowed := range map[string]bool{
+		"https://app.example.com":  true,
+		"https://APP.example.com":  true,
+		"https://evil.example.com": false,
+		"":                         false,
+	} {
+		r, _ := http.NewRequest("POST", "/v2", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		w := httptest.NewRecorder()
+		if got := setCORSHeaders(w, r); got != allowed {
+			t.Errorf("origin %q allowed: %v", origin, got)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); allowed && got != origin || !allowed && got != "" {
+			t.Errorf("origin %q got Access-Control-Allow-Origin %q", origin, got)
+		}
+	}
+
+	// a signed request may be sent from a browser
+	for _, h := range []string{"Authorization", factom.HMACKeyIDHeader, factom.HMACTimestampHeader,
+		factom.HMACNonceHeader, factom.HMACBodyHashHeader, factom.HMACSignatureHeader} {
+		if !strings.Contains(corsAllowedHeaders, h) {
+			t.Errorf("header %s is not allowed", h)
+		}
+	}
+
+	corsOrigins = []string{"*"}
+	r, _ := http.NewRequest("POST", "/v2", nil)
+	r.Header.Set("Origin", "https://any.example.com")
+	if !setCORSHeaders(httptest.NewRecorder(), r) {
+		t.Error("origin not allowed by *")
+	}
+}
+
+func TestHandlePreflight(t *testing.T) {
+	defer func(o []string) { corsOrigins = o }(corsOrigins)
+	corsOrigins = []string{"https://app.example.com"}
+
+	for origin, status := range map[string]int{
+		"https://app.example.com":  http.StatusNoContent,
+		"https://evil.example.com": http.StatusForbidden,
+	} {
+		r, _ := http.NewRequest("OPTIONS", "/v2", nil)
+		r.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		handlePreflight(w, r)
+		if w.Code != status {
+			t.Errorf("origin %q got status %d", origin, w.Code)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"context"
+	"sync"
+)
+
+// in flight request tracking so that Shutdown can wait for running handlers
+var (
+	drainLock sync.Mutex
+	draining  bool
+	inFlight  sync.WaitGroup
+)
+
+// beginRequest registers a new request with the server. It returns false if
+// the server is stopping and the request should be refused.
+func beginRequest() bool {
+	drainLock.Lock()
+	defer drainLock.Unlock()
+
+	if draining {
+		return false
+	}
+	inFlight.Add(1)
+	return true
+}
+
+// endRequest marks a request started with beginRequest as finished.
+func endRequest() {
+	inFlight.Done()
+}
+
+// drainRequests refuses any new requests and waits for the requests in flight
+// to finish or for ctx to be done, whichever happens first.
+func drainRequests(ctx context.Context) error {
+	drainLock.Lock()
+	draining = true
+	drainLock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// resetDrain allows requests again after the server has been restarted.
+func resetDrain() {
+	drainLock.Lock()
+	draining = false
+	drainLock.Unlock()
+}
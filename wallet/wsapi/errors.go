@@ -5,14 +5,35 @@
 package wsapi
 
 import (
+	"errors"
+	"time"
+
 	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factom/wallet"
 	"github.com/FactomProject/web"
 )
 
 const httpBad = 400
 
+var (
+	ErrNoAuth  = errors.New("wsapi: no auth")
+	ErrBadAuth = errors.New("wsapi: bad auth")
+
+	ErrUnknownHMACKey  = errors.New("wsapi: unknown hmac key id")
+	ErrReplayedRequest = errors.New("wsapi: replayed signed request")
+)
+
 // handleV2Error handles the error responses to RPC calls
 func handleV2Error(ctx *web.Context, j *factom.JSON2Request, err *factom.JSONError) {
+	resp := newErrorResponse(j, err)
+
+	ctx.WriteHeader(httpBad)
+	ctx.Write([]byte(resp.String()))
+}
+
+// newErrorResponse returns the response to the request j that failed with
+// err. j may be nil if the request could not be parsed.
+func newErrorResponse(j *factom.JSON2Request, err *factom.JSONError) *factom.JSON2Response {
 	resp := factom.NewJSON2Response()
 	if j != nil {
 		resp.ID = j.ID
@@ -20,9 +41,7 @@ func handleV2Error(ctx *web.Context, j *factom.JSON2Request, err *factom.JSONErr
 		resp.ID = nil
 	}
 	resp.Error = err
-
-	ctx.WriteHeader(httpBad)
-	ctx.Write([]byte(resp.String()))
+	return resp
 }
 
 /*
@@ -68,6 +87,32 @@ func newIncorrectPassphraseError() *factom.JSONError {
 	return factom.NewJSONError(-32003, "Incorrect passphrase", nil)
 }
 
+func newWalletIsReadOnlyError() *factom.JSONError {
+	return factom.NewJSONError(-32004, "Wallet is read only", nil)
+}
+
+func newPermissionDeniedError() *factom.JSONError {
+	return factom.NewJSONError(-32005, "Permission denied", nil)
+}
+
+func newMethodDisabledError() *factom.JSONError {
+	return factom.NewJSONError(-32006, "Method disabled", nil)
+}
+
+func newRateLimitedError(retryAfter time.Duration) *factom.JSONError {
+	return factom.NewJSONError(-32007, "Rate limit exceeded", map[string]float64{
+		"retry-after": retryAfter.Seconds(),
+	})
+}
+
+func newApprovalRequiredError(data interface{}) *factom.JSONError {
+	return factom.NewJSONError(-32008, "Approval required", data)
+}
+
+func newInvalidAmountError(data interface{}) *factom.JSONError {
+	return factom.NewJSONError(-32009, "Invalid amount", data)
+}
+
 // Custom Errors
 
 func newCustomInternalError(data interface{}) *factom.JSONError {
@@ -77,3 +122,35 @@ func newCustomInternalError(data interface{}) *factom.JSONError {
 func newCustomInvalidParamsError(data interface{}) *factom.JSONError {
 	return factom.NewJSONError(-32602, "Invalid params", data)
 }
+
+func newCustomInvalidRequestError(data interface{}) *factom.JSONError {
+	return factom.NewJSONError(-32600, "Invalid Request", data)
+}
+
+// newWalletError reports err from the wallet as invalid params if it was
+// caused by bad input and as an internal error otherwise.
+func newWalletError(err error) *factom.JSONError {
+	if errors.Is(err, wallet.ErrAmountTooLarge) || errors.Is(err, wallet.ErrAmountOverflow) ||
+		errors.Is(err, wallet.ErrZeroOutput) {
+		return newInvalidAmountError(err.Error())
+	}
+	if errors.Is(err, factom.ErrValidation) {
+		return newCustomInvalidParamsError(err.Error())
+	}
+	if errors.Is(err, wallet.ErrReadOnly) {
+		return newWalletIsReadOnlyError()
+	}
+	if errors.Is(err, wallet.ErrWalletLocked) {
+		return newWalletIsLockedError()
+	}
+	if errors.Is(err, wallet.ErrApprovalRequired) {
+		// a payment waiting for approval names the tmp transaction to
+		// approve, sign and send
+		var pending *wallet.PendingApprovalError
+		if errors.As(err, &pending) {
+			return newApprovalRequiredError(map[string]string{"tx-name": pending.Name})
+		}
+		return newApprovalRequiredError(nil)
+	}
+	return newCustomInternalError(err.Error())
+}
@@ -0,0 +1,110 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/FactomProject/factom/wallet"
+	"golang.org/x/net/websocket"
+)
+
+// closed by Shutdown to end the websocket subscriptions
+var eventsQuit chan struct{}
+
+// eventNotification is the JSON-RPC 2.0 notification pushed to websocket
+// clients for every wallet event.
+type eventNotification struct {
+	JSONRPC string        `json:"jsonrpc"`
+	Method  string        `json:"method"`
+	Params  *wallet.Event `json:"params"`
+}
+
+// handleEvents authenticates a websocket subscription request to /v2/ws the
+// same way as a request to /v2 and then pushes wallet events to the client
+// until it disconnects or the server shuts down. Clients using an api token
+// only receive events for keys they may list. Browsers may only subscribe
+// from the CORS origins, see eventsOriginAllowed.
+func handleEvents(w http.ResponseWriter, r *http.Request) {
+	perms, err := authenticate(r, nil)
+	if err != nil {
+		remoteIP := strings.Split(r.RemoteAddr, ":")[0]
+		fmt.Printf("Unauthorized API client connection attempt from %s: %s\n", remoteIP, err)
+		w.Header().Add("WWW-Authenticate", `Basic realm="factomd RPC"`)
+		http.Error(w, "401 Unauthorized.", http.StatusUnauthorized)
+		return
+	}
+
+	websocket.Server{Handshake: checkEventsOrigin, Handler: func(conn *websocket.Conn) {
+		defer conn.Close()
+
+		events, cancel := fctWallet.Subscribe()
+		defer cancel()
+
+		// the client does not send anything, reading only notices that it
+		// has gone away
+		gone := make(chan struct{})
+		go func() {
+			defer close(gone)
+			var msg string
+			for websocket.Message.Receive(conn, &msg) == nil {
+			}
+		}()
+
+		for {
+			select {
+			case e := <-events:
+				if !eventAllowed(perms, e) {
+					continue
+				}
+				n := &eventNotification{JSONRPC: "2.0", Method: "event", Params: e}
+				if err := websocket.JSON.Send(conn, n); err != nil {
+					return
+				}
+			case <-gone:
+				return
+			case <-eventsQuit:
+				return
+			}
+		}
+	}}.ServeHTTP(w, r)
+}
+
+// eventsOriginAllowed reports whether a websocket subscription may be made
+// from the origin of r. Browsers send the origin of the page that opens the
+// websocket, which must be one of the CORS origins so that other web pages
+// can not read the wallet events. Other clients need not send an origin.
+func eventsOriginAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	return origin == "" || originAllowed(origin)
+}
+
+// checkEventsOrigin is the websocket handshake of handleEvents.
+func checkEventsOrigin(_ *websocket.Config, r *http.Request) error {
+	if !eventsOriginAllowed(r) {
+		return errors.New("wsapi: websocket origin not allowed")
+	}
+	return nil
+}
+
+// eventAllowed reports whether a client with the token permissions perms may
+// see e. Events that do not name an address need PermList in every
+// namespace.
+func eventAllowed(perms TokenPermissions, e *wallet.Event) bool {
+	if perms == nil {
+		return true
+	}
+	if e.Address == "" {
+		return perms.allows(AllNamespaces, PermList)
+	}
+	ns, err := fctWallet.Namespace(e.Address)
+	if err != nil {
+		return false
+	}
+	return perms.allows(ns, PermList)
+}
@@ -0,0 +1,85 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FactomProject/factom/wallet"
+)
+
+func TestHandleEventsAuth(t *testing.T) {
+	SetAPITokens(map[string]TokenPermissions{"secret": {AllNamespaces: PermList}})
+	defer SetAPITokens(nil)
+
+	for _, auth := range []string{"", "Bearer wrong"} {
+		r, _ := http.NewRequest("GET", "/v2/ws", nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		handleEvents(w, r)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("authorization %q got status %d", auth, w.Code)
+		}
+	}
+}
+
+func TestEventsOrigin(t *testing.T) {
+	defer func(o []string) { corsOrigins = o }(corsOrigins)
+	corsOrigins = []string{"https://app.example.com"}
+
+	for origin, allowed := range map[string]bool{
+		"":                         true,
+		"https://app.example.com":  true,
+		"https://evil.example.com": false,
+		"http://localhost:8089":    false,
+	} {
+		r, _ := http.NewRequest("GET", "/v2/ws", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		if got := eventsOriginAllowed(r); got != allowed {
+			t.Errorf("origin %q allowed: %v", origin, got)
+		}
+		if err := checkEventsOrigin(nil, r); (err == nil) != allowed {
+			t.Errorf("origin %q handshake: %v", origin, err)
+		}
+	}
+
+	// without CORS origins no browser may subscribe
+	corsOrigins = nil
+	r, _ := http.NewRequest("GET", "/v2/ws", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	if eventsOriginAllowed(r) {
+		t.Error("origin allowed without CORS origins")
+	}
+}
+
+func TestEventAllowed(t *testing.T) {
+	b := &permBackend{namespaces: map[string]string{"FA1": "ops", "FA2": "other"}}
+	defer func(w wallet.WalletBackend) { fctWallet = w }(fctWallet)
+	fctWallet = b
+
+	perms := TokenPermissions{"ops": PermList}
+	for _, c := range []struct {
+		perms   TokenPermissions
+		e       *wallet.Event
+		allowed bool
+	}{
+		{nil, &wallet.Event{Address: "FA2"}, true},
+		{perms, &wallet.Event{Address: "FA1"}, true},
+		{perms, &wallet.Event{Address: "FA2"}, false},
+		{perms, &wallet.Event{TxName: "rent"}, false},
+		{TokenPermissions{AllNamespaces: PermList}, &wallet.Event{TxName: "rent"}, true},
+		{TokenPermissions{"ops": PermSign}, &wallet.Event{Address: "FA1"}, false},
+	} {
+		if got := eventAllowed(c.perms, c.e); got != c.allowed {
+			t.Errorf("%v sees %+v: %v", c.perms, c.e, got)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+// shared secrets for HMAC signed requests, by key id
+var (
+	hmacLock    sync.RWMutex
+	hmacKeys    map[string][]byte
+	hmacMaxSkew = factom.DefaultHMACMaxSkew
+
+	// hmacNonces are the accepted key id and nonce pairs with the time
+	// until which they are refused
+	hmacNonces      = make(map[string]time.Time)
+	hmacNoncesSwept time.Time
+)
+
+// SetHMACKeys sets the shared secrets, by key id, that clients may use to sign
+// their requests with factom.SignRequest. A correctly signed request is
+// accepted without the rpc user and password. Passing nil turns request
+// signing off.
+func SetHMACKeys(keys map[string][]byte) {
+	hmacLock.Lock()
+	defer hmacLock.Unlock()
+
+	hmacKeys = make(map[string][]byte, len(keys))
+	for id, secret := range keys {
+		hmacKeys[id] = append([]byte(nil), secret...)
+	}
+}
+
+// SetHMACMaxSkew sets how far the timestamp of a signed request may be from
+// the wallet's clock.
+func SetHMACMaxSkew(d time.Duration) {
+	hmacLock.Lock()
+	defer hmacLock.Unlock()
+
+	hmacMaxSkew = d
+}
+
+func hmacEnabled() bool {
+	hmacLock.RLock()
+	defer hmacLock.RUnlock()
+	return len(hmacKeys) > 0
+}
+
+// checkRequestSignature verifies the HMAC signature of r if it has one. It
+// returns false if the request is not signed and should be checked with the
+// Authorization header instead.
+func checkRequestSignature(r *http.Request, body []byte) (bool, error) {
+	keyID := r.Header.Get(factom.HMACKeyIDHeader)
+	if keyID == "" && r.Header.Get(factom.HMACSignatureHeader) == "" {
+		return false, nil
+	}
+
+	hmacLock.RLock()
+	secret, ok := hmacKeys[keyID]
+	skew := hmacMaxSkew
+	hmacLock.RUnlock()
+	if !ok {
+		return true, ErrUnknownHMACKey
+	}
+	if err := factom.VerifyRequestSignature(r, body, secret, skew); err != nil {
+		return true, err
+	}
+	return true, useNonce(keyID, r.Header.Get(factom.HMACNonceHeader), skew)
+}
+
+// useNonce accepts the nonce of a signed request once. A request is valid
+// for skew on either side of its timestamp, so a nonce is remembered for
+// twice skew from now, after which the timestamp check refuses it.
+func useNonce(keyID, nonce string, skew time.Duration) error {
+	hmacLock.Lock()
+	defer hmacLock.Unlock()
+
+	now := time.Now()
+	if now.Sub(hmacNoncesSwept) > time.Minute {
+		for n, until := range hmacNonces {
+			if now.After(until) {
+				delete(hmacNonces, n)
+			}
+		}
+		hmacNoncesSwept = now
+	}
+
+	key := keyID + "\x00" + nonce
+	if until, ok := hmacNonces[key]; ok && now.Before(until) {
+		return ErrReplayedRequest
+	}
+	hmacNonces[key] = now.Add(2 * skew)
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestCheckRequestSignatureReplay(t *testing.T) {
+	secret := []byte("machine secret")
+	SetHMACKeys(map[string][]byte{"machine-1": secret})
+	defer SetHMACKeys(nil)
+
+	body := []byte(`{"jsonrpc": "2.0", "id": 0, "method": "send-factoid"}`)
+	r := httptest.NewRequest("POST", "/v2", bytes.NewReader(body))
+	factom.SignRequest(r, body, "machine-1", secret)
+
+	if signed, err := checkRequestSignature(r, body); !signed || err != nil {
+		t.Fatalf("signed %v, %v", signed, err)
+	}
+	if _, err := checkRequestSignature(r, body); !errors.Is(err, ErrReplayedRequest) {
+		t.Errorf("expected the replayed request to be refused, got %v", err)
+	}
+
+	// a new signature of the same body is a new request
+	r2 := httptest.NewRequest("POST", "/v2", bytes.NewReader(body))
+	factom.SignRequest(r2, body, "machine-1", secret)
+	if _, err := checkRequestSignature(r2, body); err != nil {
+		t.Error(err)
+	}
+
+	r3 := httptest.NewRequest("POST", "/v2", bytes.NewReader(body))
+	factom.SignRequest(r3, body, "machine-2", secret)
+	if _, err := checkRequestSignature(r3, body); !errors.Is(err, ErrUnknownHMACKey) {
+		t.Errorf("expected an unknown key to be refused, got %v", err)
+	}
+
+	// unsigned requests are left to the other checks
+	r4 := httptest.NewRequest("POST", "/v2", bytes.NewReader(body))
+	if signed, err := checkRequestSignature(r4, body); signed || err != nil {
+		t.Errorf("signed %v, %v", signed, err)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+// RequestLog is the record of one api call.
+type RequestLog struct {
+	Method     string
+	ID         interface{}
+	RemoteAddr string
+	Latency    time.Duration
+
+	// ErrorCode is the JSON-RPC error code of the response, or 0 if the
+	// call succeeded.
+	ErrorCode int
+
+	// Params are the params of the call with passphrases and secret keys
+	// replaced by "[redacted]".
+	Params json.RawMessage
+}
+
+// RequestLogger records api calls. It is called after every call, so a slow
+// logger slows down the api.
+type RequestLogger interface {
+	LogRequest(*RequestLog)
+}
+
+// RequestLoggerFunc adapts a function to a RequestLogger.
+type RequestLoggerFunc func(*RequestLog)
+
+func (f RequestLoggerFunc) LogRequest(l *RequestLog) {
+	f(l)
+}
+
+var (
+	loggerLock    sync.RWMutex
+	requestLogger RequestLogger
+)
+
+// SetRequestLogger sets the logger for api calls. Passing nil turns request
+// logging off.
+func SetRequestLogger(l RequestLogger) {
+	loggerLock.Lock()
+	defer loggerLock.Unlock()
+
+	requestLogger = l
+}
+
+// serveRequest records j in the audit log if it is audited, checks the
+// permissions for it and serves it, recording the call in the metrics and
+// with the RequestLogger if there is one.
+func serveRequest(ctx context.Context, j *factom.JSON2Request, perms TokenPermissions, remoteAddr string) (*factom.JSON2Response, *factom.JSONError) {
+	start := time.Now()
+
+	var (
+		resp      *factom.JSON2Response
+		jsonError *factom.JSONError
+	)
+	// audited calls are recorded before anything else, so that denied and
+	// failed attempts are in the log too, and a call that can not be
+	// recorded is refused
+	jsonError = audit(j.Method, j.Params)
+
+	// api tokens may only use the keys in their namespaces
+	if jsonError == nil && perms != nil {
+		jsonError = checkPermissions(perms, j)
+	} else if jsonError == nil && methodPermissions[j.Method] == PermApprove {
+		// approvals need an api token of their own and never come from the
+		// rpc credentials that sign
+		jsonError = newPermissionDeniedError()
+	}
+	if jsonError == nil {
+		resp, jsonError = handleV2Request(ctx, j)
+	}
+
+	code := 0
+	if jsonError != nil {
+		code = jsonError.Code
+	}
+	observeRequest(j.Method, code, time.Since(start))
+
+	loggerLock.RLock()
+	l := requestLogger
+	loggerLock.RUnlock()
+	if l != nil {
+		rl := &RequestLog{
+			Method:     j.Method,
+			ID:         j.ID,
+			RemoteAddr: remoteAddr,
+			Latency:    time.Since(start),
+			ErrorCode:  code,
+			Params:     redactParams(j.Params),
+		}
+		l.LogRequest(rl)
+	}
+
+	return resp, jsonError
+}
+
+// secretParams are the names of params that are always redacted.
+var secretParams = map[string]bool{
+	"passphrase":    true,
+	"oldpassphrase": true,
+	"newpassphrase": true,
+	"password":      true,
+	"secret":        true,
+	"seed":          true,
+	"mnemonic":      true,
+	"words":         true,
+}
+
+// redactParams returns a copy of params with secrets replaced.
+func redactParams(params json.RawMessage) json.RawMessage {
+	if len(params) == 0 {
+		return params
+	}
+	var v interface{}
+	if err := json.Unmarshal(params, &v); err != nil {
+		return json.RawMessage(`"[redacted]"`)
+	}
+	p, err := json.Marshal(redactValue("", v))
+	if err != nil {
+		return json.RawMessage(`"[redacted]"`)
+	}
+	return p
+}
+
+func redactValue(name string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = redactValue(k, e)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = redactValue(name, e)
+		}
+		return v
+	case string:
+		if secretParams[strings.ToLower(name)] || isSecretKey(v) {
+			return "[redacted]"
+		}
+	}
+	return v
+}
+
+// isSecretKey reports whether s is a human readable secret key.
+func isSecretKey(s string) bool {
+	return factom.AddressStringType(s) == factom.FactoidSec ||
+		factom.AddressStringType(s) == factom.ECSec ||
+		factom.IdentityKeyStringType(s) == factom.IDSec
+}
@@ -0,0 +1,82 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestRedactParams(t *testing.T) {
+	const (
+		fs = "Fs2GCfAa2HBKaGEUWCtw8eGDkN1CfyS6HhdgLv8783shkrCgvcpJ"
+		es = "Es2Rf7iM6PdsqfYCo3D1tnAR65SkLENyWJG1deUzpRMQmbh9F3eG"
+		fa = "FA2jK2HcLnRdS94dEcU27rF3meoJfpUcZPSinpb7AwQvPRY6RL1Q"
+	)
+	for _, c := range []struct {
+		method  string
+		params  string
+		secrets []string
+		kept    []string
+	}{
+		{"import-addresses", `{"addresses": [{"secret": "` + fs + `"}, {"secret": "` + es + `"}]}`, []string{fs, es}, nil},
+		{"import-identity-keys", `{"keys": [{"secret": "idsec-key"}]}`, []string{"idsec-key"}, nil},
+		{"import-koinify", `{"words": "yellow yellow yellow"}`, []string{"yellow"}, nil},
+		{"import-keystore", `{"keystore": {"version": 1}, "passphrase": "hunter2"}`, []string{"hunter2"}, []string{"version"}},
+		{"unlock-wallet", `{"passphrase": "hunter2", "timeout": 300}`, []string{"hunter2"}, []string{"300"}},
+		{"encrypt-wallet", `{"Passphrase": "hunter2"}`, []string{"hunter2"}, nil},
+		{"change-passphrase", `{"oldpassphrase": "hunter2", "newpassphrase": "hunter3"}`, []string{"hunter2", "hunter3"}, nil},
+		{"add-input", `{"tx-name": "t", "address": "` + fs + `", "amount": 5}`, []string{fs}, []string{`"t"`, "5"}},
+		{"add-input", `["t", "` + fs + `"]`, []string{fs}, []string{`"t"`}},
+		{"add-output", `{"tx-name": "t", "address": "` + fa + `"}`, nil, []string{fa}},
+	} {
+		p := string(redactParams(json.RawMessage(c.params)))
+		for _, s := range c.secrets {
+			if strings.Contains(p, s) {
+				t.Errorf("%s: %s is logged as %s", c.method, s, p)
+			}
+		}
+		for _, s := range c.kept {
+			if !strings.Contains(p, s) {
+				t.Errorf("%s: %s is missing from %s", c.method, s, p)
+			}
+		}
+	}
+
+	// params that can not be parsed are redacted as a whole
+	if p := string(redactParams(json.RawMessage(`{"passphrase": "hunter2"`))); p != `"[redacted]"` {
+		t.Errorf("bad params are logged as %s", p)
+	}
+	if p := redactParams(nil); p != nil {
+		t.Errorf("empty params are logged as %s", p)
+	}
+}
+
+func TestRequestLogRedacted(t *testing.T) {
+	var logged []*RequestLog
+	SetRequestLogger(RequestLoggerFunc(func(l *RequestLog) { logged = append(logged, l) }))
+	defer SetRequestLogger(nil)
+
+	// the call is denied, and logged all the same
+	j := factom.NewJSON2Request("unlock-wallet", 1, map[string]interface{}{"passphrase": "hunter2", "timeout": 300})
+	perms := TokenPermissions{"ops": PermList}
+	if _, jsonError := serveRequest(context.Background(), j, perms, "127.0.0.1:1234"); jsonError == nil {
+		t.Fatal("unlock-wallet was not denied")
+	}
+	if len(logged) != 1 {
+		t.Fatalf("%d calls logged", len(logged))
+	}
+	l := logged[0]
+	if l.Method != "unlock-wallet" || l.RemoteAddr != "127.0.0.1:1234" || l.ErrorCode != newPermissionDeniedError().Code {
+		t.Errorf("logged %+v", l)
+	}
+	if strings.Contains(string(l.Params), "hunter2") || !strings.Contains(string(l.Params), "[redacted]") {
+		t.Errorf("params are logged as %s", l.Params)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/FactomProject/factom/wallet"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "factom",
+		Subsystem: "wallet_api",
+		Name:      "requests_total",
+		Help:      "Wallet api calls by method and JSON-RPC error code, 0 for success.",
+	}, []string{"method", "code"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "factom",
+		Subsystem: "wallet_api",
+		Name:      "request_duration_seconds",
+		Help:      "Time taken to serve wallet api calls by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "factom",
+		Subsystem: "wallet_api",
+		Name:      "db_operation_duration_seconds",
+		Help:      "Time taken by wallet database operations.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"op"})
+
+	dbErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "factom",
+		Subsystem: "wallet_api",
+		Name:      "db_errors_total",
+		Help:      "Failed wallet database operations.",
+	}, []string{"op"})
+
+	factomdFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "factom",
+		Subsystem: "wallet_api",
+		Name:      "factomd_failures_total",
+		Help:      "Failed calls to factomd made while serving wallet api calls, by api method.",
+	}, []string{"method"})
+)
+
+var (
+	metricsOnce sync.Once
+	metricsOn   bool
+)
+
+// Collectors returns the metrics of the wallet api so that they can be added
+// to a prometheus registry. They are only updated once metrics are enabled
+// with WithMetrics.
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		requestsTotal,
+		requestDuration,
+		dbDuration,
+		dbErrors,
+		factomdFailures,
+	}
+}
+
+// enableMetrics starts recording metrics, including the database timings of
+// w if it can report them, and registers them with the default prometheus
+// registry.
+func enableMetrics(w wallet.WalletBackend) {
+	metricsOnce.Do(func() {
+		prometheus.MustRegister(Collectors()...)
+	})
+	metricsOn = true
+
+	if o, ok := w.(interface{ ObserveDB(wallet.DBObserver) }); ok {
+		o.ObserveDB(func(op string, d time.Duration, err error) {
+			dbDuration.WithLabelValues(op).Observe(d.Seconds())
+			if err != nil {
+				dbErrors.WithLabelValues(op).Inc()
+			}
+		})
+	}
+}
+
+// methodLabel is the method label of a call to method. Clients choose the
+// method names they send, so names that are not api methods share one label
+// instead of adding a series each.
+func methodLabel(method string) string {
+	if !knownMethod(method) {
+		return "unknown"
+	}
+	return method
+}
+
+// observeRequest records a served api call.
+func observeRequest(method string, code int, d time.Duration) {
+	if !metricsOn {
+		return
+	}
+	method = methodLabel(method)
+	requestsTotal.WithLabelValues(method, strconv.Itoa(code)).Inc()
+	requestDuration.WithLabelValues(method).Observe(d.Seconds())
+}
+
+// factomdFailed records a failed call to factomd.
+func factomdFailed(method string) {
+	if metricsOn {
+		factomdFailures.WithLabelValues(methodLabel(method)).Inc()
+	}
+}
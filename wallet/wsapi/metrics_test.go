@@ -0,0 +1,26 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"testing"
+)
+
+func TestMethodLabel(t *testing.T) {
+	for method, label := range map[string]string{
+		"properties":          "properties",
+		"sign-transaction":    "sign-transaction",
+		"unlock-wallet":       "unlock-wallet",
+		"set-address-label":   "set-address-label",
+		"no-such-method":      "unknown",
+		"a3f9c1e0-random":     "unknown",
+		"":                    "unknown",
+		"properties\x00extra": "unknown",
+	} {
+		if got := methodLabel(method); got != label {
+			t.Errorf("method %q has label %q, want %q", method, got, label)
+		}
+	}
+}
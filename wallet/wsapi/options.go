@@ -0,0 +1,176 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"log"
+	"net"
+
+	"github.com/FactomProject/factom"
+)
+
+type startOptions struct {
+	rpcUser  string
+	rpcPass  string
+	tokens   map[string]TokenPermissions
+	hmacKeys map[string][]byte
+	allow    []string
+	deny     []string
+
+	corsOrigins []string
+	logger      RequestLogger
+	metrics     bool
+
+	limits    bool
+	global    RateLimit
+	perClient RateLimit
+
+	tls       bool
+	tlsCert   string
+	tlsKey    string
+	clientCAs string
+
+	noSecrets bool
+
+	factomdRetry *factom.RetryPolicy
+}
+
+// Option configures the api server started with Start.
+type Option func(*startOptions)
+
+// WithRPCCredentials sets the user and password for HTTP basic
+// authentication, in place of the ones in the RPCConfig passed to Start.
+func WithRPCCredentials(user, password string) Option {
+	return func(o *startOptions) {
+		o.rpcUser = user
+		o.rpcPass = password
+	}
+}
+
+// WithAPIToken adds a bearer token that may use every method with every key.
+func WithAPIToken(token string) Option {
+	return WithAPITokens(map[string]TokenPermissions{
+		token: {AllNamespaces: PermAll},
+	})
+}
+
+// WithAPITokens adds bearer tokens with their permissions. See SetAPITokens.
+func WithAPITokens(tokens map[string]TokenPermissions) Option {
+	return func(o *startOptions) {
+		if o.tokens == nil {
+			o.tokens = make(map[string]TokenPermissions)
+		}
+		for t, perms := range tokens {
+			o.tokens[t] = perms
+		}
+	}
+}
+
+// WithHMACKeys sets the shared secrets for signed requests. See SetHMACKeys.
+func WithHMACKeys(keys map[string][]byte) Option {
+	return func(o *startOptions) {
+		o.hmacKeys = keys
+	}
+}
+
+// WithMethodPolicy sets which methods are served. See SetMethodPolicy.
+func WithMethodPolicy(allow, deny []string) Option {
+	return func(o *startOptions) {
+		o.allow = allow
+		o.deny = deny
+	}
+}
+
+// WithCORS lets browser pages from the given origins call the api. An origin
+// of "*" allows every origin. The origins are added to the ones in the
+// RPCConfig passed to Start.
+func WithCORS(origins ...string) Option {
+	return func(o *startOptions) {
+		o.corsOrigins = append(o.corsOrigins, origins...)
+	}
+}
+
+// WithRequestLogger records every api call with l. See SetRequestLogger.
+func WithRequestLogger(l RequestLogger) Option {
+	return func(o *startOptions) {
+		o.logger = l
+	}
+}
+
+// WithMetrics records prometheus metrics for the api and the wallet database
+// and serves them on /metrics. See Collectors.
+func WithMetrics() Option {
+	return func(o *startOptions) {
+		o.metrics = true
+	}
+}
+
+// WithRateLimits limits the request rate. See SetRateLimits.
+func WithRateLimits(global, perClient RateLimit) Option {
+	return func(o *startOptions) {
+		o.limits = true
+		o.global = global
+		o.perClient = perClient
+	}
+}
+
+// WithTLS serves the api over TLS with the certificate and key in certFile
+// and keyFile, in place of the files in the RPCConfig passed to Start. A self
+// signed pair is generated if neither file exists. The files are read again
+// when the process receives SIGHUP.
+func WithTLS(certFile, keyFile string) Option {
+	return func(o *startOptions) {
+		o.tls = true
+		o.tlsCert = certFile
+		o.tlsKey = keyFile
+	}
+}
+
+// WithClientCAs requires api clients to present a certificate signed by one
+// of the CAs in the PEM file caFile. It only has an effect with TLS enabled.
+// The file is read again when the process receives SIGHUP.
+func WithClientCAs(caFile string) Option {
+	return func(o *startOptions) {
+		o.clientCAs = caFile
+	}
+}
+
+// WithNoSecrets leaves secret keys out of address responses unless they are
+// asked for. See SetNoSecrets.
+func WithNoSecrets() Option {
+	return func(o *startOptions) {
+		o.noSecrets = true
+	}
+}
+
+// WithFactomdRetry retries the calls the api makes to factomd, such as the
+// rate lookup of add-fee, according to p. See factom.SetFactomdRetryPolicy.
+func WithFactomdRetry(p *factom.RetryPolicy) Option {
+	return func(o *startOptions) {
+		o.factomdRetry = p
+	}
+}
+
+// isLoopback reports whether the listen address addr only accepts
+// connections from the local host.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// warnIfOpen logs a warning if the server listens on a public address
+// without any form of authentication.
+func warnIfOpen(addr string) {
+	if rpcUser == "" && !tokensEnabled() && !hmacEnabled() && !isLoopback(addr) {
+		log.Printf("warning: the wallet api on %s accepts requests without authentication", addr)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"sync"
+)
+
+// methods the operator has turned on or off
+var (
+	policyLock     sync.RWMutex
+	allowedMethods map[string]bool
+	deniedMethods  map[string]bool
+)
+
+// SetMethodPolicy sets which api methods the server will serve. If allow is
+// not empty only the methods in it are served. Methods in deny are never
+// served, even if they are also in allow. Refused methods get a "Method
+// disabled" error. Passing nil for both serves every method.
+func SetMethodPolicy(allow, deny []string) {
+	policyLock.Lock()
+	defer policyLock.Unlock()
+
+	allowedMethods = nil
+	if len(allow) > 0 {
+		allowedMethods = make(map[string]bool, len(allow))
+		for _, m := range allow {
+			allowedMethods[m] = true
+		}
+	}
+	deniedMethods = make(map[string]bool, len(deny))
+	for _, m := range deny {
+		deniedMethods[m] = true
+	}
+}
+
+// methodEnabled reports whether the method policy lets method be served.
+func methodEnabled(method string) bool {
+	policyLock.RLock()
+	defer policyLock.RUnlock()
+
+	if deniedMethods[method] {
+		return false
+	}
+	return allowedMethods == nil || allowedMethods[method]
+}
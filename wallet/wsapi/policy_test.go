@@ -0,0 +1,42 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestMethodPolicy(t *testing.T) {
+	defer SetMethodPolicy(nil, nil)
+
+	for _, c := range []struct {
+		allow, deny []string
+		enabled     map[string]bool
+	}{
+		{nil, nil, map[string]bool{"properties": true, "sign-data": true}},
+		{nil, []string{"sign-data"}, map[string]bool{"properties": true, "sign-data": false}},
+		{[]string{"properties"}, nil, map[string]bool{"properties": true, "sign-data": false}},
+		// deny wins over allow
+		{[]string{"properties", "sign-data"}, []string{"sign-data"}, map[string]bool{"properties": true, "sign-data": false}},
+	} {
+		SetMethodPolicy(c.allow, c.deny)
+		for method, enabled := range c.enabled {
+			if got := methodEnabled(method); got != enabled {
+				t.Errorf("allow %v deny %v: %s enabled %v", c.allow, c.deny, method, got)
+			}
+		}
+	}
+
+	// a disabled method is refused before the wallet is used
+	SetMethodPolicy(nil, []string{"properties"})
+	j := factom.NewJSON2Request("properties", 0, nil)
+	if _, jsonError := handleV2Request(context.Background(), j); jsonError == nil ||
+		jsonError.Code != newMethodDisabledError().Code {
+		t.Errorf("expected the method to be disabled, got %v", jsonError)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"math"
+	"net"
+	"sync"
+	"time"
+)
+
+// idleBucketTimeout is how long the bucket of a client that sends no requests
+// is kept.
+const idleBucketTimeout = 10 * time.Minute
+
+// RateLimit is a token bucket limit: Burst requests may be made at once and
+// the bucket refills at Rate requests per second. A zero Rate means no limit.
+// A Burst below 1 is taken as 1.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take removes a token from the bucket if there is one. Otherwise it returns
+// the time until the next token.
+func (b *bucket) take(l RateLimit, now time.Time) (bool, time.Duration) {
+	b.tokens = math.Min(float64(l.Burst), b.tokens+now.Sub(b.last).Seconds()*l.Rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / l.Rate * float64(time.Second))
+}
+
+var (
+	limitLock       sync.Mutex
+	globalLimit     RateLimit
+	clientLimit     RateLimit
+	globalBucket    *bucket
+	clientBuckets   map[string]*bucket
+	lastBucketSweep time.Time
+)
+
+// SetRateLimits limits the requests served for all clients together and for
+// each client address. Requests over a limit are refused with a "Rate limit
+// exceeded" error that says when to retry. A zero RateLimit turns that limit
+// off.
+func SetRateLimits(global, perClient RateLimit) {
+	limitLock.Lock()
+	defer limitLock.Unlock()
+
+	// a bucket must hold at least one request
+	if global.Burst < 1 {
+		global.Burst = 1
+	}
+	if perClient.Burst < 1 {
+		perClient.Burst = 1
+	}
+
+	now := time.Now()
+	globalLimit = global
+	clientLimit = perClient
+	globalBucket = &bucket{tokens: float64(global.Burst), last: now}
+	clientBuckets = make(map[string]*bucket)
+	lastBucketSweep = now
+}
+
+// checkRateLimit takes a token for a request from remoteAddr. It returns
+// false and the time to wait if a limit has been reached.
+func checkRateLimit(remoteAddr string) (bool, time.Duration) {
+	limitLock.Lock()
+	defer limitLock.Unlock()
+
+	now := time.Now()
+	if clientLimit.Rate > 0 {
+		host, _, err := net.SplitHostPort(remoteAddr)
+		if err != nil {
+			host = remoteAddr
+		}
+		b, ok := clientBuckets[host]
+		if !ok {
+			b = &bucket{tokens: float64(clientLimit.Burst), last: now}
+			clientBuckets[host] = b
+		}
+		if ok, wait := b.take(clientLimit, now); !ok {
+			return false, wait
+		}
+		sweepBuckets(now)
+	}
+	if globalLimit.Rate > 0 {
+		if ok, wait := globalBucket.take(globalLimit, now); !ok {
+			return false, wait
+		}
+	}
+	return true, 0
+}
+
+// sweepBuckets forgets clients that have been idle for a while.
+func sweepBuckets(now time.Time) {
+	if now.Sub(lastBucketSweep) < idleBucketTimeout {
+		return
+	}
+	for host, b := range clientBuckets {
+		if now.Sub(b.last) > idleBucketTimeout {
+			delete(clientBuckets, host)
+		}
+	}
+	lastBucketSweep = now
+}
@@ -0,0 +1,35 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestBatchRateLimit(t *testing.T) {
+	SetRateLimits(RateLimit{}, RateLimit{Rate: 0.001, Burst: 3})
+	defer SetRateLimits(RateLimit{}, RateLimit{})
+
+	// the http request of the batch pays for its first request
+	if ok, _ := checkRateLimit("10.0.0.1:1000"); !ok {
+		t.Fatal("first request was limited")
+	}
+
+	raw := json.RawMessage(`{"jsonrpc": "2.0", "id": 0, "method": "wallet-balances"}`)
+	for i := 0; i < 4; i++ {
+		resp := handleBatchRequest(context.Background(), raw, TokenPermissions{}, "10.0.0.1:1000", i > 0)
+		limited := resp.Error != nil && resp.Error.Code == newRateLimitedError(0).Code
+		if limited != (i == 3) {
+			t.Errorf("request %d limited: %v, %v", i, limited, resp.Error)
+		}
+	}
+
+	// other clients have their own limit
+	if ok, _ := checkRateLimit("10.0.0.2:1000"); !ok {
+		t.Error("another client was limited")
+	}
+}
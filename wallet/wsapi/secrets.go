@@ -0,0 +1,38 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"sync"
+)
+
+// whether address responses leave out secret keys by default
+var (
+	secretsLock sync.RWMutex
+	noSecrets   bool
+)
+
+// SetNoSecrets sets whether the address and all-addresses methods leave out
+// the secret keys of the addresses unless a request asks for them with the
+// secrets param. Keeping secrets out of responses by default keeps them out
+// of client logs and UIs that only need the public addresses.
+func SetNoSecrets(on bool) {
+	secretsLock.Lock()
+	defer secretsLock.Unlock()
+
+	noSecrets = on
+}
+
+// includeSecrets reports whether a response should include secret keys.
+// asked is the secrets param of the request, or nil if it has none.
+func includeSecrets(asked *bool) bool {
+	if asked != nil {
+		return *asked
+	}
+
+	secretsLock.RLock()
+	defer secretsLock.RUnlock()
+	return !noSecrets
+}
@@ -0,0 +1,72 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"testing"
+
+	"github.com/FactomProject/factom/wallet"
+)
+
+func TestNoSecrets(t *testing.T) {
+	b := newAddrBackend(t)
+	defer func(w wallet.WalletBackend) { fctWallet = w }(fctWallet)
+	fctWallet = b
+	defer SetNoSecrets(false)
+	SetNoSecrets(true)
+
+	export := TokenPermissions{"ops": PermList | PermExport}
+	list := TokenPermissions{"ops": PermList}
+	admin := TokenPermissions{AllNamespaces: PermAll}
+	addr := `"address": "` + b.fa.String() + `"`
+	for _, c := range []struct {
+		perms   TokenPermissions
+		method  string
+		params  string
+		secrets bool
+		denied  bool
+	}{
+		// secrets are left out unless they are asked for
+		{export, "address", `{` + addr + `}`, false, false},
+		{export, "address", `{` + addr + `, "secrets": false}`, false, false},
+		{export, "address", `{` + addr + `, "secrets": true}`, true, false},
+		{admin, "all-addresses", `{}`, false, false},
+		{admin, "all-addresses", `{"secrets": true}`, true, false},
+		{nil, "all-addresses", ``, false, false},
+
+		// keys can only be read with Export permission, and all of them only
+		// with PermAll
+		{list, "address", `{` + addr + `, "secrets": true}`, false, true},
+		{list, "address", `{` + addr + `}`, false, true},
+		{export, "all-addresses", `{"secrets": true}`, false, true},
+	} {
+		as, jsonError := callAddresses(t, c.perms, c.method, c.params)
+		if c.denied {
+			if jsonError == nil || jsonError.Code != newPermissionDeniedError().Code {
+				t.Errorf("%s %s with %v not denied: %v", c.method, c.params, c.perms, jsonError)
+			}
+			continue
+		}
+		if jsonError != nil {
+			t.Fatalf("%s %s with %v: %v", c.method, c.params, c.perms, jsonError)
+		}
+		for _, a := range as {
+			if (a.Secret != "") != c.secrets {
+				t.Errorf("%s %s: %s has secret %q", c.method, c.params, a.Public, a.Secret)
+			}
+		}
+	}
+
+	// without the server setting secrets are included unless left out
+	SetNoSecrets(false)
+	as, jsonError := callAddresses(t, export, "address", `{`+addr+`}`)
+	if jsonError != nil || as[0].Secret != b.fa.SecString() {
+		t.Errorf("address without no-secrets: %v, %v", as, jsonError)
+	}
+	as, jsonError = callAddresses(t, export, "address", `{`+addr+`, "secrets": false}`)
+	if jsonError != nil || as[0].Secret != "" {
+		t.Errorf("address with secrets false: %v, %v", as, jsonError)
+	}
+}
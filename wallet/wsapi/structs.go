@@ -6,6 +6,7 @@ package wsapi
 
 import (
 	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factom/wallet"
 )
 
 type TLSConfig struct {
@@ -21,8 +22,32 @@ type passphraseRequest struct {
 	Timeout  int64  `json:"timeout"`
 }
 
+type changePassphraseRequest struct {
+	OldPassphrase string `json:"oldpassphrase"`
+	NewPassphrase string `json:"newpassphrase"`
+}
+
 type addressRequest struct {
+	Address  string `json:"address"`
+	Balances bool   `json:"balances,omitempty"`
+
+	// Secrets asks for the secret key to be included or left out. When it
+	// is not given the server default set with SetNoSecrets is used.
+	Secrets *bool `json:"secrets,omitempty"`
+}
+
+type allAddressesRequest struct {
+	Balances bool  `json:"balances"`
+	Secrets  *bool `json:"secrets,omitempty"`
+}
+
+type removeAddressRequest struct {
 	Address string `json:"address"`
+	Backup  bool   `json:"backup,omitempty"`
+}
+
+type generateAddressRequest struct {
+	Count *int `json:"count"`
 }
 
 type addressesRequest struct {
@@ -31,10 +56,22 @@ type addressesRequest struct {
 
 type importRequest struct {
 	Addresses []struct {
-		Secret string `json:"secret"`
+		Secret    string `json:"secret"`
+		Address   string `json:"address"`
+		PublicKey string `json:"publickey"`
 	} `json:addresses`
 }
 
+type exportKeystoreRequest struct {
+	Address    string `json:"address"`
+	Passphrase string `json:"passphrase"`
+}
+
+type importKeystoreRequest struct {
+	Keystore   *wallet.Keystore `json:"keystore"`
+	Passphrase string           `json:"passphrase"`
+}
+
 type importKoinifyRequest struct {
 	Words string `json:"words"`
 }
@@ -42,17 +79,59 @@ type importKoinifyRequest struct {
 type transactionRequest struct {
 	Name  string `json:"tx-name"`
 	Force bool   `json:"force"`
+
+	// Transaction makes sign-transaction sign, or approve-transaction
+	// approve, this exported transaction instead of a tmp transaction of the
+	// wallet.
+	Transaction string `json:"transaction,omitempty"`
+}
+
+type sendFactoidRequest struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Amount amount `json:"amount"`
+	Force  bool   `json:"force"`
+}
+
+type buyECRequest struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	ECs   uint64 `json:"ecs"`
+	Force bool   `json:"force"`
+}
+
+type simulateFeesRequest struct {
+	Name  string   `json:"tx-name"`
+	Rates []uint64 `json:"rates"`
+}
+
+// transactionOutputsRequest adds many outputs to a transaction at once.
+type transactionOutputsRequest struct {
+	Name    string           `json:"tx-name"`
+	Outputs []*outputRequest `json:"outputs"`
+}
+
+type outputRequest struct {
+	Address string `json:"address"`
+	Amount  amount `json:"amount"`
 }
 
 type transactionValueRequest struct {
 	Name    string `json:"tx-name"`
 	Address string `json:"address"`
-	Amount  uint64 `json:"amount"`
+	Amount  amount `json:"amount"`
+
+	// AutoFund makes add-input select the inputs with the named
+	// wallet.SelectionStrategy instead of adding Address.
+	AutoFund string `json:"auto-fund,omitempty"`
 }
 
 type transactionAddressRequest struct {
 	Name    string `json:"tx-name"`
 	Address string `json:"address"`
+
+	// Auto makes sub-fee choose the output itself instead of Address.
+	Auto bool `json:"auto,omitempty"`
 }
 
 type txdbRequest struct {
@@ -64,6 +143,14 @@ type txdbRequest struct {
 	} `json:"range,omitempty"`
 }
 
+type historyRequest struct {
+	Address string `json:"address,omitempty"`
+	Since   int64  `json:"since,omitempty"`
+	Until   int64  `json:"until,omitempty"`
+	Offset  int    `json:"offset"`
+	Limit   int    `json:"limit"`
+}
+
 type entryRequest struct {
 	Entry factom.Entry `json:"entry"`
 	ECPub string       `json:"ecpub"`
@@ -126,11 +213,81 @@ type identityAttributeEndorsementRequest struct {
 	Force              bool   `json:"force"`
 }
 
+type signDataRequest struct {
+	Public string `json:"public"`
+	Data   string `json:"data"`
+}
+
+type addressLabelRequest struct {
+	Address string `json:"address"`
+	Label   string `json:"label"`
+}
+
+type derivationPathsRequest struct {
+	FCTPath string `json:"fctpath"`
+	ECPath  string `json:"ecpath"`
+}
+
+type contactRequest struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type derivationPathsResponse struct {
+	FCTPath string `json:"fctpath"`
+	ECPath  string `json:"ecpath"`
+}
+
+type contactsResponse struct {
+	Contacts []*contactResponse `json:"contacts"`
+}
+
+type contactResponse struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type namespaceRequest struct {
+	Public    string `json:"public"`
+	Namespace string `json:"namespace"`
+}
+
+type bookmarkRequest struct {
+	ChainID string `json:"chainid"`
+	Label   string `json:"label"`
+}
+
+type bookmarkEntriesRequest struct {
+	ChainID string `json:"chainid"`
+	Limit   int    `json:"limit"`
+	Token   string `json:"token"`
+}
+
+type auditLogRequest struct {
+	Start uint64 `json:"start"`
+	Limit int    `json:"limit"`
+}
+
 // responses
 
 type addressResponse struct {
+	Public    string `json:"public"`
+	Secret    string `json:"secret,omitempty"`
+	Label     string `json:"label,omitempty"`
+	WatchOnly bool   `json:"watchonly,omitempty"`
+	Signer    bool   `json:"signer,omitempty"`
+
+	// Balance is the balance in factoshis or entry credits, when asked for.
+	Balance *int64 `json:"balance,omitempty"`
+}
+
+type publicResponse struct {
 	Public string `json:"public"`
-	Secret string `json:"secret"`
+}
+
+type addressLabelResponse struct {
+	Address string `json:"address"`
+	Label   string `json:"label"`
 }
 
 type multiAddressResponse struct {
@@ -158,15 +315,53 @@ type walletBackupResponse struct {
 	Seed         string                 `json:"wallet-seed"`
 	Addresses    []*addressResponse     `json:"addresses"`
 	IdentityKeys []*identityKeyResponse `json:"identity-keys"`
+	Metadata     *wallet.Metadata       `json:"metadata,omitempty"`
 }
 
 type multiTransactionResponse struct {
 	Transactions []*factom.Transaction `json:"transactions"`
 }
 
+type historyResponse struct {
+	Transactions []*factom.Transaction `json:"transactions"`
+	Total        int                   `json:"total"`
+}
+
+type simulateFeesResponse struct {
+	Name string                `json:"tx-name"`
+	Fees []*wallet.FeeEstimate `json:"fees"`
+}
+
+// exportedTransaction is a tmp transaction in the portable form of
+// wallet.ExportTransaction.
+type exportedTransaction struct {
+	Name        string `json:"tx-name"`
+	Transaction string `json:"transaction"`
+}
+
+// transactionNote is the note of a tmp transaction, by name, or of a sent
+// transaction, by id.
+type transactionNote struct {
+	Name string            `json:"tx-name,omitempty"`
+	TxID string            `json:"txid,omitempty"`
+	Memo string            `json:"memo,omitempty"`
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+type estimateFeeResponse struct {
+	Name string `json:"tx-name"`
+	Rate uint64 `json:"rate"`
+	Fee  uint64 `json:"fee"`
+}
+
 type propertiesResponse struct {
-	WalletVersion    string `json:"walletversion"`
-	WalletApiVersion string `json:"walletapiversion"`
+	WalletVersion    string             `json:"walletversion"`
+	WalletApiVersion string             `json:"walletapiversion"`
+	Storage          wallet.StorageInfo `json:"storage"`
+	Locked           bool               `json:"locked"`
+	ReadOnly         bool               `json:"readonly"`
+	FactomdReachable bool               `json:"factomdreachable"`
+	FactomdError     string             `json:"factomderror,omitempty"`
 }
 
 type simpleResponse struct {
@@ -202,6 +397,35 @@ type activeIdentityKeysResponse struct {
 	Keys    []string `json:"keys"`
 }
 
+type signDataResponse struct {
+	PublicKey string `json:"publickey"`
+	Signature string `json:"signature"`
+}
+
+type namespacesResponse struct {
+	Namespaces map[string]string `json:"namespaces"`
+}
+
+type bookmarkResponse struct {
+	ChainID string `json:"chainid"`
+	Label   string `json:"label"`
+}
+
+type multiBookmarkResponse struct {
+	Bookmarks []*bookmarkResponse `json:"bookmarks"`
+}
+
+type bookmarkEntriesResponse struct {
+	ChainID   string          `json:"chainid"`
+	Label     string          `json:"label"`
+	Entries   []*factom.Entry `json:"entries"`
+	NextToken string          `json:"nexttoken,omitempty"`
+}
+
+type auditLogResponse struct {
+	Entries []*wallet.AuditEntry `json:"entries"`
+}
+
 // Helper structs
 
 type UnmarBody struct {
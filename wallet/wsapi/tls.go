@@ -0,0 +1,129 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// the reloader of the running TLS server, if any
+var tlsReloader *certReloader
+
+// certReloader serves the TLS certificate and client CAs from files that are
+// read again when the process receives SIGHUP, so that certificates can be
+// renewed without restarting the wallet.
+type certReloader struct {
+	certFile  string
+	keyFile   string
+	clientCAs string
+
+	lock sync.RWMutex
+	cert *tls.Certificate
+	pool *x509.CertPool
+
+	sighup chan os.Signal
+	quit   chan struct{}
+}
+
+func newCertReloader(certFile, keyFile, clientCAs string) (*certReloader, error) {
+	r := &certReloader{
+		certFile:  certFile,
+		keyFile:   keyFile,
+		clientCAs: clientCAs,
+		quit:      make(chan struct{}),
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload reads the certificate files. The files in use are kept if any of
+// them can not be read.
+func (r *certReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	var pool *x509.CertPool
+	if r.clientCAs != "" {
+		pem, err := ioutil.ReadFile(r.clientCAs)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in %s", r.clientCAs)
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.cert = &cert
+	r.pool = pool
+	return nil
+}
+
+// config returns a TLS config that always uses the latest certificates.
+func (r *certReloader) config() *tls.Config {
+	base := &tls.Config{MinVersion: tls.VersionTLS12}
+	if r.clientCAs != "" {
+		base.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	c := base.Clone()
+	c.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.lock.RLock()
+		defer r.lock.RUnlock()
+
+		cc := base.Clone()
+		cc.Certificates = []tls.Certificate{*r.cert}
+		cc.ClientCAs = r.pool
+		return cc, nil
+	}
+	// tls.Listen needs a certificate source on the outer config as well
+	c.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		r.lock.RLock()
+		defer r.lock.RUnlock()
+		return r.cert, nil
+	}
+	return c
+}
+
+// watch reloads the certificates on SIGHUP until stop is called.
+func (r *certReloader) watch() {
+	r.sighup = make(chan os.Signal, 1)
+	signal.Notify(r.sighup, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-r.sighup:
+				if err := r.reload(); err != nil {
+					log.Printf("keeping the current TLS certificates: %v", err)
+					continue
+				}
+				log.Printf("reloaded TLS certificates from %s", r.certFile)
+			case <-r.quit:
+				return
+			}
+		}
+	}()
+}
+
+func (r *certReloader) stop() {
+	if r.sighup != nil {
+		signal.Stop(r.sighup)
+	}
+	close(r.quit)
+}
@@ -0,0 +1,323 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factom/wallet"
+	"github.com/FactomProject/factomd/common/primitives"
+)
+
+// Permission is a set of things an api token may do with the keys in a
+// wallet namespace.
+type Permission uint8
+
+const (
+	// PermList allows listing transactions and balances.
+	PermList Permission = 1 << iota
+	// PermSign allows using keys to sign transactions, chains and entries.
+	PermSign
+	// PermExport allows reading private keys.
+	PermExport
+	// PermApprove allows approving transactions above the approval
+	// threshold of the wallet. It is not part of PermAll so that approvals
+	// come from a credential of their own.
+	PermApprove
+
+	PermAll = PermList | PermSign | PermExport
+)
+
+// AllNamespaces can be used as a namespace in TokenPermissions to grant a
+// permission in every namespace.
+const AllNamespaces = "*"
+
+// TokenPermissions holds the permissions of an api token by wallet namespace.
+// Methods that manage the wallet itself, such as generating or importing
+// keys, need PermAll in AllNamespaces.
+type TokenPermissions map[string]Permission
+
+// allows reports whether p grants perm in namespace.
+func (p TokenPermissions) allows(namespace string, perm Permission) bool {
+	return (p[namespace]|p[AllNamespaces])&perm == perm
+}
+
+type apiToken struct {
+	hash  []byte
+	perms TokenPermissions
+}
+
+var (
+	tokenLock sync.RWMutex
+	apiTokens []apiToken
+)
+
+// SetAPITokens sets the bearer tokens api clients may authenticate with and
+// their permissions. Once tokens are set, requests must carry a token or the
+// rpc user and password. Passing nil removes every token.
+func SetAPITokens(tokens map[string]TokenPermissions) {
+	tokenLock.Lock()
+	defer tokenLock.Unlock()
+
+	apiTokens = nil
+	for t, perms := range tokens {
+		h := sha256.Sum256([]byte(t))
+		apiTokens = append(apiTokens, apiToken{hash: h[:], perms: perms})
+	}
+}
+
+// checkBearerToken looks up the bearer token of r. It returns false if r has
+// no bearer token.
+func checkBearerToken(r *http.Request) (TokenPermissions, bool, error) {
+	authhdr := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authhdr, "Bearer ") {
+		return nil, false, nil
+	}
+
+	// compare hashes of every token so the time taken does not depend on
+	// which token, if any, matched
+	h := sha256.Sum256([]byte(strings.TrimPrefix(authhdr, "Bearer ")))
+
+	tokenLock.RLock()
+	defer tokenLock.RUnlock()
+
+	var perms TokenPermissions
+	for _, t := range apiTokens {
+		if subtle.ConstantTimeCompare(h[:], t.hash) == 1 {
+			perms = t.perms
+		}
+	}
+	if perms == nil {
+		return nil, true, ErrBadAuth
+	}
+	return perms, true, nil
+}
+
+func tokensEnabled() bool {
+	tokenLock.RLock()
+	defer tokenLock.RUnlock()
+	return len(apiTokens) > 0
+}
+
+// authenticate checks the credentials of r. It returns the permissions of
+// the api token used, or nil if the caller may use every method.
+func authenticate(r *http.Request, body []byte) (TokenPermissions, error) {
+	if signed, err := checkRequestSignature(r, body); signed {
+		return nil, err
+	}
+	if perms, ok, err := checkBearerToken(r); ok {
+		return perms, err
+	}
+	if rpcUser == "" && (tokensEnabled() || hmacEnabled()) {
+		return nil, ErrNoAuth
+	}
+	return nil, checkAuthHeader(r)
+}
+
+type callerKey struct{}
+
+// callerID names the credential r was authenticated with, so that approvals
+// can be told apart from signatures: api tokens by a prefix of their hash,
+// signing keys by their key id, and the rpc user and password as "rpc".
+func callerID(r *http.Request) string {
+	if keyID := r.Header.Get(factom.HMACKeyIDHeader); keyID != "" {
+		return "hmac:" + keyID
+	}
+	if authhdr := r.Header.Get("Authorization"); strings.HasPrefix(authhdr, "Bearer ") {
+		h := sha256.Sum256([]byte(strings.TrimPrefix(authhdr, "Bearer ")))
+		return "token:" + hex.EncodeToString(h[:8])
+	}
+	return "rpc"
+}
+
+func withCaller(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, callerKey{}, id)
+}
+
+// caller returns the credential of the request served with ctx, see callerID.
+func caller(ctx context.Context) string {
+	id, _ := ctx.Value(callerKey{}).(string)
+	return id
+}
+
+// methodPermissions is the permission each method needs in the namespaces of
+// the keys named in its params. Methods that need PermAll manage the wallet
+// itself and need it in AllNamespaces, as do the methods that are not listed.
+// The methods needing PermSign, PermExport or PermApprove are recorded in the
+// audit log.
+var methodPermissions = map[string]Permission{
+	"properties":           0,
+	"get-height":           0,
+	"new-transaction":      0,
+	"delete-transaction":   0,
+	"tmp-transactions":     0,
+	"transaction-hash":     0,
+	"add-output":           0,
+	"add-outputs":          0,
+	"add-ec-output":        0,
+	"remove-input":         0,
+	"remove-output":        0,
+	"remove-ec-output":     0,
+	"simulate-fees":        0,
+	"estimate-fee":         0,
+	"preview-transaction":  0,
+	"export-transaction":   0,
+	"import-transaction":   0,
+	"set-transaction-note": 0,
+	"active-identity-keys": 0,
+	"bookmarks":            0,
+	"bookmark-entries":     0,
+	"list-contacts":        0,
+	"derivation-paths":     0,
+
+	"transactions":        PermList,
+	"transaction-history": PermList,
+	"wallet-balances":     PermList,
+	"get-address-label":   PermList,
+
+	"add-input":                              PermSign,
+	"add-fee":                                PermSign,
+	"sub-fee":                                PermSign,
+	"sign-transaction":                       PermSign,
+	"compose-transaction":                    PermSign,
+	"send-transaction":                       PermSign,
+	"send-factoid":                           PermSign,
+	"buy-ec":                                 PermSign,
+	"compose-chain":                          PermSign,
+	"compose-entry":                          PermSign,
+	"compose-identity-chain":                 PermSign,
+	"compose-identity-key-replacement":       PermSign,
+	"compose-identity-attribute":             PermSign,
+	"compose-identity-attribute-endorsement": PermSign,
+	"sign-data":                              PermSign,
+
+	"approve-transaction": PermApprove,
+
+	"address":         PermExport,
+	"identity-key":    PermExport,
+	"export-keystore": PermExport,
+
+	// wallet management methods that return secret keys
+	"generate-factoid-address": PermAll,
+	"generate-ec-address":      PermAll,
+	"generate-identity-key":    PermAll,
+	"import-addresses":         PermAll,
+	"import-koinify":           PermAll,
+	"remove-address":           PermAll,
+	"all-addresses":            PermAll,
+	"all-identity-keys":        PermAll,
+	"wallet-backup":            PermAll,
+	"rotate-seed":              PermAll,
+}
+
+// walletMethods are the other methods of the api. They manage the wallet
+// itself without returning secrets and need PermAll in AllNamespaces, like
+// the wallet management methods in methodPermissions.
+var walletMethods = map[string]bool{
+	"add-bookmark":         true,
+	"add-contact":          true,
+	"audit-log":            true,
+	"change-passphrase":    true,
+	"encrypt-wallet":       true,
+	"import-identity-keys": true,
+	"import-keystore":      true,
+	"lock-wallet":          true,
+	"namespaces":           true,
+	"remove-bookmark":      true,
+	"remove-contact":       true,
+	"remove-identity-key":  true,
+	"set-address-label":    true,
+	"set-derivation-paths": true,
+	"set-namespace":        true,
+	"unlock-wallet":        true,
+}
+
+// knownMethod reports whether method is a method of the api.
+func knownMethod(method string) bool {
+	_, ok := methodPermissions[method]
+	return ok || walletMethods[method]
+}
+
+// permissionParams are the params that name wallet keys.
+type permissionParams struct {
+	Name      string `json:"tx-name"`
+	From      string `json:"from"`
+	Address   string `json:"address"`
+	Public    string `json:"public"`
+	ECPub     string `json:"ecpub"`
+	SignerKey string `json:"signerkey"`
+
+	Transaction string `json:"transaction"`
+}
+
+// checkPermissions reports an error if perms do not allow the request j.
+func checkPermissions(perms TokenPermissions, j *factom.JSON2Request) *factom.JSONError {
+	perm, ok := methodPermissions[j.Method]
+	if !ok || perm == PermAll {
+		if !perms.allows(AllNamespaces, PermAll) {
+			return newPermissionDeniedError()
+		}
+		return nil
+	}
+	if perm == 0 {
+		return nil
+	}
+
+	p := new(permissionParams)
+	if len(j.Params) > 0 {
+		json.Unmarshal(j.Params, p)
+	}
+
+	var keys []string
+	for _, k := range []string{p.From, p.Address, p.Public, p.ECPub, p.SignerKey} {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+
+	// signing or approving a transaction covers the keys of its inputs
+	switch j.Method {
+	case "sign-transaction", "compose-transaction", "send-transaction", "approve-transaction":
+		if tx, ok := fctWallet.GetTransactions()[p.Name]; ok {
+			for _, in := range tx.GetInputs() {
+				keys = append(keys, primitives.ConvertFctAddressToUserStr(in.GetAddress()))
+			}
+		}
+		if p.Transaction != "" {
+			ins, err := wallet.ExportedInputs(p.Transaction)
+			if err != nil {
+				return newWalletError(err)
+			}
+			keys = append(keys, ins...)
+		}
+	}
+
+	// methods that name no keys cover the whole wallet
+	if len(keys) == 0 {
+		if !perms.allows(AllNamespaces, perm) {
+			return newPermissionDeniedError()
+		}
+		return nil
+	}
+
+	for _, k := range keys {
+		ns, err := fctWallet.Namespace(k)
+		if err != nil {
+			return newWalletError(err)
+		}
+		if !perms.allows(ns, perm) {
+			return newPermissionDeniedError()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factom/wallet"
+	"github.com/FactomProject/factomd/common/factoid"
+)
+
+func TestAuthenticate(t *testing.T) {
+	SetAPITokens(map[string]TokenPermissions{"secret": {"ops": PermList}})
+	defer SetAPITokens(nil)
+
+	r, _ := http.NewRequest("POST", "/v2", nil)
+	if _, err := authenticate(r, nil); err != ErrNoAuth {
+		t.Errorf("expected ErrNoAuth without credentials, got %v", err)
+	}
+
+	r.Header.Set("Authorization", "Bearer wrong")
+	if _, err := authenticate(r, nil); err != ErrBadAuth {
+		t.Errorf("expected ErrBadAuth for an unknown token, got %v", err)
+	}
+
+	r.Header.Set("Authorization", "Bearer secret")
+	perms, err := authenticate(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(perms) != 1 || perms["ops"] != PermList {
+		t.Errorf("token permissions %v", perms)
+	}
+}
+
+// permBackend keeps its keys in namespaces and holds the tmp transactions
+// txs. Its other methods are not used by the tests.
+type permBackend struct {
+	wallet.WalletBackend
+	namespaces map[string]string
+	txs        map[string]*factoid.Transaction
+}
+
+func (b *permBackend) Namespace(pub string) (string, error) {
+	return b.namespaces[pub], nil
+}
+
+func (b *permBackend) GetTransactions() map[string]*factoid.Transaction {
+	return b.txs
+}
+
+func TestCheckPermissions(t *testing.T) {
+	ops, err := factom.GetFactoidAddress("Fs2GCfAa2HBKaGEUWCtw8eGDkN1CfyS6HhdgLv8783shkrCgvcpJ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := factom.NewFactoidAddress()
+
+	opsTx := new(factoid.Transaction)
+	opsTx.AddInput(factoid.NewAddress(ops.RCDHash()), 1)
+	mixedTx := new(factoid.Transaction)
+	mixedTx.AddInput(factoid.NewAddress(ops.RCDHash()), 1)
+	mixedTx.AddInput(factoid.NewAddress(other.RCDHash()), 1)
+
+	b := &permBackend{
+		namespaces: map[string]string{ops.String(): "ops", other.String(): "other"},
+		txs:        map[string]*factoid.Transaction{"ops": opsTx, "mixed": mixedTx},
+	}
+	defer func(w wallet.WalletBackend) { fctWallet = w }(fctWallet)
+	fctWallet = b
+
+	perms := TokenPermissions{"ops": PermList | PermSign}
+	admin := TokenPermissions{AllNamespaces: PermAll}
+	for _, c := range []struct {
+		perms   TokenPermissions
+		method  string
+		params  interface{}
+		allowed bool
+	}{
+		// methods that need no permission
+		{perms, "properties", nil, true},
+		{perms, "new-transaction", map[string]string{"tx-name": "t"}, true},
+
+		// keys are allowed by their namespace
+		{perms, "add-input", map[string]string{"tx-name": "t", "address": ops.String()}, true},
+		{perms, "add-input", map[string]string{"tx-name": "t", "address": other.String()}, false},
+		{perms, "send-factoid", map[string]string{"from": other.String()}, false},
+		{perms, "address", map[string]string{"address": ops.String()}, false},
+
+		// signing a transaction needs every input
+		{perms, "sign-transaction", map[string]string{"tx-name": "ops"}, true},
+		{perms, "sign-transaction", map[string]string{"tx-name": "mixed"}, false},
+		{perms, "send-transaction", map[string]string{"tx-name": "mixed"}, false},
+		{TokenPermissions{"ops": PermSign, "other": PermSign}, "sign-transaction", map[string]string{"tx-name": "mixed"}, true},
+
+		// methods without keys cover every namespace
+		{perms, "wallet-balances", nil, false},
+		{TokenPermissions{AllNamespaces: PermList}, "wallet-balances", nil, true},
+
+		// approving is not part of signing
+		{perms, "approve-transaction", map[string]string{"tx-name": "ops"}, false},
+		{TokenPermissions{"ops": PermApprove}, "approve-transaction", map[string]string{"tx-name": "ops"}, true},
+
+		// wallet management and unlisted methods need PermAll everywhere
+		{perms, "generate-ec-address", nil, false},
+		{TokenPermissions{"ops": PermAll}, "generate-ec-address", nil, false},
+		{admin, "generate-ec-address", nil, true},
+		{perms, "unlock-wallet", nil, false},
+		{admin, "unlock-wallet", nil, true},
+		{perms, "lock-wallet", nil, false},
+		{admin, "lock-wallet", nil, true},
+	} {
+		j := factom.NewJSON2Request(c.method, 0, c.params)
+		jsonError := checkPermissions(c.perms, j)
+		if c.allowed && jsonError != nil {
+			t.Errorf("%s %v with %v denied: %v", c.method, c.params, c.perms, jsonError)
+		}
+		if !c.allowed && (jsonError == nil || jsonError.Code != newPermissionDeniedError().Code) {
+			t.Errorf("%s %v with %v not denied: %v", c.method, c.params, c.perms, jsonError)
+		}
+	}
+}
+
+func TestCallerID(t *testing.T) {
+	r, _ := http.NewRequest("POST", "/v2", nil)
+	if id := callerID(r); id != "rpc" {
+		t.Errorf("rpc caller %q", id)
+	}
+
+	r.Header.Set("Authorization", "Bearer one")
+	one := callerID(r)
+	r.Header.Set("Authorization", "Bearer two")
+	if two := callerID(r); one == two || one[:6] != "token:" {
+		t.Errorf("token callers %q and %q", one, two)
+	}
+
+	r.Header.Set(factom.HMACKeyIDHeader, "ops")
+	if id := callerID(r); id != "hmac:ops" {
+		t.Errorf("hmac caller %q", id)
+	}
+}
+
+// approvalBackend holds one tmp transaction, approved by approver. Its other
+// methods are not used by the tests.
+type approvalBackend struct {
+	wallet.WalletBackend
+	tx       *factoid.Transaction
+	approver string
+}
+
+func (b *approvalBackend) GetTransactions() map[string]*factoid.Transaction {
+	return map[string]*factoid.Transaction{"large": b.tx}
+}
+
+func (b *approvalBackend) Approver(sighash string) (string, bool) {
+	if sighash != b.tx.GetSigHash().String() {
+		return "", false
+	}
+	return b.approver, true
+}
+
+func TestCheckApprover(t *testing.T) {
+	b := &approvalBackend{tx: new(factoid.Transaction), approver: "token:a"}
+	defer func(w wallet.WalletBackend) { fctWallet = w }(fctWallet)
+	fctWallet = b
+
+	req := &transactionRequest{Name: "large"}
+	if jsonError := checkApprover(withCaller(context.Background(), "token:a"), req); jsonError == nil ||
+		jsonError.Code != newPermissionDeniedError().Code {
+		t.Errorf("the approving token may sign, got %v", jsonError)
+	}
+	if jsonError := checkApprover(withCaller(context.Background(), "token:b"), req); jsonError != nil {
+		t.Errorf("another token may not sign: %v", jsonError)
+	}
+	if jsonError := checkApprover(withCaller(context.Background(), "token:a"), &transactionRequest{Name: "other"}); jsonError != nil {
+		t.Errorf("unknown transaction refused: %v", jsonError)
+	}
+}
@@ -6,9 +6,9 @@ package wsapi
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"crypto/subtle"
-	"crypto/tls"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -16,9 +16,12 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -28,15 +31,15 @@ import (
 	"github.com/FactomProject/factomd/common/factoid"
 	"github.com/FactomProject/factomd/common/interfaces"
 	"github.com/FactomProject/factomd/common/primitives"
-	"github.com/FactomProject/factomd/database/securedb"
 	"github.com/FactomProject/web"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const APIVersion string = "2.0"
 
 var (
 	webServer *web.Server
-	fctWallet *wallet.Wallet
+	fctWallet wallet.WalletBackend
 	rpcUser   string
 	rpcPass   string
 	authsha   []byte
@@ -104,53 +107,122 @@ func fileExists(name string) bool {
 	return true
 }
 
-func Start(w *wallet.Wallet, net string, c factom.RPCConfig) {
+// Start serves the wallet api for w on the address net until Shutdown is called.
+// The options are applied before the server starts listening.
+func Start(w wallet.WalletBackend, net string, c factom.RPCConfig, opts ...Option) {
+	o := &startOptions{
+		rpcUser: c.WalletRPCUser,
+		rpcPass: c.WalletRPCPassword,
+		tls:     c.WalletTLSEnable,
+		tlsCert: c.WalletTLSCertFile,
+		tlsKey:  c.WalletTLSKeyFile,
+	}
+	for _, domain := range strings.Split(c.WalletCORSDomains, ",") {
+		if domain = strings.Trim(domain, " "); domain != "" {
+			o.corsOrigins = append(o.corsOrigins, domain)
+		}
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	webServer = web.NewServer()
 	fctWallet = w
+	resetDrain()
 
-	if len(c.WalletCORSDomains) > 0 {
-		domains := strings.Split(c.WalletCORSDomains, ",")
-		var cors []string
-		for _, domain := range domains {
-			cors = append(cors, strings.Trim(domain, " "))
-		}
-		webServer.Config.CorsDomains = cors
-	}
+	corsOrigins = o.corsOrigins
 
-	rpcUser = c.WalletRPCUser
-	rpcPass = c.WalletRPCPassword
+	rpcUser = o.rpcUser
+	rpcPass = o.rpcPass
+	if o.tokens != nil {
+		SetAPITokens(o.tokens)
+	}
+	if o.hmacKeys != nil {
+		SetHMACKeys(o.hmacKeys)
+	}
+	if o.allow != nil || o.deny != nil {
+		SetMethodPolicy(o.allow, o.deny)
+	}
+	if o.logger != nil {
+		SetRequestLogger(o.logger)
+	}
+	if o.metrics {
+		enableMetrics(w)
+	}
+	if o.limits {
+		SetRateLimits(o.global, o.perClient)
+	}
+	SetNoSecrets(o.noSecrets)
+	if o.factomdRetry != nil {
+		factom.SetFactomdRetryPolicy(o.factomdRetry)
+	}
 
 	h := sha256.New()
 	h.Write(httpBasicAuth(rpcUser, rpcPass))
 	authsha = h.Sum(nil) //set this in the beginning to prevent timing attacks
 
+	warnIfOpen(net)
+
+	eventsQuit = make(chan struct{})
+
 	webServer.Post("/v2", handleV2)
 	webServer.Get("/v2", handleV2)
+	webServer.Handle("/v2", "OPTIONS", http.HandlerFunc(handlePreflight))
+	webServer.Handle("/v2/ws", "GET", http.HandlerFunc(handleEvents))
+	if o.metrics {
+		webServer.Handle("/metrics", "GET", prometheus.Handler())
+	}
 
-	if c.WalletTLSEnable == false {
+	if !o.tls {
 		webServer.Run(net)
-	} else {
-		if !fileExists(c.WalletTLSKeyFile) && !fileExists(c.WalletTLSCertFile) {
-			err := genCertPair(c.WalletTLSCertFile, c.WalletTLSKeyFile, c.WalletServer)
-			if err != nil {
-				log.Fatal(err)
-			}
-		}
-		keypair, err := tls.LoadX509KeyPair(c.WalletTLSCertFile, c.WalletTLSKeyFile)
+		return
+	}
+
+	if !fileExists(o.tlsKey) && !fileExists(o.tlsCert) {
+		err := genCertPair(o.tlsCert, o.tlsKey, c.WalletServer)
 		if err != nil {
 			log.Fatal(err)
 		}
-		tlsConfig := &tls.Config{
-			Certificates: []tls.Certificate{keypair},
-			MinVersion:   tls.VersionTLS12,
-		}
-		webServer.RunTLS(net, tlsConfig)
 	}
+	reloader, err := newCertReloader(o.tlsCert, o.tlsKey, o.clientCAs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tlsReloader = reloader
+	reloader.watch()
+	webServer.RunTLS(net, reloader.config())
 }
 
-func Stop() {
-	fctWallet.Close()
+// Shutdown closes the api server and waits for the requests in flight to
+// finish before closing the wallet database, so that responses are not cut
+// short and database writes are not left half done. If ctx is done before the
+// requests finish the wallet is closed anyway and the context error is
+// returned.
+func Shutdown(ctx context.Context) error {
 	webServer.Close()
+	close(eventsQuit)
+	if tlsReloader != nil {
+		tlsReloader.stop()
+		tlsReloader = nil
+	}
+
+	// signal long running transaction history syncs to wrap up
+	if txdb := fctWallet.TXDB(); txdb != nil {
+		txdb.Quit()
+	}
+
+	drainErr := drainRequests(ctx)
+	if err := fctWallet.Close(); err != nil {
+		return err
+	}
+	return drainErr
+}
+
+// Stop is the old name of Shutdown.
+//
+// Deprecated: use Shutdown.
+func Stop(ctx context.Context) error {
+	return Shutdown(ctx)
 }
 
 func checkAuthHeader(r *http.Request) error {
@@ -163,7 +235,7 @@ func checkAuthHeader(r *http.Request) error {
 	authhdr := r.Header["Authorization"]
 	if len(authhdr) == 0 {
 		fmt.Println("Username and Password expected, but none were received")
-		return errors.New("no auth")
+		return ErrNoAuth
 	}
 
 	h := sha256.New()
@@ -172,23 +244,46 @@ func checkAuthHeader(r *http.Request) error {
 	cmp := subtle.ConstantTimeCompare(presentedPassHash, authsha) //compare hashes because ConstantTimeCompare takes a constant time based on the slice size.  hashing gives a constant slice size.
 	if cmp != 1 {
 		fmt.Println("Incorrect Username and/or Password were received")
-		return errors.New("bad auth")
+		return ErrBadAuth
 	}
 	return nil
 }
 
 func handleV2(ctx *web.Context) {
-	if err := checkAuthHeader(ctx.Request); err != nil {
+	setCORSHeaders(ctx.ResponseWriter, ctx.Request)
+
+	if !beginRequest() {
+		handleV2Error(ctx, nil, newCustomInternalError("Wallet is shutting down"))
+		return
+	}
+	defer endRequest()
+
+	if ok, wait := checkRateLimit(ctx.Request.RemoteAddr); !ok {
+		secs := int(math.Ceil(wait.Seconds()))
+		ctx.ResponseWriter.Header().Set("Retry-After", strconv.Itoa(secs))
+		handleV2Error(ctx, nil, newRateLimitedError(wait))
+		return
+	}
+
+	body, err := ioutil.ReadAll(ctx.Request.Body)
+	if err != nil {
+		handleV2Error(ctx, nil, newInvalidRequestError())
+		return
+	}
+
+	perms, err := authenticate(ctx.Request, body)
+	if err != nil {
 		remoteIP := ""
 		remoteIP += strings.Split(ctx.Request.RemoteAddr, ":")[0]
-		fmt.Printf("Unauthorized API client connection attempt from %s\n", remoteIP)
+		fmt.Printf("Unauthorized API client connection attempt from %s: %s\n", remoteIP, err)
 		ctx.ResponseWriter.Header().Add("WWW-Authenticate", `Basic realm="factomd RPC"`)
 		http.Error(ctx.ResponseWriter, "401 Unauthorized.", http.StatusUnauthorized)
 		return
 	}
-	body, err := ioutil.ReadAll(ctx.Request.Body)
-	if err != nil {
-		handleV2Error(ctx, nil, newInvalidRequestError())
+	ctx.Request = ctx.Request.WithContext(withCaller(ctx.Request.Context(), callerID(ctx.Request)))
+
+	if isBatch(body) {
+		handleBatch(ctx, body, perms, ctx.Request.RemoteAddr)
 		return
 	}
 
@@ -198,41 +293,85 @@ func handleV2(ctx *web.Context) {
 		return
 	}
 
-	jsonResp, jsonError := handleV2Request(j)
+	jsonResp, jsonError := serveRequest(ctx.Request.Context(), j, perms, ctx.Request.RemoteAddr)
 
 	if jsonError != nil {
 		handleV2Error(ctx, j, jsonError)
 		return
 	}
 
+	if cacheableMethods[j.Method] && writeCacheHeaders(ctx, jsonResp.Result) {
+		return
+	}
+
 	ctx.Write([]byte(jsonResp.String()))
 }
 
-func handleV2Request(j *factom.JSON2Request) (*factom.JSON2Response, *factom.JSONError) {
+// readOnlyMethods are the methods served by a read only wallet. None of them
+// change the wallet or return private keys.
+var readOnlyMethods = map[string]bool{
+	"properties":           true,
+	"get-height":           true,
+	"transactions":         true,
+	"tmp-transactions":     true,
+	"transaction-hash":     true,
+	"simulate-fees":        true,
+	"estimate-fee":         true,
+	"preview-transaction":  true,
+	"export-transaction":   true,
+	"wallet-balances":      true,
+	"active-identity-keys": true,
+	"unlock-wallet":        true,
+	"lock-wallet":          true,
+	"bookmarks":            true,
+	"bookmark-entries":     true,
+	"namespaces":           true,
+	"get-address-label":    true,
+	"list-contacts":        true,
+	"derivation-paths":     true,
+	"audit-log":            true,
+	"transaction-history":  true,
+}
+
+func handleV2Request(ctx context.Context, j *factom.JSON2Request) (*factom.JSON2Response, *factom.JSONError) {
 	var resp interface{}
 	var jsonError *factom.JSONError
 	params := []byte(j.Params)
 
+	// The operator may have turned some methods off
+	if !methodEnabled(j.Method) {
+		return nil, newMethodDisabledError()
+	}
+
+	// A read only wallet refuses anything that changes it or exports secrets
+	if fctWallet.ReadOnly() && !readOnlyMethods[j.Method] {
+		return nil, newWalletIsReadOnlyError()
+	}
+
 	// Only expose a subset of endpoints if the wallet is still waiting to be unlocked
-	if fctWallet.Encrypted && (fctWallet.WalletDatabaseOverlay == nil || fctWallet.DBO.DB.(*securedb.EncryptedDB).UnlockedUntil.Unix() < time.Now().Unix()) {
+	if fctWallet.IsLocked() {
 		switch j.Method {
 		case "get-height":
 			resp, jsonError = handleGetHeight(params)
 		case "properties":
-			resp, jsonError = handleProperties(params)
+			resp, jsonError = handleProperties(ctx, params)
 		case "transactions":
-			resp, jsonError = handleAllTransactions(params)
+			resp, jsonError = handleAllTransactions(ctx, params)
 		case "unlock-wallet":
 			resp, jsonError = handleWalletPassphrase(params)
+		case "lock-wallet":
+			resp, jsonError = handleLockWallet(params)
+		case "change-passphrase":
+			resp, jsonError = handleChangePassphrase(params)
 		default:
 			jsonError = newWalletIsLockedError()
 		}
 	} else {
 		switch j.Method {
 		case "address":
-			resp, jsonError = handleAddress(params)
+			resp, jsonError = handleAddress(ctx, params)
 		case "all-addresses":
-			resp, jsonError = handleAllAddresses(params)
+			resp, jsonError = handleAllAddresses(ctx, params)
 		case "generate-ec-address":
 			resp, jsonError = handleGenerateECAddress(params)
 		case "generate-factoid-address":
@@ -241,44 +380,80 @@ func handleV2Request(j *factom.JSON2Request) (*factom.JSON2Response, *factom.JSO
 			resp, jsonError = handleImportAddresses(params)
 		case "import-koinify":
 			resp, jsonError = handleImportKoinify(params)
+		case "export-keystore":
+			resp, jsonError = handleExportKeystore(params)
+		case "import-keystore":
+			resp, jsonError = handleImportKeystore(params)
 		case "wallet-backup":
 			resp, jsonError = handleWalletBackup(params)
+		case "rotate-seed":
+			resp, jsonError = handleRotateSeed(params)
 		case "transactions":
-			resp, jsonError = handleAllTransactions(params)
+			resp, jsonError = handleAllTransactions(ctx, params)
 		case "new-transaction":
-			resp, jsonError = handleNewTransaction(params)
+			resp, jsonError = handleNewTransaction(ctx, params)
 		case "delete-transaction":
 			resp, jsonError = handleDeleteTransaction(params)
 		case "tmp-transactions":
-			resp, jsonError = handleTmpTransactions(params)
+			resp, jsonError = handleTmpTransactions(ctx, params)
 		case "transaction-hash":
 			resp, jsonError = handleTransactionHash(params)
 		case "add-input":
-			resp, jsonError = handleAddInput(params)
+			resp, jsonError = handleAddInput(ctx, params)
 		case "add-output":
-			resp, jsonError = handleAddOutput(params)
+			resp, jsonError = handleAddOutput(ctx, params)
+		case "add-outputs":
+			resp, jsonError = handleAddOutputs(ctx, params)
 		case "add-ec-output":
-			resp, jsonError = handleAddECOutput(params)
+			resp, jsonError = handleAddECOutput(ctx, params)
+		case "remove-input":
+			resp, jsonError = handleRemoveInput(ctx, params)
+		case "remove-output":
+			resp, jsonError = handleRemoveOutput(ctx, params)
+		case "remove-ec-output":
+			resp, jsonError = handleRemoveECOutput(ctx, params)
 		case "add-fee":
-			resp, jsonError = handleAddFee(params)
+			resp, jsonError = handleAddFee(ctx, params)
 		case "sub-fee":
-			resp, jsonError = handleSubFee(params)
+			resp, jsonError = handleSubFee(ctx, params)
 		case "sign-transaction":
-			resp, jsonError = handleSignTransaction(params)
+			resp, jsonError = handleSignTransaction(ctx, params)
+		case "approve-transaction":
+			resp, jsonError = handleApproveTransaction(ctx, params)
 		case "compose-transaction":
 			resp, jsonError = handleComposeTransaction(params)
+		case "send-transaction":
+			resp, jsonError = handleSendTransaction(params)
+		case "send-factoid":
+			resp, jsonError = handleSendFactoid(ctx, params)
+		case "buy-ec":
+			resp, jsonError = handleBuyEC(params)
+		case "simulate-fees":
+			resp, jsonError = handleSimulateFees(params)
+		case "estimate-fee":
+			resp, jsonError = handleEstimateFee(params)
+		case "preview-transaction":
+			resp, jsonError = handlePreviewTransaction(params)
+		case "export-transaction":
+			resp, jsonError = handleExportTransaction(params)
+		case "import-transaction":
+			resp, jsonError = handleImportTransaction(ctx, params)
+		case "set-transaction-note":
+			resp, jsonError = handleSetTransactionNote(params)
+		case "sign-data":
+			resp, jsonError = handleSignData(params)
 		case "remove-address":
 			resp, jsonError = handleRemoveAddress(params)
 		case "properties":
-			resp, jsonError = handleProperties(params)
+			resp, jsonError = handleProperties(ctx, params)
 		case "compose-chain":
-			resp, jsonError = handleComposeChain(params)
+			resp, jsonError = handleComposeChain(ctx, params)
 		case "compose-entry":
-			resp, jsonError = handleComposeEntry(params)
+			resp, jsonError = handleComposeEntry(ctx, params)
 		case "get-height":
 			resp, jsonError = handleGetHeight(params)
 		case "wallet-balances":
-			resp, jsonError = handleWalletBalances(params)
+			resp, jsonError = handleWalletBalances(ctx, params)
 		case "identity-key":
 			resp, jsonError = handleIdentityKey(params)
 		case "all-identity-keys":
@@ -290,17 +465,53 @@ func handleV2Request(j *factom.JSON2Request) (*factom.JSON2Response, *factom.JSO
 		case "remove-identity-key":
 			resp, jsonError = handleRemoveIdentityKey(params)
 		case "active-identity-keys":
-			resp, jsonError = handleActiveIdentityKeys(params)
+			resp, jsonError = handleActiveIdentityKeys(ctx, params)
 		case "compose-identity-chain":
-			resp, jsonError = handleComposeIdentityChain(params)
+			resp, jsonError = handleComposeIdentityChain(ctx, params)
 		case "compose-identity-key-replacement":
-			resp, jsonError = handleComposeIdentityKeyReplacement(params)
+			resp, jsonError = handleComposeIdentityKeyReplacement(ctx, params)
 		case "compose-identity-attribute":
-			resp, jsonError = handleComposeIdentityAttribute(params)
+			resp, jsonError = handleComposeIdentityAttribute(ctx, params)
 		case "compose-identity-attribute-endorsement":
-			resp, jsonError = handleComposeIdentityAttributeEndorsement(params)
+			resp, jsonError = handleComposeIdentityAttributeEndorsement(ctx, params)
 		case "unlock-wallet":
 			resp, jsonError = handleWalletPassphrase(params)
+		case "lock-wallet":
+			resp, jsonError = handleLockWallet(params)
+		case "encrypt-wallet":
+			resp, jsonError = handleEncryptWallet(params)
+		case "change-passphrase":
+			resp, jsonError = handleChangePassphrase(params)
+		case "set-namespace":
+			resp, jsonError = handleSetNamespace(params)
+		case "namespaces":
+			resp, jsonError = handleNamespaces(params)
+		case "set-address-label":
+			resp, jsonError = handleSetAddressLabel(params)
+		case "get-address-label":
+			resp, jsonError = handleGetAddressLabel(params)
+		case "derivation-paths":
+			resp, jsonError = handleDerivationPaths(params)
+		case "set-derivation-paths":
+			resp, jsonError = handleSetDerivationPaths(params)
+		case "add-contact":
+			resp, jsonError = handleAddContact(params)
+		case "list-contacts":
+			resp, jsonError = handleListContacts(params)
+		case "remove-contact":
+			resp, jsonError = handleRemoveContact(params)
+		case "add-bookmark":
+			resp, jsonError = handleAddBookmark(params)
+		case "remove-bookmark":
+			resp, jsonError = handleRemoveBookmark(params)
+		case "bookmarks":
+			resp, jsonError = handleBookmarks(params)
+		case "bookmark-entries":
+			resp, jsonError = handleBookmarkEntries(ctx, params)
+		case "audit-log":
+			resp, jsonError = handleAuditLog(params)
+		case "transaction-history":
+			resp, jsonError = handleTransactionHistory(params)
 		default:
 			jsonError = newMethodNotFoundError()
 		}
@@ -312,7 +523,8 @@ func handleV2Request(j *factom.JSON2Request) (*factom.JSON2Response, *factom.JSO
 
 	// don't print password attempts or private keys to output
 	switch j.Method {
-	case "import-addresses", "import-koinify", "unlock-wallet":
+	case "import-addresses", "import-koinify", "unlock-wallet", "encrypt-wallet", "change-passphrase",
+		"export-keystore", "import-keystore":
 		fmt.Printf("API V2 method: <%v>\n", j.Method)
 	default:
 		fmt.Printf("API V2 method: <%v>  parameters: %s\n", j.Method, params)
@@ -329,7 +541,7 @@ func handleV2Request(j *factom.JSON2Request) (*factom.JSON2Response, *factom.JSO
 	return jsonResp, nil
 }
 
-func handleWalletBalances(params []byte) (interface{}, *factom.JSONError) {
+func handleWalletBalances(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
 	//Get all of the addresses in the wallet
 	fs, es, err := fctWallet.GetAllAddresses()
 	if err != nil {
@@ -350,34 +562,10 @@ func handleWalletBalances(params []byte) (interface{}, *factom.JSONError) {
 		}
 	}
 
-	var stringOfAccountsEC string
-	if len(ecAccounts) != 0 {
-		stringOfAccountsEC = strings.Join(ecAccounts, `", "`)
-	}
-
-	url := "http://" + factom.FactomdServer() + "/v2"
-	if url == "http:///v2" {
-		url = "http://localhost:8088/v2"
-	}
 	// Get Entry Credit balances from multiple-ec-balances API in factomd
-	jsonStrEC := []byte(`{"jsonrpc": "2.0", "id": 0, "method": "multiple-ec-balances", "params":{"addresses":["` + stringOfAccountsEC + `"]}}  `)
-	reqEC, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonStrEC))
-	reqEC.Header.Set("content-type", "text/plain;")
-	reqEC.SetBasicAuth(factom.GetFactomdRpcConfig())
-
-	clientEC := &http.Client{}
-	callRespEC, err := clientEC.Do(reqEC)
+	respEC, err := multipleBalances(ctx, "multiple-ec-balances", ecAccounts)
 	if err != nil {
-		panic(err)
-	}
-
-	defer callRespEC.Body.Close()
-	bodyEC, _ := ioutil.ReadAll(callRespEC.Body)
-
-	respEC := new(UnmarBody)
-	errEC := json.Unmarshal([]byte(bodyEC), &respEC)
-	if errEC != nil {
-		return nil, newInvalidParamsError()
+		return nil, newCustomInternalError(err.Error())
 	}
 
 	//Total up the balances
@@ -410,30 +598,10 @@ func handleWalletBalances(params []byte) (interface{}, *factom.JSONError) {
 		}
 	}
 
-	stringOfAccountsFCT := ""
-	if len(fctAccounts) != 0 {
-		stringOfAccountsFCT = strings.Join(fctAccounts, `", "`)
-	}
-
 	// Get Factoid balances from multiple-fct-balances API in factomd
-	jsonStrFCT := []byte(`{"jsonrpc": "2.0", "id": 0, "method": "multiple-fct-balances", "params":{"addresses":["` + stringOfAccountsFCT + `"]}}  `)
-	reqFCT, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonStrFCT))
-	reqFCT.Header.Set("content-type", "text/plain;")
-	reqFCT.SetBasicAuth(factom.GetFactomdRpcConfig())
-
-	clientFCT := &http.Client{}
-	callRespFCT, errFCT := clientFCT.Do(reqFCT)
-	if errFCT != nil {
-		panic(errFCT)
-	}
-
-	defer callRespFCT.Body.Close()
-	bodyFCT, _ := ioutil.ReadAll(callRespFCT.Body)
-
-	respFCT := new(UnmarBody)
-	errFCT2 := json.Unmarshal([]byte(bodyFCT), &respFCT)
-	if errFCT2 != nil {
-		return nil, newInvalidParamsError()
+	respFCT, err := multipleBalances(ctx, "multiple-fct-balances", fctAccounts)
+	if err != nil {
+		return nil, newCustomInternalError(err.Error())
 	}
 
 	// Total up the balances
@@ -490,24 +658,72 @@ func handleWalletBalances(params []byte) (interface{}, *factom.JSONError) {
 	return resp, nil
 }
 
+// multipleBalances gets the balances of addresses with method from the
+// configured factomd, with its credentials and TLS settings.
+func multipleBalances(ctx context.Context, method string, addresses []string) (*UnmarBody, error) {
+	req := factom.NewJSON2Request(method, factom.APICounter(), map[string][]string{"addresses": addresses})
+	resp, err := factom.SendFactomdRequestWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	body := new(UnmarBody)
+	if err := json.Unmarshal(resp.JSONResult(), &body.Result); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
 func handleRemoveAddress(params []byte) (interface{}, *factom.JSONError) {
-	req := new(addressRequest)
+	req := new(removeAddressRequest)
 	if err := json.Unmarshal(params, req); err != nil {
 		return nil, newInvalidParamsError()
 	}
 
-	err := fctWallet.WalletDatabaseOverlay.RemoveAddress(req.Address)
+	// watch only and signer addresses have no secret to back up
+	watch, err := fctWallet.IsWatchOnly(req.Address)
 	if err != nil {
-		return nil, newCustomInternalError(err.Error())
+		return nil, newWalletError(err)
+	}
+	signer, err := fctWallet.IsSignerKey(req.Address)
+	if err != nil {
+		return nil, newWalletError(err)
+	}
+	if watch || signer {
+		if err := fctWallet.RemoveAddress(req.Address); err != nil {
+			return nil, newWalletError(err)
+		}
+		return &simpleResponse{Success: true}, nil
 	}
 
-	resp := new(simpleResponse)
-	resp.Success = true
+	// with backup set the removed secret is returned one last time
+	resp := new(addressResponse)
+	switch factom.AddressStringType(req.Address) {
+	case factom.FactoidPub:
+		f, err := fctWallet.RemoveFCTAddress(req.Address)
+		if err != nil {
+			return nil, newWalletError(err)
+		}
+		resp.Public, resp.Secret = f.String(), f.SecString()
+	case factom.ECPub:
+		e, err := fctWallet.RemoveECAddress(req.Address)
+		if err != nil {
+			return nil, newWalletError(err)
+		}
+		resp.Public, resp.Secret = e.String(), e.SecString()
+	default:
+		return nil, newCustomInvalidParamsError("Invalid address")
+	}
 
-	return resp, nil
+	if req.Backup {
+		return resp, nil
+	}
+	return &simpleResponse{Success: true}, nil
 }
 
-func handleAddress(params []byte) (interface{}, *factom.JSONError) {
+func handleAddress(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
 	req := new(addressRequest)
 	if err := json.Unmarshal(params, req); err != nil {
 		return nil, newInvalidParamsError()
@@ -533,28 +749,125 @@ func handleAddress(params []byte) (interface{}, *factom.JSONError) {
 	default:
 		return nil, newCustomInternalError("Invalid address type")
 	}
+	if !includeSecrets(req.Secrets) {
+		resp.Secret = ""
+	}
+
+	if req.Balances {
+		if jsonError := addBalances(ctx, "address", []*addressResponse{resp}); jsonError != nil {
+			return nil, jsonError
+		}
+	}
 
 	return resp, nil
 }
 
-func handleAllAddresses(params []byte) (interface{}, *factom.JSONError) {
+func handleAllAddresses(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
+	req := new(allAddressesRequest)
+	if p := bytes.TrimSpace(params); len(p) > 0 && p[0] == '{' {
+		if err := json.Unmarshal(params, req); err != nil {
+			return nil, newInvalidParamsError()
+		}
+	}
+
 	resp := new(multiAddressResponse)
 
 	fs, es, err := fctWallet.GetAllAddresses()
 	if err != nil {
 		return nil, newCustomInternalError(err.Error())
 	}
+	labels, err := fctWallet.GetAllAddressLabels()
+	if err != nil {
+		return nil, newWalletError(err)
+	}
 	for _, f := range fs {
-		resp.Addresses = append(resp.Addresses, mkAddressResponse(f))
+		a := mkAddressResponse(f)
+		a.Label = labels[a.Public]
+		resp.Addresses = append(resp.Addresses, a)
 	}
 	for _, e := range es {
-		resp.Addresses = append(resp.Addresses, mkAddressResponse(e))
+		a := mkAddressResponse(e)
+		a.Label = labels[a.Public]
+		resp.Addresses = append(resp.Addresses, a)
+	}
+
+	// watch only addresses are listed without a secret
+	ws, err := fctWallet.GetAllWatchOnly()
+	if err != nil {
+		return nil, newWalletError(err)
+	}
+	for _, w := range ws {
+		resp.Addresses = append(resp.Addresses, &addressResponse{Public: w, WatchOnly: true})
+	}
+
+	// the keys of signer addresses are held by the signer
+	ss, err := fctWallet.GetAllSignerKeys()
+	if err != nil {
+		return nil, newWalletError(err)
+	}
+	for _, a := range ss {
+		r := &addressResponse{Public: a, Signer: true}
+		r.Label = labels[a]
+		resp.Addresses = append(resp.Addresses, r)
+	}
+
+	if !includeSecrets(req.Secrets) {
+		for _, a := range resp.Addresses {
+			a.Secret = ""
+		}
+	}
+
+	if req.Balances {
+		if jsonError := addBalances(ctx, "all-addresses", resp.Addresses); jsonError != nil {
+			return nil, jsonError
+		}
 	}
 
 	return resp, nil
 }
 
+// maxGenerateAddresses is the largest count accepted by the address
+// generation methods.
+const maxGenerateAddresses = 10000
+
+// generateCount returns the count param of an address generation request,
+// or 0 if there is none. Older clients send no params or an empty list.
+func generateCount(params []byte) (int, *factom.JSONError) {
+	if p := bytes.TrimSpace(params); len(p) == 0 || p[0] != '{' {
+		return 0, nil
+	}
+	req := new(generateAddressRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return 0, newInvalidParamsError()
+	}
+	if req.Count == nil {
+		return 0, nil
+	}
+	if *req.Count < 1 || *req.Count > maxGenerateAddresses {
+		return 0, newCustomInvalidParamsError(
+			fmt.Sprintf("count must be between 1 and %d", maxGenerateAddresses))
+	}
+	return *req.Count, nil
+}
+
 func handleGenerateFactoidAddress(params []byte) (interface{}, *factom.JSONError) {
+	// with a count the addresses are generated in one batch
+	n, jsonError := generateCount(params)
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	if n > 0 {
+		as, err := fctWallet.GenerateFCTAddresses(n)
+		if err != nil {
+			return nil, newWalletError(err)
+		}
+		resp := new(multiAddressResponse)
+		for _, a := range as {
+			resp.Addresses = append(resp.Addresses, mkAddressResponse(a))
+		}
+		return resp, nil
+	}
+
 	a, err := fctWallet.GenerateFCTAddress()
 	if err != nil {
 		return nil, newCustomInternalError(err.Error())
@@ -566,6 +879,22 @@ func handleGenerateFactoidAddress(params []byte) (interface{}, *factom.JSONError
 }
 
 func handleGenerateECAddress(params []byte) (interface{}, *factom.JSONError) {
+	n, jsonError := generateCount(params)
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	if n > 0 {
+		as, err := fctWallet.GenerateECAddresses(n)
+		if err != nil {
+			return nil, newWalletError(err)
+		}
+		resp := new(multiAddressResponse)
+		for _, a := range as {
+			resp.Addresses = append(resp.Addresses, mkAddressResponse(a))
+		}
+		return resp, nil
+	}
+
 	a, err := fctWallet.GenerateECAddress()
 	if err != nil {
 		return nil, newCustomInternalError(err.Error())
@@ -584,6 +913,30 @@ func handleImportAddresses(params []byte) (interface{}, *factom.JSONError) {
 
 	resp := new(multiAddressResponse)
 	for _, v := range req.Addresses {
+		// with its public key a watch only address can be spent from by
+		// transactions that another wallet signs
+		if v.Secret == "" && v.PublicKey != "" {
+			pub, err := hex.DecodeString(v.PublicKey)
+			if err != nil {
+				return nil, newInvalidParamsError()
+			}
+			a, err := fctWallet.ImportWatchOnlyKey(pub)
+			if err != nil {
+				return nil, newWalletError(err)
+			}
+			resp.Addresses = append(resp.Addresses, &addressResponse{Public: a, WatchOnly: true})
+			continue
+		}
+
+		// an address without a secret is imported as watch only
+		if v.Secret == "" && v.Address != "" {
+			if err := fctWallet.ImportWatchOnly(v.Address); err != nil {
+				return nil, newWalletError(err)
+			}
+			resp.Addresses = append(resp.Addresses, &addressResponse{Public: v.Address, WatchOnly: true})
+			continue
+		}
+
 		switch factom.AddressStringType(v.Secret) {
 		case factom.FactoidSec:
 			f, err := factom.GetFactoidAddress(v.Secret)
@@ -618,17 +971,42 @@ func handleImportKoinify(params []byte) (interface{}, *factom.JSONError) {
 		return nil, newInvalidParamsError()
 	}
 
-	f, err := factom.MakeFactoidAddressFromKoinify(req.Words)
+	f, err := fctWallet.ImportKoinifyWords(req.Words)
 	if err != nil {
-		return nil, newCustomInternalError(err.Error())
-	}
-	if err := fctWallet.InsertFCTAddress(f); err != nil {
-		return nil, newCustomInternalError(err.Error())
+		return nil, newWalletError(err)
 	}
 
 	return mkAddressResponse(f), nil
 }
 
+func handleExportKeystore(params []byte) (interface{}, *factom.JSONError) {
+	req := new(exportKeystoreRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	k, err := fctWallet.ExportKeystore(req.Address, req.Passphrase)
+	if err != nil {
+		return nil, newWalletError(err)
+	}
+	return k, nil
+}
+
+func handleImportKeystore(params []byte) (interface{}, *factom.JSONError) {
+	req := new(importKeystoreRequest)
+	if err := json.Unmarshal(params, req); err != nil || req.Keystore == nil {
+		return nil, newInvalidParamsError()
+	}
+
+	pub, err := fctWallet.ImportKeystore(req.Keystore, req.Passphrase)
+	if err == wallet.ErrIncorrectPassphrase {
+		return nil, newCustomInvalidParamsError(err.Error())
+	} else if err != nil {
+		return nil, newWalletError(err)
+	}
+	return &publicResponse{Public: pub}, nil
+}
+
 func handleWalletBackup(params []byte) (interface{}, *factom.JSONError) {
 	resp := new(walletBackupResponse)
 
@@ -662,10 +1040,25 @@ func handleWalletBackup(params []byte) (interface{}, *factom.JSONError) {
 		resp.IdentityKeys = append(resp.IdentityKeys, keyResp)
 	}
 
+	// the metadata lets a restore derive exactly the keys of this wallet
+	if resp.Metadata, err = fctWallet.GetMetadata(); err != nil {
+		return nil, newWalletError(err)
+	}
+
 	return resp, nil
 }
 
-func handleAllTransactions(params []byte) (interface{}, *factom.JSONError) {
+// handleRotateSeed replaces the wallet seed after a suspected exposure and
+// reports the replacement of every key derived from the old seed.
+func handleRotateSeed(params []byte) (interface{}, *factom.JSONError) {
+	r, err := fctWallet.RotateSeed()
+	if err != nil {
+		return nil, newWalletError(err)
+	}
+	return r, nil
+}
+
+func handleAllTransactions(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
 	if fctWallet.TXDB() == nil {
 		return nil, newCustomInternalError(
 			"Wallet does not have a transaction database")
@@ -694,7 +1087,7 @@ func handleAllTransactions(params []byte) (interface{}, *factom.JSONError) {
 			resp.Transactions = append(resp.Transactions, r)
 		}
 	case req.TxID != "":
-		p, err := factom.GetRaw(req.TxID)
+		p, err := factom.GetRawWithContext(ctx, req.TxID)
 		if err != nil {
 			return nil, newCustomInternalError(err.Error())
 		}
@@ -745,20 +1138,68 @@ func handleAllTransactions(params []byte) (interface{}, *factom.JSONError) {
 			resp.Transactions = append(resp.Transactions, r)
 		}
 	}
+	for _, r := range resp.Transactions {
+		addNote(r, r.TxID)
+	}
+
+	return resp, nil
+}
+
+// handleTransactionHistory brings the history of the wallet addresses up to
+// date and returns a page of it. A read only wallet returns what has been
+// recorded so far.
+func handleTransactionHistory(params []byte) (interface{}, *factom.JSONError) {
+	req := new(historyRequest)
+	if p := bytes.TrimSpace(params); len(p) > 0 && p[0] == '{' {
+		if err := json.Unmarshal(params, req); err != nil {
+			return nil, newInvalidParamsError()
+		}
+	}
+
+	if !fctWallet.ReadOnly() {
+		if _, err := fctWallet.IndexHistory(); err != nil {
+			return nil, newWalletError(err)
+		}
+	}
+
+	f := wallet.HistoryFilter{Address: req.Address, Offset: req.Offset, Limit: req.Limit}
+	if req.Since != 0 {
+		f.Since = time.Unix(req.Since, 0)
+	}
+	if req.Until != 0 {
+		f.Until = time.Unix(req.Until, 0)
+	}
+	hs, total, err := fctWallet.GetHistory(f)
+	if err != nil {
+		return nil, newWalletError(err)
+	}
 
+	resp := &historyResponse{Transactions: []*factom.Transaction{}, Total: total}
+	for _, h := range hs {
+		tx, err := h.Transaction()
+		if err != nil {
+			return nil, newCustomInternalError(err.Error())
+		}
+		r, err := factoidTxToTransaction(tx)
+		if err != nil {
+			return nil, newCustomInternalError(err.Error())
+		}
+		addNote(r, r.TxID)
+		resp.Transactions = append(resp.Transactions, r)
+	}
 	return resp, nil
 }
 
 // transaction handlers
 
-func handleNewTransaction(params []byte) (interface{}, *factom.JSONError) {
+func handleNewTransaction(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
 	req := new(transactionRequest)
 	if err := json.Unmarshal(params, req); err != nil {
 		return nil, newInvalidParamsError()
 	}
 
 	if err := fctWallet.NewTransaction(req.Name); err != nil {
-		return nil, newCustomInternalError(err.Error())
+		return nil, newWalletError(err)
 	}
 
 	tx := fctWallet.GetTransactions()[req.Name]
@@ -767,7 +1208,7 @@ func handleNewTransaction(params []byte) (interface{}, *factom.JSONError) {
 		return nil, newCustomInternalError(err.Error())
 	}
 	resp.Name = req.Name
-	resp.FeesRequired = feesRequired(tx)
+	resp.FeesRequired = feesRequired(ctx, tx)
 
 	return resp, nil
 }
@@ -779,23 +1220,96 @@ func handleDeleteTransaction(params []byte) (interface{}, *factom.JSONError) {
 	}
 
 	if err := fctWallet.DeleteTransaction(req.Name); err != nil {
-		return nil, newCustomInternalError(err.Error())
+		return nil, newWalletError(err)
 	}
 	resp := &factom.Transaction{Name: req.Name}
 	return resp, nil
 }
 
-func handleTmpTransactions(params []byte) (interface{}, *factom.JSONError) {
+func handleExportTransaction(params []byte) (interface{}, *factom.JSONError) {
+	req := new(transactionRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	blob, err := fctWallet.ExportTransaction(req.Name)
+	if err != nil {
+		return nil, newWalletError(err)
+	}
+	return &exportedTransaction{Name: req.Name, Transaction: blob}, nil
+}
+
+func handleImportTransaction(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
+	req := new(exportedTransaction)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	if err := fctWallet.ImportTransaction(req.Name, req.Transaction); err != nil {
+		return nil, newWalletError(err)
+	}
+	tx := fctWallet.GetTransactions()[req.Name]
+	resp, err := factoidTxToTransaction(tx)
+	if err != nil {
+		return nil, newCustomInternalError(err.Error())
+	}
+	resp.Name = req.Name
+	resp.FeesRequired = feesRequired(ctx, tx)
+
+	return resp, nil
+}
+
+func handleSetTransactionNote(params []byte) (interface{}, *factom.JSONError) {
+	req := new(transactionNote)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	ref := req.Name
+	if req.TxID != "" {
+		ref = req.TxID
+	}
+	if err := fctWallet.SetTransactionNote(ref, req.Memo, req.Tags); err != nil {
+		return nil, newWalletError(err)
+	}
+	return req, nil
+}
+
+// addNote sets the memo and tags of r from the wallet note for ref, the name
+// or the id of the transaction.
+func addNote(r *factom.Transaction, ref string) {
+	if n, err := fctWallet.GetTransactionNote(ref); err == nil && n != nil {
+		r.Memo, r.Tags = n.Memo, n.Tags
+	}
+}
+
+func handleTmpTransactions(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
 	resp := new(multiTransactionResponse)
 	txs := fctWallet.GetTransactions()
 
-	for name, tx := range txs {
+	names := make([]string, 0, len(txs))
+	for name := range txs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// one rate for the whole list rather than a factomd call per transaction
+	rate, err := factom.GetRateWithContext(ctx)
+	if err != nil {
+		rate = 0
+	}
+
+	for _, name := range names {
+		tx := txs[name]
 		r, err := factoidTxToTransaction(tx)
 		if err != nil {
 			continue
 		}
 		r.Name = name
-		r.FeesRequired = feesRequired(tx)
+		r.FeesRequired = feesRequiredAt(tx, rate)
+		r.PendingApproval, _ = fctWallet.NeedsApproval(name)
+		r.LastModified, _ = fctWallet.TransactionModified(name)
+		addNote(r, name)
 		resp.Transactions = append(resp.Transactions, r)
 	}
 
@@ -822,150 +1336,420 @@ func handleTransactionHash(params []byte) (interface{}, *factom.JSONError) {
 	return nil, newCustomInternalError("Transaction not found")
 }
 
-func handleAddInput(params []byte) (interface{}, *factom.JSONError) {
+func handleAddInput(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
 	req := new(transactionValueRequest)
 	if err := json.Unmarshal(params, req); err != nil {
 		return nil, newInvalidParamsError()
 	}
 
-	if err := fctWallet.AddInput(req.Name, req.Address, req.Amount); err != nil {
+	if req.AutoFund != "" {
+		strategy := wallet.SelectionStrategy(req.AutoFund)
+		if err := fctWallet.FundTransaction(req.Name, strategy); err != nil {
+			return nil, newWalletError(err)
+		}
+	} else {
+		n, jsonError := req.Amount.Factoshis(ctx, false)
+		if jsonError != nil {
+			return nil, jsonError
+		}
+		if err := fctWallet.AddInput(req.Name, req.Address, n); err != nil {
+			return nil, newWalletError(err)
+		}
+	}
+	tx := fctWallet.GetTransactions()[req.Name]
+	resp, err := factoidTxToTransaction(tx)
+	if err != nil {
 		return nil, newCustomInternalError(err.Error())
 	}
+	resp.Name = req.Name
+	resp.FeesRequired = feesRequired(ctx, tx)
+
+	return resp, nil
+}
+
+func handleAddOutput(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
+	req := new(transactionValueRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	// the output may name a contact from the address book
+	addr, err := fctWallet.ResolveAddress(req.Address)
+	if err != nil {
+		return nil, newWalletError(err)
+	}
+	n, jsonError := req.Amount.Factoshis(ctx, false)
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	if err := fctWallet.AddOutput(req.Name, addr, n); err != nil {
+		return nil, newWalletError(err)
+	}
+	tx := fctWallet.GetTransactions()[req.Name]
+	resp, err := factoidTxToTransaction(tx)
+	if err != nil {
+		return nil, newCustomInternalError(err.Error())
+	}
+	resp.Name = req.Name
+	resp.FeesRequired = feesRequired(ctx, tx)
+
+	return resp, nil
+}
+
+func handleAddOutputs(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
+	req := new(transactionOutputsRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	// every output may name a contact from the address book
+	outputs := make([]*factom.TransAddress, len(req.Outputs))
+	for i, out := range req.Outputs {
+		if out == nil {
+			return nil, newInvalidParamsError()
+		}
+		addr, err := fctWallet.ResolveAddress(out.Address)
+		if err != nil {
+			return nil, newWalletError(err)
+		}
+		n, jsonError := out.Amount.Factoshis(ctx, false)
+		if jsonError != nil {
+			return nil, jsonError
+		}
+		outputs[i] = &factom.TransAddress{Address: addr, Amount: n}
+	}
+	if err := fctWallet.AddOutputs(req.Name, outputs); err != nil {
+		return nil, newWalletError(err)
+	}
 	tx := fctWallet.GetTransactions()[req.Name]
 	resp, err := factoidTxToTransaction(tx)
 	if err != nil {
 		return nil, newCustomInternalError(err.Error())
 	}
 	resp.Name = req.Name
-	resp.FeesRequired = feesRequired(tx)
+	resp.FeesRequired = feesRequired(ctx, tx)
 
 	return resp, nil
 }
 
-func handleAddOutput(params []byte) (interface{}, *factom.JSONError) {
+func handleAddECOutput(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
 	req := new(transactionValueRequest)
 	if err := json.Unmarshal(params, req); err != nil {
 		return nil, newInvalidParamsError()
 	}
 
-	if err := fctWallet.AddOutput(req.Name, req.Address, req.Amount); err != nil {
+	addr, err := fctWallet.ResolveAddress(req.Address)
+	if err != nil {
+		return nil, newWalletError(err)
+	}
+	n, jsonError := req.Amount.Factoshis(ctx, true)
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	if err := fctWallet.AddECOutput(req.Name, addr, n); err != nil {
+		return nil, newWalletError(err)
+	}
+	tx := fctWallet.GetTransactions()[req.Name]
+	resp, err := factoidTxToTransaction(tx)
+	if err != nil {
+		return nil, newCustomInternalError(err.Error())
+	}
+	resp.Name = req.Name
+	resp.FeesRequired = feesRequired(ctx, tx)
+
+	return resp, nil
+}
+
+func handleRemoveInput(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
+	return removeFromTransaction(ctx, params, fctWallet.RemoveInput)
+}
+
+func handleRemoveOutput(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
+	return removeFromTransaction(ctx, params, fctWallet.RemoveOutput)
+}
+
+func handleRemoveECOutput(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
+	return removeFromTransaction(ctx, params, fctWallet.RemoveECOutput)
+}
+
+// removeFromTransaction removes the address in params from a tmp transaction
+// with remove.
+func removeFromTransaction(ctx context.Context, params []byte, remove func(name, address string) error) (interface{}, *factom.JSONError) {
+	req := new(transactionAddressRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	if err := remove(req.Name, req.Address); err != nil {
+		return nil, newWalletError(err)
+	}
+	tx := fctWallet.GetTransactions()[req.Name]
+	resp, err := factoidTxToTransaction(tx)
+	if err != nil {
+		return nil, newCustomInternalError(err.Error())
+	}
+	resp.Name = req.Name
+	resp.FeesRequired = feesRequired(ctx, tx)
+
+	return resp, nil
+}
+
+func handleAddFee(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
+	req := new(transactionAddressRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	rate, err := factom.GetRateWithContext(ctx)
+	if err != nil {
+		factomdFailed("add-fee")
 		return nil, newCustomInternalError(err.Error())
 	}
+	if err := fctWallet.AddFee(req.Name, req.Address, rate); err != nil {
+		return nil, newWalletError(err)
+	}
 	tx := fctWallet.GetTransactions()[req.Name]
 	resp, err := factoidTxToTransaction(tx)
 	if err != nil {
 		return nil, newCustomInternalError(err.Error())
 	}
-	resp.Name = req.Name
-	resp.FeesRequired = feesRequired(tx)
+	resp.Name = req.Name
+	resp.FeesRequired = feesRequired(ctx, tx)
+
+	return resp, nil
+}
+
+func handleSubFee(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
+	req := new(transactionAddressRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	rate, err := factom.GetRateWithContext(ctx)
+	if err != nil {
+		factomdFailed("sub-fee")
+		return nil, newCustomInternalError(err.Error())
+	}
+	if req.Auto {
+		if _, err := fctWallet.SubFeeAuto(req.Name, rate); err != nil {
+			return nil, newWalletError(err)
+		}
+	} else if err := fctWallet.SubFee(req.Name, req.Address, rate); err != nil {
+		return nil, newWalletError(err)
+	}
+	tx := fctWallet.GetTransactions()[req.Name]
+	resp, err := factoidTxToTransaction(tx)
+	if err != nil {
+		return nil, newCustomInternalError(err.Error())
+	}
+	resp.Name = req.Name
+	resp.FeesRequired = feesRequired(ctx, tx)
+
+	return resp, nil
+}
+
+func handleSignTransaction(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
+	req := new(transactionRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	force := req.Force
+
+	if jsonError := checkApprover(ctx, req); jsonError != nil {
+		return nil, jsonError
+	}
+
+	if req.Transaction != "" {
+		signed, err := fctWallet.SignExportedTransaction(req.Transaction, force)
+		if err != nil {
+			return nil, newWalletError(err)
+		}
+		return &exportedTransaction{Transaction: signed}, nil
+	}
+
+	if err := fctWallet.SignTransaction(req.Name, force); err != nil {
+		return nil, newWalletError(err)
+	}
+	tx := fctWallet.GetTransactions()[req.Name]
+	resp, err := factoidTxToTransaction(tx)
+	if err != nil {
+		return nil, newCustomInternalError(err.Error())
+	}
+	resp.Name = req.Name
+	resp.FeesRequired = feesRequired(ctx, tx)
+
+	return resp, nil
+}
+
+// checkApprover refuses to sign a transaction with the credential that
+// approved it, so that an approval always comes from a second credential.
+func checkApprover(ctx context.Context, req *transactionRequest) *factom.JSONError {
+	var sighash string
+	if req.Transaction != "" {
+		h, err := wallet.ExportedSigHash(req.Transaction)
+		if err != nil {
+			return newWalletError(err)
+		}
+		sighash = h
+	} else if tx, ok := fctWallet.GetTransactions()[req.Name]; ok {
+		sighash = tx.GetSigHash().String()
+	} else {
+		return nil
+	}
+	if approver, ok := fctWallet.Approver(sighash); ok && approver == caller(ctx) {
+		return newPermissionDeniedError()
+	}
+	return nil
+}
+
+// handleApproveTransaction approves a tmp transaction, or an exported
+// transaction, above the approval threshold so that sign-transaction will
+// sign it for any credential but the approving one.
+func handleApproveTransaction(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
+	req := new(transactionRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	if req.Transaction != "" {
+		if err := fctWallet.ApproveExportedTransaction(req.Transaction, caller(ctx)); err != nil {
+			return nil, newWalletError(err)
+		}
+		return &exportedTransaction{Transaction: req.Transaction}, nil
+	}
+
+	if err := fctWallet.ApproveTransactionAs(req.Name, caller(ctx)); err != nil {
+		return nil, newWalletError(err)
+	}
+	tx := fctWallet.GetTransactions()[req.Name]
+	resp, err := factoidTxToTransaction(tx)
+	if err != nil {
+		return nil, newCustomInternalError(err.Error())
+	}
+	resp.Name = req.Name
+	resp.FeesRequired = feesRequired(ctx, tx)
+
+	return resp, nil
+}
+
+func handleComposeTransaction(params []byte) (interface{}, *factom.JSONError) {
+	req := new(transactionRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	t, err := fctWallet.ComposeTransaction(req.Name)
+	if err != nil {
+		return nil, newWalletError(err)
+	}
+	return t, nil
+}
+
+// handleSendTransaction submits a signed tmp transaction to factomd and
+// returns its id once factomd has acknowledged it.
+func handleSendTransaction(params []byte) (interface{}, *factom.JSONError) {
+	req := new(transactionRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
 
-	return resp, nil
+	txid, err := fctWallet.SendTransaction(req.Name)
+	if errors.Is(err, wallet.ErrTXNotAcked) {
+		// the transaction was submitted, so its id is still of use
+		return nil, newCustomInternalError(fmt.Sprintf("%s: %s", err, txid))
+	} else if err != nil {
+		return nil, newWalletError(err)
+	}
+	return &factom.Transaction{Name: req.Name, TxID: txid}, nil
 }
 
-func handleAddECOutput(params []byte) (interface{}, *factom.JSONError) {
-	req := new(transactionValueRequest)
+// handleSendFactoid builds, signs and sends a payment from one wallet address
+// in a single call.
+func handleSendFactoid(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
+	req := new(sendFactoidRequest)
 	if err := json.Unmarshal(params, req); err != nil {
 		return nil, newInvalidParamsError()
 	}
 
-	if err := fctWallet.AddECOutput(req.Name, req.Address, req.Amount); err != nil {
-		return nil, newCustomInternalError(err.Error())
+	n, jsonError := req.Amount.Factoshis(ctx, false)
+	if jsonError != nil {
+		return nil, jsonError
 	}
-	tx := fctWallet.GetTransactions()[req.Name]
-	resp, err := factoidTxToTransaction(tx)
-	if err != nil {
-		return nil, newCustomInternalError(err.Error())
+	txid, err := fctWallet.SendFactoid(req.From, req.To, n, req.Force)
+	if errors.Is(err, wallet.ErrTXNotAcked) {
+		return nil, newCustomInternalError(fmt.Sprintf("%s: %s", err, txid))
+	} else if err != nil {
+		return nil, newWalletError(err)
 	}
-	resp.Name = req.Name
-	resp.FeesRequired = feesRequired(tx)
-
-	return resp, nil
+	return &factom.Transaction{TxID: txid}, nil
 }
 
-func handleAddFee(params []byte) (interface{}, *factom.JSONError) {
-	req := new(transactionAddressRequest)
+// handleBuyEC converts factoids to entry credits in a single call and returns
+// the entry credits added to the balance of the Entry Credit address.
+func handleBuyEC(params []byte) (interface{}, *factom.JSONError) {
+	req := new(buyECRequest)
 	if err := json.Unmarshal(params, req); err != nil {
 		return nil, newInvalidParamsError()
 	}
 
-	rate, err := factom.GetRate()
-	if err != nil {
-		return nil, newCustomInternalError(err.Error())
-	}
-	if err := fctWallet.AddFee(req.Name, req.Address, rate); err != nil {
-		return nil, newCustomInternalError(err.Error())
-	}
-	tx := fctWallet.GetTransactions()[req.Name]
-	resp, err := factoidTxToTransaction(tx)
-	if err != nil {
-		return nil, newCustomInternalError(err.Error())
+	p, err := fctWallet.BuyEC(req.From, req.To, req.ECs, req.Force)
+	if errors.Is(err, wallet.ErrTXNotAcked) {
+		return nil, newCustomInternalError(fmt.Sprintf("%s: %s", err, p.TxID))
+	} else if err != nil {
+		return nil, newWalletError(err)
 	}
-	resp.Name = req.Name
-	resp.FeesRequired = feesRequired(tx)
-
-	return resp, nil
+	return p, nil
 }
 
-func handleSubFee(params []byte) (interface{}, *factom.JSONError) {
-	req := new(transactionAddressRequest)
+// handleSimulateFees returns the fee a transaction needs at the current entry
+// credit rate and at each of the requested rates.
+func handleSimulateFees(params []byte) (interface{}, *factom.JSONError) {
+	req := new(simulateFeesRequest)
 	if err := json.Unmarshal(params, req); err != nil {
 		return nil, newInvalidParamsError()
 	}
 
-	rate, err := factom.GetRate()
-	if err != nil {
-		return nil, newCustomInternalError(err.Error())
-	}
-	if err := fctWallet.SubFee(req.Name, req.Address, rate); err != nil {
-		return nil, newCustomInternalError(err.Error())
-	}
-	tx := fctWallet.GetTransactions()[req.Name]
-	resp, err := factoidTxToTransaction(tx)
+	fees, err := fctWallet.SimulateFees(req.Name, req.Rates...)
 	if err != nil {
-		return nil, newCustomInternalError(err.Error())
+		return nil, newWalletError(err)
 	}
-	resp.Name = req.Name
-	resp.FeesRequired = feesRequired(tx)
 
+	resp := new(simulateFeesResponse)
+	resp.Name = req.Name
+	resp.Fees = fees
 	return resp, nil
 }
 
-func handleSignTransaction(params []byte) (interface{}, *factom.JSONError) {
+// handleEstimateFee returns the fee a transaction needs at the current entry
+// credit rate without changing it.
+func handleEstimateFee(params []byte) (interface{}, *factom.JSONError) {
 	req := new(transactionRequest)
 	if err := json.Unmarshal(params, req); err != nil {
 		return nil, newInvalidParamsError()
 	}
 
-	force := req.Force
-
-	if err := fctWallet.SignTransaction(req.Name, force); err != nil {
-		return nil, newCustomInternalError(err.Error())
-	}
-	tx := fctWallet.GetTransactions()[req.Name]
-	resp, err := factoidTxToTransaction(tx)
+	fee, err := fctWallet.EstimateFee(req.Name)
 	if err != nil {
-		return nil, newCustomInternalError(err.Error())
+		return nil, newWalletError(err)
 	}
-	resp.Name = req.Name
-	resp.FeesRequired = feesRequired(tx)
-
-	return resp, nil
+	return &estimateFeeResponse{Name: req.Name, Rate: fee.Rate, Fee: fee.Fee}, nil
 }
 
-func handleComposeTransaction(params []byte) (interface{}, *factom.JSONError) {
+func handlePreviewTransaction(params []byte) (interface{}, *factom.JSONError) {
 	req := new(transactionRequest)
 	if err := json.Unmarshal(params, req); err != nil {
 		return nil, newInvalidParamsError()
 	}
 
-	t, err := fctWallet.ComposeTransaction(req.Name)
+	p, err := fctWallet.PreviewTransaction(req.Name)
 	if err != nil {
-		return nil, newCustomInternalError(err.Error())
+		return nil, newWalletError(err)
 	}
-	return t, nil
+	return p, nil
 }
 
-func handleComposeChain(params []byte) (interface{}, *factom.JSONError) {
+func handleComposeChain(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
 	req := new(chainRequest)
 	if err := json.Unmarshal(params, req); err != nil {
 		return nil, newInvalidParamsError()
@@ -975,17 +1759,13 @@ func handleComposeChain(params []byte) (interface{}, *factom.JSONError) {
 	ecpub := req.ECPub
 	force := req.Force
 
-	ec, err := fctWallet.GetECAddress(ecpub)
-	if err != nil {
+	if _, err := fctWallet.PublicKey(ecpub); err != nil {
 		return nil, newCustomInternalError(err.Error())
 	}
-	if ec == nil {
-		return nil, newCustomInternalError("Wallet: address not found")
-	}
 
 	if !force {
 		// check ec address balance
-		balance, err := factom.GetECBalance(ecpub)
+		balance, err := factom.GetECBalanceWithContext(ctx, ecpub)
 		if err != nil {
 			return nil, newCustomInternalError(err.Error())
 		}
@@ -996,12 +1776,13 @@ func handleComposeChain(params []byte) (interface{}, *factom.JSONError) {
 			return nil, newCustomInternalError("Not enough Entry Credits")
 		}
 
-		if factom.ChainExists(c.ChainID) {
+		if factom.ChainExistsWithContext(ctx, c.ChainID) {
 			return nil, newCustomInvalidParamsError("Chain " + c.ChainID + " already exists")
 		}
 	}
 
-	commit, err := factom.ComposeChainCommit(c, ec)
+	// the commit is signed by the wallet or its remote signer
+	commit, err := fctWallet.ComposeChainCommit(c, ecpub)
 	if err != nil {
 		return nil, newCustomInternalError(err.Error())
 	}
@@ -1017,7 +1798,7 @@ func handleComposeChain(params []byte) (interface{}, *factom.JSONError) {
 	return resp, nil
 }
 
-func handleComposeEntry(params []byte) (interface{}, *factom.JSONError) {
+func handleComposeEntry(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
 	req := new(entryRequest)
 	if err := json.Unmarshal(params, req); err != nil {
 		return nil, newInvalidParamsError()
@@ -1027,16 +1808,13 @@ func handleComposeEntry(params []byte) (interface{}, *factom.JSONError) {
 	ecpub := req.ECPub
 	force := req.Force
 
-	ec, err := fctWallet.GetECAddress(ecpub)
-	if err != nil {
+	if _, err := fctWallet.PublicKey(ecpub); err != nil {
 		return nil, newCustomInternalError(err.Error())
 	}
-	if ec == nil {
-		return nil, newCustomInternalError("Wallet: address not found")
-	}
+
 	if !force {
 		// check ec address balance
-		balance, err := factom.GetECBalance(ecpub)
+		balance, err := factom.GetECBalanceWithContext(ctx, ecpub)
 		if err != nil {
 			return nil, newCustomInternalError(err.Error())
 		}
@@ -1047,12 +1825,13 @@ func handleComposeEntry(params []byte) (interface{}, *factom.JSONError) {
 			newCustomInternalError("Not enough Entry Credits")
 		}
 
-		if !factom.ChainExists(e.ChainID) {
+		if !factom.ChainExistsWithContext(ctx, e.ChainID) {
 			return nil, newCustomInvalidParamsError("Chain " + e.ChainID + " was not found")
 		}
 	}
 
-	commit, err := factom.ComposeEntryCommit(&e, ec)
+	// the commit is signed by the wallet or its remote signer
+	commit, err := fctWallet.ComposeEntryCommit(&e, ecpub)
 	if err != nil {
 		return nil, newCustomInternalError(err.Error())
 	}
@@ -1068,10 +1847,20 @@ func handleComposeEntry(params []byte) (interface{}, *factom.JSONError) {
 	return resp, nil
 }
 
-func handleProperties(params []byte) (interface{}, *factom.JSONError) {
+func handleProperties(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
 	props := new(propertiesResponse)
 	props.WalletVersion = fctWallet.GetVersion()
 	props.WalletApiVersion = fctWallet.GetApiVersion()
+	props.Storage = fctWallet.Storage()
+	props.Locked = fctWallet.IsLocked()
+	props.ReadOnly = fctWallet.ReadOnly()
+
+	// health checks want to know if the wallet can reach factomd
+	if _, err := factom.GetHeightsWithContext(ctx); err != nil {
+		props.FactomdError = err.Error()
+	} else {
+		props.FactomdReachable = true
+	}
 	return props, nil
 }
 
@@ -1170,7 +1959,7 @@ func handleRemoveIdentityKey(params []byte) (interface{}, *factom.JSONError) {
 		return nil, newInvalidParamsError()
 	}
 
-	err := fctWallet.WalletDatabaseOverlay.RemoveIdentityKey(req.Public)
+	err := fctWallet.RemoveIdentityKey(req.Public)
 	if err != nil {
 		return nil, newCustomInternalError(err.Error())
 	}
@@ -1181,7 +1970,7 @@ func handleRemoveIdentityKey(params []byte) (interface{}, *factom.JSONError) {
 	return resp, nil
 }
 
-func handleActiveIdentityKeys(params []byte) (interface{}, *factom.JSONError) {
+func handleActiveIdentityKeys(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
 	req := new(activeIdentityKeysRequest)
 	if err := json.Unmarshal(params, req); err != nil {
 		return nil, newInvalidParamsError()
@@ -1191,7 +1980,7 @@ func handleActiveIdentityKeys(params []byte) (interface{}, *factom.JSONError) {
 	resp.ChainID = req.ChainID
 
 	if req.Height == nil {
-		keys, currentHeight, err := factom.GetActiveIdentityKeys(req.ChainID)
+		keys, currentHeight, err := factom.GetActiveIdentityKeysWithContext(ctx, req.ChainID)
 		if err != nil {
 			return nil, newCustomInternalError(fmt.Sprintf("ActiveIdentityKeys: %s", err.Error()))
 		}
@@ -1200,7 +1989,7 @@ func handleActiveIdentityKeys(params []byte) (interface{}, *factom.JSONError) {
 		return resp, nil
 	}
 
-	keys, err := factom.GetActiveIdentityKeysAtHeight(req.ChainID, *req.Height)
+	keys, err := factom.GetActiveIdentityKeysAtHeightWithContext(ctx, req.ChainID, *req.Height)
 	if err != nil {
 		return nil, newCustomInternalError(fmt.Sprintf("ActiveIdentityKeys: %s", err.Error()))
 	}
@@ -1209,7 +1998,7 @@ func handleActiveIdentityKeys(params []byte) (interface{}, *factom.JSONError) {
 	return resp, nil
 }
 
-func handleComposeIdentityChain(params []byte) (interface{}, *factom.JSONError) {
+func handleComposeIdentityChain(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
 	req := new(identityChainRequest)
 	if err := json.Unmarshal(params, req); err != nil {
 		return nil, newInvalidParamsError()
@@ -1230,7 +2019,7 @@ func handleComposeIdentityChain(params []byte) (interface{}, *factom.JSONError)
 	}
 	if !req.Force {
 		// check ec address balance
-		balance, err := factom.GetECBalance(ecpub)
+		balance, err := factom.GetECBalanceWithContext(ctx, ecpub)
 		if err != nil {
 			return nil, newCustomInternalError(err.Error())
 		}
@@ -1241,7 +2030,7 @@ func handleComposeIdentityChain(params []byte) (interface{}, *factom.JSONError)
 			return nil, newCustomInternalError("Not enough Entry Credits")
 		}
 
-		if factom.ChainExists(c.ChainID) {
+		if factom.ChainExistsWithContext(ctx, c.ChainID) {
 			return nil, newCustomInvalidParamsError("Chain " + c.ChainID + " already exists")
 		}
 	}
@@ -1262,7 +2051,7 @@ func handleComposeIdentityChain(params []byte) (interface{}, *factom.JSONError)
 	return resp, nil
 }
 
-func handleComposeIdentityKeyReplacement(params []byte) (interface{}, *factom.JSONError) {
+func handleComposeIdentityKeyReplacement(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
 	req := new(identityKeyReplacementRequest)
 	if err := json.Unmarshal(params, req); err != nil {
 		return nil, newInvalidParamsError()
@@ -1288,7 +2077,7 @@ func handleComposeIdentityKeyReplacement(params []byte) (interface{}, *factom.JS
 	}
 	if !req.Force {
 		// check ec address balance
-		balance, err := factom.GetECBalance(ecpub)
+		balance, err := factom.GetECBalanceWithContext(ctx, ecpub)
 		if err != nil {
 			return nil, newCustomInternalError(err.Error())
 		}
@@ -1299,7 +2088,7 @@ func handleComposeIdentityKeyReplacement(params []byte) (interface{}, *factom.JS
 			newCustomInternalError("Not enough Entry Credits")
 		}
 
-		if !factom.ChainExists(e.ChainID) {
+		if !factom.ChainExistsWithContext(ctx, e.ChainID) {
 			return nil, newCustomInvalidParamsError("Chain " + e.ChainID + " was not found")
 		}
 	}
@@ -1320,7 +2109,7 @@ func handleComposeIdentityKeyReplacement(params []byte) (interface{}, *factom.JS
 	return resp, nil
 }
 
-func handleComposeIdentityAttribute(params []byte) (interface{}, *factom.JSONError) {
+func handleComposeIdentityAttribute(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
 	req := new(identityAttributeRequest)
 	if err := json.Unmarshal(params, req); err != nil {
 		return nil, newInvalidParamsError()
@@ -1357,7 +2146,7 @@ func handleComposeIdentityAttribute(params []byte) (interface{}, *factom.JSONErr
 	}
 	if !force {
 		// check ec address balance
-		balance, err := factom.GetECBalance(ecpub)
+		balance, err := factom.GetECBalanceWithContext(ctx, ecpub)
 		if err != nil {
 			return nil, newCustomInternalError(err.Error())
 		}
@@ -1368,7 +2157,7 @@ func handleComposeIdentityAttribute(params []byte) (interface{}, *factom.JSONErr
 			newCustomInternalError("Not enough Entry Credits")
 		}
 
-		if !factom.ChainExists(e.ChainID) {
+		if !factom.ChainExistsWithContext(ctx, e.ChainID) {
 			return nil, newCustomInvalidParamsError("Chain " + e.ChainID + " was not found")
 		}
 	}
@@ -1389,7 +2178,7 @@ func handleComposeIdentityAttribute(params []byte) (interface{}, *factom.JSONErr
 	return resp, nil
 }
 
-func handleComposeIdentityAttributeEndorsement(params []byte) (interface{}, *factom.JSONError) {
+func handleComposeIdentityAttributeEndorsement(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
 	req := new(identityAttributeEndorsementRequest)
 	if err := json.Unmarshal(params, req); err != nil {
 		return nil, newInvalidParamsError()
@@ -1413,7 +2202,7 @@ func handleComposeIdentityAttributeEndorsement(params []byte) (interface{}, *fac
 	}
 	if !force {
 		// check ec address balance
-		balance, err := factom.GetECBalance(ecpub)
+		balance, err := factom.GetECBalanceWithContext(ctx, ecpub)
 		if err != nil {
 			return nil, newCustomInternalError(err.Error())
 		}
@@ -1424,7 +2213,7 @@ func handleComposeIdentityAttributeEndorsement(params []byte) (interface{}, *fac
 			newCustomInternalError("Not enough Entry Credits")
 		}
 
-		if !factom.ChainExists(e.ChainID) {
+		if !factom.ChainExistsWithContext(ctx, e.ChainID) {
 			return nil, newCustomInvalidParamsError("Chain " + e.ChainID + " was not found")
 		}
 	}
@@ -1456,32 +2245,322 @@ func handleWalletPassphrase(params []byte) (interface{}, *factom.JSONError) {
 		req.Timeout = 1073741824
 	}
 
-	// If this isn't the first time booting an encrypted wallet, we postpone creating the database until now
-	if fctWallet.WalletDatabaseOverlay == nil {
-		db, err := wallet.NewEncryptedBoltDB(fctWallet.DBPath, req.Password)
-		if err != nil {
-			return nil, newIncorrectPassphraseError()
-		}
-		fctWallet.WalletDatabaseOverlay = db
+	until, err := fctWallet.Unlock(req.Password, time.Second*time.Duration(req.Timeout))
+	if err == wallet.ErrIncorrectPassphrase {
+		return nil, newIncorrectPassphraseError()
+	} else if err == wallet.ErrNotEncrypted {
+		return nil, newCustomInternalError("Cannot unlock non-encrypted wallet. This database is always unlocked")
+	} else if err != nil {
+		return nil, newCustomInternalError(err.Error())
+	}
 
-		err = fctWallet.InitWallet()
-		if err != nil {
-			return nil, newCustomInternalError(err.Error())
-		}
-		fctWallet.DBO.DB.(*securedb.EncryptedDB).Lock()
+	return &unlockResponse{Success: true, UnlockedUntil: until.Unix()}, nil
+}
+
+// handleLockWallet locks an unlocked encrypted wallet before its timeout.
+func handleLockWallet(params []byte) (interface{}, *factom.JSONError) {
+	if err := fctWallet.Lock(); err == wallet.ErrNotEncrypted {
+		return nil, newCustomInternalError("Cannot lock non-encrypted wallet. This database is always unlocked")
+	} else if err != nil {
+		return nil, newWalletError(err)
 	}
 
-	encdb, ok := fctWallet.DBO.DB.(*securedb.EncryptedDB)
-	if !ok {
-		return nil, newCustomInternalError("Cannot unlock non-encrypted wallet. This database is always unlocked")
+	resp := new(simpleResponse)
+	resp.Success = true
+	return resp, nil
+}
+
+// handleEncryptWallet encrypts an unencrypted wallet with a passphrase. The
+// wallet is locked afterwards and must be unlocked with unlock-wallet.
+func handleEncryptWallet(params []byte) (interface{}, *factom.JSONError) {
+	req := new(passphraseRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
 	}
 
-	err := encdb.UnlockFor(req.Password, time.Second*time.Duration(req.Timeout))
-	if err != nil {
+	if err := fctWallet.Encrypt(req.Password); err != nil {
+		return nil, newWalletError(err)
+	}
+
+	resp := new(simpleResponse)
+	resp.Success = true
+	return resp, nil
+}
+
+// handleChangePassphrase re-encrypts an encrypted wallet with a new
+// passphrase. It may be used while the wallet is locked.
+func handleChangePassphrase(params []byte) (interface{}, *factom.JSONError) {
+	req := new(changePassphraseRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	err := fctWallet.Rekey(req.OldPassphrase, req.NewPassphrase)
+	if err == wallet.ErrIncorrectPassphrase {
 		return nil, newIncorrectPassphraseError()
+	} else if err == wallet.ErrNotEncrypted {
+		return nil, newCustomInternalError("Cannot change the passphrase of a non-encrypted wallet")
+	} else if err != nil {
+		return nil, newWalletError(err)
+	}
+
+	resp := new(simpleResponse)
+	resp.Success = true
+	return resp, nil
+}
+
+// handleSignData signs raw data with the key of a wallet address or identity
+// key, or with the remote signer of the wallet.
+func handleSignData(params []byte) (interface{}, *factom.JSONError) {
+	req := new(signDataRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+	data, err := hex.DecodeString(req.Data)
+	if err != nil {
+		return nil, newCustomInvalidParamsError("data must be hex encoded")
+	}
+
+	pub, err := fctWallet.PublicKey(req.Public)
+	if err != nil {
+		return nil, newWalletError(err)
+	}
+	sig, err := fctWallet.Sign(req.Public, data)
+	if err != nil {
+		return nil, newWalletError(err)
+	}
+
+	resp := new(signDataResponse)
+	resp.PublicKey = hex.EncodeToString(pub)
+	resp.Signature = hex.EncodeToString(sig)
+	return resp, nil
+}
+
+// Namespace handlers
+
+func handleSetNamespace(params []byte) (interface{}, *factom.JSONError) {
+	req := new(namespaceRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	if err := fctWallet.SetNamespace(req.Public, req.Namespace); err != nil {
+		return nil, newWalletError(err)
+	}
+
+	resp := new(simpleResponse)
+	resp.Success = true
+	return resp, nil
+}
+
+// handleNamespaces returns the namespace of every key that is not in the
+// default namespace.
+func handleNamespaces(params []byte) (interface{}, *factom.JSONError) {
+	ns, err := fctWallet.GetAllNamespaces()
+	if err != nil {
+		return nil, newWalletError(err)
+	}
+
+	resp := new(namespacesResponse)
+	resp.Namespaces = ns
+	return resp, nil
+}
+
+// Address label handlers
+
+func handleSetAddressLabel(params []byte) (interface{}, *factom.JSONError) {
+	req := new(addressLabelRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	if err := fctWallet.SetAddressLabel(req.Address, req.Label); err != nil {
+		return nil, newWalletError(err)
+	}
+
+	resp := new(simpleResponse)
+	resp.Success = true
+	return resp, nil
+}
+
+func handleGetAddressLabel(params []byte) (interface{}, *factom.JSONError) {
+	req := new(addressRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	l, err := fctWallet.AddressLabel(req.Address)
+	if err != nil {
+		return nil, newWalletError(err)
+	}
+
+	resp := new(addressLabelResponse)
+	resp.Address = req.Address
+	resp.Label = l
+	return resp, nil
+}
+
+// handleDerivationPaths returns the derivation path templates of the
+// addresses generated by the wallet.
+func handleDerivationPaths(params []byte) (interface{}, *factom.JSONError) {
+	fct, ec, err := fctWallet.DerivationPaths()
+	if err != nil {
+		return nil, newWalletError(err)
+	}
+
+	resp := new(derivationPathsResponse)
+	resp.FCTPath = fct
+	resp.ECPath = ec
+	return resp, nil
+}
+
+func handleSetDerivationPaths(params []byte) (interface{}, *factom.JSONError) {
+	req := new(derivationPathsRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	if err := fctWallet.SetDerivationPaths(req.FCTPath, req.ECPath); err != nil {
+		return nil, newWalletError(err)
+	}
+
+	resp := new(simpleResponse)
+	resp.Success = true
+	return resp, nil
+}
+
+// Address book handlers
+
+func handleAddContact(params []byte) (interface{}, *factom.JSONError) {
+	req := new(contactRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	if err := fctWallet.AddContact(req.Name, req.Address); err != nil {
+		return nil, newWalletError(err)
+	}
+
+	resp := new(simpleResponse)
+	resp.Success = true
+	return resp, nil
+}
+
+func handleListContacts(params []byte) (interface{}, *factom.JSONError) {
+	cs, err := fctWallet.GetAllContacts()
+	if err != nil {
+		return nil, newWalletError(err)
+	}
+
+	resp := new(contactsResponse)
+	resp.Contacts = make([]*contactResponse, 0, len(cs))
+	for _, c := range cs {
+		resp.Contacts = append(resp.Contacts, &contactResponse{Name: c.Name, Address: c.Address})
+	}
+	return resp, nil
+}
+
+func handleRemoveContact(params []byte) (interface{}, *factom.JSONError) {
+	req := new(contactRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	if err := fctWallet.RemoveContact(req.Name); err != nil {
+		return nil, newWalletError(err)
+	}
+
+	resp := new(simpleResponse)
+	resp.Success = true
+	return resp, nil
+}
+
+// Bookmark handlers
+
+// defaultBookmarkEntries is the number of entries returned by
+// bookmark-entries when no limit is given.
+const defaultBookmarkEntries = 10
+
+func handleAddBookmark(params []byte) (interface{}, *factom.JSONError) {
+	req := new(bookmarkRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	if err := fctWallet.AddBookmark(req.ChainID, req.Label); err != nil {
+		return nil, newWalletError(err)
+	}
+
+	resp := new(simpleResponse)
+	resp.Success = true
+	return resp, nil
+}
+
+func handleRemoveBookmark(params []byte) (interface{}, *factom.JSONError) {
+	req := new(bookmarkRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+
+	if err := fctWallet.RemoveBookmark(req.ChainID); err != nil {
+		return nil, newWalletError(err)
+	}
+
+	resp := new(simpleResponse)
+	resp.Success = true
+	return resp, nil
+}
+
+func handleBookmarks(params []byte) (interface{}, *factom.JSONError) {
+	bs, err := fctWallet.GetAllBookmarks()
+	if err != nil {
+		return nil, newWalletError(err)
+	}
+
+	resp := new(multiBookmarkResponse)
+	resp.Bookmarks = make([]*bookmarkResponse, 0, len(bs))
+	for _, b := range bs {
+		resp.Bookmarks = append(resp.Bookmarks, &bookmarkResponse{ChainID: b.ChainID, Label: b.Label})
+	}
+	return resp, nil
+}
+
+// handleBookmarkEntries returns the latest entries of a bookmarked chain,
+// newest first. The returned token may be passed back to read older entries.
+func handleBookmarkEntries(ctx context.Context, params []byte) (interface{}, *factom.JSONError) {
+	req := new(bookmarkEntriesRequest)
+	if err := json.Unmarshal(params, req); err != nil {
+		return nil, newInvalidParamsError()
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultBookmarkEntries
+	}
+
+	bs, err := fctWallet.GetAllBookmarks()
+	if err != nil {
+		return nil, newWalletError(err)
+	}
+	var bookmark *wallet.Bookmark
+	for _, b := range bs {
+		if b.ChainID == req.ChainID {
+			bookmark = b
+		}
+	}
+	if bookmark == nil {
+		return nil, newWalletError(wallet.ErrNoSuchBookmark)
+	}
+
+	es, next, err := factom.GetChainEntriesPageWithContext(ctx, req.ChainID, req.Token, req.Limit)
+	if err != nil {
+		return nil, newWalletError(err)
 	}
 
-	return &unlockResponse{Success: true, UnlockedUntil: encdb.UnlockedUntil.Unix()}, nil
+	resp := new(bookmarkEntriesResponse)
+	resp.ChainID = bookmark.ChainID
+	resp.Label = bookmark.Label
+	resp.Entries = es
+	resp.NextToken = next
+	return resp, nil
 }
 
 // utility functions
@@ -1563,12 +2642,17 @@ func factoidTxToTransaction(t interfaces.ITransaction) (
 	return r, nil
 }
 
-func feesRequired(t interfaces.ITransaction) uint64 {
-	rate, err := factom.GetRate()
+func feesRequired(ctx context.Context, t interfaces.ITransaction) uint64 {
+	rate, err := factom.GetRateWithContext(ctx)
 	if err != nil {
 		rate = 0
 	}
 
+	return feesRequiredAt(t, rate)
+}
+
+// feesRequiredAt is feesRequired for an EC rate already fetched from factomd.
+func feesRequiredAt(t interfaces.ITransaction, rate uint64) uint64 {
 	fee, err := t.CalculateFee(rate)
 	if err != nil {
 		return 0
@@ -0,0 +1,102 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package wsapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	"github.com/FactomProject/factom/wallet"
+)
+
+func TestGenerateCount(t *testing.T) {
+	for _, c := range []struct {
+		params string
+		count  int
+		valid  bool
+	}{
+		{``, 0, true},
+		{`[]`, 0, true},
+		{`{}`, 0, true},
+		{`{"count": 1}`, 1, true},
+		{`{"count": 10000}`, 10000, true},
+		{`{"count": 0}`, 0, false},
+		{`{"count": -1}`, 0, false},
+		{`{"count": 10001}`, 0, false},
+		{`{"count": "5"}`, 0, false},
+	} {
+		n, jsonError := generateCount([]byte(c.params))
+		if c.valid && (jsonError != nil || n != c.count) {
+			t.Errorf("%q gave %d, %v, want %d", c.params, n, jsonError, c.count)
+		}
+		if !c.valid && jsonError == nil {
+			t.Errorf("%q gave %d, want an error", c.params, n)
+		}
+	}
+}
+
+// propsBackend is a wallet that reports fixed properties. Its other methods
+// are not used by the tests.
+type propsBackend struct {
+	wallet.WalletBackend
+	locked bool
+}
+
+func (b *propsBackend) GetVersion() string    { return "2" }
+func (b *propsBackend) GetApiVersion() string { return "v2" }
+func (b *propsBackend) IsLocked() bool        { return b.locked }
+func (b *propsBackend) ReadOnly() bool        { return false }
+
+func (b *propsBackend) Storage() wallet.StorageInfo {
+	return wallet.StorageInfo{Type: "bolt", Path: "/tmp/wallet.db", Encrypted: true}
+}
+
+func TestHandleProperties(t *testing.T) {
+	b := new(propsBackend)
+	defer func(w wallet.WalletBackend) { fctWallet = w }(fctWallet)
+	fctWallet = b
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"jsonrpc": "2.0", "id": 0, "result": {"directoryblockheight": 10}}`)
+	}))
+	defer func(s string) { factom.SetFactomdServer(s) }(factom.FactomdServer())
+	factom.SetFactomdServer(ts.URL[7:])
+
+	properties := func() *propertiesResponse {
+		j := factom.NewJSON2Request("properties", 1, nil)
+		resp, jsonError := handleV2Request(context.Background(), j)
+		if jsonError != nil {
+			t.Fatal(jsonError)
+		}
+		props := new(propertiesResponse)
+		if err := json.Unmarshal(resp.JSONResult(), props); err != nil {
+			t.Fatal(err)
+		}
+		return props
+	}
+
+	props := properties()
+	want := wallet.StorageInfo{Type: "bolt", Path: "/tmp/wallet.db", Encrypted: true}
+	if props.WalletVersion != "2" || props.WalletApiVersion != "v2" || props.Storage != want {
+		t.Errorf("got properties %+v", props)
+	}
+	if props.Locked || props.ReadOnly || !props.FactomdReachable || props.FactomdError != "" {
+		t.Errorf("got health %+v", props)
+	}
+
+	// properties are served while the wallet is locked and report a factomd
+	// that can not be reached
+	b.locked = true
+	ts.Close()
+	props = properties()
+	if !props.Locked || props.FactomdReachable || props.FactomdError == "" {
+		t.Errorf("got health %+v with a locked wallet and no factomd", props)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"os"
 
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/FactomProject/factom/wallet"
@@ -246,7 +247,7 @@ func StartTestWallet() (chan int, error) {
 	go wsapi.Start(fctWallet, ":8089", *RpcConfig)
 	go func() {
 		<-done
-		wsapi.Stop()
+		wsapi.Shutdown(context.Background())
 		fctWallet.Close()
 		txdb.Close()
 	}()
@@ -20,6 +20,7 @@ type ackRequest struct {
 
 type addressRequest struct {
 	Address string `json:"address"`
+	Secrets bool   `json:"secrets,omitempty"`
 }
 
 type passphraseRequest struct {
@@ -83,17 +84,36 @@ type secretRequest struct {
 }
 
 type transactionRequest struct {
-	Name  string `json:"tx-name"`
-	Force bool   `json:"force"`
+	Name        string `json:"tx-name"`
+	Force       bool   `json:"force"`
+	Transaction string `json:"transaction,omitempty"`
 }
 
 type transactionValueRequest struct {
-	Name    string `json:"tx-name"`
-	Address string `json:"address"`
-	Amount  uint64 `json:"amount"`
+	Name     string `json:"tx-name"`
+	Address  string `json:"address"`
+	Amount   uint64 `json:"amount"`
+	AutoFund string `json:"auto-fund,omitempty"`
+}
+
+type exportedTransaction struct {
+	Name        string `json:"tx-name"`
+	Transaction string `json:"transaction"`
+}
+
+type transactionNoteRequest struct {
+	Name string            `json:"tx-name"`
+	Memo string            `json:"memo,omitempty"`
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+type transactionOutputsRequest struct {
+	Name    string          `json:"tx-name"`
+	Outputs []*TransAddress `json:"outputs"`
 }
 
 type transactionAddressRequest struct {
 	Name    string `json:"tx-name"`
 	Address string `json:"address"`
+	Auto    bool   `json:"auto,omitempty"`
 }